@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// runClean реализует команду clean: удаляет файлы ggconfig, которые не создает ни одна
+// директива //go:generate ggconfig под root - остатки удаленных директив, переименованных
+// пакетов и интерфейсов. Такие файлы ссылаются на исчезнувшие типы и ломают сборку
+// до того, как go generate успеет их перезаписать.
+func runClean(args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	dry := fs.Bool("dry-run", false, "list the stale files without removing them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig clean [--dry-run] [root]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+
+	directives, err := generator.WalkDirectives(root)
+	if err != nil {
+		return err
+	}
+	expected := map[string]bool{}
+	for _, d := range directives {
+		if !interfaceDeclared(d) {
+			fmt.Printf("⚠ interface %s is not declared in %s: its files are stale, update or remove the directive\n", d.Interface, d.Dir)
+			continue
+		}
+		files, err := directiveFiles(d)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			expected[f] = true
+		}
+	}
+
+	stale, err := findStaleFiles(root, expected)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		fmt.Println("✅ No stale generated files")
+		return nil
+	}
+	for _, path := range stale {
+		if *dry {
+			fmt.Printf("  - %s\n", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		fmt.Printf("  ✗ %s\n", path)
+	}
+	if *dry {
+		fmt.Printf("✅ %d stale generated files would be removed\n", len(stale))
+	} else {
+		fmt.Printf("✅ Removed %d stale generated files\n", len(stale))
+	}
+	return nil
+}
+
+// directiveFiles возвращает абсолютные пути файлов, которые директива создает в выходной директории
+func directiveFiles(d generator.Directive) ([]string, error) {
+	unique := d.Name
+	if unique == "" {
+		var err error
+		if unique, err = generator.PackageUniqueName(d.DirectiveDir, d.Dir); err != nil {
+			return nil, err
+		}
+	}
+	// Имена файлов строятся, как и имена типов, с --type-prefix и --type-suffix
+	unique = d.TypeName(unique)
+	out, err := filepath.Abs(filepath.Join(d.Dir, d.Output))
+	if err != nil {
+		return nil, err
+	}
+	name := unique + ".gen.go"
+	if d.OutFile != "" {
+		name = d.OutFile
+	}
+	files := []string{filepath.Join(out, name)}
+	if d.Registry {
+		files = append(files, filepath.Join(out, "registry.gen.go"))
+	}
+	if d.VendorRuntime {
+		files = append(files, filepath.Join(out, generator.VendoredRuntimeFile))
+	}
+	if d.Descriptor {
+		files = append(files, filepath.Join(out, unique+".descriptor.json"))
+	}
+	if d.DocExamples {
+		files = append(files, filepath.Join(out, unique+"_example_test.go"))
+	}
+	return files, nil
+}
+
+// interfaceDeclared сообщает, объявлен ли интерфейс директивы в ее пакете. Если файл пакета не
+// разбирается, интерфейс считается существующим: при сомнении файлы не удаляются
+func interfaceDeclared(d generator.Directive) bool {
+	name, _, _ := strings.Cut(d.Interface, "[")
+	files := []string{filepath.Join(d.Dir, d.SourceFile)}
+	if d.SourceFile == "" {
+		var err error
+		if files, err = filepath.Glob(filepath.Join(d.Dir, "*.go")); err != nil {
+			return true
+		}
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return true
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// findStaleFiles находит под root файлы ggconfig (Go файлы с заголовком generatedHeader и JSON
+// описания ключей), которых нет в expected. Файл с директивой ggconfig, который не разбирается,
+// - ошибка: директива без --interface в нем не была найдена, и ее файлы оказались бы лишними
+func findStaleFiles(root string, expected map[string]bool) ([]string, error) {
+	var stale []string
+	err := generator.WalkPackageDirs(root, func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			name := e.Name()
+			path := filepath.Join(dir, name)
+			var generated bool
+			switch {
+			case strings.HasSuffix(name, ".go"):
+				gen, lines, err := generator.ScanDirectives(path)
+				if err != nil {
+					return err
+				}
+				if len(lines) > 0 {
+					if _, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution); err != nil {
+						return fmt.Errorf("%s does not parse, fix it before clean: %w", path, err)
+					}
+				}
+				generated = gen
+			case strings.HasSuffix(name, ".descriptor.json"):
+				generated = isDescriptorFile(path)
+			}
+			if !generated {
+				continue
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if !expected[abs] {
+				stale = append(stale, path)
+			}
+		}
+		return nil
+	})
+	sort.Strings(stale)
+	return stale, err
+}
+
+// isDescriptorFile сообщает, что JSON файл - описание ключей из --descriptor
+func isDescriptorFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var d runtime.Descriptor
+	return json.Unmarshal(data, &d) == nil && d.Interface != "" && d.Keys != nil
+}
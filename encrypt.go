@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// runEncrypt реализует команду encrypt: значение шифруется ключом из GGCONFIG_DECRYPT_KEY
+// (или файла GGCONFIG_DECRYPT_KEY_FILE) и печатается в виде enc:AES256GCM:... для YAML.
+// Без аргумента значение читается из stdin, чтобы секрет не попадал в историю shell.
+func runEncrypt(args []string) error {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	generateKey := fs.Bool("generate-key", false, "print a new random key for "+runtime.DecryptKeyEnv+" and exit")
+	decrypt := fs.Bool("decrypt", false, "decrypt an "+runtime.EncryptedPrefix+"... value instead of encrypting")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig encrypt [--decrypt] [value]   (value is read from stdin if omitted)")
+		fmt.Fprintln(fs.Output(), "       ggconfig encrypt --generate-key")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *generateKey {
+		key, err := runtime.NewDecryptKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a single value")
+	}
+	value := fs.Arg(0)
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	key, err := runtime.DecryptKey()
+	if err != nil {
+		return err
+	}
+	var out string
+	if *decrypt {
+		out, err = runtime.DecryptValue(key, value)
+	} else {
+		out, err = runtime.EncryptValue(key, value)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
@@ -1,69 +1,128 @@
 // Code generated by ggconfig. DO NOT EDIT.
+// Content hash: sha256:d9f54b8ab00d623db3f632020467d62d069cb8eb623b926cd403a0824ab12bfa
 
 package db
 
 import (
-	
 	"os"
-	
+
 	"github.com/apopov-app/ggconfig/runtime"
-	
 )
 
 // ===== ENV Implementation =====
 
-type internal_dbEnvConfig struct{
+// internal_dbEnvConfig reads db.Config from environment variables named
+// DB_<KEY> (passed through mapKey); the method docs list the exact names in lookup order.
+// A set DB_<KEY>_FILE variable, the Docker secrets convention, is read instead: the value is the
+// content of the file it names.
+type internal_dbEnvConfig struct {
 	mapKey func(string) string
+	lookup func(string) (string, bool) // nil - os.LookupEnv
 }
 
+// lookupEnv reads a variable with the lookup of the constructor or os.LookupEnv. When key+"_FILE" is set,
+// the value is the content of that file without one trailing newline. A file that cannot be read is
+// an invalid value: the key is absent and the error goes to report, or to the parse
+// error observer when report is nil.
+func (c *internal_dbEnvConfig) lookupEnv(key string, report func(*runtime.ParseError)) (string, bool) {
+	lookup := c.lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if path, ok := lookup(key + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if report != nil {
+				report(&runtime.ParseError{Source: "env", Key: key + "_FILE", Value: path, Type: "file", Err: err})
+			} else {
+				runtime.ObserveParseError("env", key+"_FILE", path, "file", err)
+			}
+			return "", false
+		}
+		if n := len(data); n > 0 && data[n-1] == '\n' {
+			data = data[:n-1]
+			if n > 1 && data[n-2] == '\r' {
+				data = data[:n-2]
+			}
+		}
+		return string(data), true
+	}
+	return lookup(key)
+}
+
+// getenv is lookupEnv without the presence flag: an unset variable reads as "".
+func (c *internal_dbEnvConfig) getenv(key string, report func(*runtime.ParseError)) string {
+	value, _ := c.lookupEnv(key, report)
+	return value
+}
 
+// Host reads the ENV variable DB_HOST; without a valid value it returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_dbEnvConfig) Host(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_HOST")); value != "" {
+	if value := c.getenv(c.mapKey("DB_HOST"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Port reads the ENV variable DB_PORT; without a valid value it returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_dbEnvConfig) Port(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_PORT")); value != "" {
+	if value := c.getenv(c.mapKey("DB_PORT"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// User reads the ENV variable DB_USER; without a valid value it returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_dbEnvConfig) User(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_USER")); value != "" {
+	if value := c.getenv(c.mapKey("DB_USER"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Password reads the ENV variable DB_PASSWORD; without a valid value it returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_dbEnvConfig) Password(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_PASSWORD")); value != "" {
+	if value := c.getenv(c.mapKey("DB_PASSWORD"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Name reads the ENV variable DB_NAME; without a valid value it returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_dbEnvConfig) Name(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_NAME")); value != "" {
+	if value := c.getenv(c.mapKey("DB_NAME"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// SSLMode reads the ENV variable DB_SSL_MODE; without a valid value it returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_dbEnvConfig) SSLMode(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DB_SSL_MODE")); value != "" {
+	if value := c.getenv(c.mapKey("DB_SSL_MODE"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
-
+// NewInternalDbConfigEnvConfig reads db.Config from the process environment on every call.
 func NewInternalDbConfigEnvConfig() *internal_dbEnvConfig {
 	return NewInternalDbConfigEnvConfigWithMap(nil)
 }
 
+// NewInternalDbConfigEnvConfigWithMap reads the variables under the names returned by mapKey, e.g. with a prefix
+// for several instances of the package; nil keeps the derived names.
 func NewInternalDbConfigEnvConfigWithMap(mapKey func(string) string) *internal_dbEnvConfig {
 	if mapKey == nil {
 		mapKey = func(k string) string { return k }
@@ -71,13 +130,37 @@ func NewInternalDbConfigEnvConfigWithMap(mapKey func(string) string) *internal_d
 	return &internal_dbEnvConfig{mapKey: mapKey}
 }
 
+// NewInternalDbConfigEnvConfigWithLookup reads variables with lookup instead of os.LookupEnv (nil mapKey - keys as is).
+func NewInternalDbConfigEnvConfigWithLookup(mapKey func(string) string, lookup func(string) (string, bool)) *internal_dbEnvConfig {
+	c := NewInternalDbConfigEnvConfigWithMap(mapKey)
+	c.lookup = lookup
+	return c
+}
+
+// NewInternalDbConfigEnvConfigWithKeys reads the ENV variables returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalDbConfigEnvConfigWithKeys(keys runtime.KeyFunc) *internal_dbEnvConfig {
+	return NewInternalDbConfigEnvConfigWithMap(runtime.EnvKeys(keys, map[string]string{
+		"DB_HOST":     "Host",
+		"DB_PORT":     "Port",
+		"DB_USER":     "User",
+		"DB_PASSWORD": "Password",
+		"DB_NAME":     "Name",
+		"DB_SSL_MODE": "SSLMode",
+	}))
+}
+
 // ===== YAML Implementation =====
 
+// internal_dbYAMLConfig reads db.Config from the db section of a YAML document
+// (db: {key: value}); the method docs list the exact keys in lookup order.
 type internal_dbYAMLConfig struct {
-	y *runtime.YAML
+	y   *runtime.YAML
 	err error
 }
 
+// NewInternalDbConfigYAMLConfig reads the YAML file at path once. A read or parse error is kept in Err,
+// and every key then reports absence, so the composite falls through to other sources.
 func NewInternalDbConfigYAMLConfig(path string) *internal_dbYAMLConfig {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -90,114 +173,167 @@ func NewInternalDbConfigYAMLConfig(path string) *internal_dbYAMLConfig {
 	return &internal_dbYAMLConfig{y: y}
 }
 
+// NewInternalDbConfigYAMLConfigParsed reads an already parsed document, e.g. one shared by several packages
+// or kept up to date by a remote source.
 func NewInternalDbConfigYAMLConfigParsed(y *runtime.YAML) *internal_dbYAMLConfig {
 	return &internal_dbYAMLConfig{
 		y: y,
 	}
 }
 
-func (c *internal_dbYAMLConfig) Err() error { return c.err }
+// NewInternalDbConfigYAMLConfigWithKeys reads y with the YAML keys returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalDbConfigYAMLConfigWithKeys(y *runtime.YAML, keys runtime.KeyFunc) *internal_dbYAMLConfig {
+	return NewInternalDbConfigYAMLConfigParsed(runtime.RemapYAML(y, keys,
+		runtime.YAMLField{Method: "Host", Sections: []string{"db"}, Keys: []string{"host"}},
+		runtime.YAMLField{Method: "Port", Sections: []string{"db"}, Keys: []string{"port"}},
+		runtime.YAMLField{Method: "User", Sections: []string{"db"}, Keys: []string{"user"}},
+		runtime.YAMLField{Method: "Password", Sections: []string{"db"}, Keys: []string{"password"}},
+		runtime.YAMLField{Method: "Name", Sections: []string{"db"}, Keys: []string{"name"}},
+		runtime.YAMLField{Method: "SSLMode", Sections: []string{"db"}, Keys: []string{"sslmode"}},
+	))
+}
 
+// Err returns the error of reading or parsing the file, nil if it was loaded.
+func (c *internal_dbYAMLConfig) Err() error { return c.err }
 
+// Host reads the YAML key db.host; without a valid value it returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_dbYAMLConfig) Host(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "host"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Port reads the YAML key db.port; without a valid value it returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_dbYAMLConfig) Port(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "port"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// User reads the YAML key db.user; without a valid value it returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_dbYAMLConfig) User(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "user"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Password reads the YAML key db.password; without a valid value it returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_dbYAMLConfig) Password(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "password"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Name reads the YAML key db.name; without a valid value it returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_dbYAMLConfig) Name(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "name"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// SSLMode reads the YAML key db.sslmode; without a valid value it returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_dbYAMLConfig) SSLMode(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция db
 	if v, ok := c.y.GetString("db", "sslmode"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
-
 // ===== Mock Implementation =====
 
+// internal_dbMockConfig reports every key as absent, so code under test sees its own defaults;
+// wrap it with NewInternalDbConfigOverride to set some keys.
 type internal_dbMockConfig struct{}
 
-
+// Host always returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_dbMockConfig) Host(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Port always returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_dbMockConfig) Port(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// User always returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_dbMockConfig) User(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Password always returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_dbMockConfig) Password(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Name always returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_dbMockConfig) Name(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// SSLMode always returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_dbMockConfig) SSLMode(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
-
+// NewInternalDbConfigMock returns a config without values.
 func NewInternalDbConfigMock() *internal_dbMockConfig {
 	return &internal_dbMockConfig{}
 }
 
 // ===== Composite Implementation =====
 
+// internal_dbAllConfig is the composite db.Config: every method asks the sources in
+// priority order and returns the first value set. Pass it wherever the interface is expected.
 type internal_dbAllConfig struct {
-	sources []interface{
+	sources []interface {
 		Host(defaultValue string) (string, bool)
 		Port(defaultValue string) (string, bool)
 		User(defaultValue string) (string, bool)
@@ -205,9 +341,13 @@ type internal_dbAllConfig struct {
 		Name(defaultValue string) (string, bool)
 		SSLMode(defaultValue string) (string, bool)
 	}
+	record func(key string, position int) // Учет источника каждого чтения (WithStats), position -1 - default
 }
 
-func NewInternalDbConfigAll(sources ...interface{
+// NewInternalDbConfigAll combines sources, highest priority first, e.g.
+// NewInternalDbConfigAll(NewInternalDbConfigEnvConfig(), NewInternalDbConfigYAMLConfig("config.yaml")).
+// A source may be any implementation of db.Config.
+func NewInternalDbConfigAll(sources ...interface {
 	Host(defaultValue string) (string, bool)
 	Port(defaultValue string) (string, bool)
 	User(defaultValue string) (string, bool)
@@ -218,66 +358,462 @@ func NewInternalDbConfigAll(sources ...interface{
 	return &internal_dbAllConfig{sources: sources}
 }
 
-
+// Host returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_dbAllConfig) Host(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Host(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.host", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.host", -1)
+	}
 	return defaultValue, false
 }
 
+// Port returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_dbAllConfig) Port(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Port(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.port", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.port", -1)
+	}
 	return defaultValue, false
 }
 
+// User returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// User returns database username
 func (c *internal_dbAllConfig) User(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.User(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.user", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.user", -1)
+	}
 	return defaultValue, false
 }
 
+// Password returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Password returns database password
 func (c *internal_dbAllConfig) Password(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Password(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.password", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.password", -1)
+	}
 	return defaultValue, false
 }
 
+// Name returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Name returns database name
 func (c *internal_dbAllConfig) Name(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Name(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.name", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.name", -1)
+	}
 	return defaultValue, false
 }
 
+// SSLMode returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_dbAllConfig) SSLMode(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.SSLMode(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("db.sslmode", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("db.sslmode", -1)
+	}
 	return defaultValue, false
 }
 
+// Report resolves every key through the sources and describes the result: the source that
+// returned each value (secrets redacted), the keys left to defaults and the malformed values
+// skipped on the way. Log it once at startup: log.Printf("config: %s", cfg.Report()).
+func (c *internal_dbAllConfig) Report() runtime.StartupReport {
+	var r runtime.StartupReport
+	r.Observe(func() {
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Host(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.host", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Port(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.port", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.User(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.user", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Password(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.password", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Name(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.name", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.SSLMode(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("db.sslmode", source, value, false)
+		}
+	})
+	return r
+}
 
+// WithStats returns c counting in stats which source satisfied every lookup (see
+// runtime.ResolutionStats), so a key that starts resolving from a lower-priority source
+// after a deploy is visible; c itself is not changed.
+func (c *internal_dbAllConfig) WithStats(stats *runtime.ResolutionStats) *internal_dbAllConfig {
+	names := make([]string, len(c.sources))
+	for i, s := range c.sources {
+		names[i] = runtime.SourceName(s)
+	}
+	return &internal_dbAllConfig{sources: c.sources, record: func(key string, position int) {
+		source := ""
+		if position >= 0 {
+			source = names[position]
+		}
+		stats.Record(key, position, source)
+	}}
+}
+
+// WithOverrides returns c with overrides applied on top (see NewInternalDbConfigOverride); c itself is not changed.
+func (c *internal_dbAllConfig) WithOverrides(overrides map[string]any) *internal_dbOverrideConfig {
+	return NewInternalDbConfigOverride(c, overrides)
+}
+
+// Freeze resolves every key once and returns a config that keeps returning these values
+// (and the caller's default for keys that were absent), even if the environment, files or
+// remote sources change later. Use it for components that must not observe configuration
+// drift mid-run, such as crypto parameters.
+func (c *internal_dbAllConfig) Freeze() *internal_dbOverrideConfig {
+	values := make(map[string]any, 6)
+	{
+		var zero string
+		values["Host"] = nil
+		if v, ok := c.Host(zero); ok {
+			values["Host"] = v
+		}
+	}
+	{
+		var zero string
+		values["Port"] = nil
+		if v, ok := c.Port(zero); ok {
+			values["Port"] = v
+		}
+	}
+	{
+		var zero string
+		values["User"] = nil
+		if v, ok := c.User(zero); ok {
+			values["User"] = v
+		}
+	}
+	{
+		var zero string
+		values["Password"] = nil
+		if v, ok := c.Password(zero); ok {
+			values["Password"] = v
+		}
+	}
+	{
+		var zero string
+		values["Name"] = nil
+		if v, ok := c.Name(zero); ok {
+			values["Name"] = v
+		}
+	}
+	{
+		var zero string
+		values["SSLMode"] = nil
+		if v, ok := c.SSLMode(zero); ok {
+			values["SSLMode"] = v
+		}
+	}
+	return &internal_dbOverrideConfig{base: c, overrides: values}
+}
 
+// ===== Override Implementation =====
+
+// internal_dbOverrideConfig returns fixed values for some methods and the base values for the
+// rest (see NewInternalDbConfigOverride and Freeze).
+type internal_dbOverrideConfig struct {
+	base interface {
+		Host(defaultValue string) (string, bool)
+		Port(defaultValue string) (string, bool)
+		User(defaultValue string) (string, bool)
+		Password(defaultValue string) (string, bool)
+		Name(defaultValue string) (string, bool)
+		SSLMode(defaultValue string) (string, bool)
+	}
+	overrides map[string]any
+}
+
+// NewInternalDbConfigOverride wraps base and returns the overridden values (keyed by method name)
+// instead of the base ones, e.g. NewInternalDbConfigOverride(cfg, map[string]any{"Port": 9999});
+// a nil value makes the key absent. Intended for tests: it panics on an unknown method or
+// a value of the wrong type. Integer methods also accept int values, such as untyped constants,
+// and pointer methods (*T) accept both T and *T values.
+func NewInternalDbConfigOverride(base interface {
+	Host(defaultValue string) (string, bool)
+	Port(defaultValue string) (string, bool)
+	User(defaultValue string) (string, bool)
+	Password(defaultValue string) (string, bool)
+	Name(defaultValue string) (string, bool)
+	SSLMode(defaultValue string) (string, bool)
+}, overrides map[string]any) *internal_dbOverrideConfig {
+	values := make(map[string]any, len(overrides))
+	for k, v := range overrides {
+		values[k] = v
+		if v == nil {
+			continue
+		}
+		switch k {
+		case "Host":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Host must be string")
+			}
+		case "Port":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Port must be string")
+			}
+		case "User":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override User must be string")
+			}
+		case "Password":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Password must be string")
+			}
+		case "Name":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Name must be string")
+			}
+		case "SSLMode":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override SSLMode must be string")
+			}
+		default:
+			panic("ggconfig: unknown override " + k)
+		}
+	}
+	return &internal_dbOverrideConfig{base: base, overrides: values}
+}
+
+// Host returns the override "Host" when one is set (nil - absent), otherwise the base value.
+//
+// Host returns database host address
+func (c *internal_dbOverrideConfig) Host(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Host"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Host(defaultValue)
+}
+
+// Port returns the override "Port" when one is set (nil - absent), otherwise the base value.
+//
+// Port returns database port number
+func (c *internal_dbOverrideConfig) Port(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Port"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Port(defaultValue)
+}
+
+// User returns the override "User" when one is set (nil - absent), otherwise the base value.
+//
+// User returns database username
+func (c *internal_dbOverrideConfig) User(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["User"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.User(defaultValue)
+}
+
+// Password returns the override "Password" when one is set (nil - absent), otherwise the base value.
+//
+// Password returns database password
+func (c *internal_dbOverrideConfig) Password(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Password"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Password(defaultValue)
+}
+
+// Name returns the override "Name" when one is set (nil - absent), otherwise the base value.
+//
+// Name returns database name
+func (c *internal_dbOverrideConfig) Name(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Name"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Name(defaultValue)
+}
+
+// SSLMode returns the override "SSLMode" when one is set (nil - absent), otherwise the base value.
+//
+// SSLMode returns SSL mode configuration
+func (c *internal_dbOverrideConfig) SSLMode(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["SSLMode"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.SSLMode(defaultValue)
+}
+
+// ===== Snapshot =====
+
+// SnapshotInternalDbConfig captures all keys resolved by cfg ("section.key" -> value).
+// Use runtime.Diff to compare snapshots (reload auditing, tests, environment comparison).
+func SnapshotInternalDbConfig(cfg interface {
+	Host(defaultValue string) (string, bool)
+	Port(defaultValue string) (string, bool)
+	User(defaultValue string) (string, bool)
+	Password(defaultValue string) (string, bool)
+	Name(defaultValue string) (string, bool)
+	SSLMode(defaultValue string) (string, bool)
+}) runtime.Snapshot {
+	s := runtime.Snapshot{}
+	{
+		var zero string
+		if v, ok := cfg.Host(zero); ok {
+			s.Set("db.host", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Port(zero); ok {
+			s.Set("db.port", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.User(zero); ok {
+			s.Set("db.user", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Password(zero); ok {
+			s.Set("db.password", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Name(zero); ok {
+			s.Set("db.name", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.SSLMode(zero); ok {
+			s.Set("db.sslmode", v)
+		}
+	}
+	return s
+}
@@ -1,69 +1,128 @@
 // Code generated by ggconfig. DO NOT EDIT.
+// Content hash: sha256:5435430809b2c2182a6dcaeebda1c38715b0288c8d9798dc94c8e262414bc03b
 
 package gconfig
 
 import (
-	
 	"os"
-	
+
 	"github.com/apopov-app/ggconfig/runtime"
-	
 )
 
 // ===== ENV Implementation =====
 
-type internal_databaseEnvConfig struct{
+// internal_databaseEnvConfig reads database.Config from environment variables named
+// DATABASE_<KEY> (passed through mapKey); the method docs list the exact names in lookup order.
+// A set DATABASE_<KEY>_FILE variable, the Docker secrets convention, is read instead: the value is the
+// content of the file it names.
+type internal_databaseEnvConfig struct {
 	mapKey func(string) string
+	lookup func(string) (string, bool) // nil - os.LookupEnv
 }
 
+// lookupEnv reads a variable with the lookup of the constructor or os.LookupEnv. When key+"_FILE" is set,
+// the value is the content of that file without one trailing newline. A file that cannot be read is
+// an invalid value: the key is absent and the error goes to report, or to the parse
+// error observer when report is nil.
+func (c *internal_databaseEnvConfig) lookupEnv(key string, report func(*runtime.ParseError)) (string, bool) {
+	lookup := c.lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if path, ok := lookup(key + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if report != nil {
+				report(&runtime.ParseError{Source: "env", Key: key + "_FILE", Value: path, Type: "file", Err: err})
+			} else {
+				runtime.ObserveParseError("env", key+"_FILE", path, "file", err)
+			}
+			return "", false
+		}
+		if n := len(data); n > 0 && data[n-1] == '\n' {
+			data = data[:n-1]
+			if n > 1 && data[n-2] == '\r' {
+				data = data[:n-2]
+			}
+		}
+		return string(data), true
+	}
+	return lookup(key)
+}
+
+// getenv is lookupEnv without the presence flag: an unset variable reads as "".
+func (c *internal_databaseEnvConfig) getenv(key string, report func(*runtime.ParseError)) string {
+	value, _ := c.lookupEnv(key, report)
+	return value
+}
 
+// Host reads the ENV variable DATABASE_HOST; without a valid value it returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_databaseEnvConfig) Host(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_HOST")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_HOST"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Port reads the ENV variable DATABASE_PORT; without a valid value it returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_databaseEnvConfig) Port(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_PORT")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_PORT"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// User reads the ENV variable DATABASE_USER; without a valid value it returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_databaseEnvConfig) User(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_USER")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_USER"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Password reads the ENV variable DATABASE_PASSWORD; without a valid value it returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_databaseEnvConfig) Password(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_PASSWORD")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_PASSWORD"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// Name reads the ENV variable DATABASE_NAME; without a valid value it returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_databaseEnvConfig) Name(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_NAME")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_NAME"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// SSLMode reads the ENV variable DATABASE_SSL_MODE; without a valid value it returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_databaseEnvConfig) SSLMode(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("DATABASE_SSL_MODE")); value != "" {
+	if value := c.getenv(c.mapKey("DATABASE_SSL_MODE"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
-
+// NewInternalDatabaseConfigEnvConfig reads database.Config from the process environment on every call.
 func NewInternalDatabaseConfigEnvConfig() *internal_databaseEnvConfig {
 	return NewInternalDatabaseConfigEnvConfigWithMap(nil)
 }
 
+// NewInternalDatabaseConfigEnvConfigWithMap reads the variables under the names returned by mapKey, e.g. with a prefix
+// for several instances of the package; nil keeps the derived names.
 func NewInternalDatabaseConfigEnvConfigWithMap(mapKey func(string) string) *internal_databaseEnvConfig {
 	if mapKey == nil {
 		mapKey = func(k string) string { return k }
@@ -71,13 +130,37 @@ func NewInternalDatabaseConfigEnvConfigWithMap(mapKey func(string) string) *inte
 	return &internal_databaseEnvConfig{mapKey: mapKey}
 }
 
+// NewInternalDatabaseConfigEnvConfigWithLookup reads variables with lookup instead of os.LookupEnv (nil mapKey - keys as is).
+func NewInternalDatabaseConfigEnvConfigWithLookup(mapKey func(string) string, lookup func(string) (string, bool)) *internal_databaseEnvConfig {
+	c := NewInternalDatabaseConfigEnvConfigWithMap(mapKey)
+	c.lookup = lookup
+	return c
+}
+
+// NewInternalDatabaseConfigEnvConfigWithKeys reads the ENV variables returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalDatabaseConfigEnvConfigWithKeys(keys runtime.KeyFunc) *internal_databaseEnvConfig {
+	return NewInternalDatabaseConfigEnvConfigWithMap(runtime.EnvKeys(keys, map[string]string{
+		"DATABASE_HOST":     "Host",
+		"DATABASE_PORT":     "Port",
+		"DATABASE_USER":     "User",
+		"DATABASE_PASSWORD": "Password",
+		"DATABASE_NAME":     "Name",
+		"DATABASE_SSL_MODE": "SSLMode",
+	}))
+}
+
 // ===== YAML Implementation =====
 
+// internal_databaseYAMLConfig reads database.Config from the database section of a YAML document
+// (database: {key: value}); the method docs list the exact keys in lookup order.
 type internal_databaseYAMLConfig struct {
-	y *runtime.YAML
+	y   *runtime.YAML
 	err error
 }
 
+// NewInternalDatabaseConfigYAMLConfig reads the YAML file at path once. A read or parse error is kept in Err,
+// and every key then reports absence, so the composite falls through to other sources.
 func NewInternalDatabaseConfigYAMLConfig(path string) *internal_databaseYAMLConfig {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -90,114 +173,167 @@ func NewInternalDatabaseConfigYAMLConfig(path string) *internal_databaseYAMLConf
 	return &internal_databaseYAMLConfig{y: y}
 }
 
+// NewInternalDatabaseConfigYAMLConfigParsed reads an already parsed document, e.g. one shared by several packages
+// or kept up to date by a remote source.
 func NewInternalDatabaseConfigYAMLConfigParsed(y *runtime.YAML) *internal_databaseYAMLConfig {
 	return &internal_databaseYAMLConfig{
 		y: y,
 	}
 }
 
-func (c *internal_databaseYAMLConfig) Err() error { return c.err }
+// NewInternalDatabaseConfigYAMLConfigWithKeys reads y with the YAML keys returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalDatabaseConfigYAMLConfigWithKeys(y *runtime.YAML, keys runtime.KeyFunc) *internal_databaseYAMLConfig {
+	return NewInternalDatabaseConfigYAMLConfigParsed(runtime.RemapYAML(y, keys,
+		runtime.YAMLField{Method: "Host", Sections: []string{"database"}, Keys: []string{"host"}},
+		runtime.YAMLField{Method: "Port", Sections: []string{"database"}, Keys: []string{"port"}},
+		runtime.YAMLField{Method: "User", Sections: []string{"database"}, Keys: []string{"user"}},
+		runtime.YAMLField{Method: "Password", Sections: []string{"database"}, Keys: []string{"password"}},
+		runtime.YAMLField{Method: "Name", Sections: []string{"database"}, Keys: []string{"name"}},
+		runtime.YAMLField{Method: "SSLMode", Sections: []string{"database"}, Keys: []string{"sslmode"}},
+	))
+}
 
+// Err returns the error of reading or parsing the file, nil if it was loaded.
+func (c *internal_databaseYAMLConfig) Err() error { return c.err }
 
+// Host reads the YAML key database.host; without a valid value it returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_databaseYAMLConfig) Host(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "host"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Port reads the YAML key database.port; without a valid value it returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_databaseYAMLConfig) Port(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "port"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// User reads the YAML key database.user; without a valid value it returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_databaseYAMLConfig) User(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "user"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Password reads the YAML key database.password; without a valid value it returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_databaseYAMLConfig) Password(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "password"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// Name reads the YAML key database.name; without a valid value it returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_databaseYAMLConfig) Name(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "name"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// SSLMode reads the YAML key database.sslmode; without a valid value it returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_databaseYAMLConfig) SSLMode(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция database
 	if v, ok := c.y.GetString("database", "sslmode"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
-
 // ===== Mock Implementation =====
 
+// internal_databaseMockConfig reports every key as absent, so code under test sees its own defaults;
+// wrap it with NewInternalDatabaseConfigOverride to set some keys.
 type internal_databaseMockConfig struct{}
 
-
+// Host always returns defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_databaseMockConfig) Host(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Port always returns defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_databaseMockConfig) Port(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// User always returns defaultValue and false.
+//
+// User returns database username
 func (c *internal_databaseMockConfig) User(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Password always returns defaultValue and false.
+//
+// Password returns database password
 func (c *internal_databaseMockConfig) Password(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// Name always returns defaultValue and false.
+//
+// Name returns database name
 func (c *internal_databaseMockConfig) Name(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// SSLMode always returns defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_databaseMockConfig) SSLMode(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
-
+// NewInternalDatabaseConfigMock returns a config without values.
 func NewInternalDatabaseConfigMock() *internal_databaseMockConfig {
 	return &internal_databaseMockConfig{}
 }
 
 // ===== Composite Implementation =====
 
+// internal_databaseAllConfig is the composite database.Config: every method asks the sources in
+// priority order and returns the first value set. Pass it wherever the interface is expected.
 type internal_databaseAllConfig struct {
-	sources []interface{
+	sources []interface {
 		Host(defaultValue string) (string, bool)
 		Port(defaultValue string) (string, bool)
 		User(defaultValue string) (string, bool)
@@ -205,9 +341,13 @@ type internal_databaseAllConfig struct {
 		Name(defaultValue string) (string, bool)
 		SSLMode(defaultValue string) (string, bool)
 	}
+	record func(key string, position int) // Учет источника каждого чтения (WithStats), position -1 - default
 }
 
-func NewInternalDatabaseConfigAll(sources ...interface{
+// NewInternalDatabaseConfigAll combines sources, highest priority first, e.g.
+// NewInternalDatabaseConfigAll(NewInternalDatabaseConfigEnvConfig(), NewInternalDatabaseConfigYAMLConfig("config.yaml")).
+// A source may be any implementation of database.Config.
+func NewInternalDatabaseConfigAll(sources ...interface {
 	Host(defaultValue string) (string, bool)
 	Port(defaultValue string) (string, bool)
 	User(defaultValue string) (string, bool)
@@ -218,69 +358,467 @@ func NewInternalDatabaseConfigAll(sources ...interface{
 	return &internal_databaseAllConfig{sources: sources}
 }
 
-
+// Host returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Host returns database host address
 func (c *internal_databaseAllConfig) Host(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Host(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.host", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.host", -1)
+	}
 	return defaultValue, false
 }
 
+// Port returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Port returns database port number
 func (c *internal_databaseAllConfig) Port(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Port(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.port", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.port", -1)
+	}
 	return defaultValue, false
 }
 
+// User returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// User returns database username
 func (c *internal_databaseAllConfig) User(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.User(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.user", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.user", -1)
+	}
 	return defaultValue, false
 }
 
+// Password returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Password returns database password
 func (c *internal_databaseAllConfig) Password(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Password(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.password", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.password", -1)
+	}
 	return defaultValue, false
 }
 
+// Name returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Name returns database name
 func (c *internal_databaseAllConfig) Name(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Name(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.name", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.name", -1)
+	}
 	return defaultValue, false
 }
 
+// SSLMode returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// SSLMode returns SSL mode configuration
 func (c *internal_databaseAllConfig) SSLMode(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.SSLMode(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("database.sslmode", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("database.sslmode", -1)
+	}
 	return defaultValue, false
 }
 
+// Report resolves every key through the sources and describes the result: the source that
+// returned each value (secrets redacted), the keys left to defaults and the malformed values
+// skipped on the way. Log it once at startup: log.Printf("config: %s", cfg.Report()).
+func (c *internal_databaseAllConfig) Report() runtime.StartupReport {
+	var r runtime.StartupReport
+	r.Observe(func() {
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Host(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.host", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Port(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.port", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.User(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.user", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Password(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.password", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Name(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.name", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.SSLMode(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("database.sslmode", source, value, false)
+		}
+	})
+	return r
+}
+
+// WithStats returns c counting in stats which source satisfied every lookup (see
+// runtime.ResolutionStats), so a key that starts resolving from a lower-priority source
+// after a deploy is visible; c itself is not changed.
+func (c *internal_databaseAllConfig) WithStats(stats *runtime.ResolutionStats) *internal_databaseAllConfig {
+	names := make([]string, len(c.sources))
+	for i, s := range c.sources {
+		names[i] = runtime.SourceName(s)
+	}
+	return &internal_databaseAllConfig{sources: c.sources, record: func(key string, position int) {
+		source := ""
+		if position >= 0 {
+			source = names[position]
+		}
+		stats.Record(key, position, source)
+	}}
+}
+
+// WithOverrides returns c with overrides applied on top (see NewInternalDatabaseConfigOverride); c itself is not changed.
+func (c *internal_databaseAllConfig) WithOverrides(overrides map[string]any) *internal_databaseOverrideConfig {
+	return NewInternalDatabaseConfigOverride(c, overrides)
+}
+
+// Freeze resolves every key once and returns a config that keeps returning these values
+// (and the caller's default for keys that were absent), even if the environment, files or
+// remote sources change later. Use it for components that must not observe configuration
+// drift mid-run, such as crypto parameters.
+func (c *internal_databaseAllConfig) Freeze() *internal_databaseOverrideConfig {
+	values := make(map[string]any, 6)
+	{
+		var zero string
+		values["Host"] = nil
+		if v, ok := c.Host(zero); ok {
+			values["Host"] = v
+		}
+	}
+	{
+		var zero string
+		values["Port"] = nil
+		if v, ok := c.Port(zero); ok {
+			values["Port"] = v
+		}
+	}
+	{
+		var zero string
+		values["User"] = nil
+		if v, ok := c.User(zero); ok {
+			values["User"] = v
+		}
+	}
+	{
+		var zero string
+		values["Password"] = nil
+		if v, ok := c.Password(zero); ok {
+			values["Password"] = v
+		}
+	}
+	{
+		var zero string
+		values["Name"] = nil
+		if v, ok := c.Name(zero); ok {
+			values["Name"] = v
+		}
+	}
+	{
+		var zero string
+		values["SSLMode"] = nil
+		if v, ok := c.SSLMode(zero); ok {
+			values["SSLMode"] = v
+		}
+	}
+	return &internal_databaseOverrideConfig{base: c, overrides: values}
+}
+
+// ===== Override Implementation =====
+
+// internal_databaseOverrideConfig returns fixed values for some methods and the base values for the
+// rest (see NewInternalDatabaseConfigOverride and Freeze).
+type internal_databaseOverrideConfig struct {
+	base interface {
+		Host(defaultValue string) (string, bool)
+		Port(defaultValue string) (string, bool)
+		User(defaultValue string) (string, bool)
+		Password(defaultValue string) (string, bool)
+		Name(defaultValue string) (string, bool)
+		SSLMode(defaultValue string) (string, bool)
+	}
+	overrides map[string]any
+}
+
+// NewInternalDatabaseConfigOverride wraps base and returns the overridden values (keyed by method name)
+// instead of the base ones, e.g. NewInternalDatabaseConfigOverride(cfg, map[string]any{"Port": 9999});
+// a nil value makes the key absent. Intended for tests: it panics on an unknown method or
+// a value of the wrong type. Integer methods also accept int values, such as untyped constants,
+// and pointer methods (*T) accept both T and *T values.
+func NewInternalDatabaseConfigOverride(base interface {
+	Host(defaultValue string) (string, bool)
+	Port(defaultValue string) (string, bool)
+	User(defaultValue string) (string, bool)
+	Password(defaultValue string) (string, bool)
+	Name(defaultValue string) (string, bool)
+	SSLMode(defaultValue string) (string, bool)
+}, overrides map[string]any) *internal_databaseOverrideConfig {
+	values := make(map[string]any, len(overrides))
+	for k, v := range overrides {
+		values[k] = v
+		if v == nil {
+			continue
+		}
+		switch k {
+		case "Host":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Host must be string")
+			}
+		case "Port":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Port must be string")
+			}
+		case "User":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override User must be string")
+			}
+		case "Password":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Password must be string")
+			}
+		case "Name":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Name must be string")
+			}
+		case "SSLMode":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override SSLMode must be string")
+			}
+		default:
+			panic("ggconfig: unknown override " + k)
+		}
+	}
+	return &internal_databaseOverrideConfig{base: base, overrides: values}
+}
+
+// Host returns the override "Host" when one is set (nil - absent), otherwise the base value.
+//
+// Host returns database host address
+func (c *internal_databaseOverrideConfig) Host(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Host"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Host(defaultValue)
+}
 
+// Port returns the override "Port" when one is set (nil - absent), otherwise the base value.
+//
+// Port returns database port number
+func (c *internal_databaseOverrideConfig) Port(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Port"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Port(defaultValue)
+}
+
+// User returns the override "User" when one is set (nil - absent), otherwise the base value.
+//
+// User returns database username
+func (c *internal_databaseOverrideConfig) User(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["User"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.User(defaultValue)
+}
 
+// Password returns the override "Password" when one is set (nil - absent), otherwise the base value.
+//
+// Password returns database password
+func (c *internal_databaseOverrideConfig) Password(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Password"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Password(defaultValue)
+}
+
+// Name returns the override "Name" when one is set (nil - absent), otherwise the base value.
+//
+// Name returns database name
+func (c *internal_databaseOverrideConfig) Name(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Name"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Name(defaultValue)
+}
+
+// SSLMode returns the override "SSLMode" when one is set (nil - absent), otherwise the base value.
+//
+// SSLMode returns SSL mode configuration
+func (c *internal_databaseOverrideConfig) SSLMode(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["SSLMode"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.SSLMode(defaultValue)
+}
+
+// ===== Snapshot =====
+
+// SnapshotInternalDatabaseConfig captures all keys resolved by cfg ("section.key" -> value).
+// Use runtime.Diff to compare snapshots (reload auditing, tests, environment comparison).
+func SnapshotInternalDatabaseConfig(cfg interface {
+	Host(defaultValue string) (string, bool)
+	Port(defaultValue string) (string, bool)
+	User(defaultValue string) (string, bool)
+	Password(defaultValue string) (string, bool)
+	Name(defaultValue string) (string, bool)
+	SSLMode(defaultValue string) (string, bool)
+}) runtime.Snapshot {
+	s := runtime.Snapshot{}
+	{
+		var zero string
+		if v, ok := cfg.Host(zero); ok {
+			s.Set("database.host", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Port(zero); ok {
+			s.Set("database.port", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.User(zero); ok {
+			s.Set("database.user", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Password(zero); ok {
+			s.Set("database.password", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Name(zero); ok {
+			s.Set("database.name", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.SSLMode(zero); ok {
+			s.Set("database.sslmode", v)
+		}
+	}
+	return s
+}
+
+// init registers the package, so GlobalConfig can build its composite over the shared sources.
 func init() {
 	Register("internal_database", Provider{
 		Package: "internal_database",
@@ -305,4 +843,3 @@ func (g *GlobalConfig) GetInternalDatabase() (*internal_databaseAllConfig, bool)
 	cfg, ok := v.(*internal_databaseAllConfig)
 	return cfg, ok
 }
-
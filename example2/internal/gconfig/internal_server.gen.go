@@ -1,64 +1,124 @@
 // Code generated by ggconfig. DO NOT EDIT.
+// Content hash: sha256:71077d635c977e92a4cd912c5b61efc8c9c9a207024dad35789087555ca5e87a
 
 package gconfig
 
 import (
-	
 	"os"
 	"strconv"
+
 	"github.com/apopov-app/ggconfig/runtime"
-	
 )
 
 // ===== ENV Implementation =====
 
-type internal_serverEnvConfig struct{
+// internal_serverEnvConfig reads server.Config from environment variables named
+// SERVER_<KEY> (passed through mapKey); the method docs list the exact names in lookup order.
+// A set SERVER_<KEY>_FILE variable, the Docker secrets convention, is read instead: the value is the
+// content of the file it names.
+type internal_serverEnvConfig struct {
 	mapKey func(string) string
+	lookup func(string) (string, bool) // nil - os.LookupEnv
 }
 
+// lookupEnv reads a variable with the lookup of the constructor or os.LookupEnv. When key+"_FILE" is set,
+// the value is the content of that file without one trailing newline. A file that cannot be read is
+// an invalid value: the key is absent and the error goes to report, or to the parse
+// error observer when report is nil.
+func (c *internal_serverEnvConfig) lookupEnv(key string, report func(*runtime.ParseError)) (string, bool) {
+	lookup := c.lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if path, ok := lookup(key + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if report != nil {
+				report(&runtime.ParseError{Source: "env", Key: key + "_FILE", Value: path, Type: "file", Err: err})
+			} else {
+				runtime.ObserveParseError("env", key+"_FILE", path, "file", err)
+			}
+			return "", false
+		}
+		if n := len(data); n > 0 && data[n-1] == '\n' {
+			data = data[:n-1]
+			if n > 1 && data[n-2] == '\r' {
+				data = data[:n-2]
+			}
+		}
+		return string(data), true
+	}
+	return lookup(key)
+}
 
+// getenv is lookupEnv without the presence flag: an unset variable reads as "".
+func (c *internal_serverEnvConfig) getenv(key string, report func(*runtime.ParseError)) string {
+	value, _ := c.lookupEnv(key, report)
+	return value
+}
+
+// Port reads the ENV variable SERVER_PORT; without a valid value it returns defaultValue and false.
+//
+// Port returns server port number
 func (c *internal_serverEnvConfig) Port(defaultValue int) (int, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_PORT")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_PORT"), nil); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue, true
+		} else {
+			runtime.ObserveParseError("env", c.mapKey("SERVER_PORT"), value, "int", err)
 		}
 	}
 	return defaultValue, false
 }
 
+// Host reads the first set of the ENV variables SERVER_ADDRESS_ALIASE, SERVER_HOST; without a valid value it returns defaultValue and false.
+//
+// Host returns server host address
 func (c *internal_serverEnvConfig) Host(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_ADDRESS_ALIASE")); value != "" {
-    return value, true
-}
-	if value := os.Getenv(c.mapKey("SERVER_HOST")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_ADDRESS_ALIASE"), nil); value != "" {
+		return value, true
+	}
+	if value := c.getenv(c.mapKey("SERVER_HOST"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
+// ReadTimeout reads the ENV variable SERVER_READ_TIMEOUT; without a valid value it returns defaultValue and false.
+//
+// ReadTimeout returns read timeout in seconds
 func (c *internal_serverEnvConfig) ReadTimeout(defaultValue int) (int, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_READ_TIMEOUT")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_READ_TIMEOUT"), nil); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue, true
+		} else {
+			runtime.ObserveParseError("env", c.mapKey("SERVER_READ_TIMEOUT"), value, "int", err)
 		}
 	}
 	return defaultValue, false
 }
 
+// WriteTimeout reads the ENV variable SERVER_WRITE_TIMEOUT; without a valid value it returns defaultValue and false.
+//
+// WriteTimeout returns write timeout in seconds
 func (c *internal_serverEnvConfig) WriteTimeout(defaultValue int) (int, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_WRITE_TIMEOUT")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_WRITE_TIMEOUT"), nil); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue, true
+		} else {
+			runtime.ObserveParseError("env", c.mapKey("SERVER_WRITE_TIMEOUT"), value, "int", err)
 		}
 	}
 	return defaultValue, false
 }
 
-
+// NewInternalServerConfigEnvConfig reads server.Config from the process environment on every call.
 func NewInternalServerConfigEnvConfig() *internal_serverEnvConfig {
 	return NewInternalServerConfigEnvConfigWithMap(nil)
 }
 
+// NewInternalServerConfigEnvConfigWithMap reads the variables under the names returned by mapKey, e.g. with a prefix
+// for several instances of the package; nil keeps the derived names.
 func NewInternalServerConfigEnvConfigWithMap(mapKey func(string) string) *internal_serverEnvConfig {
 	if mapKey == nil {
 		mapKey = func(k string) string { return k }
@@ -66,13 +126,36 @@ func NewInternalServerConfigEnvConfigWithMap(mapKey func(string) string) *intern
 	return &internal_serverEnvConfig{mapKey: mapKey}
 }
 
+// NewInternalServerConfigEnvConfigWithLookup reads variables with lookup instead of os.LookupEnv (nil mapKey - keys as is).
+func NewInternalServerConfigEnvConfigWithLookup(mapKey func(string) string, lookup func(string) (string, bool)) *internal_serverEnvConfig {
+	c := NewInternalServerConfigEnvConfigWithMap(mapKey)
+	c.lookup = lookup
+	return c
+}
+
+// NewInternalServerConfigEnvConfigWithKeys reads the ENV variables returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalServerConfigEnvConfigWithKeys(keys runtime.KeyFunc) *internal_serverEnvConfig {
+	return NewInternalServerConfigEnvConfigWithMap(runtime.EnvKeys(keys, map[string]string{
+		"SERVER_PORT":           "Port",
+		"SERVER_ADDRESS_ALIASE": "Host",
+		"SERVER_HOST":           "Host",
+		"SERVER_READ_TIMEOUT":   "ReadTimeout",
+		"SERVER_WRITE_TIMEOUT":  "WriteTimeout",
+	}))
+}
+
 // ===== YAML Implementation =====
 
+// internal_serverYAMLConfig reads server.Config from the server section of a YAML document
+// (server: {key: value}); the method docs list the exact keys in lookup order.
 type internal_serverYAMLConfig struct {
-	y *runtime.YAML
+	y   *runtime.YAML
 	err error
 }
 
+// NewInternalServerConfigYAMLConfig reads the YAML file at path once. A read or parse error is kept in Err,
+// and every key then reports absence, so the composite falls through to other sources.
 func NewInternalServerConfigYAMLConfig(path string) *internal_serverYAMLConfig {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -85,18 +168,34 @@ func NewInternalServerConfigYAMLConfig(path string) *internal_serverYAMLConfig {
 	return &internal_serverYAMLConfig{y: y}
 }
 
+// NewInternalServerConfigYAMLConfigParsed reads an already parsed document, e.g. one shared by several packages
+// or kept up to date by a remote source.
 func NewInternalServerConfigYAMLConfigParsed(y *runtime.YAML) *internal_serverYAMLConfig {
 	return &internal_serverYAMLConfig{
 		y: y,
 	}
 }
 
-func (c *internal_serverYAMLConfig) Err() error { return c.err }
+// NewInternalServerConfigYAMLConfigWithKeys reads y with the YAML keys returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewInternalServerConfigYAMLConfigWithKeys(y *runtime.YAML, keys runtime.KeyFunc) *internal_serverYAMLConfig {
+	return NewInternalServerConfigYAMLConfigParsed(runtime.RemapYAML(y, keys,
+		runtime.YAMLField{Method: "Port", Sections: []string{"server"}, Keys: []string{"port"}},
+		runtime.YAMLField{Method: "Host", Sections: []string{"server"}, Keys: []string{"host"}},
+		runtime.YAMLField{Method: "ReadTimeout", Sections: []string{"server"}, Keys: []string{"readtimeout"}},
+		runtime.YAMLField{Method: "WriteTimeout", Sections: []string{"server"}, Keys: []string{"writetimeout"}},
+	))
+}
 
+// Err returns the error of reading or parsing the file, nil if it was loaded.
+func (c *internal_serverYAMLConfig) Err() error { return c.err }
 
+// Port reads the YAML key server.port; without a valid value it returns defaultValue and false.
+//
+// Port returns server port number
 func (c *internal_serverYAMLConfig) Port(defaultValue int) (int, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetInt("server", "port"); ok {
 		return v, true
@@ -104,19 +203,25 @@ func (c *internal_serverYAMLConfig) Port(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
+// Host reads the YAML key server.host; without a valid value it returns defaultValue and false.
+//
+// Host returns server host address
 func (c *internal_serverYAMLConfig) Host(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetString("server", "host"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
+// ReadTimeout reads the YAML key server.readtimeout; without a valid value it returns defaultValue and false.
+//
+// ReadTimeout returns read timeout in seconds
 func (c *internal_serverYAMLConfig) ReadTimeout(defaultValue int) (int, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetInt("server", "readtimeout"); ok {
 		return v, true
@@ -124,9 +229,12 @@ func (c *internal_serverYAMLConfig) ReadTimeout(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
+// WriteTimeout reads the YAML key server.writetimeout; without a valid value it returns defaultValue and false.
+//
+// WriteTimeout returns write timeout in seconds
 func (c *internal_serverYAMLConfig) WriteTimeout(defaultValue int) (int, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetInt("server", "writetimeout"); ok {
 		return v, true
@@ -134,45 +242,63 @@ func (c *internal_serverYAMLConfig) WriteTimeout(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
-
 // ===== Mock Implementation =====
 
+// internal_serverMockConfig reports every key as absent, so code under test sees its own defaults;
+// wrap it with NewInternalServerConfigOverride to set some keys.
 type internal_serverMockConfig struct{}
 
-
+// Port always returns defaultValue and false.
+//
+// Port returns server port number
 func (c *internal_serverMockConfig) Port(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
+// Host always returns defaultValue and false.
+//
+// Host returns server host address
 func (c *internal_serverMockConfig) Host(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
+// ReadTimeout always returns defaultValue and false.
+//
+// ReadTimeout returns read timeout in seconds
 func (c *internal_serverMockConfig) ReadTimeout(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
+// WriteTimeout always returns defaultValue and false.
+//
+// WriteTimeout returns write timeout in seconds
 func (c *internal_serverMockConfig) WriteTimeout(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
-
+// NewInternalServerConfigMock returns a config without values.
 func NewInternalServerConfigMock() *internal_serverMockConfig {
 	return &internal_serverMockConfig{}
 }
 
 // ===== Composite Implementation =====
 
+// internal_serverAllConfig is the composite server.Config: every method asks the sources in
+// priority order and returns the first value set. Pass it wherever the interface is expected.
 type internal_serverAllConfig struct {
-	sources []interface{
+	sources []interface {
 		Port(defaultValue int) (int, bool)
 		Host(defaultValue string) (string, bool)
 		ReadTimeout(defaultValue int) (int, bool)
 		WriteTimeout(defaultValue int) (int, bool)
 	}
+	record func(key string, position int) // Учет источника каждого чтения (WithStats), position -1 - default
 }
 
-func NewInternalServerConfigAll(sources ...interface{
+// NewInternalServerConfigAll combines sources, highest priority first, e.g.
+// NewInternalServerConfigAll(NewInternalServerConfigEnvConfig(), NewInternalServerConfigYAMLConfig("config.yaml")).
+// A source may be any implementation of server.Config.
+func NewInternalServerConfigAll(sources ...interface {
 	Port(defaultValue int) (int, bool)
 	Host(defaultValue string) (string, bool)
 	ReadTimeout(defaultValue int) (int, bool)
@@ -181,49 +307,341 @@ func NewInternalServerConfigAll(sources ...interface{
 	return &internal_serverAllConfig{sources: sources}
 }
 
-
+// Port returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Port returns server port number
 func (c *internal_serverAllConfig) Port(defaultValue int) (int, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Port(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.port", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.port", -1)
+	}
 	return defaultValue, false
 }
 
+// Host returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Host returns server host address
 func (c *internal_serverAllConfig) Host(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Host(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.host", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.host", -1)
+	}
 	return defaultValue, false
 }
 
+// ReadTimeout returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// ReadTimeout returns read timeout in seconds
 func (c *internal_serverAllConfig) ReadTimeout(defaultValue int) (int, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.ReadTimeout(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.readtimeout", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.readtimeout", -1)
+	}
 	return defaultValue, false
 }
 
+// WriteTimeout returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// WriteTimeout returns write timeout in seconds
 func (c *internal_serverAllConfig) WriteTimeout(defaultValue int) (int, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.WriteTimeout(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.writetimeout", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.writetimeout", -1)
+	}
 	return defaultValue, false
 }
 
+// Report resolves every key through the sources and describes the result: the source that
+// returned each value (secrets redacted), the keys left to defaults and the malformed values
+// skipped on the way. Log it once at startup: log.Printf("config: %s", cfg.Report()).
+func (c *internal_serverAllConfig) Report() runtime.StartupReport {
+	var r runtime.StartupReport
+	r.Observe(func() {
+		{
+			var zero int
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Port(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.port", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Host(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.host", source, value, false)
+		}
+		{
+			var zero int
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.ReadTimeout(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.readtimeout", source, value, false)
+		}
+		{
+			var zero int
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.WriteTimeout(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.writetimeout", source, value, false)
+		}
+	})
+	return r
+}
+
+// WithStats returns c counting in stats which source satisfied every lookup (see
+// runtime.ResolutionStats), so a key that starts resolving from a lower-priority source
+// after a deploy is visible; c itself is not changed.
+func (c *internal_serverAllConfig) WithStats(stats *runtime.ResolutionStats) *internal_serverAllConfig {
+	names := make([]string, len(c.sources))
+	for i, s := range c.sources {
+		names[i] = runtime.SourceName(s)
+	}
+	return &internal_serverAllConfig{sources: c.sources, record: func(key string, position int) {
+		source := ""
+		if position >= 0 {
+			source = names[position]
+		}
+		stats.Record(key, position, source)
+	}}
+}
 
+// WithOverrides returns c with overrides applied on top (see NewInternalServerConfigOverride); c itself is not changed.
+func (c *internal_serverAllConfig) WithOverrides(overrides map[string]any) *internal_serverOverrideConfig {
+	return NewInternalServerConfigOverride(c, overrides)
+}
 
+// Freeze resolves every key once and returns a config that keeps returning these values
+// (and the caller's default for keys that were absent), even if the environment, files or
+// remote sources change later. Use it for components that must not observe configuration
+// drift mid-run, such as crypto parameters.
+func (c *internal_serverAllConfig) Freeze() *internal_serverOverrideConfig {
+	values := make(map[string]any, 4)
+	{
+		var zero int
+		values["Port"] = nil
+		if v, ok := c.Port(zero); ok {
+			values["Port"] = v
+		}
+	}
+	{
+		var zero string
+		values["Host"] = nil
+		if v, ok := c.Host(zero); ok {
+			values["Host"] = v
+		}
+	}
+	{
+		var zero int
+		values["ReadTimeout"] = nil
+		if v, ok := c.ReadTimeout(zero); ok {
+			values["ReadTimeout"] = v
+		}
+	}
+	{
+		var zero int
+		values["WriteTimeout"] = nil
+		if v, ok := c.WriteTimeout(zero); ok {
+			values["WriteTimeout"] = v
+		}
+	}
+	return &internal_serverOverrideConfig{base: c, overrides: values}
+}
+
+// ===== Override Implementation =====
+
+// internal_serverOverrideConfig returns fixed values for some methods and the base values for the
+// rest (see NewInternalServerConfigOverride and Freeze).
+type internal_serverOverrideConfig struct {
+	base interface {
+		Port(defaultValue int) (int, bool)
+		Host(defaultValue string) (string, bool)
+		ReadTimeout(defaultValue int) (int, bool)
+		WriteTimeout(defaultValue int) (int, bool)
+	}
+	overrides map[string]any
+}
+
+// NewInternalServerConfigOverride wraps base and returns the overridden values (keyed by method name)
+// instead of the base ones, e.g. NewInternalServerConfigOverride(cfg, map[string]any{"Port": 9999});
+// a nil value makes the key absent. Intended for tests: it panics on an unknown method or
+// a value of the wrong type. Integer methods also accept int values, such as untyped constants,
+// and pointer methods (*T) accept both T and *T values.
+func NewInternalServerConfigOverride(base interface {
+	Port(defaultValue int) (int, bool)
+	Host(defaultValue string) (string, bool)
+	ReadTimeout(defaultValue int) (int, bool)
+	WriteTimeout(defaultValue int) (int, bool)
+}, overrides map[string]any) *internal_serverOverrideConfig {
+	values := make(map[string]any, len(overrides))
+	for k, v := range overrides {
+		values[k] = v
+		if v == nil {
+			continue
+		}
+		switch k {
+		case "Port":
+			if _, ok := v.(int); !ok {
+				panic("ggconfig: override Port must be int")
+			}
+		case "Host":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Host must be string")
+			}
+		case "ReadTimeout":
+			if _, ok := v.(int); !ok {
+				panic("ggconfig: override ReadTimeout must be int")
+			}
+		case "WriteTimeout":
+			if _, ok := v.(int); !ok {
+				panic("ggconfig: override WriteTimeout must be int")
+			}
+		default:
+			panic("ggconfig: unknown override " + k)
+		}
+	}
+	return &internal_serverOverrideConfig{base: base, overrides: values}
+}
+
+// Port returns the override "Port" when one is set (nil - absent), otherwise the base value.
+//
+// Port returns server port number
+func (c *internal_serverOverrideConfig) Port(defaultValue int) (int, bool) {
+	if v, ok := c.overrides["Port"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(int), true
+	}
+	return c.base.Port(defaultValue)
+}
+
+// Host returns the override "Host" when one is set (nil - absent), otherwise the base value.
+//
+// Host returns server host address
+func (c *internal_serverOverrideConfig) Host(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Host"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Host(defaultValue)
+}
+
+// ReadTimeout returns the override "ReadTimeout" when one is set (nil - absent), otherwise the base value.
+//
+// ReadTimeout returns read timeout in seconds
+func (c *internal_serverOverrideConfig) ReadTimeout(defaultValue int) (int, bool) {
+	if v, ok := c.overrides["ReadTimeout"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(int), true
+	}
+	return c.base.ReadTimeout(defaultValue)
+}
+
+// WriteTimeout returns the override "WriteTimeout" when one is set (nil - absent), otherwise the base value.
+//
+// WriteTimeout returns write timeout in seconds
+func (c *internal_serverOverrideConfig) WriteTimeout(defaultValue int) (int, bool) {
+	if v, ok := c.overrides["WriteTimeout"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(int), true
+	}
+	return c.base.WriteTimeout(defaultValue)
+}
+
+// ===== Snapshot =====
+
+// SnapshotInternalServerConfig captures all keys resolved by cfg ("section.key" -> value).
+// Use runtime.Diff to compare snapshots (reload auditing, tests, environment comparison).
+func SnapshotInternalServerConfig(cfg interface {
+	Port(defaultValue int) (int, bool)
+	Host(defaultValue string) (string, bool)
+	ReadTimeout(defaultValue int) (int, bool)
+	WriteTimeout(defaultValue int) (int, bool)
+}) runtime.Snapshot {
+	s := runtime.Snapshot{}
+	{
+		var zero int
+		if v, ok := cfg.Port(zero); ok {
+			s.Set("server.port", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Host(zero); ok {
+			s.Set("server.host", v)
+		}
+	}
+	{
+		var zero int
+		if v, ok := cfg.ReadTimeout(zero); ok {
+			s.Set("server.readtimeout", v)
+		}
+	}
+	{
+		var zero int
+		if v, ok := cfg.WriteTimeout(zero); ok {
+			s.Set("server.writetimeout", v)
+		}
+	}
+	return s
+}
+
+// init registers the package, so GlobalConfig can build its composite over the shared sources.
 func init() {
 	Register("internal_server", Provider{
 		Package: "internal_server",
@@ -248,4 +666,3 @@ func (g *GlobalConfig) GetInternalServer() (*internal_serverAllConfig, bool) {
 	cfg, ok := v.(*internal_serverAllConfig)
 	return cfg, ok
 }
-
@@ -1,43 +1,93 @@
 // Code generated by ggconfig. DO NOT EDIT.
+// Content hash: sha256:a14b860078ba8e687d2d53e71edecd32a6cf75b331eeaa1b20a07350ec47e291
 
 package gconfig
 
 import (
-	
 	"os"
 	"strconv"
+
 	"github.com/apopov-app/ggconfig/runtime"
-	
 )
 
 // ===== ENV Implementation =====
 
-type cmd_Bbin_internal_serverEnvConfig struct{
+// cmd_Bbin_internal_serverEnvConfig reads server.Config from environment variables named
+// SERVER_<KEY> (passed through mapKey); the method docs list the exact names in lookup order.
+// A set SERVER_<KEY>_FILE variable, the Docker secrets convention, is read instead: the value is the
+// content of the file it names.
+type cmd_Bbin_internal_serverEnvConfig struct {
 	mapKey func(string) string
+	lookup func(string) (string, bool) // nil - os.LookupEnv
 }
 
+// lookupEnv reads a variable with the lookup of the constructor or os.LookupEnv. When key+"_FILE" is set,
+// the value is the content of that file without one trailing newline. A file that cannot be read is
+// an invalid value: the key is absent and the error goes to report, or to the parse
+// error observer when report is nil.
+func (c *cmd_Bbin_internal_serverEnvConfig) lookupEnv(key string, report func(*runtime.ParseError)) (string, bool) {
+	lookup := c.lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if path, ok := lookup(key + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if report != nil {
+				report(&runtime.ParseError{Source: "env", Key: key + "_FILE", Value: path, Type: "file", Err: err})
+			} else {
+				runtime.ObserveParseError("env", key+"_FILE", path, "file", err)
+			}
+			return "", false
+		}
+		if n := len(data); n > 0 && data[n-1] == '\n' {
+			data = data[:n-1]
+			if n > 1 && data[n-2] == '\r' {
+				data = data[:n-2]
+			}
+		}
+		return string(data), true
+	}
+	return lookup(key)
+}
+
+// getenv is lookupEnv without the presence flag: an unset variable reads as "".
+func (c *cmd_Bbin_internal_serverEnvConfig) getenv(key string, report func(*runtime.ParseError)) string {
+	value, _ := c.lookupEnv(key, report)
+	return value
+}
 
+// Port reads the ENV variable SERVER_PORT; without a valid value it returns defaultValue and false.
+//
+// Port returns server port number
 func (c *cmd_Bbin_internal_serverEnvConfig) Port(defaultValue int) (int, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_PORT")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_PORT"), nil); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue, true
+		} else {
+			runtime.ObserveParseError("env", c.mapKey("SERVER_PORT"), value, "int", err)
 		}
 	}
 	return defaultValue, false
 }
 
+// Host reads the ENV variable SERVER_HOST; without a valid value it returns defaultValue and false.
+//
+// Host returns server host address
 func (c *cmd_Bbin_internal_serverEnvConfig) Host(defaultValue string) (string, bool) {
-	if value := os.Getenv(c.mapKey("SERVER_HOST")); value != "" {
+	if value := c.getenv(c.mapKey("SERVER_HOST"), nil); value != "" {
 		return value, true
 	}
 	return defaultValue, false
 }
 
-
+// NewCmdBbinInternalServerConfigEnvConfig reads server.Config from the process environment on every call.
 func NewCmdBbinInternalServerConfigEnvConfig() *cmd_Bbin_internal_serverEnvConfig {
 	return NewCmdBbinInternalServerConfigEnvConfigWithMap(nil)
 }
 
+// NewCmdBbinInternalServerConfigEnvConfigWithMap reads the variables under the names returned by mapKey, e.g. with a prefix
+// for several instances of the package; nil keeps the derived names.
 func NewCmdBbinInternalServerConfigEnvConfigWithMap(mapKey func(string) string) *cmd_Bbin_internal_serverEnvConfig {
 	if mapKey == nil {
 		mapKey = func(k string) string { return k }
@@ -45,13 +95,33 @@ func NewCmdBbinInternalServerConfigEnvConfigWithMap(mapKey func(string) string)
 	return &cmd_Bbin_internal_serverEnvConfig{mapKey: mapKey}
 }
 
+// NewCmdBbinInternalServerConfigEnvConfigWithLookup reads variables with lookup instead of os.LookupEnv (nil mapKey - keys as is).
+func NewCmdBbinInternalServerConfigEnvConfigWithLookup(mapKey func(string) string, lookup func(string) (string, bool)) *cmd_Bbin_internal_serverEnvConfig {
+	c := NewCmdBbinInternalServerConfigEnvConfigWithMap(mapKey)
+	c.lookup = lookup
+	return c
+}
+
+// NewCmdBbinInternalServerConfigEnvConfigWithKeys reads the ENV variables returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewCmdBbinInternalServerConfigEnvConfigWithKeys(keys runtime.KeyFunc) *cmd_Bbin_internal_serverEnvConfig {
+	return NewCmdBbinInternalServerConfigEnvConfigWithMap(runtime.EnvKeys(keys, map[string]string{
+		"SERVER_PORT": "Port",
+		"SERVER_HOST": "Host",
+	}))
+}
+
 // ===== YAML Implementation =====
 
+// cmd_Bbin_internal_serverYAMLConfig reads server.Config from the server section of a YAML document
+// (server: {key: value}); the method docs list the exact keys in lookup order.
 type cmd_Bbin_internal_serverYAMLConfig struct {
-	y *runtime.YAML
+	y   *runtime.YAML
 	err error
 }
 
+// NewCmdBbinInternalServerConfigYAMLConfig reads the YAML file at path once. A read or parse error is kept in Err,
+// and every key then reports absence, so the composite falls through to other sources.
 func NewCmdBbinInternalServerConfigYAMLConfig(path string) *cmd_Bbin_internal_serverYAMLConfig {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -64,18 +134,32 @@ func NewCmdBbinInternalServerConfigYAMLConfig(path string) *cmd_Bbin_internal_se
 	return &cmd_Bbin_internal_serverYAMLConfig{y: y}
 }
 
+// NewCmdBbinInternalServerConfigYAMLConfigParsed reads an already parsed document, e.g. one shared by several packages
+// or kept up to date by a remote source.
 func NewCmdBbinInternalServerConfigYAMLConfigParsed(y *runtime.YAML) *cmd_Bbin_internal_serverYAMLConfig {
 	return &cmd_Bbin_internal_serverYAMLConfig{
 		y: y,
 	}
 }
 
-func (c *cmd_Bbin_internal_serverYAMLConfig) Err() error { return c.err }
+// NewCmdBbinInternalServerConfigYAMLConfigWithKeys reads y with the YAML keys returned by keys instead of the derived ones
+// for organizations with their own naming conventions (see runtime.KeyFunc).
+func NewCmdBbinInternalServerConfigYAMLConfigWithKeys(y *runtime.YAML, keys runtime.KeyFunc) *cmd_Bbin_internal_serverYAMLConfig {
+	return NewCmdBbinInternalServerConfigYAMLConfigParsed(runtime.RemapYAML(y, keys,
+		runtime.YAMLField{Method: "Port", Sections: []string{"server"}, Keys: []string{"port"}},
+		runtime.YAMLField{Method: "Host", Sections: []string{"server"}, Keys: []string{"host"}},
+	))
+}
 
+// Err returns the error of reading or parsing the file, nil if it was loaded.
+func (c *cmd_Bbin_internal_serverYAMLConfig) Err() error { return c.err }
 
+// Port reads the YAML key server.port; without a valid value it returns defaultValue and false.
+//
+// Port returns server port number
 func (c *cmd_Bbin_internal_serverYAMLConfig) Port(defaultValue int) (int, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetInt("server", "port"); ok {
 		return v, true
@@ -83,74 +167,275 @@ func (c *cmd_Bbin_internal_serverYAMLConfig) Port(defaultValue int) (int, bool)
 	return defaultValue, false
 }
 
+// Host reads the YAML key server.host; without a valid value it returns defaultValue and false.
+//
+// Host returns server host address
 func (c *cmd_Bbin_internal_serverYAMLConfig) Host(defaultValue string) (string, bool) {
 	// Алиасные секции
-	
+
 	// Основная секция server
 	if v, ok := c.y.GetString("server", "host"); ok {
 		return v, true
-		}
+	}
 	return defaultValue, false
 }
 
-
 // ===== Mock Implementation =====
 
+// cmd_Bbin_internal_serverMockConfig reports every key as absent, so code under test sees its own defaults;
+// wrap it with NewCmdBbinInternalServerConfigOverride to set some keys.
 type cmd_Bbin_internal_serverMockConfig struct{}
 
-
+// Port always returns defaultValue and false.
+//
+// Port returns server port number
 func (c *cmd_Bbin_internal_serverMockConfig) Port(defaultValue int) (int, bool) {
 	return defaultValue, false
 }
 
+// Host always returns defaultValue and false.
+//
+// Host returns server host address
 func (c *cmd_Bbin_internal_serverMockConfig) Host(defaultValue string) (string, bool) {
 	return defaultValue, false
 }
 
-
+// NewCmdBbinInternalServerConfigMock returns a config without values.
 func NewCmdBbinInternalServerConfigMock() *cmd_Bbin_internal_serverMockConfig {
 	return &cmd_Bbin_internal_serverMockConfig{}
 }
 
 // ===== Composite Implementation =====
 
+// cmd_Bbin_internal_serverAllConfig is the composite server.Config: every method asks the sources in
+// priority order and returns the first value set. Pass it wherever the interface is expected.
 type cmd_Bbin_internal_serverAllConfig struct {
-	sources []interface{
+	sources []interface {
 		Port(defaultValue int) (int, bool)
 		Host(defaultValue string) (string, bool)
 	}
+	record func(key string, position int) // Учет источника каждого чтения (WithStats), position -1 - default
 }
 
-func NewCmdBbinInternalServerConfigAll(sources ...interface{
+// NewCmdBbinInternalServerConfigAll combines sources, highest priority first, e.g.
+// NewCmdBbinInternalServerConfigAll(NewCmdBbinInternalServerConfigEnvConfig(), NewCmdBbinInternalServerConfigYAMLConfig("config.yaml")).
+// A source may be any implementation of server.Config.
+func NewCmdBbinInternalServerConfigAll(sources ...interface {
 	Port(defaultValue int) (int, bool)
 	Host(defaultValue string) (string, bool)
 }) *cmd_Bbin_internal_serverAllConfig {
 	return &cmd_Bbin_internal_serverAllConfig{sources: sources}
 }
 
-
+// Port returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Port returns server port number
 func (c *cmd_Bbin_internal_serverAllConfig) Port(defaultValue int) (int, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Port(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.port", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.port", -1)
+	}
 	return defaultValue, false
 }
 
+// Host returns the value of the first source that sets it, otherwise defaultValue and false.
+//
+// Host returns server host address
 func (c *cmd_Bbin_internal_serverAllConfig) Host(defaultValue string) (string, bool) {
-	for _, s := range c.sources {
+	for i, s := range c.sources {
 		v, ok := s.Host(defaultValue)
 		if ok {
+			if c.record != nil {
+				c.record("server.host", i)
+			}
 			return v, true
 		}
 	}
+	if c.record != nil {
+		c.record("server.host", -1)
+	}
 	return defaultValue, false
 }
 
+// Report resolves every key through the sources and describes the result: the source that
+// returned each value (secrets redacted), the keys left to defaults and the malformed values
+// skipped on the way. Log it once at startup: log.Printf("config: %s", cfg.Report()).
+func (c *cmd_Bbin_internal_serverAllConfig) Report() runtime.StartupReport {
+	var r runtime.StartupReport
+	r.Observe(func() {
+		{
+			var zero int
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Port(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.port", source, value, false)
+		}
+		{
+			var zero string
+			source, value := "", any(nil)
+			for _, s := range c.sources {
+				if v, ok := s.Host(zero); ok {
+					source, value = runtime.SourceName(s), v
+					break
+				}
+			}
+			r.Add("server.host", source, value, false)
+		}
+	})
+	return r
+}
+
+// WithStats returns c counting in stats which source satisfied every lookup (see
+// runtime.ResolutionStats), so a key that starts resolving from a lower-priority source
+// after a deploy is visible; c itself is not changed.
+func (c *cmd_Bbin_internal_serverAllConfig) WithStats(stats *runtime.ResolutionStats) *cmd_Bbin_internal_serverAllConfig {
+	names := make([]string, len(c.sources))
+	for i, s := range c.sources {
+		names[i] = runtime.SourceName(s)
+	}
+	return &cmd_Bbin_internal_serverAllConfig{sources: c.sources, record: func(key string, position int) {
+		source := ""
+		if position >= 0 {
+			source = names[position]
+		}
+		stats.Record(key, position, source)
+	}}
+}
+
+// WithOverrides returns c with overrides applied on top (see NewCmdBbinInternalServerConfigOverride); c itself is not changed.
+func (c *cmd_Bbin_internal_serverAllConfig) WithOverrides(overrides map[string]any) *cmd_Bbin_internal_serverOverrideConfig {
+	return NewCmdBbinInternalServerConfigOverride(c, overrides)
+}
+
+// Freeze resolves every key once and returns a config that keeps returning these values
+// (and the caller's default for keys that were absent), even if the environment, files or
+// remote sources change later. Use it for components that must not observe configuration
+// drift mid-run, such as crypto parameters.
+func (c *cmd_Bbin_internal_serverAllConfig) Freeze() *cmd_Bbin_internal_serverOverrideConfig {
+	values := make(map[string]any, 2)
+	{
+		var zero int
+		values["Port"] = nil
+		if v, ok := c.Port(zero); ok {
+			values["Port"] = v
+		}
+	}
+	{
+		var zero string
+		values["Host"] = nil
+		if v, ok := c.Host(zero); ok {
+			values["Host"] = v
+		}
+	}
+	return &cmd_Bbin_internal_serverOverrideConfig{base: c, overrides: values}
+}
+
+// ===== Override Implementation =====
+
+// cmd_Bbin_internal_serverOverrideConfig returns fixed values for some methods and the base values for the
+// rest (see NewCmdBbinInternalServerConfigOverride and Freeze).
+type cmd_Bbin_internal_serverOverrideConfig struct {
+	base interface {
+		Port(defaultValue int) (int, bool)
+		Host(defaultValue string) (string, bool)
+	}
+	overrides map[string]any
+}
+
+// NewCmdBbinInternalServerConfigOverride wraps base and returns the overridden values (keyed by method name)
+// instead of the base ones, e.g. NewCmdBbinInternalServerConfigOverride(cfg, map[string]any{"Port": 9999});
+// a nil value makes the key absent. Intended for tests: it panics on an unknown method or
+// a value of the wrong type. Integer methods also accept int values, such as untyped constants,
+// and pointer methods (*T) accept both T and *T values.
+func NewCmdBbinInternalServerConfigOverride(base interface {
+	Port(defaultValue int) (int, bool)
+	Host(defaultValue string) (string, bool)
+}, overrides map[string]any) *cmd_Bbin_internal_serverOverrideConfig {
+	values := make(map[string]any, len(overrides))
+	for k, v := range overrides {
+		values[k] = v
+		if v == nil {
+			continue
+		}
+		switch k {
+		case "Port":
+			if _, ok := v.(int); !ok {
+				panic("ggconfig: override Port must be int")
+			}
+		case "Host":
+			if _, ok := v.(string); !ok {
+				panic("ggconfig: override Host must be string")
+			}
+		default:
+			panic("ggconfig: unknown override " + k)
+		}
+	}
+	return &cmd_Bbin_internal_serverOverrideConfig{base: base, overrides: values}
+}
+
+// Port returns the override "Port" when one is set (nil - absent), otherwise the base value.
+//
+// Port returns server port number
+func (c *cmd_Bbin_internal_serverOverrideConfig) Port(defaultValue int) (int, bool) {
+	if v, ok := c.overrides["Port"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(int), true
+	}
+	return c.base.Port(defaultValue)
+}
+
+// Host returns the override "Host" when one is set (nil - absent), otherwise the base value.
+//
+// Host returns server host address
+func (c *cmd_Bbin_internal_serverOverrideConfig) Host(defaultValue string) (string, bool) {
+	if v, ok := c.overrides["Host"]; ok {
+		if v == nil {
+			return defaultValue, false
+		}
+		return v.(string), true
+	}
+	return c.base.Host(defaultValue)
+}
+
+// ===== Snapshot =====
 
+// SnapshotCmdBbinInternalServerConfig captures all keys resolved by cfg ("section.key" -> value).
+// Use runtime.Diff to compare snapshots (reload auditing, tests, environment comparison).
+func SnapshotCmdBbinInternalServerConfig(cfg interface {
+	Port(defaultValue int) (int, bool)
+	Host(defaultValue string) (string, bool)
+}) runtime.Snapshot {
+	s := runtime.Snapshot{}
+	{
+		var zero int
+		if v, ok := cfg.Port(zero); ok {
+			s.Set("server.port", v)
+		}
+	}
+	{
+		var zero string
+		if v, ok := cfg.Host(zero); ok {
+			s.Set("server.host", v)
+		}
+	}
+	return s
+}
 
+// init registers the package, so GlobalConfig can build its composite over the shared sources.
 func init() {
 	Register("cmd_Bbin_internal_server", Provider{
 		Package: "cmd_Bbin_internal_server",
@@ -175,4 +460,3 @@ func (g *GlobalConfig) GetCmdBbinInternalServer() (*cmd_Bbin_internal_serverAllC
 	cfg, ok := v.(*cmd_Bbin_internal_serverAllConfig)
 	return cfg, ok
 }
-
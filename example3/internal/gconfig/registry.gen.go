@@ -1,30 +1,39 @@
 // Code generated by ggconfig. DO NOT EDIT.
+// Content hash: sha256:f3f98f2e09061a537e86fe833e8a34c0da847d51740dd3fb4d4ea6023fd83a7b
 
 package gconfig
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/apopov-app/ggconfig/runtime"
 )
 
+// Provider is the registry entry of one generated package; every generated file registers its
+// package from init().
 type Provider struct {
-	Package string
+	Package          string
 	NewAllFromParsed func(y *runtime.YAML, mapKey func(string) string) any
+	Descriptor       func() runtime.Descriptor // nil unless generated with --descriptor
 }
 
 var (
 	registryMu sync.RWMutex
-	registry = map[string]Provider{}
+	registry   = map[string]Provider{}
 )
 
+// Register adds or replaces the provider of pkg. Generated code calls it; call it yourself only
+// for hand-written implementations.
 func Register(pkg string, p Provider) {
 	registryMu.Lock()
 	defer registryMu.Unlock()
 	registry[pkg] = p
 }
 
+// Providers returns a copy of the registered providers keyed by unique package name.
 func Providers() map[string]Provider {
 	registryMu.RLock()
 	defer registryMu.RUnlock()
@@ -56,6 +65,7 @@ type EnvConfig struct {
 	mapKey func(string) string
 }
 
+// NewEnvConfig maps every ENV key through mapKey before it is read (nil - keys as is).
 func NewEnvConfig(mapKey func(key string) string) *EnvConfig {
 	if mapKey == nil {
 		mapKey = func(k string) string { return k }
@@ -63,23 +73,88 @@ func NewEnvConfig(mapKey func(key string) string) *EnvConfig {
 	return &EnvConfig{mapKey: mapKey}
 }
 
+// GlobalYamlConfig is the YAML file passed to NewGlobalConfig, shared by all packages.
 type GlobalYamlConfig struct {
-	path string
+	path      string
+	env       string
+	fallbacks []string // nil - DefaultConfigFallbacks
 }
 
+// DefaultConfigFileEnv is the ENV variable consulted by a GlobalYamlConfig with an empty path.
+const DefaultConfigFileEnv = "APP_CONFIG_FILE"
+
+// NewGlobalYamlConfig names the YAML file NewGlobalConfig loads once. An empty path is located
+// the way daemons usually find their config: the file named by the ENV variable
+// DefaultConfigFileEnv (see WithEnv), otherwise the first existing fallback location,
+// DefaultConfigFallbacks unless WithFallbacks replaces them; if none exists, no YAML is loaded.
 func NewGlobalYamlConfig(path string) *GlobalYamlConfig {
-	return &GlobalYamlConfig{path: path}
+	return &GlobalYamlConfig{path: path, env: DefaultConfigFileEnv}
 }
 
+// DefaultConfigFallbacks returns the fallback locations of a GlobalYamlConfig: ./config.yaml,
+// then /etc/<app>/config.yaml, where <app> is the name of the running executable.
+func DefaultConfigFallbacks() []string {
+	fallbacks := []string{"./config.yaml"}
+	if app := filepath.Base(os.Args[0]); app != "" && app != "." && app != string(filepath.Separator) {
+		fallbacks = append(fallbacks, filepath.Join("/etc", app, "config.yaml"))
+	}
+	return fallbacks
+}
+
+// WithEnv replaces the ENV variable that names the file when the path is empty, e.g.
+// "MYAPP_CONFIG"; an empty name disables the lookup.
+func (c *GlobalYamlConfig) WithEnv(name string) *GlobalYamlConfig {
+	c.env = name
+	return c
+}
+
+// WithFallbacks replaces DefaultConfigFallbacks with the locations tried in order when neither
+// the path nor the ENV variable is set, e.g. WithFallbacks("./config.yaml", "/etc/myapp/config.yaml");
+// no arguments disable the fallbacks. A missing fallback is skipped; an explicit path or one from
+// ENV must exist.
+func (c *GlobalYamlConfig) WithFallbacks(paths ...string) *GlobalYamlConfig {
+	c.fallbacks = append([]string{}, paths...)
+	return c
+}
+
+// Path resolves the file to load: the explicit path, the ENV variable or the first existing
+// fallback; "" means no YAML.
+func (c *GlobalYamlConfig) Path() (string, error) {
+	if c.path != "" {
+		return c.path, nil
+	}
+	if c.env != "" {
+		if p := os.Getenv(c.env); p != "" {
+			return p, nil
+		}
+	}
+	fallbacks := c.fallbacks
+	if fallbacks == nil {
+		fallbacks = DefaultConfigFallbacks()
+	}
+	for _, p := range fallbacks {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// GlobalConfig holds the sources shared by all registered packages; Get<Package> methods build
+// the composite config of one package over them.
 type GlobalConfig struct {
-	y *runtime.YAML
-	mapKey func(string) string
+	y          *runtime.YAML
+	mapKey     func(string) string
+	configFile string
 }
 
 // NewGlobalConfig creates app-wide config wrapper. Sources order does not matter.
 // Supported sources:
 // - *GlobalYamlConfig
 // - *EnvConfig
+// - any document source exposing YAML() (e.g. runtime.ApolloSource); it is read live, so reloads are visible
 func NewGlobalConfig(sources ...any) (*GlobalConfig, error) {
 	g := &GlobalConfig{
 		y:      &runtime.YAML{},
@@ -93,8 +168,19 @@ func NewGlobalConfig(sources ...any) (*GlobalConfig, error) {
 				g.mapKey = t.mapKey
 			}
 		case *GlobalYamlConfig:
-			if t != nil && t.path != "" {
-				yamlPath = t.path
+			if t == nil {
+				continue
+			}
+			p, err := t.Path()
+			if err != nil {
+				return nil, err
+			}
+			if p != "" {
+				yamlPath = p
+			}
+		case interface{ YAML() *runtime.YAML }:
+			if y := t.YAML(); y != nil {
+				g.y = y
 			}
 		}
 	}
@@ -108,7 +194,52 @@ func NewGlobalConfig(sources ...any) (*GlobalConfig, error) {
 			return nil, err
 		}
 		g.y = y
+		g.configFile = yamlPath
 	}
 	return g, nil
 }
 
+// ConfigFile returns the YAML file loaded by NewGlobalConfig ("" - none), e.g. for the startup log.
+func (g *GlobalConfig) ConfigFile() string {
+	return g.configFile
+}
+
+// Descriptors returns the descriptors of registered packages generated with --descriptor,
+// in name order, e.g. mux.Handle("/admin/config/descriptor", runtime.DescriptorHandler(Descriptors()...)).
+func Descriptors() []runtime.Descriptor {
+	providers := Providers()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []runtime.Descriptor
+	for _, name := range names {
+		if d := providers[name].Descriptor; d != nil {
+			out = append(out, d())
+		}
+	}
+	return out
+}
+
+// Report builds the startup report of all registered packages in name order
+// (see runtime.StartupReport), e.g. log.Printf("config: %s", global.Report()).
+func (g *GlobalConfig) Report() runtime.StartupReport {
+	providers := Providers()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var r runtime.StartupReport
+	for _, name := range names {
+		p := providers[name]
+		if p.NewAllFromParsed == nil {
+			continue
+		}
+		if cfg, ok := p.NewAllFromParsed(g.y, g.mapKey).(interface{ Report() runtime.StartupReport }); ok {
+			r.Merge(cfg.Report())
+		}
+	}
+	return r
+}
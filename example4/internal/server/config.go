@@ -1,6 +1,6 @@
 package server
 
-//go:generate ggconfig --interface=Config --output=../gconfig --registry --example=../../configs
+//go:generate ggconfig --interface=Config --output=../gconfig --registry --example=configs
 type Config interface {
 	// Realms returns list of realm configurations
 	Realms(defaultValue []RealmInfo) ([]RealmInfo, bool)
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// runExplain реализует команду explain: для ключей интерфейсов (директивы //go:generate ggconfig)
+// показывает, в каких источниках задано значение и какой источник побеждает при заданном
+// порядке. С --all печатается таблица приоритетов всех ключей сразу.
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "YAML (or .json, .hcl, .cue) config file of the yaml source")
+	sources := fs.String("sources", "env,yaml", "sources in priority order, highest first (env, yaml)")
+	var pkgs generator.AliasFlags
+	fs.Var(&pkgs, "pkg", "package dir with //go:generate ggconfig directives (repeatable, default: all packages under the current directory)")
+	all := fs.Bool("all", false, "print the precedence table of every key")
+	format := fs.String("format", "text", "output format: text | markdown")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig explain [--config=config.yaml] [--sources=env,yaml] [--pkg=dir] [--format=text|markdown] section.key")
+		fmt.Fprintln(fs.Output(), "       ggconfig explain --all [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "text" && *format != "markdown" {
+		return fmt.Errorf("unknown format %q (supported: text, markdown)", *format)
+	}
+	if *all == (fs.NArg() == 1) || fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected section.key or --all")
+	}
+
+	order, err := parseExplainSources(*sources)
+	if err != nil {
+		return err
+	}
+	var y *runtime.YAML
+	if containsString(order, "yaml") {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("read config: %w (use --sources=env without a YAML file)", err)
+		}
+		if y, err = parseConfigData(*configPath, data); err != nil {
+			return fmt.Errorf("parse config %s: %w", *configPath, err)
+		}
+	}
+
+	var directives []generator.Directive
+	if len(pkgs) == 0 {
+		if directives, err = generator.WalkDirectives("."); err != nil {
+			return err
+		}
+	}
+	for _, dir := range pkgs {
+		found, err := generator.FindDirectives(dir)
+		if err != nil {
+			return err
+		}
+		directives = append(directives, found...)
+	}
+	if len(directives) == 0 {
+		return fmt.Errorf("no //go:generate ggconfig directives found")
+	}
+
+	var rows []explainRow
+	for _, d := range directives {
+		abs, err := filepath.Abs(d.Dir)
+		if err != nil {
+			return err
+		}
+		packageName := filepath.Base(abs)
+		info, err := generator.ParseInterface(d.Dir, d.SourceFile, packageName, packageName, d.Interface)
+		if err != nil {
+			return err
+		}
+		d.Aliases = generator.MethodAliases(info, d.Aliases)
+		for _, m := range info.Methods {
+			if !*all && !explainMatches(fs.Arg(0), packageName, m, d.Aliases) {
+				continue
+			}
+			rows = append(rows, resolveExplainRow(packageName, d, m, order, map[string]explainSource{"env": {env: os.LookupEnv}, "yaml": {y: y}}))
+		}
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("unknown key %s: no interface reads it (use --pkg to point at the package)", fs.Arg(0))
+	}
+
+	color := *format == "text" && isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	if *format == "markdown" {
+		writeExplainMarkdown(os.Stdout, order, rows)
+	} else {
+		writeExplainTable(os.Stdout, order, rows, color)
+	}
+	if !*all {
+		for _, r := range rows {
+			writeExplainDetails(os.Stdout, order, r)
+		}
+	}
+	return nil
+}
+
+// parseExplainSources разбирает порядок источников --sources
+func parseExplainSources(s string) ([]string, error) {
+	var order []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "env" && name != "yaml" {
+			return nil, fmt.Errorf("unknown source %q (supported: env, yaml)", name)
+		}
+		if containsString(order, name) {
+			return nil, fmt.Errorf("source %q is listed twice", name)
+		}
+		order = append(order, name)
+	}
+	return order, nil
+}
+
+// explainMatches сообщает, читает ли метод ключ section.key (с учетом алиасов yaml.section и yaml.key)
+func explainMatches(key, packageName string, m generator.Method, aliases generator.AliasSettings) bool {
+	section, name, ok := strings.Cut(key, ".")
+	if !ok {
+		return false
+	}
+	return containsString(append([]string{packageName}, aliases.YAMLSection...), section) &&
+		containsString(append([]string{strings.ToLower(m.Name)}, aliases.YAMLKey[m.Name]...), name)
+}
+
+// Состояние значения в одном месте источника
+const (
+	explainMissing = iota
+	explainSet
+	explainInvalid // Задано, но не разбирается типом метода: реализация пропускает его
+	explainUnset   // Равно заглушке ggconfig:unset: источник сообщает об отсутствии
+)
+
+// explainCandidate - одно место, где источник ищет значение: переменная окружения или путь YAML
+type explainCandidate struct {
+	Where      string
+	Value      string
+	State      int
+	Deprecated bool // Ключ прежнего имени метода (ggconfig:was)
+}
+
+type explainRow struct {
+	Key    string // section.key, как в снимках и отчетах
+	Method generator.Method
+	Secret bool
+	// Источник -> места в порядке поиска (алиасы первыми)
+	Candidates map[string][]explainCandidate
+	Winner     string // Источник, значение которого используется; пусто - default
+	Value      string
+}
+
+// explainSource - данные источника: переменные (env, dotenv) или документ со структурой YAML
+type explainSource struct {
+	env func(string) (string, bool)
+	y   *runtime.YAML
+}
+
+// lookupEnvFile читает ENV ключ как сгенерированные реализации: заданная <KEY>_FILE (соглашение
+// Docker secrets) важнее самой переменной, значение - содержимое файла без завершающего перевода
+// строки. where - переменная, из которой взято значение
+func lookupEnvFile(lookup func(string) (string, bool), key string) (value string, ok bool, where string) {
+	if path, set := lookup(key + "_FILE"); set && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, key + "_FILE"
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), true, key + "_FILE"
+	}
+	value, ok = lookup(key)
+	return value, ok, key
+}
+
+// resolveExplainRow ищет значение ключа в источниках так же, как сгенерированные реализации
+func resolveExplainRow(packageName string, d generator.Directive, m generator.Method, order []string, sources map[string]explainSource) explainRow {
+	r := explainRow{Key: packageName + "." + strings.ToLower(m.Name), Method: m, Candidates: map[string][]explainCandidate{}}
+	_, r.Secret = m.Directive("secret")
+	for _, source := range order {
+		var found []explainCandidate
+		// Ключи прежних имен (ggconfig:was) проверяются после ключей метода
+		for _, name := range append([]string{m.Name}, m.Was()...) {
+			deprecated := name != m.Name
+			switch s := sources[source]; {
+			case s.env != nil:
+				_, allowEmpty := m.Directive("allow-empty")
+				for _, key := range append(append([]string{}, d.Aliases.Env[name]...), generator.EnvKey(d.EnvKeyPrefix(packageName), name)) {
+					value, ok, where := lookupEnvFile(s.env, key)
+					c := explainCandidate{Where: where, Deprecated: deprecated}
+					if ok && (value != "" || allowEmpty) {
+						c.Value, c.State = value, explainState(m, value, true)
+					}
+					found = append(found, c)
+				}
+			case s.y != nil:
+				for _, sec := range append(append([]string{}, d.Aliases.YAMLSection...), packageName) {
+					for _, key := range append(append([]string{}, d.Aliases.YAMLKey[name]...), strings.ToLower(name)) {
+						c := explainCandidate{Where: sec + "." + key, Deprecated: deprecated}
+						if raw, ok := s.y.GetRaw(sec, key); ok {
+							if value, ok := exportNodeValue(raw, m); ok {
+								c.Value, c.State = value, explainState(m, value, false)
+							}
+						}
+						found = append(found, c)
+					}
+				}
+			}
+		}
+		r.Candidates[source] = found
+		if r.Winner != "" {
+			continue
+		}
+		for _, c := range found {
+			if c.State == explainUnset {
+				// Заглушка завершает поиск в источнике, следующие места не проверяются
+				break
+			}
+			if c.State == explainSet {
+				r.Winner, r.Value = source, c.Value
+				break
+			}
+		}
+	}
+	return r
+}
+
+// explainState проверяет значение по типу метода, как это делает реализация источника
+func explainState(m generator.Method, value string, fromEnv bool) int {
+	if m.Kind == generator.KindDuration && fromEnv {
+		// ENV разбирается только time.ParseDuration, целые секунды допускает лишь YAML
+		if _, err := time.ParseDuration(value); err != nil {
+			return explainInvalid
+		}
+	} else if _, err := generator.ParseValue(m, value); err != nil {
+		return explainInvalid
+	}
+	if unset, ok := m.Directive("unset"); ok {
+		v, err1 := generator.ParseValue(m, value)
+		u, err2 := generator.ParseValue(m, unset)
+		if err1 == nil && err2 == nil && fmt.Sprint(v) == fmt.Sprint(u) {
+			return explainUnset
+		}
+	}
+	return explainSet
+}
+
+// displayValue возвращает значение для вывода: секреты скрываются, длинные значения обрезаются
+func (r explainRow) displayValue(value string) string {
+	if r.Secret {
+		return "******"
+	}
+	if utf8.RuneCountInString(value) > 40 {
+		value = string([]rune(value)[:37]) + "..."
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// sourceMark - отметка источника в таблице: ✓ задано, ✗ задано с ошибкой, пусто - не задано
+func (r explainRow) sourceMark(source string) string {
+	mark := ""
+	for _, c := range r.Candidates[source] {
+		switch c.State {
+		case explainSet:
+			return "✓"
+		case explainInvalid:
+			mark = "✗"
+		case explainUnset:
+			return mark
+		}
+	}
+	return mark
+}
+
+func (r explainRow) resolvedValue() string {
+	if r.Winner == "" {
+		return "(default)"
+	}
+	return r.displayValue(r.Value)
+}
+
+// writeExplainTable печатает таблицу приоритетов: колонка на источник (слева направо по
+// убыванию приоритета) и default; значение победившего источника выделяется полужирным
+// в терминале и скобками [✓] без цвета
+func writeExplainTable(w io.Writer, order []string, rows []explainRow, color bool) {
+	header := append(append([]string{"KEY", "TYPE"}, upperAll(order)...), "DEFAULT", "VALUE")
+	table := [][]string{header}
+	var bold [][]bool
+	bold = append(bold, make([]bool, len(header)))
+	for _, r := range rows {
+		line := []string{r.Key, r.Method.DeclaredType()}
+		marks := make([]bool, len(header))
+		for i, source := range order {
+			mark := r.sourceMark(source)
+			if r.Winner == source {
+				marks[2+i] = true
+				if !color {
+					mark = "[" + mark + "]"
+				}
+			}
+			line = append(line, mark)
+		}
+		def := ""
+		if r.Winner == "" {
+			def = "✓"
+			marks[2+len(order)] = true
+			if !color {
+				def = "[✓]"
+			}
+		}
+		table = append(table, append(line, def, r.resolvedValue()))
+		bold = append(bold, marks)
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range table {
+		for i, cell := range line {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for n, line := range table {
+		var b strings.Builder
+		for i, cell := range line {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if bold[n][i] && color {
+				cell = "\x1b[1m" + cell + "\x1b[0m"
+			}
+			b.WriteString(cell)
+			if i < len(line)-1 {
+				b.WriteString(padding + "  ")
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+	fmt.Fprintln(w)
+	winner := "[✓]"
+	if color {
+		winner = "\x1b[1m✓\x1b[0m"
+	}
+	fmt.Fprintf(w, "✓ value set, ✗ set but invalid (skipped), %s the value in use; sources by priority: %s\n", winner, strings.Join(order, " > "))
+}
+
+// writeExplainMarkdown печатает таблицу приоритетов в Markdown (для документации и PR)
+func writeExplainMarkdown(w io.Writer, order []string, rows []explainRow) {
+	fmt.Fprintf(w, "| Key | Type | %s | default | Value |\n", strings.Join(order, " | "))
+	fmt.Fprintf(w, "|---|---|%s---|---|\n", strings.Repeat("---|", len(order)))
+	for _, r := range rows {
+		cells := []string{"`" + r.Key + "`", "`" + r.Method.DeclaredType() + "`"}
+		for _, source := range order {
+			mark := r.sourceMark(source)
+			if r.Winner == source {
+				mark = "**" + mark + "**"
+			}
+			cells = append(cells, mark)
+		}
+		def := ""
+		if r.Winner == "" {
+			def = "**✓**"
+		}
+		value := r.resolvedValue()
+		if r.Winner != "" {
+			value = "`" + value + "`"
+		}
+		cells = append(cells, def, strings.ReplaceAll(value, "|", `\|`))
+		fmt.Fprintln(w, "| "+strings.Join(cells, " | ")+" |")
+	}
+}
+
+// writeExplainDetails печатает для одного ключа все места поиска в порядке проверки
+func writeExplainDetails(w io.Writer, order []string, r explainRow) {
+	fmt.Fprintf(w, "\n%s (%s, %s):\n", r.Key, r.Method.Name, r.Method.DeclaredType())
+	inUse := false
+	for i, source := range order {
+		for j, c := range r.Candidates[source] {
+			label := ""
+			if j == 0 {
+				label = fmt.Sprintf("%d. %s", i+1, source)
+			}
+			var status string
+			switch c.State {
+			case explainMissing:
+				status = "not set"
+			case explainSet:
+				status = r.displayValue(c.Value)
+				if r.Winner == source && !inUse {
+					status += "  <- in use"
+					inUse = true
+				}
+			case explainInvalid:
+				status = r.displayValue(c.Value) + "  (invalid " + r.Method.DeclaredType() + ", skipped)"
+			case explainUnset:
+				status = r.displayValue(c.Value) + "  (ggconfig:unset marker, treated as not set)"
+			}
+			if c.Deprecated && c.State != explainMissing {
+				status += "  (deprecated key, ggconfig:was)"
+			}
+			fmt.Fprintf(w, "  %-10s %-28s %s\n", label, c.Where, status)
+		}
+	}
+	status := "not used"
+	if r.Winner == "" {
+		status = "<- in use (the defaultValue argument)"
+	}
+	fmt.Fprintf(w, "  %-10s %-28s %s\n", fmt.Sprintf("%d. default", len(order)+1), "", status)
+}
+
+func upperAll(list []string) []string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = strings.ToUpper(s)
+	}
+	return out
+}
+
+// isTerminal сообщает, выводится ли f в терминал (цветной вывод только для терминала)
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"github.com/apopov-app/ggconfig/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// runExportEnv реализует команду export-env: значения из YAML конфига печатаются
+// как переменные окружения с именами, которые читают сгенерированные ENV реализации.
+func runExportEnv(args []string) error {
+	fs := flag.NewFlagSet("export-env", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "YAML (or .json, .hcl, .cue) config file to export")
+	format := fs.String("format", "shell", "output format: shell (export KEY='value') | dotenv (KEY=\"value\")")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig export-env [--config=config.yaml] [--format=shell|dotenv] [-o file] [package dirs...]")
+		fmt.Fprintln(fs.Output(), "\nPackage dirs contain interfaces with //go:generate ggconfig directives (default: current directory).")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "shell" && *format != "dotenv" {
+		return fmt.Errorf("unknown format %q (supported: shell, dotenv)", *format)
+	}
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	y, err := parseConfigData(*configPath, data)
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", *configPath, err)
+	}
+
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	var lines []string
+	for _, dir := range dirs {
+		directives, err := generator.FindDirectives(dir)
+		if err != nil {
+			return err
+		}
+		if len(directives) == 0 {
+			return fmt.Errorf("no //go:generate ggconfig directives found in %s", dir)
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		packageName := filepath.Base(abs)
+		for _, d := range directives {
+			info, err := generator.ParseInterface(dir, d.SourceFile, packageName, packageName, d.Interface)
+			if err != nil {
+				return err
+			}
+			d.Aliases = generator.MethodAliases(info, d.Aliases)
+			for _, m := range info.Methods {
+				value, ok := lookupExportValue(y, packageName, m, d.Aliases)
+				if !ok {
+					continue
+				}
+				lines = append(lines, formatEnvLine(*format, generator.EnvKey(d.EnvKeyPrefix(packageName), m.Name), value))
+			}
+		}
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", *output, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupExportValue ищет значение метода в YAML в том же порядке, что и сгенерированная
+// YAML реализация (сначала алиасные секции и ключи), и переводит его в формат ENV:
+// скаляры как есть, массивы и объекты - JSON.
+// parseConfigData разбирает конфиг по расширению: .json, .hcl и .cue - как JSONConfig, HCLConfig
+// и CUEConfig, остальное - как YAML
+func parseConfigData(path string, data []byte) (*runtime.YAML, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return runtime.ParseJSON(data)
+	case ".hcl":
+		return runtime.ParseHCL(data)
+	case ".cue":
+		return runtime.ParseCUE(data)
+	}
+	return runtime.ParseYAML(data)
+}
+
+func lookupExportValue(y *runtime.YAML, section string, m generator.Method, aliases generator.AliasSettings) (string, bool) {
+	keys := append(append([]string{}, aliases.YAMLKey[m.Name]...), strings.ToLower(m.Name))
+	sections := append(append([]string{}, aliases.YAMLSection...), section)
+	for _, sec := range sections {
+		raw, ok := y.GetRaw(sec, keys...)
+		if !ok {
+			continue
+		}
+		if value, ok := exportNodeValue(raw, m); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// exportNodeValue переводит значение YAML в формат ENV: скаляры как есть, массивы и
+// объекты - JSON. Пустые значения и null не считаются заданными
+func exportNodeValue(raw runtime.Raw, m generator.Method) (string, bool) {
+	if raw.IsZero() {
+		return "", false
+	}
+	n := raw.Node
+	if n.Kind == yaml.ScalarNode {
+		if n.Tag == "!!null" {
+			return "", false
+		}
+		// Длительность в YAML может быть числом секунд, а ENV разбирается time.ParseDuration
+		if m.Kind == generator.KindDuration && (n.Tag == "!!int" || n.Tag == "!!float") {
+			return n.Value + "s", true
+		}
+		return n.Value, true
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return "", false
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func formatEnvLine(format, key, value string) string {
+	if format == "dotenv" {
+		return key + "=" + dotenvQuote(value)
+	}
+	// В одинарных кавычках shell ничего не раскрывает; сама кавычка записывается как '\''
+	return "export " + key + "='" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// dotenvQuote оставляет простые значения без кавычек, остальные записывает в двойных кавычках
+func dotenvQuote(value string) string {
+	safe := value != ""
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:@,+", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
+
+// runFacade реализует команду facade: интерфейсы разных пакетов, сгенерированные с --registry
+// в один выходной пакет, собираются в одну структуру с полем на пакет (DB db.Config,
+// Server server.Config), которая заполняется из GlobalConfig одним вызовом.
+func runFacade(args []string) error {
+	fs := flag.NewFlagSet("facade", flag.ExitOnError)
+	output := fs.String("output", "", "registry package dir (default: the only --output of --registry directives)")
+	name := fs.String("name", "AppConfig", "facade struct name")
+	templateDir := fs.String("template-dir", "", "directory with a facade.go.tmpl that replaces the embedded template")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig facade [--output=internal/gconfig] [--name=AppConfig] [--template-dir=dir] [root]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *templateDir != "" {
+		if err := generator.CheckTemplateDir(*templateDir); err != nil {
+			return err
+		}
+	}
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+	if !token.IsIdentifier(*name) || !token.IsExported(*name) {
+		return fmt.Errorf("--name=%s is not an exported Go identifier", *name)
+	}
+
+	directives, err := generator.WalkDirectives(root)
+	if err != nil {
+		return err
+	}
+	// Директивы с --registry группируются по выходному пакету
+	byOutput := map[string][]generator.Directive{}
+	for _, d := range directives {
+		if !d.Registry || d.Output == "" {
+			continue
+		}
+		out, err := filepath.Abs(filepath.Join(d.Dir, d.Output))
+		if err != nil {
+			return err
+		}
+		byOutput[out] = append(byOutput[out], d)
+	}
+	outDir := ""
+	if *output != "" {
+		if outDir, err = filepath.Abs(*output); err != nil {
+			return err
+		}
+	} else if len(byOutput) == 1 {
+		for dir := range byOutput {
+			outDir = dir
+		}
+	} else if len(byOutput) > 1 {
+		var dirs []string
+		for dir := range byOutput {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		return fmt.Errorf("several registry packages found, choose one with --output: %s", strings.Join(dirs, ", "))
+	}
+	if len(byOutput[outDir]) == 0 {
+		return fmt.Errorf("no //go:generate ggconfig --registry directives with --output found under %s", root)
+	}
+
+	data, err := buildFacade(outDir, *name, *templateDir, byOutput[outDir])
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(outDir, facadeFileName(*name))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Generated %s with %d configs\n", path, len(byOutput[outDir]))
+	return nil
+}
+
+// facadeField - поле фасада: конфигурация одного пакета
+type facadeField struct {
+	Name       string // Имя поля (по имени пакета)
+	Type       string // Исходный интерфейс или конкретный тип AllConfig
+	Getter     string // Метод GlobalConfig реестра
+	UniqueName string
+}
+
+type facadeImport struct {
+	Alias, Path string
+}
+
+func buildFacade(outDir, name, templateDir string, directives []generator.Directive) ([]byte, error) {
+	paths := generator.NewImportPaths()
+	var fields []facadeField
+	var imports []facadeImport
+	usedFields, usedImports := map[string]string{}, map[string]bool{}
+	for _, d := range directives {
+		abs, err := filepath.Abs(d.Dir)
+		if err != nil {
+			return nil, err
+		}
+		unique := d.Name
+		if unique == "" {
+			if unique, err = generator.PackageUniqueName(d.DirectiveDir, d.Dir); err != nil {
+				return nil, err
+			}
+		}
+		packageName := filepath.Base(abs)
+		info, err := generator.ParseInterface(d.Dir, d.SourceFile, packageName, unique, d.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		typeName := d.TypeName(unique)
+		f := facadeField{Name: generator.TitleName(packageName), Getter: "Get" + generator.TitleName(typeName), UniqueName: unique}
+		if prev, ok := usedFields[f.Name]; ok {
+			if prev == typeName {
+				return nil, fmt.Errorf("%s: several registry interfaces in one package need distinct --type-prefix or --type-suffix", d.Dir)
+			}
+			// Одноименные пакеты из разных директорий и интерфейсы одного пакета различаются по имени типов
+			f.Name = generator.TitleName(typeName)
+		}
+		usedFields[f.Name] = typeName
+
+		if facadeInterfaceUsable(d.Interface, info.Methods) {
+			pkgPath, err := paths.Of(d.Dir)
+			if err != nil {
+				return nil, err
+			}
+			alias := packageName
+			if usedImports[alias] {
+				alias = unique
+			}
+			usedImports[alias] = true
+			imports = append(imports, facadeImport{Alias: alias, Path: pkgPath})
+			f.Type = alias + "." + d.Interface
+		} else {
+			// Неэкспортируемые интерфейсы и методы не видны из выходного пакета
+			f.Type = "*" + typeName + "AllConfig"
+		}
+		fields = append(fields, f)
+	}
+
+	var buf bytes.Buffer
+	tmpl, err := generator.LoadTemplate(templateDir, "facade", "facade.go.tmpl", nil)
+	if err != nil {
+		return nil, err
+	}
+	err = tmpl.Execute(&buf, struct {
+		Package string
+		Name    string
+		Imports []facadeImport
+		Fields  []facadeField
+	}{facadePackageName(outDir), name, imports, fields})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// facadeInterfaceUsable сообщает, можно ли объявить поле фасада исходным интерфейсом: он и
+// его методы экспортируются, а аргументы типа обобщенного интерфейса - встроенные типы
+func facadeInterfaceUsable(iface string, methods []generator.Method) bool {
+	base, typeArgs, _ := strings.Cut(iface, "[")
+	if !token.IsExported(base) || strings.Contains(typeArgs, ".") {
+		return false
+	}
+	for _, m := range methods {
+		if !token.IsExported(m.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// facadePackageName берет имя пакета из сгенерированных файлов выходной директории
+func facadePackageName(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range files {
+		if f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly); err == nil {
+			return f.Name.Name
+		}
+	}
+	return filepath.Base(dir)
+}
+
+// facadeFileName - имя файла фасада: AppConfig -> app_config.gen.go
+func facadeFileName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + ".gen.go"
+}
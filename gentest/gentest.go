@@ -0,0 +1,120 @@
+// Package gentest catches stale ggconfig output in go test.
+//
+//	func TestGeneratedConfigs(t *testing.T) {
+//		gentest.RequireUpToDate(t, "./...")
+//	}
+package gentest
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
+
+// RequireUpToDate finds the //go:generate ggconfig directives in the packages matched by
+// patterns (directories, "dir/..." for a directory tree; default "."), checks each of them
+// with the generator of the ggconfig version required by go.mod and fails the test if a
+// checked-in generated or example file differs from what the generator produces now.
+// Nothing is written: the generator compares in memory.
+func RequireUpToDate(t testing.TB, patterns ...string) {
+	t.Helper()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	var dirs []string
+	for _, p := range patterns {
+		found, err := expandPattern(p)
+		if err != nil {
+			t.Fatalf("gentest: %s: %v", p, err)
+		}
+		dirs = append(dirs, found...)
+	}
+
+	var directives []generator.Directive
+	for _, dir := range dirs {
+		found, err := generator.FindDirectives(dir)
+		if err != nil {
+			t.Fatalf("gentest: %v", err)
+		}
+		directives = append(directives, found...)
+	}
+	if len(directives) == 0 {
+		t.Fatalf("gentest: no //go:generate ggconfig directives found in %s", strings.Join(patterns, " "))
+	}
+
+	failed := false
+	for _, d := range directives {
+		stale, err := check(d)
+		if err != nil {
+			failed = true
+			t.Errorf("%s:%d: %v", d.File, d.Line, err)
+		} else if len(stale) > 0 {
+			failed = true
+			t.Errorf("%s:%d: out of date: %s", d.File, d.Line, strings.Join(stale, ", "))
+		}
+	}
+	if failed {
+		t.Fatalf("gentest: generated files are out of date, run go generate ./...")
+	}
+}
+
+// check повторяет генерацию директивы d в режиме Check, как ее запустил бы go generate
+// (директория файла, $GOFILE, $GOLINE), и возвращает устаревшие файлы
+func check(d generator.Directive) ([]string, error) {
+	opts, err := d.Flags.Options()
+	if err != nil {
+		return nil, err
+	}
+	opts.Dir, opts.File = d.DirectiveDir, filepath.Base(d.File)
+	if opts.Interface == "" {
+		opts.Line = d.Line
+	}
+	if opts.VendorRuntime && !opts.NoDeps {
+		if opts.RuntimeSources, err = runtimeSources(d.DirectiveDir); err != nil {
+			return nil, err
+		}
+	}
+	res, err := generator.New(opts).Check()
+	if err != nil {
+		return nil, err
+	}
+	return res.Stale, nil
+}
+
+// runtimeSources возвращает исходники пакета runtime версии из go.mod: их копирует --vendor-runtime
+func runtimeSources(dir string) (fs.FS, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.Dir}}", generator.RuntimeImportPath)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list %s: %w: %s", generator.RuntimeImportPath, err, strings.TrimSpace(stderr.String()))
+	}
+	return os.DirFS(strings.TrimSpace(string(out))), nil
+}
+
+// expandPattern превращает "dir" в саму директорию, "dir/..." - во все пакеты модуля под ней
+// (обход generator.WalkPackageDirs)
+func expandPattern(pattern string) ([]string, error) {
+	root, recursive := strings.CutSuffix(pattern, "/...")
+	if root == "" {
+		root = "."
+	}
+	if !recursive {
+		return []string{root}, nil
+	}
+	var dirs []string
+	err := generator.WalkPackageDirs(root, func(dir string) error {
+		dirs = append(dirs, dir)
+		return nil
+	})
+	return dirs, err
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
+
+// runGraph реализует команду graph: граф пакетов с интерфейсами конфигурации, бинарников,
+// которые их используют (напрямую или через реестр), и YAML секций / ENV префиксов.
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := fs.String("format", "dot", "output format: dot | mermaid")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig graph [--format=dot|mermaid] [-o file] [root]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "dot" && *format != "mermaid" {
+		return fmt.Errorf("unknown format %q (supported: dot, mermaid)", *format)
+	}
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+
+	g, err := buildConfigGraph(root)
+	if err != nil {
+		return err
+	}
+	var text string
+	if *format == "mermaid" {
+		text = g.mermaid()
+	} else {
+		text = g.dot()
+	}
+	if *output == "" {
+		fmt.Print(text)
+		return nil
+	}
+	return os.WriteFile(*output, []byte(text), 0644)
+}
+
+type graphNode struct {
+	ID    string
+	Kind  string // binary | config | yaml | env
+	Label string
+}
+
+type graphEdge struct {
+	From, To string
+	Label    string
+	Dashed   bool
+}
+
+type configGraph struct {
+	nodes map[string]graphNode
+	edges map[graphEdge]bool
+}
+
+func (g *configGraph) node(kind, key, label string) string {
+	id := kind + ":" + key
+	if _, ok := g.nodes[id]; !ok {
+		g.nodes[id] = graphNode{ID: id, Kind: kind, Label: label}
+	}
+	return id
+}
+
+func buildConfigGraph(root string) (*configGraph, error) {
+	directives, err := generator.WalkDirectives(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(directives) == 0 {
+		return nil, fmt.Errorf("no //go:generate ggconfig directives found under %s", root)
+	}
+	g := &configGraph{nodes: map[string]graphNode{}, edges: map[graphEdge]bool{}}
+	paths := generator.NewImportPaths()
+
+	// Пакет (import path) -> узлы конфигураций, которые он предоставляет
+	type provided struct {
+		id       string
+		registry bool
+	}
+	providers := map[string][]provided{}
+	for _, d := range directives {
+		pkgPath, err := paths.Of(d.Dir)
+		if err != nil {
+			return nil, err
+		}
+		abs, err := filepath.Abs(d.Dir)
+		if err != nil {
+			return nil, err
+		}
+		packageName := filepath.Base(abs)
+		cfg := g.node("config", pkgPath+"."+d.Interface, dirLabel(d.Dir)+"\n"+d.Interface)
+		providers[pkgPath] = append(providers[pkgPath], provided{id: cfg})
+		if d.Output != "" {
+			outPath, err := paths.Of(filepath.Join(d.Dir, d.Output))
+			if err != nil {
+				return nil, err
+			}
+			providers[outPath] = append(providers[outPath], provided{id: cfg, registry: d.Registry})
+		}
+
+		for _, section := range append([]string{packageName}, d.Aliases.YAMLSection...) {
+			g.edges[graphEdge{From: cfg, To: g.node("yaml", section, "yaml: "+section)}] = true
+		}
+		prefix := strings.ToUpper(packageName) + "_*"
+		g.edges[graphEdge{From: cfg, To: g.node("env", prefix, "env: "+prefix)}] = true
+		for _, names := range d.Aliases.Env {
+			for _, name := range names {
+				g.edges[graphEdge{From: cfg, To: g.node("env", name, "env: "+name), Label: "alias"}] = true
+			}
+		}
+	}
+
+	binaries, err := findMainPackages(root)
+	if err != nil {
+		return nil, err
+	}
+	for dir, imports := range binaries {
+		binPath, err := paths.Of(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range imports {
+			for _, p := range providers[imp] {
+				bin := g.node("binary", binPath, dirLabel(dir)+"\n(main)")
+				// Одна связь на пару бинарник-конфигурация: использование через реестр важнее прямого импорта
+				direct := graphEdge{From: bin, To: p.id}
+				registry := graphEdge{From: bin, To: p.id, Label: "registry", Dashed: true}
+				if p.registry {
+					delete(g.edges, direct)
+					g.edges[registry] = true
+				} else if !g.edges[registry] {
+					g.edges[direct] = true
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// dirLabel - путь директории для подписи узла
+func dirLabel(dir string) string {
+	return filepath.ToSlash(filepath.Clean(dir))
+}
+
+// findMainPackages находит пакеты main под root и их импорты
+func findMainPackages(root string) (map[string][]string, error) {
+	out := map[string][]string{}
+	err := generator.WalkPackageDirs(root, func(dir string) error {
+		pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi fs.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, parser.ImportsOnly)
+		if err != nil {
+			return nil // Пакеты с ошибками разбора пропускаем
+		}
+		pkg, ok := pkgs["main"]
+		if !ok {
+			return nil
+		}
+		seen := map[string]bool{}
+		for _, f := range pkg.Files {
+			for _, imp := range f.Imports {
+				if p, err := strconv.Unquote(imp.Path.Value); err == nil && !seen[p] {
+					seen[p] = true
+					out[dir] = append(out[dir], p)
+				}
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (g *configGraph) sortedNodes() []graphNode {
+	nodes := make([]graphNode, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	order := map[string]int{"binary": 0, "config": 1, "yaml": 2, "env": 3}
+	sort.Slice(nodes, func(i, j int) bool {
+		if order[nodes[i].Kind] != order[nodes[j].Kind] {
+			return order[nodes[i].Kind] < order[nodes[j].Kind]
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+func (g *configGraph) sortedEdges() []graphEdge {
+	edges := make([]graphEdge, 0, len(g.edges))
+	for e := range g.edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		// Связи одной пары узлов (прямая и через алиас) тоже упорядочены: sort.Slice не стабилен
+		return edges[i].Label < edges[j].Label
+	})
+	return edges
+}
+
+func (g *configGraph) dot() string {
+	shapes := map[string]string{"binary": "component", "config": "box", "yaml": "note", "env": "note"}
+	var b strings.Builder
+	b.WriteString("digraph ggconfig {\n\trankdir=LR;\n")
+	for _, n := range g.sortedNodes() {
+		fmt.Fprintf(&b, "\t%q [shape=%s, label=%q];\n", n.ID, shapes[n.Kind], n.Label)
+	}
+	for _, e := range g.sortedEdges() {
+		var attrs []string
+		if e.Label != "" {
+			attrs = append(attrs, "label="+strconv.Quote(e.Label))
+		}
+		if e.Dashed {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "\t%q -> %q [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *configGraph) mermaid() string {
+	// В Mermaid идентификаторы узлов - простые имена, подписи - в кавычках
+	ids := map[string]string{}
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, n := range g.sortedNodes() {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		label := strings.ReplaceAll(strings.ReplaceAll(n.Label, `"`, "#quot;"), "\n", "<br/>")
+		switch n.Kind {
+		case "binary":
+			fmt.Fprintf(&b, "\t%s[[\"%s\"]]\n", id, label)
+		case "config":
+			fmt.Fprintf(&b, "\t%s[\"%s\"]\n", id, label)
+		default:
+			fmt.Fprintf(&b, "\t%s[/\"%s\"/]\n", id, label)
+		}
+	}
+	for _, e := range g.sortedEdges() {
+		arrow := "-->"
+		if e.Dashed {
+			arrow = "-.->"
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "\t%s %s|%s| %s\n", ids[e.From], arrow, e.Label, ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "\t%s %s %s\n", ids[e.From], arrow, ids[e.To])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"gopkg.in/yaml.v3"
+)
+
+// viperKey - ключ viper внутри секции и тип метода, который для него будет создан
+type viperKey struct {
+	Key      string // Исходный ключ (read_timeout, readTimeout)
+	Method   string // Имя метода (пусто - выводится из ключа)
+	Type     string // Тип метода в интерфейсе
+	Original string // Исходный тип, если он заменен на runtime.Raw
+}
+
+// viperSection - секция верхнего уровня конфига viper, из нее получается пакет с интерфейсом Config
+type viperSection struct {
+	Name string
+	Keys []viperKey
+}
+
+// runImportViper реализует команду import-viper: по YAML файлу viper или структуре
+// с тегами mapstructure создает по пакету на секцию с интерфейсом Config,
+// директивой go:generate и алиасами для исходных имен ключей.
+func runImportViper(args []string) error {
+	fs := flag.NewFlagSet("import-viper", flag.ExitOnError)
+	yamlPath := fs.String("yaml", "", "viper YAML config file")
+	goPath := fs.String("go", "", "Go file or package directory with the viper config struct")
+	structName := fs.String("struct", "Config", "name of the viper config struct (with --go)")
+	outDir := fs.String("out", "internal", "directory for generated packages (one per section)")
+	generateArgs := fs.String("generate-args", "", "extra ggconfig flags for the go:generate directive, e.g. \"--output=../gconfig --registry\"")
+	envPrefix := fs.String("env-prefix", "", "viper SetEnvPrefix value: adds env aliases PREFIX_SECTION_KEY (with SetEnvKeyReplacer(\".\" -> \"_\"))")
+	stdout := fs.Bool("stdout", false, "print generated files instead of writing them")
+	force := fs.Bool("force", false, "overwrite existing config.go files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig import-viper (--yaml=config.yaml | --go=path [--struct=Config]) [--out=internal] [options]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var sections []viperSection
+	var skipped []string
+	var err error
+	switch {
+	case *yamlPath != "" && *goPath != "":
+		return fmt.Errorf("--yaml and --go are mutually exclusive")
+	case *yamlPath != "":
+		sections, skipped, err = viperSectionsFromYAML(*yamlPath)
+	case *goPath != "":
+		sections, skipped, err = viperSectionsFromStruct(*goPath, *structName)
+	default:
+		fs.Usage()
+		return fmt.Errorf("--yaml or --go is required")
+	}
+	if err != nil {
+		return err
+	}
+	if len(sections) == 0 {
+		return fmt.Errorf("no sections found")
+	}
+
+	for _, sec := range sections {
+		pkg := viperPackageName(sec.Name)
+		src := renderViperInterface(pkg, sec, *generateArgs, *envPrefix)
+		if *stdout {
+			fmt.Printf("// ===== %s =====\n%s\n", filepath.Join(*outDir, pkg, "config.go"), src)
+			continue
+		}
+		dir := filepath.Join(*outDir, pkg)
+		path := filepath.Join(dir, "config.go")
+		if _, err := os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+		if err := os.WriteFile(path, src, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		fmt.Printf("✅ %s: %d methods\n", path, len(sec.Keys))
+	}
+	for _, key := range skipped {
+		fmt.Fprintf(os.Stderr, "⚠️  skipped %q: top-level keys outside a section are not supported, move it into a section\n", key)
+	}
+	return nil
+}
+
+func viperSectionsFromYAML(path string) ([]viperSection, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%s: top-level mapping expected", path)
+	}
+
+	var sections []viperSection
+	var skipped []string
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name, value := root.Content[i].Value, root.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			skipped = append(skipped, name)
+			continue
+		}
+		sec := viperSection{Name: name}
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			sec.Keys = append(sec.Keys, viperKey{Key: value.Content[j].Value, Type: yamlNodeType(value.Content[j+1])})
+		}
+		sections = append(sections, sec)
+	}
+	return sections, skipped, nil
+}
+
+// yamlNodeType подбирает тип метода по значению в YAML
+func yamlNodeType(n *yaml.Node) string {
+	if n.Kind == yaml.SequenceNode && len(n.Content) > 0 {
+		// Список скаляров - []string, только целые числа - []int, числа с дробными - []float64
+		ints, numbers := true, true
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "runtime.Raw"
+			}
+			ints = ints && item.Tag == "!!int"
+			numbers = numbers && (item.Tag == "!!int" || item.Tag == "!!float")
+		}
+		switch {
+		case ints:
+			return "[]int"
+		case numbers:
+			return "[]float64"
+		}
+		return "[]string"
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "runtime.Raw"
+	}
+	switch n.Tag {
+	case "!!int":
+		return "int"
+	case "!!bool":
+		return "bool"
+	}
+	return "string"
+}
+
+func viperSectionsFromStruct(path, structName string) ([]viperSection, []string, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	if st, err := os.Stat(path); err == nil && st.IsDir() {
+		pkgs, err := parser.ParseDir(fset, path, nil, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		// Файлы - в порядке имен: одноименные структуры разных файлов не должны выбираться случайно
+		byName := map[string]*ast.File{}
+		var names []string
+		for _, pkg := range pkgs {
+			for name, f := range pkg.Files {
+				byName[name] = f
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, byName[name])
+		}
+	} else {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		files = append(files, f)
+	}
+
+	structs := map[string]*ast.StructType{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+	root, ok := structs[structName]
+	if !ok {
+		return nil, nil, fmt.Errorf("struct %s not found in %s", structName, path)
+	}
+
+	var sections []viperSection
+	var skipped []string
+	for _, field := range root.Fields.List {
+		for _, name := range viperFieldNames(field) {
+			st := viperStructType(field.Type, structs)
+			if st == nil {
+				skipped = append(skipped, name)
+				continue
+			}
+			sec := viperSection{Name: name}
+			for _, f := range st.Fields.List {
+				typ, original := viperFieldType(f.Type)
+				keys := viperFieldNames(f)
+				for i, key := range keys {
+					k := viperKey{Key: key, Type: typ, Original: original}
+					// Без тега mapstructure имя метода совпадает с именем поля
+					if len(f.Names) == len(keys) && strings.ToLower(f.Names[i].Name) == key {
+						k.Method = f.Names[i].Name
+					}
+					sec.Keys = append(sec.Keys, k)
+				}
+			}
+			sections = append(sections, sec)
+		}
+	}
+	return sections, skipped, nil
+}
+
+// viperFieldNames возвращает ключи viper для поля: тег mapstructure или имя поля
+// (viper сопоставляет ключи без учета регистра, поэтому имя поля приводится к нижнему регистру)
+func viperFieldNames(field *ast.Field) []string {
+	if field.Tag != nil {
+		tag, _ := strconv.Unquote(field.Tag.Value)
+		if v, ok := reflect.StructTag(tag).Lookup("mapstructure"); ok {
+			name, _, _ := strings.Cut(v, ",")
+			if name == "-" {
+				return nil
+			}
+			if name != "" {
+				return []string{name}
+			}
+		}
+	}
+	var names []string
+	for _, n := range field.Names {
+		if n.IsExported() {
+			names = append(names, strings.ToLower(n.Name))
+		}
+	}
+	return names
+}
+
+func viperStructType(expr ast.Expr, structs map[string]*ast.StructType) *ast.StructType {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t
+	case *ast.StarExpr:
+		return viperStructType(t.X, structs)
+	case *ast.Ident:
+		return structs[t.Name]
+	}
+	return nil
+}
+
+// viperFieldType возвращает тип метода для поля структуры и исходный тип,
+// если он не поддерживается генератором и заменен на runtime.Raw
+func viperFieldType(expr ast.Expr) (string, string) {
+	var buf bytes.Buffer
+	if err := formatNode(&buf, expr); err != nil {
+		return "runtime.Raw", "?"
+	}
+	typ := buf.String()
+	if typ == "string" || typ == "bool" || generator.IsIntegerType(typ) || typ == "time.Duration" || typ == "time.Time" || typ == "[]string" || generator.IsNumberSliceType(typ) {
+		return typ, ""
+	}
+	return "runtime.Raw", typ
+}
+
+func formatNode(buf *bytes.Buffer, expr ast.Expr) error {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		buf.WriteString(t.Name)
+	case *ast.SelectorExpr:
+		if err := formatNode(buf, t.X); err != nil {
+			return err
+		}
+		buf.WriteString("." + t.Sel.Name)
+	case *ast.StarExpr:
+		buf.WriteString("*")
+		return formatNode(buf, t.X)
+	case *ast.ArrayType:
+		buf.WriteString("[]")
+		return formatNode(buf, t.Elt)
+	case *ast.MapType:
+		buf.WriteString("map[")
+		if err := formatNode(buf, t.Key); err != nil {
+			return err
+		}
+		buf.WriteString("]")
+		return formatNode(buf, t.Value)
+	case *ast.StructType:
+		buf.WriteString("struct{...}")
+	case *ast.InterfaceType:
+		buf.WriteString("interface{}")
+	default:
+		return fmt.Errorf("unsupported type expression %T", expr)
+	}
+	return nil
+}
+
+// viperMethodName превращает ключ viper в имя метода: read_timeout, read-timeout, readTimeout -> ReadTimeout
+func viperMethodName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if r == '_' || r == '-' || r == '.' || r == ' ' {
+			upper = true
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		if upper {
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Key" + name
+	}
+	return name
+}
+
+// viperPackageName превращает имя секции в имя пакета Go
+func viperPackageName(section string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(section) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "cfg" + name
+	}
+	return name
+}
+
+// renderViperInterface формирует config.go пакета секции с алиасами, сохраняющими имена viper:
+// YAML читает секцию по имени пакета и ключи по имени метода в нижнем регистре,
+// ENV - <SECTION>_<METHOD>; все расхождения закрываются флагами --alias.
+func renderViperInterface(pkg string, sec viperSection, generateArgs, envPrefix string) []byte {
+	var aliases []string
+	if pkg != sec.Name {
+		aliases = append(aliases, "--alias yaml.section="+sec.Name)
+	}
+	usesRaw, usesTime := false, false
+	var methods strings.Builder
+	seen := map[string]bool{}
+	for _, k := range sec.Keys {
+		name := k.Method
+		if name == "" {
+			name = viperMethodName(k.Key)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if k.Key != strings.ToLower(name) {
+			aliases = append(aliases, "--alias yaml.key."+name+"="+k.Key)
+		}
+		if envPrefix != "" {
+			viperEnv := strings.ToUpper(envPrefix + "_" + sec.Name + "_" + k.Key)
+			viperEnv = strings.NewReplacer("-", "_", ".", "_").Replace(viperEnv)
+			if viperEnv != generator.EnvKey(pkg, name) {
+				aliases = append(aliases, "--alias env."+name+"="+viperEnv)
+			}
+		}
+		if k.Type == "runtime.Raw" {
+			usesRaw = true
+		}
+		if k.Type == "time.Duration" || k.Type == "time.Time" {
+			usesTime = true
+		}
+		if k.Original != "" {
+			fmt.Fprintf(&methods, "\t// %s was %s in the viper struct: decode it with Raw.Decode\n", name, k.Original)
+		} else {
+			fmt.Fprintf(&methods, "\t// %s is %s.%s\n", name, sec.Name, k.Key)
+		}
+		fmt.Fprintf(&methods, "\t%s(defaultValue %s) (%s, bool)\n", name, k.Type, k.Type)
+	}
+
+	directive := "//go:generate ggconfig --interface=Config"
+	if generateArgs != "" {
+		directive += " " + generateArgs
+	}
+	for _, a := range aliases {
+		directive += " " + a
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	switch {
+	case usesRaw && usesTime:
+		b.WriteString("import (\n\t\"time\"\n\n\t\"github.com/apopov-app/ggconfig/runtime\"\n)\n\n")
+	case usesRaw:
+		b.WriteString("import \"github.com/apopov-app/ggconfig/runtime\"\n\n")
+	case usesTime:
+		b.WriteString("import \"time\"\n\n")
+	}
+	fmt.Fprintf(&b, "// Config is generated by ggconfig import-viper from the %q section.\n//\n", sec.Name)
+	b.WriteString(directive + "\n")
+	b.WriteString("type Config interface {\n")
+	b.WriteString(methods.String())
+	b.WriteString("}\n")
+	return b.Bytes()
+}
@@ -1,74 +1,105 @@
 package main
 
 import (
+	"embed"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"io/fs"
 	"log"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
-	"text/template"
-)
-
-const version = "1.0.4"
-
-type Method struct {
-	Name       string
-	ParamType  string
-	ReturnType string // value type (first return value)
-	Comment    string // Добавляем поле для комментария
-	IsSlice    bool   // Является ли возвращаемый тип массивом
-	ElemType   string // Тип элемента массива (если IsSlice == true)
-}
-
-type InterfaceInfo struct {
-	PackageName       string // Оригинальное имя пакета (для обратной совместимости)
-	UniquePackageName string // Уникальное имя на основе пути
-	InterfaceName     string
-	Methods           []Method
-	ImportPath        string // Путь для импорта пакета (если генерация в другой пакет)
-	NeedImport        bool   // Нужен ли импорт оригинального пакета
-}
-
-// Настройки алиасов, передаваемые через --alias
-type AliasSettings struct {
-	// ENV: MethodName -> []EnvVarAlias (полные имена переменных окружения)
-	Env map[string][]string
-	// YAML: алиасы имени секции (например, server_name, svc)
-	YAMLSection []string
-	// YAML: MethodName -> []KeyAlias внутри секции
-	YAMLKey map[string][]string
-}
 
-// Поддержка повторяющегося флага --alias
-type aliasFlag []string
-
-func (a *aliasFlag) String() string {
-	return strings.Join(*a, ",")
-}
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
 
-func (a *aliasFlag) Set(value string) error {
-	*a = append(*a, value)
-	return nil
-}
+// Исходники пакета runtime встраиваются в бинарник генератора,
+// чтобы --vendor-runtime мог скопировать их в сгенерированный пакет.
+//
+//go:embed runtime/*.go
+var runtimeSources embed.FS
 
 func main() {
-	interfaceName := flag.String("interface", "", "interface name")
-	outputPath := flag.String("output", "", "output directory path")
-	examplePath := flag.String("example", "", "generate example config file")
-	registryEnabled := flag.Bool("registry", false, "enable global registry: generates registry.gen.go in output package and init() self-registration in each generated file")
-	packageNameOverride := flag.String("name", "", "override package name for generation (default: auto-detect from path)")
+	// Режим рабочего пространства: ggconfig ./... выполняет все директивы модуля
+	if hasWorkspacePattern(os.Args[1:]) {
+		if err := runWorkspace(os.Args[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+	// Подкоманды: ggconfig <command> [flags]
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export-env":
+			if err := runExportEnv(os.Args[2:]); err != nil {
+				log.Fatalf("export-env: %v", err)
+			}
+			return
+		case "import-viper":
+			if err := runImportViper(os.Args[2:]); err != nil {
+				log.Fatalf("import-viper: %v", err)
+			}
+			return
+		case "set":
+			if err := runSet(os.Args[2:]); err != nil {
+				log.Fatalf("set: %v", err)
+			}
+			return
+		case "scaffold":
+			if err := runScaffold(os.Args[2:]); err != nil {
+				log.Fatalf("scaffold: %v", err)
+			}
+			return
+		case "graph":
+			if err := runGraph(os.Args[2:]); err != nil {
+				log.Fatalf("graph: %v", err)
+			}
+			return
+		case "explain":
+			if err := runExplain(os.Args[2:]); err != nil {
+				log.Fatalf("explain: %v", err)
+			}
+			return
+		case "facade":
+			if err := runFacade(os.Args[2:]); err != nil {
+				log.Fatalf("facade: %v", err)
+			}
+			return
+		case "probe":
+			if err := runProbe(os.Args[2:]); err != nil {
+				log.Fatalf("probe: %v", err)
+			}
+			return
+		case "clean":
+			if err := runClean(os.Args[2:]); err != nil {
+				log.Fatalf("clean: %v", err)
+			}
+			return
+		case "encrypt":
+			if err := runEncrypt(os.Args[2:]); err != nil {
+				log.Fatalf("encrypt: %v", err)
+			}
+			return
+		}
+	}
+
 	showVersion := flag.Bool("version", false, "show version information")
-	var aliasFlags aliasFlag
-	flag.Var(&aliasFlags, "alias", "alias mapping: env.<Method>=ALIAS1,ALIAS2 | yaml.section=ALIAS1,ALIAS2 | yaml.key.<Method>=ALIAS1,ALIAS2")
+	showTemplates := flag.Bool("print-templates", false, "print the code generation templates embedded in this binary (with sha256 checksums) and exit")
+	var gen generator.Flags
+	gen.Register(flag.CommandLine)
 	flag.Parse()
 
-	// Show version and info if no arguments or --version flag
-	if *showVersion || (flag.NFlag() == 0 && len(os.Args) == 1) {
-		fmt.Printf("ggconfig v%s - Go Configuration Generator\n", version)
+	if *showTemplates {
+		if err := generator.PrintTemplates(os.Stdout); err != nil {
+			log.Fatalf("print-templates: %v", err)
+		}
+		return
+	}
+
+	// Show version and info if no arguments or --version flag.
+	// Под go generate (GOFILE) директива без флагов генерирует интерфейс под ней по настройкам манифеста
+	if *showVersion || (flag.NFlag() == 0 && len(os.Args) == 1 && os.Getenv("GOFILE") == "") {
+		fmt.Printf("ggconfig v%s - Go Configuration Generator\n", generator.Version)
 		fmt.Println("\nA Go-way configuration generator that creates type-safe config implementations")
 		fmt.Println("from interface definitions.")
 		fmt.Println("\nFeatures:")
@@ -79,1068 +110,92 @@ func main() {
 		fmt.Println("  • Alias support for ENV and YAML keys")
 		fmt.Println("\nUsage:")
 		fmt.Println("  ggconfig --interface=Config [options]")
+		fmt.Println("  ggconfig export-env [--config=config.yaml] [--format=shell|dotenv] [package dirs...]")
+		fmt.Println("  ggconfig import-viper (--yaml=config.yaml | --go=path [--struct=Config]) [--out=internal]")
+		fmt.Println("  ggconfig set [--file=config.yaml] [--pkg=dir] section.key value")
+		fmt.Println("  ggconfig scaffold [--out=internal] db|http-server|redis|kafka|s3...")
+		fmt.Println("  ggconfig graph [--format=dot|mermaid] [-o file] [root]")
+		fmt.Println("  ggconfig explain [--config=config.yaml] [--sources=env,yaml] [--format=text|markdown] section.key | --all")
+		fmt.Println("  ggconfig facade [--output=internal/gconfig] [--name=AppConfig] [root]")
+		fmt.Println("  ggconfig probe [--sources=env,yaml=config.yaml,consul=prefix] [--pkg=dir] [--fail-on-missing]")
+		fmt.Println("  ggconfig encrypt [--decrypt] [value] | --generate-key")
+		fmt.Println("  ggconfig clean [--dry-run] [root]")
+		fmt.Println("  ggconfig [-j N] [-v] [--check] [--dry-run] ./...")
 		fmt.Println("\nOptions:")
 		flag.PrintDefaults()
 		fmt.Println("\nExamples:")
 		fmt.Println("  ggconfig --interface=Config")
 		fmt.Println("  ggconfig --interface=Config --output=internal/gconfig --registry")
 		fmt.Println("  ggconfig --interface=Config --alias yaml.section=jwt")
+		fmt.Println("  ggconfig --check ./...")
+		fmt.Println("  ggconfig export-env --config=config.yaml internal/server internal/database > .env.sh")
+		fmt.Println("  ggconfig set --file config.yaml server.port 9090")
+		fmt.Println("  ggconfig scaffold --generate-args=\"--example=example_configs\" db http-server")
 		fmt.Println("\nDocumentation:")
 		fmt.Println("  https://github.com/apopov-app/ggconfig")
 		return
 	}
 
-	// Автоматически определяем пакет из текущей директории
-	currentDir, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("failed to get current directory: %v", err)
-	}
-
-	var uniquePackageName string
-	packageName := filepath.Base(currentDir)
-
-	if *packageNameOverride != "" {
-		// Используем имя, заданное вручную
-		uniquePackageName = *packageNameOverride
-		fmt.Printf("Using package name: %s\n", uniquePackageName)
-	} else {
-		// Находим корень модуля Go и вычисляем уникальное имя пакета
-		moduleRoot, err := findModuleRoot(currentDir)
-		if err != nil {
-			log.Fatalf("failed to find module root: %v", err)
-		}
-
-		// Вычисляем относительный путь от корня модуля до текущего пакета
-		relPath, err := filepath.Rel(moduleRoot, currentDir)
-		if err != nil {
-			log.Fatalf("failed to compute relative path: %v", err)
-		}
-
-		// Преобразуем путь в уникальное имя (заменяем / на _)
-		uniquePackageName = pathToUniqueName(relPath)
-		fmt.Printf("Auto-detected package: %s (unique: %s)\n", packageName, uniquePackageName)
-	}
-
-	if interfaceName == nil || *interfaceName == "" {
-		log.Fatalf("interface name is required")
-	}
-	fmt.Printf("Generating config for package: %s, interface: %s\n", packageName, *interfaceName)
-
-	// Парсим интерфейс
-	info, err := parseInterface(packageName, uniquePackageName, *interfaceName)
-	if err != nil {
-		log.Fatalf("failed to parse interface: %v", err)
-	}
-
-	// Парсим алиасы
-	aliasSettings := parseAliasSettings(aliasFlags)
-
-	fmt.Printf("Found %d methods in interface\n", len(info.Methods))
-	for _, method := range info.Methods {
-		fmt.Printf("  - %s(%s) (%s, bool)\n", method.Name, method.ParamType, method.ReturnType)
-	}
-
-	// Определяем, нужно ли добавлять импорт
-	if *outputPath != "" {
-		// Проверяем, есть ли кастомные типы (не string, не int)
-		hasCustomTypes := false
-		for _, method := range info.Methods {
-			// Проверяем тип параметра
-			if method.ParamType != "string" && method.ParamType != "int" && method.ParamType != "" {
-				if !strings.HasPrefix(method.ParamType, "[]string") && !strings.HasPrefix(method.ParamType, "[]int") {
-					hasCustomTypes = true
-					break
-				}
-			}
-			// Проверяем тип возвращаемого значения
-			if method.ReturnType != "string" && method.ReturnType != "int" && method.ReturnType != "" {
-				if !strings.HasPrefix(method.ReturnType, "[]string") && !strings.HasPrefix(method.ReturnType, "[]int") {
-					hasCustomTypes = true
-					break
-				}
-			}
-		}
-
-		if hasCustomTypes {
-			// Генерация в другой пакет - нужен импорт
-			info.NeedImport = true
-			// Вычисляем import path
-			moduleRoot, err := findModuleRoot(currentDir)
-			if err == nil {
-				moduleName, err := getModuleName(moduleRoot)
-				if err == nil {
-					relPath, err := filepath.Rel(moduleRoot, currentDir)
-					if err == nil && relPath != "." {
-						info.ImportPath = filepath.Join(moduleName, relPath)
-					} else {
-						info.ImportPath = moduleName
-					}
-				}
-			}
-		}
-	}
-
-	// Генерируем все реализации в одном файле
-	if err := generateImplementation(info, aliasSettings, *outputPath, *registryEnabled); err != nil {
-		log.Fatalf("failed to generate implementation: %v", err)
-	}
-
-	// Генерируем пример конфига если указан путь
-	if *examplePath != "" {
-		if err := generateExampleConfig(info, *examplePath); err != nil {
-			log.Fatalf("failed to generate example config: %v", err)
-		}
-	}
-
-	outputDisplayPath := *outputPath
-	if outputDisplayPath == "" {
-		outputDisplayPath = "current package"
-	}
-	fmt.Printf("✅ Generated config for %s.%s in %s\n", info.UniquePackageName, info.InterfaceName, outputDisplayPath)
-}
-
-func parseInterface(packageName, uniquePackageName, interfaceName string) (*InterfaceInfo, error) {
-	// Парсим текущую директорию (где находится go:generate директива)
-	packagePath := "."
-
-	fmt.Printf("Parsing package: %s\n", packagePath)
-
-	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, packagePath, nil, parser.ParseComments)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse package %s: %w", packagePath, err)
-	}
-
-	var methods []Method
-
-	// Ищем интерфейс во всех файлах пакета
-	for _, pkg := range pkgs {
-		for _, file := range pkg.Files {
-			ast.Inspect(file, func(n ast.Node) bool {
-				if typeDecl, ok := n.(*ast.TypeSpec); ok {
-					if typeDecl.Name.Name == interfaceName {
-						if interfaceType, ok := typeDecl.Type.(*ast.InterfaceType); ok {
-							for _, method := range interfaceType.Methods.List {
-								if funcType, ok := method.Type.(*ast.FuncType); ok {
-									methodName := method.Names[0].Name
-									paramType, returnType, err := getMethodSignature(funcType)
-									if err != nil {
-										// Fail fast: new ggconfig requires (T, bool) return signature
-										log.Fatalf("bad method signature %s.%s: %v", interfaceName, methodName, err)
-									}
-
-									// Извлекаем комментарий из документации
-									comment := ""
-									if method.Doc != nil && len(method.Doc.List) > 0 {
-										comment = strings.TrimSpace(strings.TrimPrefix(method.Doc.List[0].Text, "//"))
-									}
-
-									// Определяем, является ли тип массивом
-									isSlice := strings.HasPrefix(returnType, "[]")
-									elemType := ""
-									if isSlice {
-										elemType = strings.TrimPrefix(returnType, "[]")
-									}
-
-									methods = append(methods, Method{
-										Name:       methodName,
-										ParamType:  paramType,
-										ReturnType: returnType,
-										Comment:    comment,
-										IsSlice:    isSlice,
-										ElemType:   elemType,
-									})
-								}
-							}
-						}
-					}
-				}
-				return true
-			})
-		}
-	}
-
-	if len(methods) == 0 {
-		return nil, fmt.Errorf("interface %s not found in package %s", interfaceName, packageName)
-	}
-
-	return &InterfaceInfo{
-		PackageName:       packageName,
-		UniquePackageName: uniquePackageName,
-		InterfaceName:     interfaceName,
-		Methods:           methods,
-	}, nil
-}
-
-// findModuleRoot находит корень модуля Go, ища go.mod файл
-func findModuleRoot(startDir string) (string, error) {
-	dir := startDir
-	for {
-		goModPath := filepath.Join(dir, "go.mod")
-		if _, err := os.Stat(goModPath); err == nil {
-			return dir, nil
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			// Дошли до корня файловой системы
-			return "", fmt.Errorf("go.mod not found from %s", startDir)
-		}
-		dir = parent
-	}
-}
-
-// getModuleName читает имя модуля из go.mod
-func getModuleName(moduleRoot string) (string, error) {
-	goModPath := filepath.Join(moduleRoot, "go.mod")
-	data, err := os.ReadFile(goModPath)
-	if err != nil {
-		return "", err
-	}
-
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
-		}
-	}
-	return "", fmt.Errorf("module name not found in %s", goModPath)
-}
-
-// pathToUniqueName преобразует путь в уникальное имя, заменяя / на _
-func pathToUniqueName(path string) string {
-	// Нормализуем путь (убираем ./ в начале)
-	path = strings.TrimPrefix(path, "./")
-	path = strings.TrimPrefix(path, ".")
-
-	// Заменяем все разделители на _
-	path = strings.ReplaceAll(path, string(filepath.Separator), "_")
-	path = strings.ReplaceAll(path, "/", "_")
-
-	// Убираем повторяющиеся подчеркивания
-	for strings.Contains(path, "__") {
-		path = strings.ReplaceAll(path, "__", "_")
-	}
-
-	// Убираем подчеркивания в начале и конце
-	path = strings.Trim(path, "_")
-
-	// Если путь пустой (корень модуля), используем "root"
-	if path == "" {
-		path = "root"
-	}
-
-	return path
-}
-
-type ReturnTypeInfo struct {
-	TypeName string
-	IsSlice  bool
-	ElemType string
-}
-
-func getTypeName(expr ast.Expr) string {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.SelectorExpr:
-		// For qualified types like pkg.Type
-		return fmt.Sprintf("%s.%s", getTypeName(t.X), t.Sel.Name)
-	case *ast.ArrayType:
-		return "[]" + getTypeName(t.Elt)
-	case *ast.StarExpr:
-		return "*" + getTypeName(t.X)
-	default:
-		return ""
-	}
-}
-
-func getReturnTypes(funcType *ast.FuncType) []ReturnTypeInfo {
-	if funcType.Results == nil || len(funcType.Results.List) == 0 {
-		return nil
-	}
-	var out []ReturnTypeInfo
-	for _, r := range funcType.Results.List {
-		typeName := getTypeName(r.Type)
-		isSlice := strings.HasPrefix(typeName, "[]")
-		elemType := ""
-		if isSlice {
-			elemType = strings.TrimPrefix(typeName, "[]")
-		}
-		out = append(out, ReturnTypeInfo{
-			TypeName: typeName,
-			IsSlice:  isSlice,
-			ElemType: elemType,
-		})
-	}
-	return out
-}
-
-// getEnvValue generates snippet to read env by expression (envKeyExpr) without quoting.
-// envKeyExpr must be a valid Go expression producing a string.
-func getEnvValue(envKeyExpr, defaultValue, returnType string) string {
-	switch returnType {
-	case "int":
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue, true
-		}
-	}
-	return %s, false`, envKeyExpr, defaultValue)
-	case "string":
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-		return value, true
-	}
-	return %s, false`, envKeyExpr, defaultValue)
-	default:
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-		return value, true
-	}
-	return %s, false`, envKeyExpr, defaultValue)
-	}
-}
-
-// Генерирует фрагмент кода проверки ENV по конкретному ключу без возврата default
-func getEnvCheckSnippet(envKeyExpr, returnType string) string {
-	switch returnType {
-	case "int":
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-    if intValue, err := strconv.Atoi(value); err == nil {
-        return intValue, true
-    }
-}`, envKeyExpr)
-	case "string":
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-    return value, true
-}`, envKeyExpr)
-	default:
-		return fmt.Sprintf(`if value := os.Getenv(%s); value != "" {
-    return value, true
-}`, envKeyExpr)
-	}
-}
-
-// Парсинг повторяющихся флагов --alias
-// Допустимые формы:
-// - env.<Method>=ALIAS1,ALIAS2
-// - yaml.section=ALIAS1,ALIAS2
-// - yaml.key.<Method>=ALIAS1,ALIAS2
-func parseAliasSettings(flags aliasFlag) AliasSettings {
-	settings := AliasSettings{
-		Env:         map[string][]string{},
-		YAMLSection: []string{},
-		YAMLKey:     map[string][]string{},
-	}
-
-	for _, item := range flags {
-		parts := strings.SplitN(item, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		left := strings.TrimSpace(parts[0])
-		right := strings.TrimSpace(parts[1])
-		var values []string
-		if right != "" {
-			for _, v := range strings.Split(right, ",") {
-				vv := strings.TrimSpace(v)
-				if vv != "" {
-					values = append(values, vv)
-				}
-			}
-		}
-
-		segs := strings.Split(left, ".")
-		if len(segs) == 0 {
-			continue
-		}
-		switch segs[0] {
-		case "env":
-			if len(segs) == 2 {
-				method := segs[1]
-				if len(values) > 0 {
-					settings.Env[method] = append(settings.Env[method], values...)
-				}
-			}
-		case "yaml":
-			if len(segs) >= 2 {
-				switch segs[1] {
-				case "section":
-					settings.YAMLSection = append(settings.YAMLSection, values...)
-				case "key":
-					if len(segs) == 3 {
-						method := segs[2]
-						if len(values) > 0 {
-							settings.YAMLKey[method] = append(settings.YAMLKey[method], values...)
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return settings
-}
-
-func getMethodSignature(funcType *ast.FuncType) (string, string, error) {
-	// Получаем тип параметра (для простоты берем первый)
-	var paramType string
-	if funcType.Params != nil && len(funcType.Params.List) > 0 {
-		paramType = getTypeName(funcType.Params.List[0].Type)
-	}
-
-	rets := getReturnTypes(funcType)
-	if len(rets) != 2 {
-		return "", "", fmt.Errorf("expected 2 return values (T, bool), got %d", len(rets))
-	}
-	if rets[1].TypeName != "bool" {
-		return "", "", fmt.Errorf("second return value must be bool, got %q", rets[1].TypeName)
-	}
-	// Разрешаем: string, int, []Type
-	if rets[0].TypeName == "" {
-		return "", "", fmt.Errorf("could not parse return type")
-	}
-	if !rets[0].IsSlice && rets[0].TypeName != "string" && rets[0].TypeName != "int" {
-		return "", "", fmt.Errorf("unsupported value return type %q (supported: string, int, []Type)", rets[0].TypeName)
-	}
-	return paramType, rets[0].TypeName, nil
-}
-
-func generateImplementation(info *InterfaceInfo, aliases AliasSettings, outputPath string, registryEnabled bool) error {
-	// Определяем путь для генерации
-	var fullOutputPath string
-	var packageName string
-	var isSamePackage bool
-
-	if outputPath == "" {
-		// По умолчанию - создаем в текущем пакете
-		fullOutputPath = "."
-		packageName = info.PackageName
-		isSamePackage = true
-	} else {
-		// Пользователь указал свой путь
-		fullOutputPath = outputPath
-		packageName = filepath.Base(outputPath)
-		isSamePackage = false
-	}
-
-	// Создаем директорию если не существует
-	if err := os.MkdirAll(fullOutputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	if registryEnabled {
-		if err := ensureRegistryFile(fullOutputPath, packageName); err != nil {
-			return err
-		}
-	}
-
-	// Генерируем один файл со всеми реализациями
-	// Используем уникальное имя для избежания конфликтов
-	fileName := fmt.Sprintf("%s.gen.go", info.UniquePackageName)
-	filePath := filepath.Join(fullOutputPath, fileName)
-
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	// Шаблон для генерации всех реализаций
-	tmpl := template.Must(template.New("config").Funcs(template.FuncMap{
-		"title": func(s string) string {
-			// Убираем подчеркивания и применяем Title к каждой части
-			parts := strings.Split(s, "_")
-			var result strings.Builder
-			for _, part := range parts {
-				if len(part) > 0 {
-					result.WriteString(strings.Title(part))
-				}
-			}
-			return result.String()
-		},
-		"envKey": func(methodName string) string { return getEnvKey(info.PackageName, methodName) },
-		// Проверка ENV по ключу без возврата default
-		"envCheck": func(returnType, key string) string { return getEnvCheckSnippet(key, returnType) },
-		// Возврат ENV по основному ключу с fallback на default
-		"envReturn": func(returnType, key string) string { return getEnvValue(key, "defaultValue", returnType) },
-		"hasIntType": func(methods []Method) bool {
-			for _, method := range methods {
-				if method.ReturnType == "int" {
-					return true
-				}
-			}
-			return false
-		},
-		"hasSliceType": func(methods []Method) bool {
-			for _, method := range methods {
-				if method.IsSlice {
-					return true
-				}
-			}
-			return false
-		},
-		"isSlice": func(m Method) bool {
-			return m.IsSlice
-		},
-		"baseType": func(returnType string) string {
-			if strings.HasPrefix(returnType, "[]") {
-				return strings.TrimPrefix(returnType, "[]")
-			}
-			return returnType
-		},
-		"qualifyType": func(typeName string, needImport bool, pkgName string) string {
-			// Если тип не примитивный и нужен импорт, добавляем префикс пакета
-			if !needImport {
-				return typeName
-			}
-			// Проверяем, является ли тип примитивным
-			if typeName == "string" || typeName == "int" || typeName == "bool" ||
-				typeName == "int64" || typeName == "float64" {
-				return typeName
-			}
-			// Если это слайс, обрабатываем элемент
-			if strings.HasPrefix(typeName, "[]") {
-				elemType := strings.TrimPrefix(typeName, "[]")
-				if elemType == "string" || elemType == "int" || elemType == "bool" {
-					return typeName
-				}
-				return "[]" + pkgName + "." + elemType
-			}
-			// Добавляем префикс пакета
-			return pkgName + "." + typeName
-		},
-		"toLower": strings.ToLower,
-		// Алиасы
-		"envAliasKeys": func(methodName string) []string {
-			if aliases.Env == nil {
-				return nil
-			}
-			return aliases.Env[methodName]
-		},
-		"yamlSectionAliases": func() []string { return aliases.YAMLSection },
-		"yamlKeyAliases": func(methodName string) []string {
-			if aliases.YAMLKey == nil {
-				return nil
-			}
-			return aliases.YAMLKey[methodName]
-		},
-		"yamlAssertType": func(returnType string) string {
-			switch returnType {
-			case "int":
-				return "int"
-			default:
-				return "string"
-			}
-		},
-		"paramDefaultLiteral": func(paramType string) string {
-			if strings.HasPrefix(paramType, "[]") {
-				return "nil"
-			}
-			switch paramType {
-			case "int":
-				return "0"
-			default:
-				return "\"\""
-			}
-		},
-	}).Parse(unifiedTemplate))
-
-	data := struct {
-		UniquePackageName string // Уникальное имя на основе пути
-		InterfaceName     string
-		Methods           []Method
-		GenPackageName    string
-		IsSamePackage     bool
-		EnableRegistry    bool
-		NeedImport        bool
-		ImportPath        string
-		SourcePackageName string // Имя исходного пакета для квалификации типов
-	}{
-		UniquePackageName: info.UniquePackageName,
-		InterfaceName:     info.InterfaceName,
-		Methods:           info.Methods,
-		GenPackageName:    packageName,
-		IsSamePackage:     isSamePackage,
-		EnableRegistry:    registryEnabled,
-		NeedImport:        info.NeedImport,
-		ImportPath:        info.ImportPath,
-		SourcePackageName: info.PackageName,
-	}
-
-	return tmpl.Execute(file, data)
-}
-
-func ensureRegistryFile(outputDir string, genPackageName string) error {
-	filePath := filepath.Join(outputDir, "registry.gen.go")
-	f, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("create registry file %s: %w", filePath, err)
-	}
-	defer f.Close()
-
-	// Registry API: package self-registration via init() in each generated file.
-	// GlobalConfig loads YAML once (optional) and provides typed access via Get().
-	content := fmt.Sprintf(`// Code generated by ggconfig. DO NOT EDIT.
-
-package %s
-
-import (
-	"os"
-	"sync"
-
-	"github.com/apopov-app/ggconfig/runtime"
-)
-
-type Provider struct {
-	Package string
-	NewAllFromParsed func(y *runtime.YAML, mapKey func(string) string) any
-}
-
-var (
-	registryMu sync.RWMutex
-	registry = map[string]Provider{}
-)
-
-func Register(pkg string, p Provider) {
-	registryMu.Lock()
-	defer registryMu.Unlock()
-	registry[pkg] = p
-}
-
-func Providers() map[string]Provider {
-	registryMu.RLock()
-	defer registryMu.RUnlock()
-	out := make(map[string]Provider, len(registry))
-	for k, v := range registry {
-		out[k] = v
-	}
-	return out
-}
-
-// NewAllFromYAML builds a single package AllConfig from YAML bytes (YAML parsed once per call).
-// Returns (nil, false, nil) if the package is not registered.
-func NewAllFromYAML(pkg string, yamlData []byte) (any, bool, error) {
-	y, err := runtime.ParseYAML(yamlData)
+	opts, err := gen.Options()
 	if err != nil {
-		return nil, false, err
-	}
-	registryMu.RLock()
-	p, ok := registry[pkg]
-	registryMu.RUnlock()
-	if !ok || p.NewAllFromParsed == nil {
-		return nil, false, nil
-	}
-	return p.NewAllFromParsed(y, func(k string) string { return k }), true, nil
-}
-
-// EnvConfig allows post-processing of env keys before os.Getenv, e.g. to inject prefixes.
-type EnvConfig struct {
-	mapKey func(string) string
-}
-
-func NewEnvConfig(mapKey func(key string) string) *EnvConfig {
-	if mapKey == nil {
-		mapKey = func(k string) string { return k }
+		log.Fatal(err)
 	}
-	return &EnvConfig{mapKey: mapKey}
-}
-
-type GlobalYamlConfig struct {
-	path string
-}
-
-func NewGlobalYamlConfig(path string) *GlobalYamlConfig {
-	return &GlobalYamlConfig{path: path}
-}
-
-type GlobalConfig struct {
-	y *runtime.YAML
-	mapKey func(string) string
-}
-
-// NewGlobalConfig creates app-wide config wrapper. Sources order does not matter.
-// Supported sources:
-// - *GlobalYamlConfig
-// - *EnvConfig
-func NewGlobalConfig(sources ...any) (*GlobalConfig, error) {
-	g := &GlobalConfig{
-		y:      &runtime.YAML{},
-		mapKey: func(k string) string { return k },
-	}
-	var yamlPath string
-	for _, s := range sources {
-		switch t := s.(type) {
-		case *EnvConfig:
-			if t != nil && t.mapKey != nil {
-				g.mapKey = t.mapKey
-			}
-		case *GlobalYamlConfig:
-			if t != nil && t.path != "" {
-				yamlPath = t.path
-			}
-		}
-	}
-	if yamlPath != "" {
-		b, err := os.ReadFile(yamlPath)
-		if err != nil {
-			return nil, err
-		}
-		y, err := runtime.ParseYAML(b)
+	// go generate передает файл и строку директивы: без --interface генерируется интерфейс под ней
+	opts.File = os.Getenv("GOFILE")
+	if v := os.Getenv("GOLINE"); v != "" && opts.Interface == "" {
+		n, err := strconv.Atoi(v)
 		if err != nil {
-			return nil, err
+			log.Fatalf("invalid GOLINE %q: %v", v, err)
 		}
-		g.y = y
+		opts.Line = n
 	}
-	return g, nil
-}
-
-`, genPackageName)
-
-	if _, err := f.WriteString(content); err != nil {
-		return fmt.Errorf("write registry file: %w", err)
+	if opts.RuntimeSources, err = fs.Sub(runtimeSources, "runtime"); err != nil {
+		log.Fatalf("%v", err)
 	}
-	return nil
-}
-
-func generateExampleConfig(info *InterfaceInfo, examplePath string) error {
-	// Создаем директорию если не существует
-	var fullOutputPath string
-	if examplePath == "" {
-		// По умолчанию - создаем в текущем пакете
-		fullOutputPath = "."
-	} else {
-		// Пользователь указал свой путь - путь относительно корня проекта
-		// Нужно подняться на два уровня вверх от internal/database или internal/server
-		fullOutputPath = filepath.Join("..", "..", examplePath)
-	}
-
-	if err := os.MkdirAll(fullOutputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Генерируем файл с именованием originalfile.yaml.go
-	fileName := fmt.Sprintf("%s_example.yaml", info.UniquePackageName)
-	filePath := filepath.Join(fullOutputPath, fileName)
-
-	file, err := os.Create(filePath)
+	opts.Log = os.Stdout
+	res, err := generator.New(opts).Generate()
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+		log.Fatal(err)
 	}
-	defer file.Close()
+	info := res.Interface
 
-	// Шаблон для генерации моков
-	tmpl := template.Must(template.New("example").Funcs(template.FuncMap{
-		"title": func(s string) string {
-			// Убираем подчеркивания и применяем Title к каждой части
-			parts := strings.Split(s, "_")
-			var result strings.Builder
-			for _, part := range parts {
-				if len(part) > 0 {
-					result.WriteString(strings.Title(part))
-				}
-			}
-			return result.String()
-		},
-		"envKey": func(methodName string) string { return getEnvKey(info.PackageName, methodName) },
-		"defaultValue": func(paramType string) string {
-			switch paramType {
-			case "string":
-				return "\"\""
-			case "int":
-				return "0"
-			default:
-				return "\"\""
-			}
-		},
-	}).Parse(exampleTemplate))
-
-	data := struct {
-		UniquePackageName string
-		InterfaceName     string
-		Methods           []Method
-	}{
-		UniquePackageName: info.UniquePackageName,
-		InterfaceName:     info.InterfaceName,
-		Methods:           info.Methods,
-	}
-
-	return tmpl.Execute(file, data)
-}
-
-func toEnvKey(methodName string) string {
-	// Преобразуем имя метода в ключ переменной окружения
-	// Например: Host -> HOST, SSLMode -> SSL_MODE, UserName -> USER_NAME
-	var result strings.Builder
-
-	for i, char := range methodName {
-		isUpper := char >= 'A' && char <= 'Z'
-
-		// Добавляем подчеркивание если:
-		// 1. Текущий символ заглавный и предыдущий был строчным
-		// 2. Или если следующий символ строчной (для аббревиатур)
-		if isUpper && i > 0 {
-			nextChar := byte(0)
-			if i+1 < len(methodName) {
-				nextChar = methodName[i+1]
-			}
-
-			// Если следующий символ строчной, то это начало слова
-			if nextChar >= 'a' && nextChar <= 'z' {
-				result.WriteByte('_')
-			}
+	// --dry-run - тот же режим без записи, что и --check, но с выводом разницы и без ошибки
+	if gen.DryRun {
+		if len(res.Stale) == 0 {
+			fmt.Printf("✅ Dry run for %s.%s: no changes\n", info.UniquePackageName, info.InterfaceName)
+		} else {
+			fmt.Printf("✅ Dry run for %s.%s: %d of %d files would change: %s\n", info.UniquePackageName, info.InterfaceName, len(res.Stale), len(res.Files), strings.Join(res.Stale, ", "))
 		}
-
-		result.WriteByte(byte(char))
-	}
-
-	return strings.ToUpper(result.String())
-}
-
-func getEnvKey(packageName, methodName string) string {
-	// Добавляем префикс пакета к ключу
-	prefix := strings.ToUpper(packageName)
-	return prefix + "_" + toEnvKey(methodName)
-}
-
-const unifiedTemplate = `// Code generated by ggconfig. DO NOT EDIT.
-
-package {{.GenPackageName}}
-
-import (
-	{{if hasSliceType .Methods}}"encoding/json"{{end}}
-	"os"
-	{{if hasIntType .Methods}}"strconv"{{end}}
-	"github.com/apopov-app/ggconfig/runtime"
-	{{if .NeedImport}}{{if .ImportPath}}"{{.ImportPath}}"{{end}}{{end}}
-)
-
-// ===== ENV Implementation =====
-
-type {{.UniquePackageName}}EnvConfig struct{
-	mapKey func(string) string
-}
-
-{{range .Methods}}
-func (c *{{$.UniquePackageName}}EnvConfig) {{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool) {
-	{{- if isSlice . -}}
-	{{- $ret := qualifyType .ReturnType $.NeedImport $.SourcePackageName -}}
-	{{- range envAliasKeys .Name}}
-	if value := os.Getenv(c.mapKey("{{.}}")); value != "" {
-		var result {{$ret}}
-		if err := json.Unmarshal([]byte(value), &result); err == nil {
-			return result, true
+		if !gen.Check {
+			return
 		}
 	}
-	{{- end}}
-	if value := os.Getenv(c.mapKey("{{envKey .Name}}")); value != "" {
-		var result {{$ret}}
-		if err := json.Unmarshal([]byte(value), &result); err == nil {
-			return result, true
+	if gen.Check {
+		// Список проверенных файлов
+		stale := map[string]bool{}
+		for _, path := range res.Stale {
+			stale[path] = true
 		}
-	}
-	return defaultValue, false
-	{{- else -}}
-	{{- $ret := .ReturnType -}}
-	{{- range envAliasKeys .Name}}
-	{{envCheck $ret (printf "c.mapKey(%q)" .)}}
-	{{- end}}
-	{{envReturn .ReturnType (printf "c.mapKey(%q)" (envKey .Name))}}
-	{{- end}}
-}
-{{end}}
-
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}EnvConfig() *{{.UniquePackageName}}EnvConfig {
-	return New{{.UniquePackageName | title}}{{.InterfaceName | title}}EnvConfigWithMap(nil)
-}
-
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}EnvConfigWithMap(mapKey func(string) string) *{{.UniquePackageName}}EnvConfig {
-	if mapKey == nil {
-		mapKey = func(k string) string { return k }
-	}
-	return &{{.UniquePackageName}}EnvConfig{mapKey: mapKey}
-}
-
-// ===== YAML Implementation =====
-
-type {{.UniquePackageName}}YAMLConfig struct {
-	y *runtime.YAML
-	err error
-}
-
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}YAMLConfig(path string) *{{.UniquePackageName}}YAMLConfig {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return &{{.UniquePackageName}}YAMLConfig{y: &runtime.YAML{}, err: err}
-	}
-	y, err := runtime.ParseYAML(b)
-	if err != nil {
-		return &{{.UniquePackageName}}YAMLConfig{y: &runtime.YAML{}, err: err}
-	}
-	return &{{.UniquePackageName}}YAMLConfig{y: y}
-}
-
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}YAMLConfigParsed(y *runtime.YAML) *{{.UniquePackageName}}YAMLConfig {
-	return &{{.UniquePackageName}}YAMLConfig{
-		y: y,
-	}
-}
-
-func (c *{{.UniquePackageName}}YAMLConfig) Err() error { return c.err }
-
-{{range .Methods}}
-func (c *{{$.UniquePackageName}}YAMLConfig) {{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool) {
-	{{- $methodName := .Name -}}
-	{{- $keyPrimary := (.Name | toLower) -}}
-	{{- $qualifiedReturnType := qualifyType .ReturnType $.NeedImport $.SourcePackageName -}}
-	{{- $qualifiedElemType := qualifyType (baseType .ReturnType) $.NeedImport $.SourcePackageName -}}
-	{{- if isSlice . }}
-	// Алиасные секции
-	{{ range yamlSectionAliases }}
-	{{ $section := . }}
-	if v, ok := c.y.GetSlice("{{$section}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		var result {{$qualifiedReturnType}}
-		for _, item := range v {
-			if m, ok := item.(map[string]any); ok {
-				var elem {{$qualifiedElemType}}
-				data, _ := json.Marshal(m)
-				if err := json.Unmarshal(data, &elem); err == nil {
-					result = append(result, elem)
-				}
-			}
-		}
-		if len(result) > 0 {
-			return result, true
-		}
-	}
-	{{- end}}
-	// Основная секция {{$.SourcePackageName}}
-	if v, ok := c.y.GetSlice("{{$.SourcePackageName}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		var result {{$qualifiedReturnType}}
-		for _, item := range v {
-			if m, ok := item.(map[string]any); ok {
-				var elem {{$qualifiedElemType}}
-				data, _ := json.Marshal(m)
-				if err := json.Unmarshal(data, &elem); err == nil {
-					result = append(result, elem)
-				}
+		for _, path := range res.Files {
+			if stale[path] {
+				fmt.Printf("  ✗ %s\n", path)
+			} else {
+				fmt.Printf("  ✓ %s\n", path)
 			}
 		}
-		if len(result) > 0 {
-			return result, true
+		if len(res.Stale) > 0 {
+			log.Fatalf("generated files are out of date, run go generate: %s", strings.Join(res.Stale, ", "))
 		}
+		fmt.Printf("✅ Generated files for %s.%s are up to date\n", info.UniquePackageName, info.InterfaceName)
+		return
 	}
-	return defaultValue, false
-	{{- else if eq .ReturnType "int" }}
-	// Алиасные секции
-	{{ range yamlSectionAliases }}
-	{{ $section := . }}
-	if v, ok := c.y.GetInt("{{$section}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		return v, true
-		}
-		{{- end}}
-	// Основная секция {{$.SourcePackageName}}
-	if v, ok := c.y.GetInt("{{$.SourcePackageName}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		return v, true
-	}
-	return defaultValue, false
-	{{- else }}
-	// Алиасные секции
-	{{ range yamlSectionAliases }}
-	{{ $section := . }}
-	if v, ok := c.y.GetString("{{$section}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		return v, true
-	}
-	{{- end}}
-	// Основная секция {{$.SourcePackageName}}
-	if v, ok := c.y.GetString("{{$.SourcePackageName}}", {{- range yamlKeyAliases $methodName }}"{{.}}",{{- end}} "{{$keyPrimary}}"); ok {
-		return v, true
-		}
-	return defaultValue, false
-	{{- end }}
-}
-{{end}}
-
-// ===== Mock Implementation =====
-
-type {{.UniquePackageName}}MockConfig struct{}
-
-{{range .Methods}}
-func (c *{{$.UniquePackageName}}MockConfig) {{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool) {
-	return defaultValue, false
-}
-{{end}}
-
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}Mock() *{{.UniquePackageName}}MockConfig {
-	return &{{.UniquePackageName}}MockConfig{}
-}
-
-// ===== Composite Implementation =====
-
-type {{.UniquePackageName}}AllConfig struct {
-	sources []interface{
-		{{- range .Methods}}
-		{{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool)
-		{{- end}}
-	}
-}
 
-func New{{.UniquePackageName | title}}{{.InterfaceName | title}}All(sources ...interface{
-	{{- range .Methods}}
-	{{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool)
-	{{- end}}
-}) *{{.UniquePackageName}}AllConfig {
-	return &{{.UniquePackageName}}AllConfig{sources: sources}
-}
-
-{{range .Methods}}
-func (c *{{$.UniquePackageName}}AllConfig) {{.Name}}(defaultValue {{qualifyType .ParamType $.NeedImport $.SourcePackageName}}) ({{qualifyType .ReturnType $.NeedImport $.SourcePackageName}}, bool) {
-	for _, s := range c.sources {
-		v, ok := s.{{.Name}}(defaultValue)
-		if ok {
-			return v, true
-		}
+	outputDisplayPath := res.Output
+	if outputDisplayPath == "" {
+		outputDisplayPath = "current package"
 	}
-	return defaultValue, false
-}
-{{end}}
-
-{{if .EnableRegistry}}
-func init() {
-	Register("{{.UniquePackageName}}", Provider{
-		Package: "{{.UniquePackageName}}",
-		NewAllFromParsed: func(y *runtime.YAML, mapKey func(string) string) any {
-			envCfg := New{{.UniquePackageName | title}}{{.InterfaceName | title}}EnvConfigWithMap(mapKey)
-			yamlCfg := New{{.UniquePackageName | title}}{{.InterfaceName | title}}YAMLConfigParsed(y)
-			return New{{.UniquePackageName | title}}{{.InterfaceName | title}}All(envCfg, yamlCfg)
-		},
-	})
-}
-
-// Get{{.UniquePackageName | title}} returns the concrete AllConfig type for this package.
-// It can be passed anywhere the original interface is expected (structural typing).
-func (g *GlobalConfig) Get{{.UniquePackageName | title}}() (*{{.UniquePackageName}}AllConfig, bool) {
-	registryMu.RLock()
-	p, ok := registry["{{.UniquePackageName}}"]
-	registryMu.RUnlock()
-	if !ok || p.NewAllFromParsed == nil {
-		return nil, false
+	if len(res.Unchanged) > 0 {
+		outputDisplayPath += fmt.Sprintf(" (%d of %d files unchanged)", len(res.Unchanged), len(res.Files))
 	}
-	v := p.NewAllFromParsed(g.y, g.mapKey)
-	cfg, ok := v.(*{{.UniquePackageName}}AllConfig)
-	return cfg, ok
+	fmt.Printf("✅ Generated config for %s.%s in %s\n", info.UniquePackageName, info.InterfaceName, outputDisplayPath)
 }
-{{end}}
-`
-
-const exampleTemplate = `# Example configuration for {{.UniquePackageName}} package
-# Copy this file to config.yaml or use with your application
-
-{{.UniquePackageName}}:
-{{range .Methods}}  # {{.Name}} - {{.ParamType}} parameter{{if .Comment}} - {{.Comment}}{{end}}
-  {{.Name}}: {{.ParamType | defaultValue}}
-{{end}}
-# Usage:
-# 1. Copy this file to config.yaml
-# 2. Or use with viper/cobra for config management
-# 3. Or convert to environment variables
-`
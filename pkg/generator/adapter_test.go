@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const adapterConfig = `package svc
+
+type Config interface {
+	Port(defaultValue int) (int, bool)
+	Name(defaultValue *string) (*string, bool)
+	Limit(defaultValue int64) (int64, error)
+	Level(defaultValue string) (string, error)
+}
+`
+
+// adapterConfigTest собирает композит и обертки над реализацией Config, написанной вручную:
+// конструкторы принимают методы интерфейса, а не bool-формы lookup<Name>
+const adapterConfigTest = `package svc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// fake - реализация Config вне сгенерированных источников
+type fake struct {
+	name  *string
+	limit error
+}
+
+func (f fake) Port(defaultValue int) (int, bool) { return 9090, true }
+
+func (f fake) Name(defaultValue *string) (*string, bool) {
+	if f.name == nil {
+		return defaultValue, false
+	}
+	return f.name, true
+}
+
+func (f fake) Limit(defaultValue int64) (int64, error) {
+	if f.limit != nil {
+		return defaultValue, f.limit
+	}
+	return 100, nil
+}
+
+func (f fake) Level(defaultValue string) (string, error) {
+	return defaultValue, runtime.ErrNotSet
+}
+
+var _ Config = fake{}
+
+func env(values map[string]string) *svcEnvConfig {
+	return NewSvcConfigEnvConfigWithLookup(nil, func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	})
+}
+
+func TestCompositeOverUserSource(t *testing.T) {
+	cfg := NewSvcConfigAll(fake{}, env(map[string]string{"SVC_NAME": "api", "SVC_LEVEL": "debug", "SVC_LIMIT": "5"}))
+	if v, ok := cfg.Port(1); !ok || v != 9090 {
+		t.Errorf("Port = %d, %v; want 9090 from the user source", v, ok)
+	}
+	// Отсутствующий указатель и ErrNotSet передают чтение следующему источнику
+	if v, ok := cfg.Name(nil); !ok || v == nil || *v != "api" {
+		t.Errorf("Name = %v, %v; want api from ENV", v, ok)
+	}
+	if v, err := cfg.Level("info"); err != nil || v != "debug" {
+		t.Errorf("Level = %q, %v; want debug from ENV", v, err)
+	}
+	if v, err := cfg.Limit(1); err != nil || v != 100 {
+		t.Errorf("Limit = %d, %v; want 100 from the user source", v, err)
+	}
+	name := "fake"
+	if v, ok := NewSvcConfigAll(fake{name: &name}).Name(nil); !ok || *v != "fake" {
+		t.Errorf("Name = %v, %v; want fake", v, ok)
+	}
+
+	// Другая ошибка пользовательского источника возвращается, а не считается отсутствием
+	broken := errors.New("backend is down")
+	if _, err := NewSvcConfigAll(fake{limit: broken}, env(map[string]string{"SVC_LIMIT": "5"})).Limit(1); !errors.Is(err, broken) {
+		t.Errorf("Limit error = %v, want the user source error", err)
+	}
+	if err := NewSvcConfigAll(fake{limit: broken}).Validate(); !errors.Is(err, broken) {
+		t.Errorf("Validate = %v, want the user source error", err)
+	}
+	if s := SnapshotSvcConfig(fake{}); s["svc.port"] != 9090 || s["svc.limit"] != int64(100) {
+		t.Errorf("Snapshot = %v, want port and limit of the user source", s)
+	}
+	if r := NewSvcConfigAll(fake{}).Report(); !strings.Contains(r.String(), "svc.fake") {
+		t.Errorf("Report does not name the user source:\n%s", r)
+	}
+}
+
+func TestWrappersOverUserSource(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"override": NewSvcConfigOverride(fake{}, map[string]any{"Level": "warn"}),
+		"context":  NewSvcConfigContext(context.Background(), fake{}),
+		"chaos":    NewSvcConfigChaos(fake{}, runtime.NewChaos(runtime.ChaosOptions{})),
+	} {
+		if v, ok := cfg.Port(1); !ok || v != 9090 {
+			t.Errorf("%s: Port = %d, %v; want 9090", name, v, ok)
+		}
+		if v, err := cfg.Limit(1); err != nil || v != 100 {
+			t.Errorf("%s: Limit = %d, %v; want 100", name, v, err)
+		}
+	}
+	if v, err := NewSvcConfigOverride(fake{}, map[string]any{"Level": "warn"}).Level("info"); err != nil || v != "warn" {
+		t.Errorf("overridden Level = %q, %v; want warn", v, err)
+	}
+	chain, err := NewSvcConfigChain("user,env", map[string]func(string) (any, error){
+		"user": func(string) (any, error) { return fake{}, nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := chain.Port(1); !ok || v != 9090 {
+		t.Errorf("chain: Port = %d, %v; want 9090", v, ok)
+	}
+}
+`
+
+func TestUserSourceAdapter(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{
+		"svc/config.go":       adapterConfig,
+		"svc/adapter_test.go": adapterConfigTest,
+	})
+	opts := Options{
+		Dir:       filepath.Join(dir, "svc"),
+		Interface: "Config",
+		Sources:   []string{"env", "mock", "composite", "context", "chaos", "chain"},
+	}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
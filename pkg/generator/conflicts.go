@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkRedeclarations проверяет сгенерированный файл filePath до записи: его объявления
+// верхнего уровня не должны совпадать с объявлениями других файлов выходного пакета, а
+// существующий файл по тому же пути не должен принадлежать другому интерфейсу. Оба случая
+// возникают, когда интерфейсы с одним уникальным именем (из одного пакета или в общий
+// --out-package/--out-file) генерируются без --type-prefix/--type-suffix: вместо кода, который
+// не компилируется ("redeclared in this block"), генерация завершается понятной ошибкой
+func (g *Generator) checkRedeclarations(filePath string, src []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.SkipObjectResolution)
+	if err != nil {
+		return fmt.Errorf("parse generated %s: %w", filePath, err)
+	}
+	declared := map[string]bool{}
+	for _, name := range topLevelNames(file) {
+		declared[name] = true
+	}
+
+	dir, base := filepath.Dir(filePath), filepath.Base(filePath)
+	if !g.opts.Force {
+		if owner := existingOwner(fset, filePath); owner != "" && owner != overrideCtor(file) {
+			return fmt.Errorf("%s already holds the config of another interface (%s): the interfaces share the generated file; set --out-file or --type-prefix/--type-suffix, or pass --force to replace it", filePath, owner)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == base || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		// Файлы, исключенные ограничениями сборки, с пакетом не компилируются
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		other, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil || other.Name.Name != file.Name.Name {
+			continue // Синтаксические ошибки чужих файлов покажет компилятор
+		}
+		for _, ident := range topLevelNames(other) {
+			if declared[ident] {
+				return fmt.Errorf("%s declares %s, which is already declared in %s: generate the interfaces into different packages or files with distinct names, or set --type-prefix/--type-suffix", filePath, ident, filepath.Join(dir, name))
+			}
+		}
+	}
+	return nil
+}
+
+// existingOwner возвращает конструктор New<TypeName><Interface>Override существующего
+// сгенерированного файла: он есть в каждом файле и определяет интерфейс, для которого файл
+// создан. Пусто, если файла нет или он не от ggconfig
+func existingOwner(fset *token.FileSet, filePath string) string {
+	data, err := os.ReadFile(filePath)
+	if err != nil || !bytes.HasPrefix(data, []byte(GeneratedHeader)) {
+		return ""
+	}
+	file, err := parser.ParseFile(fset, filePath, data, parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+	return overrideCtor(file)
+}
+
+// overrideCtor находит в файле конструктор обертки Override (New... или new... для
+// неэкспортируемого интерфейса)
+func overrideCtor(file *ast.File) string {
+	for _, name := range topLevelNames(file) {
+		if (strings.HasPrefix(name, "New") || strings.HasPrefix(name, "new")) && strings.HasSuffix(name, "Override") {
+			return name
+		}
+	}
+	return ""
+}
+
+// topLevelNames возвращает имена типов, функций без получателя, переменных и констант файла.
+// init и _ можно объявлять многократно
+func topLevelNames(file *ast.File) []string {
+	var names []string
+	add := func(id *ast.Ident) {
+		if id.Name != "_" && id.Name != "init" {
+			names = append(names, id.Name)
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				add(d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name)
+				case *ast.ValueSpec:
+					for _, id := range s.Names {
+						add(id)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTwoInterfaces создает модуль с двумя интерфейсами конфигурации в одном пакете
+func writeTwoInterfaces(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/tc\n\ngo 1.21\n",
+		"svc/config.go": `package svc
+
+type Config interface {
+	Port(defaultValue int) (int, bool)
+}
+
+type Admin interface {
+	Token(defaultValue string) (string, bool)
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "svc")
+}
+
+func TestGenerateDetectsTypeCollisions(t *testing.T) {
+	dir := writeTwoInterfaces(t)
+	if _, err := New(Options{Dir: dir, Interface: "Config"}).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	config, err := os.ReadFile(filepath.Join(dir, "svc.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		err  string
+	}{
+		{"shared file", Options{}, "already holds the config of another interface (NewSvcConfigOverride)"},
+		{"shared package", Options{OutFile: "admin.gen.go"}, "declares svcEnvConfig, which is already declared in"},
+		{"invalid prefix", Options{TypePrefix: "1x"}, "invalid --type-prefix"},
+		{"invalid suffix", Options{TypeSuffix: "-admin"}, "invalid --type-suffix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Dir, opts.Interface = dir, "Admin"
+			_, err := New(opts).Generate()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+	// Ошибка не должна портить уже сгенерированный файл другого интерфейса
+	if after, err := os.ReadFile(filepath.Join(dir, "svc.gen.go")); err != nil || string(after) != string(config) {
+		t.Fatalf("svc.gen.go changed after a failed generation: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "admin.gen.go")); !os.IsNotExist(err) {
+		t.Fatalf("admin.gen.go written despite the collision: %v", err)
+	}
+}
+
+func TestGenerateTypeSuffix(t *testing.T) {
+	dir := writeTwoInterfaces(t)
+	if _, err := New(Options{Dir: dir, Interface: "Config"}).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := New(Options{Dir: dir, Interface: "Admin", TypeSuffix: "_admin"}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "svc_admin.gen.go"); len(res.Files) == 0 || res.Files[0] != want {
+		t.Fatalf("files = %v, want %s first", res.Files, want)
+	}
+
+	// Объявления двух файлов не пересекаются, а имена несут суффикс
+	fset := token.NewFileSet()
+	seen := map[string]string{}
+	for _, name := range []string{"svc.gen.go", "svc_admin.gen.go"} {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ident := range topLevelNames(file) {
+			if prev, ok := seen[ident]; ok {
+				t.Errorf("%s declared in %s and %s", ident, prev, name)
+			}
+			seen[ident] = name
+		}
+	}
+	for _, ident := range []string{"svc_adminEnvConfig", "NewSvcAdminAdminEnvConfig", "svcEnvConfig", "NewSvcConfigEnvConfig"} {
+		if _, ok := seen[ident]; !ok {
+			t.Errorf("%s is not declared", ident)
+		}
+	}
+
+	// Повторная генерация обоих интерфейсов не считается конфликтом
+	for _, opts := range []Options{{Dir: dir, Interface: "Config"}, {Dir: dir, Interface: "Admin", TypeSuffix: "_admin"}} {
+		if _, err := New(opts).Generate(); err != nil {
+			t.Fatalf("regenerate %s: %v", opts.Interface, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext - строки контекста вокруг изменений, как у diff -u
+const diffContext = 3
+
+// diffLine - строка построчной разницы: ' ' общая, '-' только в старом файле, '+' только в новом
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff возвращает разницу старого и нового содержимого path в формате diff -u;
+// для отсутствующего файла старая сторона - /dev/null. Пустая строка - содержимое совпадает
+func unifiedDiff(path string, old, new []byte, exists bool) string {
+	if exists && string(old) == string(new) {
+		return ""
+	}
+	oldName := path
+	if !exists {
+		oldName = "/dev/null"
+	}
+	lines := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	// Номера строк перед каждой строкой разницы в старом и новом файлах
+	oldPos, newPos := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if l.op != '+' {
+			oldPos[i+1]++
+		}
+		if l.op != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, path)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+		// Изменения, между которыми не больше 2*diffContext общих строк, попадают в один фрагмент
+		start, end := max(i-diffContext, 0), i
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end = min(end+diffContext, len(lines))
+			break
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[end]-oldPos[start]),
+			hunkRange(newPos[start], newPos[end]-newPos[start]))
+		for _, l := range lines[start:end] {
+			b.WriteByte(l.op)
+			b.WriteString(l.text)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange - диапазон строк заголовка фрагмента: пустой диапазон указывает на строку перед ним
+func hunkRange(before, count int) string {
+	start := before + 1
+	if count == 0 {
+		start = before
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines строит кратчайшую построчную разницу алгоритмом Майерса. Общие начало и конец
+// отбрасываются заранее: перегенерация обычно меняет несколько строк большого файла, и
+// поиск идет только по измененной середине
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append(suffix, diffLine{' ', a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var middle []diffLine
+	switch {
+	case len(a) == 0:
+		middle = replaceAll(nil, b)
+	case len(b) == 0:
+		middle = replaceAll(a, nil)
+	default:
+		middle = myersDiff(a, b)
+	}
+
+	lines := append(prefix, middle...)
+	for i := len(suffix) - 1; i >= 0; i-- {
+		lines = append(lines, suffix[i])
+	}
+	return lines
+}
+
+// maxDiffEdits ограничивает число правок, которое ищет myersDiff: память обратного прохода растет
+// как квадрат числа правок, и для совсем разных файлов разница выводится одним фрагментом замены
+const maxDiffEdits = 2000
+
+// myersDiff - алгоритм Майерса с сохранением фронта на каждом шаге для обратного прохода.
+// На шаге d сохраняются только диагонали -d..d, поэтому память - O(D²), а не O((n+m)·D)
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	for d := 0; d <= min(offset, maxDiffEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(trace, a, b)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+// replaceAll - разница без поиска общих строк: все строки a удалены, все строки b добавлены
+func replaceAll(a, b []string) []diffLine {
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, l := range a {
+		lines = append(lines, diffLine{'-', l})
+	}
+	for _, l := range b {
+		lines = append(lines, diffLine{'+', l})
+	}
+	return lines
+}
+
+// myersBacktrack восстанавливает путь по сохраненным фронтам: trace[d][k+d] - x диагонали k
+// перед шагом d
+func myersBacktrack(trace [][]int, a, b []string) []diffLine {
+	var reversed []diffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			reversed = append(reversed, diffLine{'+', b[prevY]})
+		} else {
+			reversed = append(reversed, diffLine{'-', a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffLine{' ', a[x-1]})
+		x, y = x-1, y-1
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, l := range reversed {
+		lines[len(reversed)-1-i] = l
+	}
+	return lines
+}
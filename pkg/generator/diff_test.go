@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// sides восстанавливает старый и новый файлы из разницы
+func sides(lines []diffLine) (a, b []string) {
+	for _, l := range lines {
+		if l.op != '+' {
+			a = append(a, l.text)
+		}
+		if l.op != '-' {
+			b = append(b, l.text)
+		}
+	}
+	return a, b
+}
+
+func edits(lines []diffLine) int {
+	n := 0
+	for _, l := range lines {
+		if l.op != ' ' {
+			n++
+		}
+	}
+	return n
+}
+
+func numbered(prefix string, from, to int) []string {
+	var lines []string
+	for i := from; i < to; i++ {
+		lines = append(lines, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return lines
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  []string
+		edits int
+	}{
+		{"equal", []string{"a", "b"}, []string{"a", "b"}, 0},
+		{"insert", []string{"a", "c"}, []string{"a", "b", "c"}, 1},
+		{"delete", []string{"a", "b", "c"}, []string{"a", "c"}, 1},
+		{"replace", []string{"a", "b", "c"}, []string{"a", "x", "c"}, 2},
+		{"from empty", nil, []string{"a", "b"}, 2},
+		{"to empty", []string{"a", "b"}, nil, 2},
+		{"myers paper", strings.Split("ABCABBA", ""), strings.Split("CBABAC", ""), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := diffLines(tt.a, tt.b)
+			a, b := sides(lines)
+			if strings.Join(a, "\n") != strings.Join(tt.a, "\n") || strings.Join(b, "\n") != strings.Join(tt.b, "\n") {
+				t.Fatalf("diff does not reproduce the inputs: %v", lines)
+			}
+			if got := edits(lines); got != tt.edits {
+				t.Errorf("edits = %d, want %d", got, tt.edits)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeDifferentInputs(t *testing.T) {
+	// Два совсем разных файла по 6000 строк: разница выходит за maxDiffEdits
+	a, b := numbered("old ", 0, 6000), numbered("new ", 0, 6000)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	lines := diffLines(a, b)
+	runtime.ReadMemStats(&after)
+
+	gotA, gotB := sides(lines)
+	if len(gotA) != len(a) || len(gotB) != len(b) {
+		t.Fatalf("diff does not reproduce the inputs: %d/%d lines", len(gotA), len(gotB))
+	}
+	if got := edits(lines); got != len(a)+len(b) {
+		t.Errorf("edits = %d, want %d", got, len(a)+len(b))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 256<<20 {
+		t.Errorf("diff of two 6000-line files allocated %d MB", alloc>>20)
+	}
+}
+
+func TestDiffLinesLargeSimilarInputs(t *testing.T) {
+	// Большой файл с разбросанными правками: разница остается минимальной
+	a := numbered("line ", 0, 6000)
+	b := append([]string(nil), a...)
+	for i := 0; i < len(b); i += 100 {
+		b[i] = "changed"
+	}
+	lines := diffLines(a, b)
+	gotA, gotB := sides(lines)
+	if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+		t.Fatal("diff does not reproduce the inputs")
+	}
+	if got := edits(lines); got != 120 {
+		t.Errorf("edits = %d, want 120", got)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	new := "a\nb\nc\nd\nE\nf\ng\nh\n"
+	want := "--- x.go\n+++ x.go\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n"
+	if got := unifiedDiff("x.go", []byte(old), []byte(new), true); got != want {
+		t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := unifiedDiff("x.go", []byte(old), []byte(old), true); got != "" {
+		t.Errorf("equal files: %q", got)
+	}
+	want = "--- /dev/null\n+++ x.go\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := unifiedDiff("x.go", nil, []byte("a\nb\n"), false); got != want {
+		t.Errorf("new file diff =\n%s\nwant\n%s", got, want)
+	}
+}
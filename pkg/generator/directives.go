@@ -0,0 +1,314 @@
+package generator
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Directive - разобранная директива //go:generate ggconfig из исходников пакета
+type Directive struct {
+	Dir          string // Директория пакета интерфейса (с --package - найденная go list)
+	DirectiveDir string // Директория файла с директивой (с --package отличается от Dir)
+	File         string // Файл с директивой и ее строка ($GOFILE и $GOLINE под go generate)
+	Line         int
+	// Флаги директивы как есть, до настроек манифеста (их применяет генератор): Options
+	Flags     Flags
+	Interface string
+	// --source-file относительно Dir (пусто - весь пакет)
+	SourceFile string
+	Aliases    AliasSettings
+	Output     string // --output относительно Dir (пусто - сам пакет интерфейса)
+	Registry   bool
+	Name       string // --name: уникальное имя пакета вместо вычисленного по пути
+	EnvPrefix  string // --env-prefix: префикс ENV ключей вместо имени пакета
+	TypePrefix string // --type-prefix и --type-suffix: обрамление уникального имени в именах типов
+	TypeSuffix string
+	// Флаги, от которых зависит набор файлов в выходной директории (команда clean)
+	OutFile       string
+	VendorRuntime bool
+	Descriptor    bool
+	DocExamples   bool
+}
+
+// EnvKeyPrefix возвращает префикс ENV ключей директивы: --env-prefix или имя пакета
+func (d Directive) EnvKeyPrefix(packageName string) string {
+	if d.EnvPrefix != "" {
+		return d.EnvPrefix
+	}
+	return packageName
+}
+
+// TypeName возвращает основу имен сгенерированных типов директивы по уникальному имени пакета
+func (d Directive) TypeName(unique string) string {
+	return d.TypePrefix + unique + d.TypeSuffix
+}
+
+// commandPath - пакет команды ggconfig в директивах вида go run github.com/apopov-app/ggconfig@v1.2.0
+const commandPath = "github.com/apopov-app/ggconfig"
+
+// DirectiveLine - строка //go:generate ggconfig в исходном файле
+type DirectiveLine struct {
+	File string
+	Line int
+	Text string   // Строка директивы без пробелов по краям
+	Args []string // Аргументы ggconfig без самой команды, кавычки сняты, как это делает go generate
+}
+
+// ScanDirectives находит директивы ggconfig в файле path: "ggconfig ..." и
+// "go run github.com/apopov-app/ggconfig[@version] ...". Это единственный разбор строк
+// //go:generate: его используют FindDirectives, режим ./..., clean и gentest. Сгенерированный
+// ggconfig файл (GeneratedHeader в первой строке) директив не содержит: generated = true
+func ScanDirectives(path string) (generated bool, lines []DirectiveLine, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		text := strings.TrimSpace(sc.Text())
+		if lineNo == 1 && text == GeneratedHeader {
+			// В сгенерированных файлах директив нет, дальше читать незачем
+			return true, nil, nil
+		}
+		rest, ok := strings.CutPrefix(text, "//go:generate ")
+		if !ok {
+			continue
+		}
+		fields := generateFields(rest)
+		var args []string
+		switch {
+		case len(fields) > 0 && filepath.Base(fields[0]) == "ggconfig":
+			args = fields[1:]
+		case len(fields) > 2 && fields[0] == "go" && fields[1] == "run" &&
+			strings.SplitN(fields[2], "@", 2)[0] == commandPath:
+			args = fields[3:]
+		default:
+			continue
+		}
+		lines = append(lines, DirectiveLine{File: path, Line: lineNo, Text: text, Args: args})
+	}
+	return false, lines, sc.Err()
+}
+
+// generateFields делит строку директивы как go generate: по пробелам, строки в двойных
+// кавычках - одно поле
+func generateFields(s string) []string {
+	var fields []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return fields
+		}
+		if s[0] == '"' {
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(s) {
+				if v, err := strconv.Unquote(s[:end+1]); err == nil {
+					fields = append(fields, v)
+					s = s[end+1:]
+					continue
+				}
+			}
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+}
+
+// directiveFiles - .go файлы директории, в которых ищутся директивы пакета (без тестов и
+// сгенерированных файлов), по имени
+func directiveFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var out []string
+	for _, path := range files {
+		if !strings.HasSuffix(path, "_test.go") && !strings.HasSuffix(path, ".gen.go") {
+			out = append(out, path)
+		}
+	}
+	return out, nil
+}
+
+// FindDirectives находит директивы //go:generate ggconfig в .go файлах директории
+func FindDirectives(dir string) ([]Directive, error) {
+	files, err := directiveFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var directives []Directive
+	for _, path := range files {
+		_, lines, err := ScanDirectives(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, dl := range lines {
+			// Флаги директивы разбираются тем же набором, что и командная строка ggconfig
+			fs := flag.NewFlagSet("ggconfig", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			var f Flags
+			f.Register(fs)
+			if err := fs.Parse(dl.Args); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, dl.Text, err)
+			}
+			iface, output, registry, envPrefix, aliases := f.Interface, f.Output, f.Registry, f.EnvPrefix, f.Aliases
+			pkgDir := dir
+			if f.Package != "" {
+				// Интерфейс из другого пакета: выход задан относительно директивы, пересчитываем его от пакета
+				if pkgDir, err = packageDir(dir, f.Package); err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", path, dl.Text, err)
+				}
+				abs, err := filepath.Abs(filepath.Join(dir, output))
+				if err != nil {
+					return nil, err
+				}
+				if output, err = filepath.Rel(pkgDir, abs); err != nil {
+					return nil, err
+				}
+			} else if iface == "" {
+				// Без --interface генерируется интерфейс, объявленный после директивы
+				if iface, err = interfaceAfterLine(path, dl.Line); err != nil {
+					continue
+				}
+			}
+			if iface == "" {
+				continue
+			}
+			// go generate запускает ggconfig в директории пакета: --manifest задан относительно нее,
+			// ggconfig.yaml ищется в корне модуля
+			mf, moduleDir, err := loadProjectManifest(dir, f.Manifest)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if mf != nil {
+				// Настройки пакета из манифеста заменяют флаги, которых нет в директиве
+				settings := mf.settings(moduleDir, pkgDir, iface)
+				if settings.Output != "" && !f.IsSet("output") {
+					if output, err = settings.outputFrom(moduleDir, pkgDir); err != nil {
+						return nil, err
+					}
+				}
+				if settings.Registry != nil && !f.IsSet("registry") {
+					registry = *settings.Registry
+				}
+				if settings.EnvPrefix != "" && !f.IsSet("env-prefix") {
+					envPrefix = settings.EnvPrefix
+				}
+				aliases = append(append(append(AliasFlags{}, mf.Aliases...), settings.Aliases...), aliases...)
+			}
+			directives = append(directives, Directive{
+				Dir:          pkgDir,
+				DirectiveDir: dir,
+				File:         path,
+				Line:         dl.Line,
+				Flags:        f,
+				Interface:    iface,
+				SourceFile:   f.SourceFile,
+				Aliases:      ParseAliasSettings(aliases),
+				Output:       output,
+				Registry:     registry,
+				Name:         f.Name,
+				EnvPrefix:    strings.ToUpper(envPrefix),
+				TypePrefix:   f.TypePrefix,
+				TypeSuffix:   f.TypeSuffix,
+				// --no-deps отключает копию runtime, как и в генераторе
+				OutFile:       f.OutFile,
+				VendorRuntime: f.VendorRuntime && !f.NoDeps,
+				Descriptor:    f.Descriptor,
+				DocExamples:   f.DocExamples,
+			})
+		}
+	}
+	return directives, nil
+}
+
+// WalkDirectives находит директивы ggconfig во всех пакетах под root (обход WalkPackageDirs)
+func WalkDirectives(root string) ([]Directive, error) {
+	var all []Directive
+	err := WalkPackageDirs(root, func(dir string) error {
+		directives, err := FindDirectives(dir)
+		if err != nil {
+			return err
+		}
+		all = append(all, directives...)
+		return nil
+	})
+	return all, err
+}
+
+// WalkPackageDirs calls fn for root and every directory under it that may hold a package
+// of the root module, like the ./... pattern of the go command: vendor, testdata, directories
+// starting with "." or "_" and nested modules (directories with their own go.mod) are skipped.
+func WalkPackageDirs(root string, fn func(dir string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root {
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		return fn(path)
+	})
+}
+
+// interfaceAfterLine возвращает имя первого интерфейса, объявленного в файле path после строки
+// line - так директива без --interface относится к интерфейсу под ней (go generate передает
+// файл и строку директивы в GOFILE и GOLINE)
+func interfaceAfterLine(path string, line int) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || fset.Position(gen.End()).Line <= line {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.InterfaceType); !ok || fset.Position(ts.Pos()).Line <= line {
+				continue
+			}
+			if ts.TypeParams != nil {
+				return "", fmt.Errorf("interface %s after line %d of %s is generic: name the instantiation with --interface=%s[...]", ts.Name.Name, line, path, ts.Name.Name)
+			}
+			return ts.Name.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no interface declared after line %d of %s: pass --interface", line, path)
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanDirectives(t *testing.T) {
+	dir := t.TempDir()
+	src := `package svc
+
+//go:generate ggconfig --interface=Config --alias "env.Host=A B" --sources=env,yaml,json
+//go:generate go run github.com/apopov-app/ggconfig@v1.2.0 --interface=Admin --registry
+//go:generate go run golang.org/x/tools/cmd/stringer -type=Mode
+//go:generate /usr/local/bin/ggconfig --interface=Other
+
+type Config interface {
+	Host(defaultValue string) (string, bool)
+}
+
+type Admin interface {
+	Token(defaultValue string) (string, bool)
+}
+
+type Other interface {
+	Port(defaultValue int) (int, bool)
+}
+`
+	path := filepath.Join(dir, "config.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generated, lines, err := ScanDirectives(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated {
+		t.Fatal("config.go reported as generated")
+	}
+	want := []struct {
+		line int
+		args string
+	}{
+		{3, "--interface=Config|--alias|env.Host=A B|--sources=env,yaml,json"},
+		{4, "--interface=Admin|--registry"},
+		{6, "--interface=Other"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("directives = %+v, want %d", lines, len(want))
+	}
+	for i, w := range want {
+		if lines[i].Line != w.line || strings.Join(lines[i].Args, "|") != w.args {
+			t.Errorf("directive %d = line %d %q, want line %d %q", i, lines[i].Line, strings.Join(lines[i].Args, "|"), w.line, w.args)
+		}
+	}
+
+	// FindDirectives разбирает аргументы тем же набором флагов, что и команда
+	directives, err := FindDirectives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(directives) != 3 || directives[0].Flags.Sources != "env,yaml,json" || !directives[1].Registry || directives[2].Interface != "Other" {
+		t.Fatalf("directives = %+v", directives)
+	}
+	if aliases := directives[0].Aliases.Env["Host"]; len(aliases) != 1 || aliases[0] != "A B" {
+		t.Errorf("Host aliases = %v, want [A B]", aliases)
+	}
+
+	gen := filepath.Join(dir, "svc.gen.go")
+	if err := os.WriteFile(gen, []byte(GeneratedHeader+"\n//go:generate ggconfig --interface=Config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if generated, lines, err := ScanDirectives(gen); err != nil || !generated || len(lines) != 0 {
+		t.Errorf("generated file: %v, %v, %v; want generated without directives", generated, lines, err)
+	}
+}
+
+func TestWalkPackageDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"svc/internal", "vendor/x", "testdata/x", ".git/x", "_tools", "nested/pkg", "svc/testdata"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Корень - сам модуль; вложенный модуль nested обходится отдельно
+	for _, file := range []string{"go.mod", "nested/go.mod"} {
+		if err := os.WriteFile(filepath.Join(root, file), []byte("module example.com/m\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var dirs []string
+	err := WalkPackageDirs(root, func(dir string) error {
+		rel, _ := filepath.Rel(root, dir)
+		dirs = append(dirs, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(dirs, " "); got != ". svc svc/internal" {
+		t.Errorf("dirs = %s, want . svc svc/internal", got)
+	}
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"strings"
+)
+
+// docExampleValue - значение метода для Example функций: в ENV/YAML, в Go коде и в выводе
+type docExampleValue struct {
+	Text     string // ENV и YAML
+	GoValue  string // значение для Override
+	Zero     string // аргумент defaultValue
+	Duration bool   // GoValue использует пакет time
+}
+
+// docExampleMethod выбирает метод для Example функций: первый скаляр без директив (директивы
+// вроде oneof или min могли бы отклонить пример значения)
+func docExampleMethod(methods []Method) (Method, docExampleValue, bool) {
+	for _, m := range methods {
+		if m.Pointer || m.IsSlice || m.WasOf != "" || len(m.Directives) > 0 {
+			continue
+		}
+		switch {
+		case m.Kind == KindDuration:
+			return m, docExampleValue{Text: "30s", GoValue: "30 * time.Second", Zero: "0", Duration: true}, true
+		case m.Kind != "":
+			continue
+		case m.ReturnType == "string":
+			return m, docExampleValue{Text: "example", GoValue: `"example"`, Zero: `""`}, true
+		case m.ReturnType == "bool":
+			return m, docExampleValue{Text: "true", GoValue: "true", Zero: "false"}, true
+		case IsIntegerType(m.ReturnType):
+			return m, docExampleValue{Text: "42", GoValue: "42", Zero: "0"}, true
+		}
+	}
+	return Method{}, docExampleValue{}, false
+}
+
+// generateDocExamples записывает <package>_example_test.go с Example функциями конструкторов
+// (--doc-examples): go doc и pkg.go.dev показывают их рядом с документацией, а go test
+// проверяет вывод
+func (g *Generator) generateDocExamples(info *InterfaceInfo, aliases AliasSettings, outputPath, packageName string, opts GenerateOptions) error {
+	if !ast.IsExported(info.InterfaceName) && opts.OutputPath == "" {
+		return fmt.Errorf("--doc-examples: interface %s is unexported, so its constructors cannot have Example functions", info.InterfaceName)
+	}
+	m, value, ok := docExampleMethod(info.Methods)
+	if !ok {
+		g.logf("--doc-examples: interface %s has no plain string, bool, integer or time.Duration method; Example functions skipped\n", info.InterfaceName)
+		return nil
+	}
+	filePath := filepath.Join(outputPath, info.TypeName()+"_example_test.go")
+	if err := g.guardOverwrite(filePath, GeneratedHeader); err != nil {
+		return err
+	}
+
+	env, _ := methodKeys(info, aliases, m.Name)
+	output := value.Text + " true"
+	if m.ReturnsError {
+		output = value.Text + " <nil>"
+	}
+	data := struct {
+		GenPackageName string
+		Ctor           string
+		Method         string
+		EnvKey         string
+		EnvValue       string
+		Section        string
+		YAMLKey        string
+		GoValue        string
+		Zero           string
+		Output         string
+		Duration       bool
+		NoDeps         bool
+		ImportRuntime  bool
+		ParseYAML      string
+	}{
+		GenPackageName: packageName,
+		Ctor:           "New" + TitleName(info.TypeName()) + TitleName(info.InterfaceName),
+		Method:         m.Name,
+		// Алиасы ENV читаются первыми, поэтому пример задает первый ключ
+		EnvKey:        env[0],
+		EnvValue:      value.Text,
+		Section:       info.PackageName,
+		YAMLKey:       strings.ToLower(m.Name),
+		GoValue:       value.GoValue,
+		Zero:          value.Zero,
+		Output:        output,
+		Duration:      value.Duration,
+		NoDeps:        opts.NoDeps,
+		ImportRuntime: !opts.NoDeps && !opts.VendorRuntime,
+		ParseYAML:     runtimeIdent("ParseYAML", opts.VendorRuntime),
+	}
+	tmpl, err := g.loadTemplate("doc_example", "doc_example.go.tmpl", nil)
+	if err != nil {
+		return err
+	}
+	return g.writeTemplate(filePath, opts.FileMode, tmpl, data)
+}
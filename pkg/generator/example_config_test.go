@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const exampleConfig = `package svc
+
+import (
+	"net"
+	"net/url"
+	"time"
+)
+
+type Config interface {
+	// Port of the server
+	// default: 8080
+	Port(defaultValue int) (int, bool)
+	// ggconfig:default=localhost
+	Host(defaultValue string) (string, bool)
+	Name(defaultValue *string) (*string, bool)
+	// ggconfig:oneof=debug,info
+	Level(defaultValue string) (string, bool)
+	Debug(defaultValue bool) (bool, bool)
+	// default: 5s
+	ReadTimeout(defaultValue time.Duration) (time.Duration, bool)
+	// default: a,b
+	Tags(defaultValue []string) ([]string, bool)
+	Endpoint(defaultValue *url.URL) (*url.URL, bool)
+	Bind(defaultValue net.IP) (net.IP, bool)
+	Allow(defaultValue *net.IPNet) (*net.IPNet, bool)
+	// ggconfig:format=unixms
+	Window(defaultValue int64) (int64, bool)
+	// ggconfig:secret
+	Password(defaultValue string) (string, bool)
+}
+`
+
+// exampleConfigTest загружает сгенерированные примеры сгенерированными YAML и JSON источниками:
+// каждый ключ примера должен находиться под тем именем, которое читает реализация
+const exampleConfigTest = `package svc
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type source interface {
+	Err() error
+	Port(int) (int, bool)
+	Host(string) (string, bool)
+	Name(*string) (*string, bool)
+	Level(string) (string, bool)
+	Debug(bool) (bool, bool)
+	ReadTimeout(time.Duration) (time.Duration, bool)
+	Tags([]string) ([]string, bool)
+	Endpoint(*url.URL) (*url.URL, bool)
+	Bind(net.IP) (net.IP, bool)
+	Allow(*net.IPNet) (*net.IPNet, bool)
+	Window(int64) (int64, bool)
+	Password(string) (string, bool)
+}
+
+func TestExampleLoads(t *testing.T) {
+	for name, cfg := range map[string]source{
+		"yaml": NewSvcConfigYAMLConfig("../configs/svc_example.yaml"),
+		"json": NewSvcConfigJSONConfig("../configs/svc_example.json"),
+	} {
+		if err := cfg.Err(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if v, ok := cfg.Port(1); !ok || v != 8080 {
+			t.Errorf("%s: Port = %d, %v; want 8080", name, v, ok)
+		}
+		if v, ok := cfg.Host("x"); !ok || v != "localhost" {
+			t.Errorf("%s: Host = %q, %v; want localhost", name, v, ok)
+		}
+		if v, ok := cfg.Name(nil); !ok || v == nil {
+			t.Errorf("%s: Name = %v, %v; want a value from the example", name, v, ok)
+		}
+		if v, ok := cfg.Level("info"); !ok || v != "debug" {
+			t.Errorf("%s: Level = %q, %v; want debug", name, v, ok)
+		}
+		if _, ok := cfg.Debug(true); !ok {
+			t.Errorf("%s: Debug is not set", name)
+		}
+		if v, ok := cfg.ReadTimeout(0); !ok || v != 5*time.Second {
+			t.Errorf("%s: ReadTimeout = %v, %v; want 5s", name, v, ok)
+		}
+		if v, ok := cfg.Tags(nil); !ok || len(v) != 2 {
+			t.Errorf("%s: Tags = %v, %v; want [a b]", name, v, ok)
+		}
+		if v, ok := cfg.Endpoint(nil); !ok || v == nil || v.Host == "" {
+			t.Errorf("%s: Endpoint = %v, %v; want a sample URL", name, v, ok)
+		}
+		if v, ok := cfg.Bind(nil); !ok || v == nil {
+			t.Errorf("%s: Bind = %v, %v; want a sample IP", name, v, ok)
+		}
+		if v, ok := cfg.Allow(nil); !ok || v == nil {
+			t.Errorf("%s: Allow = %v, %v; want a sample CIDR", name, v, ok)
+		}
+		if v, ok := cfg.Window(0); !ok || v != time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC).UnixMilli() {
+			t.Errorf("%s: Window = %d, %v; want the sample time in milliseconds", name, v, ok)
+		}
+		// Пример проходит строгую проверку: значения-образцы разбираются своими типами
+		if err := NewSvcConfigAll(cfg).Validate(); err != nil {
+			t.Errorf("%s: Validate: %v", name, err)
+		}
+	}
+}
+`
+
+func TestExampleConfigReadable(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{"svc/config.go": exampleConfig})
+	opts := Options{
+		Dir:           filepath.Join(dir, "svc"),
+		Interface:     "Config",
+		Example:       "configs",
+		ExampleFormat: "yaml,json",
+		Sources:       []string{"env", "yaml", "json", "mock", "composite"},
+		Strict:        true,
+	}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	example, err := os.ReadFile(filepath.Join(dir, "configs", "svc_example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Комментарий описывает тип из объявления метода
+	for _, want := range []string{"\nsvc:\n", "  port: 8080\n", "  readtimeout: \"5s\"\n", "  window: \"2024-01-01T09:00:00Z\"\n", "  # password: \"${secret:SVC_PASSWORD}\"\n", "# Name - *string parameter"} {
+		if !strings.Contains(string(example), want) {
+			t.Errorf("example lacks %q:\n%s", want, example)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "svc", "example_test.go"), []byte(exampleConfigTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExamplesGolden генерирует директивы примеров репозитория в режиме Check: закоммиченные
+// .gen.go файлы и примеры конфигов - эталон вывода генератора, и любое изменение шаблонов должно
+// сопровождаться их перегенерацией (go generate ./... в каждом примере)
+func TestExamplesGolden(t *testing.T) {
+	roots, err := filepath.Glob("../../example*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) == 0 {
+		t.Fatal("no examples found")
+	}
+	for _, root := range roots {
+		directives, err := WalkDirectives(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(directives) == 0 {
+			t.Errorf("%s: no ggconfig directives", root)
+		}
+		for _, d := range directives {
+			name, err := filepath.Rel("../..", d.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Run(filepath.ToSlash(name), func(t *testing.T) {
+				opts, err := d.Flags.Options()
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.Dir, opts.File = d.DirectiveDir, filepath.Base(d.File)
+				if opts.Interface == "" {
+					opts.Line = d.Line
+				}
+				opts.RuntimeSources = os.DirFS("../../runtime")
+				res, err := New(opts).Check()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(res.Files) == 0 {
+					t.Fatal("no files generated")
+				}
+				for _, path := range res.Stale {
+					t.Errorf("%s differs from the generator output, run go generate in %s", path, root)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Flags holds the generation flags of the ggconfig command. The command registers them on its
+// command line and FindDirectives parses directive arguments with the same set, so a flag is
+// declared once.
+type Flags struct {
+	Interface       string
+	Output          string
+	Example         string
+	ExampleFormat   string
+	Registry        bool
+	Name            string
+	NoDeps          bool
+	VendorRuntime   bool
+	Strict          bool
+	Descriptor      bool
+	DocExamples     bool
+	OptionalSection bool
+	NoYAMLAnchors   bool
+	OutFile         string
+	OutPackage      string
+	EnvPrefix       string
+	TypePrefix      string
+	TypeSuffix      string
+	Sources         string
+	Package         string
+	SourceFile      string
+	Manifest        string
+	FileMode        string
+	Aliases         AliasFlags
+	TemplateDir     string
+	Force           bool
+	Check           bool
+	DryRun          bool
+
+	fs *flag.FlagSet // Набор, в котором зарегистрированы флаги: по нему Options узнает явно заданные
+}
+
+// Register defines the generation flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	f.fs = fs
+	fs.StringVar(&f.Interface, "interface", "", "interface name (default under go generate: the interface declared below the directive)")
+	fs.StringVar(&f.Output, "output", "", "output directory path")
+	fs.StringVar(&f.Example, "example", "", "generate example config file")
+	fs.StringVar(&f.ExampleFormat, "example-format", "yaml", "example config format: yaml | json | env (.env file of ENV variables), comma-separated for several, e.g. yaml,env")
+	fs.BoolVar(&f.Registry, "registry", false, "enable global registry: generates registry.gen.go in output package and init() self-registration in each generated file")
+	fs.StringVar(&f.Name, "name", "", "override package name for generation (default: auto-detect from path)")
+	fs.BoolVar(&f.NoDeps, "no-deps", false, "dependency-free mode: generate only ENV, Mock and composite implementations with no external imports (no YAML, no runtime package)")
+	fs.BoolVar(&f.VendorRuntime, "vendor-runtime", false, "copy runtime helpers into the output package (unexported, "+VendoredRuntimeFile+") instead of importing "+RuntimeImportPath)
+	fs.BoolVar(&f.Strict, "strict", false, "strict mode: malformed ENV/YAML values are passed to the runtime.SetParseErrorHandler handler (panic by default) instead of silently falling back to the default")
+	fs.BoolVar(&f.Descriptor, "descriptor", false, "write a JSON descriptor of all config keys (<package>.descriptor.json) next to the generated code and embed it (go:embed) for admin endpoints")
+	fs.BoolVar(&f.DocExamples, "doc-examples", false, "write runnable Example functions for the generated constructors (<package>_example_test.go) next to the generated code: shown by go doc, checked by go test")
+	fs.BoolVar(&f.OptionalSection, "optional-section", false, "the section can be switched off with enabled: false (ENV: <PACKAGE>_ENABLED=false): all its keys then resolve as absent; generates Enabled()")
+	fs.BoolVar(&f.NoYAMLAnchors, "no-yaml-anchors", false, "reject YAML files with anchors (&name), aliases (*name) and merge keys (<<) when generated code and the registry load them")
+	fs.StringVar(&f.OutFile, "out-file", "", "name of the generated file in the output directory (default: <package>.gen.go by the unique package name)")
+	fs.StringVar(&f.OutPackage, "out-package", "", "package name of the generated code (default: the output directory name)")
+	fs.StringVar(&f.EnvPrefix, "env-prefix", "", "prefix of the derived ENV variables instead of the package name, e.g. APP_SERVER for APP_SERVER_HOST")
+	fs.StringVar(&f.TypePrefix, "type-prefix", "", "prefix of the generated type, constructor and file names, e.g. Admin for Admininternal_serverEnvConfig")
+	fs.StringVar(&f.TypeSuffix, "type-suffix", "", "suffix of the generated type, constructor and file names, e.g. _admin for internal_server_adminEnvConfig")
+	fs.StringVar(&f.Sources, "sources", "", "comma-separated sources to generate, e.g. env,yaml,mock,composite,json,chaos, or all (default: env,yaml,mock,composite, plus flag and secret for annotated methods)")
+	fs.StringVar(&f.Package, "package", "", "import path of the package that declares the interface (default: the package of the directive), e.g. to generate from a central gconfig package")
+	fs.StringVar(&f.SourceFile, "source-file", "", "parse the interface from this file only instead of the whole package (for packages that temporarily do not parse, e.g. mid-refactor)")
+	fs.StringVar(&f.Manifest, "manifest", "", "service manifest (YAML): aliases shared by all packages, per-package settings and profiles (dev, staging, prod...) with per-environment example values (default: "+ProjectManifestFile+" at the module root, if present)")
+	fs.StringVar(&f.FileMode, "file-mode", "", "permissions of generated and example files, octal (default 0644; 0600 for examples with secret placeholders)")
+	fs.Var(&f.Aliases, "alias", "alias mapping: env.<Method>=ALIAS1,ALIAS2 | yaml.section=ALIAS1,ALIAS2 | yaml.key.<Method>=ALIAS1,ALIAS2")
+	fs.StringVar(&f.TemplateDir, "template-dir", "", "directory with templates that replace the embedded ones of the same name (config.go.tmpl, example.yaml.tmpl, ...; see --print-templates)")
+	fs.BoolVar(&f.Force, "force", false, "overwrite existing *.gen.go and example YAML files even if they were not generated by ggconfig")
+	fs.BoolVar(&f.Check, "check", false, "check that generated and example files are up to date without writing them (exit status 1 if any differ)")
+	fs.BoolVar(&f.DryRun, "dry-run", false, "render all files without writing them and print unified diffs against the existing files")
+}
+
+// IsSet reports whether the flag name was given explicitly on the parsed command line.
+func (f *Flags) IsSet(name string) bool {
+	set := false
+	if f.fs != nil {
+		f.fs.Visit(func(fl *flag.Flag) {
+			if fl.Name == name {
+				set = true
+			}
+		})
+	}
+	return set
+}
+
+// Options converts the parsed flags to generator options. Registry is set only when --registry
+// is given, so the manifest settings apply otherwise. Dir, File, Line, RuntimeSources and Log
+// are left to the caller.
+func (f *Flags) Options() (Options, error) {
+	var mode os.FileMode
+	if f.FileMode != "" {
+		m, err := strconv.ParseUint(f.FileMode, 8, 32)
+		if err != nil || m > 0777 {
+			return Options{}, fmt.Errorf("invalid --file-mode %q: expected octal permissions, e.g. 0600", f.FileMode)
+		}
+		mode = os.FileMode(m)
+	}
+	opts := Options{
+		Interface:       f.Interface,
+		Package:         f.Package,
+		SourceFile:      f.SourceFile,
+		Name:            f.Name,
+		Output:          f.Output,
+		OutFile:         f.OutFile,
+		OutPackage:      f.OutPackage,
+		NoDeps:          f.NoDeps,
+		VendorRuntime:   f.VendorRuntime,
+		Strict:          f.Strict,
+		Descriptor:      f.Descriptor,
+		DocExamples:     f.DocExamples,
+		OptionalSection: f.OptionalSection,
+		NoYAMLAnchors:   f.NoYAMLAnchors,
+		EnvPrefix:       f.EnvPrefix,
+		TypePrefix:      f.TypePrefix,
+		TypeSuffix:      f.TypeSuffix,
+		Aliases:         f.Aliases,
+		Example:         f.Example,
+		ExampleFormat:   f.ExampleFormat,
+		Manifest:        f.Manifest,
+		FileMode:        mode,
+		TemplateDir:     f.TemplateDir,
+		Force:           f.Force,
+		Check:           f.Check,
+		DryRun:          f.DryRun,
+	}
+	if f.Sources != "" {
+		opts.Sources = strings.Split(f.Sources, ",")
+	}
+	// --registry, заданный явно, важнее настройки пакета в манифесте
+	if f.IsSet("registry") {
+		registry := f.Registry
+		opts.Registry = &registry
+	}
+	return opts, nil
+}
@@ -0,0 +1,512 @@
+// Package generator parses configuration interfaces and generates their implementations
+// (ENV, YAML, Mock, composite and the other sources), example configs and descriptors.
+// It is the engine of the ggconfig command and can be embedded into other tools:
+//
+//	res, err := generator.New(generator.Options{
+//		Dir:       "internal/server",
+//		Interface: "Config",
+//		Output:    "../gconfig",
+//		Log:       os.Stderr,
+//	}).Generate()
+//
+// Options mirror the flags of the ggconfig command.
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// Options configures one generation run. The zero value of a field means the default of the
+// corresponding ggconfig flag; the project manifest (ggconfig.yaml) fills Output, Registry,
+// Example, Sources and EnvPrefix when they are not set.
+type Options struct {
+	// Dir is the directory of the directive's package; relative paths of the other options
+	// are resolved against it. Empty means the current directory.
+	Dir string
+	// Interface is the name of the interface, with type arguments for generic interfaces
+	// (Config[int]). Empty means the interface declared below line Line of File.
+	Interface string
+	// File and Line locate the //go:generate directive ($GOFILE and $GOLINE).
+	File string
+	Line int
+
+	// Package is the import path of the package that declares the interface (--package).
+	Package string
+	// SourceFile restricts parsing to one file of the package (--source-file).
+	SourceFile string
+	// Name overrides the unique package name (--name).
+	Name string
+
+	// Output is the directory of the generated code (--output); empty means the package itself.
+	Output string
+	// OutFile and OutPackage override the generated file and package names.
+	OutFile    string
+	OutPackage string
+
+	// Registry enables registry.gen.go and init() self-registration; nil leaves it to the manifest.
+	Registry        *bool
+	NoDeps          bool
+	VendorRuntime   bool
+	Strict          bool
+	Descriptor      bool
+	DocExamples     bool
+	OptionalSection bool
+	NoYAMLAnchors   bool
+	// Sources lists the generated sources and wrappers (env, yaml, mock, composite, json, chaos, ...);
+	// empty means the defaults (env, yaml, mock, composite, and flag/secret for annotated methods),
+	// "all" means every source the interface supports.
+	Sources   []string
+	EnvPrefix string
+	// TypePrefix and TypeSuffix are added to the unique package name in the generated type,
+	// constructor and file names, so several interfaces can share a package.
+	TypePrefix string
+	TypeSuffix string
+	// Aliases are --alias mappings: env.<Method>=A,B | yaml.section=A,B | yaml.key.<Method>=A,B.
+	Aliases []string
+
+	// Example is the directory of example configs, relative to the module root; empty disables them.
+	Example string
+	// ExampleFormat is a comma-separated list of yaml, json and env; empty means yaml.
+	ExampleFormat string
+
+	// Manifest is the path of the project manifest; empty means ggconfig.yaml at the module root.
+	Manifest string
+	// FileMode sets the permissions of the written files; 0 keeps the defaults.
+	FileMode os.FileMode
+	// TemplateDir holds templates that replace the embedded ones of the same name.
+	TemplateDir string
+	// RuntimeSources holds the .go files of the runtime package; required by VendorRuntime.
+	RuntimeSources fs.FS
+
+	// Force overwrites files that were not generated by ggconfig and rewrites unchanged ones.
+	Force bool
+	// Check compares the generated files with the existing ones instead of writing them.
+	Check bool
+	// DryRun is Check that also writes unified diffs to Log.
+	DryRun bool
+
+	// Log receives progress messages and diffs; nil discards them.
+	Log io.Writer
+}
+
+// Result describes a finished generation run.
+type Result struct {
+	// Interface is the parsed interface.
+	Interface *InterfaceInfo
+	// Output is the output directory after the manifest is applied; empty means the package itself.
+	Output string
+	// Files lists every generated file, in the order they were rendered.
+	Files []string
+	// Stale lists the files that differ from their rendering (Check and DryRun only).
+	Stale []string
+	// Unchanged lists the files that were already up to date and were not rewritten.
+	Unchanged []string
+}
+
+// Generator runs the generation for one interface.
+type Generator struct {
+	opts   Options
+	dir    string
+	result *Result
+	// goSources - Go файлы этого запуска: по ним копия runtime выбирает нужные объявления
+	goSources map[string][]byte
+}
+
+// New returns a Generator for opts.
+func New(opts Options) *Generator {
+	return &Generator{opts: opts}
+}
+
+// Check compares the generated and example files with the existing ones without writing
+// anything, as Generate with Options.Check. The files that differ are listed in Result.Stale.
+func (g *Generator) Check() (*Result, error) {
+	g.opts.Check = true
+	return g.Generate()
+}
+
+// Generate parses the interface and writes (or, with Check and DryRun, compares) its
+// implementations and example configs. The result is returned even when the files are stale.
+func (g *Generator) Generate() (*Result, error) {
+	g.result = &Result{}
+	var err error
+	if g.dir, err = filepath.Abs(g.path(".")); err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	opts := g.opts
+	if opts.TemplateDir != "" {
+		if err := CheckTemplateDir(g.path(opts.TemplateDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Пакет интерфейса: по умолчанию пакет директивы, с Package - найденный go list
+	sourceDir, sourcePath := g.dir, "."
+	if opts.Package != "" {
+		if sourceDir, err = packageDir(g.dir, opts.Package); err != nil {
+			return nil, fmt.Errorf("failed to find package %s: %w", opts.Package, err)
+		}
+		sourcePath = sourceDir
+	}
+
+	uniquePackageName := opts.Name
+	packageName := filepath.Base(sourceDir)
+	if uniquePackageName != "" {
+		g.logf("Using package name: %s\n", uniquePackageName)
+	} else {
+		// Уникальное имя вычисляется по пути пакета интерфейса в модуле
+		if uniquePackageName, err = PackageUniqueName(g.dir, sourceDir); err != nil {
+			return nil, fmt.Errorf("failed to resolve package of %s: %w", sourceDir, err)
+		}
+		g.logf("Auto-detected package: %s (unique: %s)\n", packageName, uniquePackageName)
+	}
+
+	if opts.OutFile != "" && (filepath.Base(opts.OutFile) != opts.OutFile || !strings.HasSuffix(opts.OutFile, ".go") || strings.HasSuffix(opts.OutFile, "_test.go")) {
+		return nil, fmt.Errorf("invalid --out-file %q: expected a .go file name without directories, e.g. server_config.gen.go (use --output for the directory)", opts.OutFile)
+	}
+	if opts.OutPackage != "" && !token.IsIdentifier(opts.OutPackage) {
+		return nil, fmt.Errorf("invalid --out-package %q: expected a Go package name", opts.OutPackage)
+	}
+	if opts.Interface == "" && opts.Package != "" {
+		return nil, fmt.Errorf("--package requires --interface")
+	}
+	if opts.Interface == "" {
+		// go generate передает файл и строку директивы: генерируется интерфейс под ней
+		if opts.File == "" || opts.Line == 0 {
+			return nil, fmt.Errorf("interface name is required (--interface, or run from a //go:generate directive above the interface)")
+		}
+		if opts.Interface, err = interfaceAfterLine(g.path(opts.File), opts.Line); err != nil {
+			return nil, err
+		}
+	}
+
+	// Манифест: Manifest или ggconfig.yaml в корне модуля. Настройки пакета заменяют
+	// незаданные опции
+	mf, moduleDir, err := loadProjectManifest(g.dir, opts.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if mf != nil {
+		settings := mf.settings(moduleDir, sourceDir, opts.Interface)
+		if settings.Output != "" && opts.Output == "" {
+			if opts.Output, err = settings.outputFrom(moduleDir, g.dir); err != nil {
+				return nil, fmt.Errorf("manifest %s: %w", mf.path, err)
+			}
+		}
+		if settings.Registry != nil && opts.Registry == nil {
+			opts.Registry = settings.Registry
+		}
+		if settings.Example != "" && opts.Example == "" {
+			opts.Example = settings.Example
+		}
+		if len(settings.Sources) > 0 && len(opts.Sources) == 0 {
+			opts.Sources = settings.Sources
+		}
+		if settings.EnvPrefix != "" && opts.EnvPrefix == "" {
+			opts.EnvPrefix = settings.EnvPrefix
+		}
+		opts.Aliases = append(append(append([]string{}, mf.Aliases...), settings.Aliases...), opts.Aliases...)
+	}
+	registry := opts.Registry != nil && *opts.Registry
+	if opts.Package != "" && opts.Output == "" {
+		// Интерфейс в другом пакете: генерация в пакет директивы с импортом исходного
+		opts.Output = "."
+	}
+	g.result.Output = opts.Output
+	if err := checkEnvPrefix(opts.EnvPrefix); err != nil {
+		return nil, fmt.Errorf("invalid --env-prefix: %w", err)
+	}
+	if opts.TypePrefix != "" && !token.IsIdentifier(opts.TypePrefix) {
+		return nil, fmt.Errorf("invalid --type-prefix: %q is not a Go identifier", opts.TypePrefix)
+	}
+	if opts.TypeSuffix != "" && !token.IsIdentifier("_"+opts.TypeSuffix) {
+		return nil, fmt.Errorf("invalid --type-suffix: %q must be letters, digits and '_'", opts.TypeSuffix)
+	}
+	if opts.NoDeps && opts.NoYAMLAnchors {
+		return nil, fmt.Errorf("--no-yaml-anchors applies to YAML sources, which are not generated with --no-deps")
+	}
+	if opts.NoDeps && opts.Descriptor {
+		return nil, fmt.Errorf("--descriptor requires the runtime package and is not supported with --no-deps")
+	}
+	if opts.NoDeps && registry {
+		// Реестр построен на runtime.YAML, поэтому без зависимостей он невозможен
+		return nil, fmt.Errorf("--no-deps cannot be combined with --registry (registry requires the runtime YAML package)")
+	}
+	g.logf("Generating config for package: %s, interface: %s\n", packageName, opts.Interface)
+
+	if opts.SourceFile != "" {
+		g.logf("Parsing file: %s\n", opts.SourceFile)
+	} else {
+		g.logf("Parsing package: %s\n", sourcePath)
+	}
+	info, err := ParseInterface(g.path(sourcePath), opts.SourceFile, packageName, uniquePackageName, opts.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse interface: %w", err)
+	}
+	info.EnvPrefix = strings.ToUpper(opts.EnvPrefix)
+	info.TypePrefix, info.TypeSuffix = opts.TypePrefix, opts.TypeSuffix
+	g.result.Interface = info
+	if err := checkMethods(info, opts); err != nil {
+		return nil, err
+	}
+
+	// Алиасы: сначала из директив методов, затем из манифеста (общие, затем пакета) и опций
+	aliasSettings := MethodAliases(info, ParseAliasSettings(opts.Aliases))
+	// Значения профилей проверяются до записи файлов
+	var profiles []exampleProfile
+	if mf != nil && opts.Example != "" {
+		if profiles, err = mf.exampleProfiles(info, aliasSettings); err != nil {
+			return nil, fmt.Errorf("manifest: %w", err)
+		}
+	}
+
+	g.logf("Found %d methods in interface\n", len(info.Methods))
+	for _, method := range info.Methods {
+		second := "bool"
+		if method.ReturnsError {
+			second = "error"
+		}
+		param := method.ParamType
+		if method.Pointer {
+			param = method.DeclaredType()
+		}
+		g.logf("  - %s(%s) (%s, %s)\n", method.Name, param, method.DeclaredType(), second)
+	}
+
+	// Определяем, нужно ли добавлять импорт
+	if opts.Output != "" {
+		// Проверяем, есть ли кастомные типы исходного пакета (не встроенные и не pkg.Type)
+		hasCustomTypes := false
+		for _, method := range info.Methods {
+			if isLocalType(method.ParamType) || isLocalType(method.ReturnType) {
+				hasCustomTypes = true
+				break
+			}
+		}
+
+		if hasCustomTypes {
+			// Генерация в другой пакет - нужен импорт
+			info.NeedImport = true
+			// Вычисляем import path
+			if info.sourcePackageName() == "main" {
+				return nil, fmt.Errorf("interface %s uses types of package main, which cannot be imported from --output=%s: generate into the package (remove --output) or move the types", info.InterfaceName, opts.Output)
+			}
+			pkg, err := resolvePackage(sourceDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve import path of %s: %w", sourceDir, err)
+			}
+			info.ImportPath = pkg.ImportPath
+		}
+	}
+
+	// Генерируем все реализации в одном файле
+	genOpts := GenerateOptions{
+		OutputPath:      opts.Output,
+		Registry:        registry,
+		NoDeps:          opts.NoDeps,
+		VendorRuntime:   opts.VendorRuntime && !opts.NoDeps,
+		Strict:          opts.Strict,
+		FileMode:        opts.FileMode,
+		Descriptor:      opts.Descriptor,
+		NoYAMLAnchors:   opts.NoYAMLAnchors,
+		OptionalSection: opts.OptionalSection,
+		DocExamples:     opts.DocExamples,
+		OutFile:         opts.OutFile,
+		OutPackage:      opts.OutPackage,
+	}
+	if genOpts.Sources, err = selectSources(strings.Join(opts.Sources, ","), info, genOpts); err != nil {
+		return nil, err
+	}
+	if err := g.generateImplementation(info, aliasSettings, genOpts); err != nil {
+		return nil, fmt.Errorf("failed to generate implementation: %w", err)
+	}
+
+	// Генерируем пример конфига если указан путь
+	if opts.Example != "" {
+		format := opts.ExampleFormat
+		if format == "" {
+			format = "yaml"
+		}
+		if err := g.generateExampleConfig(info, opts.Example, format, opts.FileMode, profiles); err != nil {
+			return nil, fmt.Errorf("failed to generate example config: %w", err)
+		}
+	}
+	return g.result, nil
+}
+
+// checkMethods проверяет директивы методов до генерации: неподдерживаемое сочетание типа,
+// директивы и опций - ошибка с именем метода
+func checkMethods(info *InterfaceInfo, opts Options) error {
+	for _, method := range info.Methods {
+		// Флаги поддерживаются только для bool и string
+		if _, ok := method.Directive("flag"); ok && method.ReturnType != "bool" && method.ReturnType != "string" {
+			return fmt.Errorf("method %s is annotated with ggconfig:flag but returns %s (supported: bool, string)", method.Name, method.ReturnType)
+		}
+		if _, ok := method.Directive("secret"); ok && method.ReturnType != "string" {
+			return fmt.Errorf("method %s is annotated with ggconfig:secret but returns %s (supported: string)", method.Name, method.ReturnType)
+		}
+		if v, ok := method.Directive("default"); ok {
+			if method.ReturnType == "bool" && v != "true" && v != "false" {
+				return fmt.Errorf("method %s: ggconfig:default=%q must be true or false", method.Name, v)
+			}
+			// Документированное значение проверяется по типу метода, как значения профилей
+			if _, err := encodeProfileValue(method, v); err != nil {
+				return fmt.Errorf("method %s: ggconfig:default=%q: %w", method.Name, v, err)
+			}
+		}
+		if v, ok := method.Directive("env"); ok && (strings.Trim(v, ", ") == "" || strings.ContainsAny(v, " =")) {
+			return fmt.Errorf("method %s: ggconfig:env requires comma-separated ENV variable names, e.g. ggconfig:env=DATABASE_URL", method.Name)
+		}
+		if v, ok := method.Directive("yaml"); ok && (strings.Trim(v, ", ") == "" || strings.ContainsAny(v, " =")) {
+			return fmt.Errorf("method %s: ggconfig:yaml requires comma-separated YAML keys, e.g. ggconfig:yaml=db_url", method.Name)
+		}
+		if _, ok := method.Directive("allow-empty"); ok && method.ReturnType != "string" {
+			return fmt.Errorf("method %s is annotated with ggconfig:allow-empty but returns %s (supported: string)", method.Name, method.ReturnType)
+		}
+		if _, ok := method.Directive("cache"); ok && !cacheableMethod(method) {
+			return fmt.Errorf("method %s is annotated with ggconfig:cache but returns %s (cached values are shared by all callers: slices, maps, pointers and raw YAML are not supported)", method.Name, method.ReturnType)
+		}
+		if _, err := unsetLiteral(method); err != nil {
+			return fmt.Errorf("method %s: %w", method.Name, err)
+		}
+		if _, ok := method.Directive("oneof"); ok {
+			if method.ReturnType != "string" || method.Kind != "" {
+				return fmt.Errorf("method %s is annotated with ggconfig:oneof but returns %s (supported: string)", method.Name, method.ReturnType)
+			}
+			if len(method.OneOf()) == 0 {
+				return fmt.Errorf("method %s: ggconfig:oneof requires a comma-separated list of values", method.Name)
+			}
+			if opts.NoDeps {
+				// Проверку выполняют runtime.ParseOneOf и runtime.YAML.GetOneOf
+				return fmt.Errorf("method %s: ggconfig:oneof is not supported with --no-deps", method.Name)
+			}
+		}
+		if sep, ok := method.Directive("separator"); ok && (!isListType(method.ReturnType) || sep == "") {
+			return fmt.Errorf("method %s: ggconfig:separator requires a non-empty value and a []string or numeric slice method", method.Name)
+		}
+		if method.ReturnsError {
+			if opts.NoDeps {
+				return fmt.Errorf("method %s returns (%s, error): not supported with --no-deps", method.Name, method.ReturnType)
+			}
+			if method.ParamType != method.ReturnType {
+				return fmt.Errorf("method %s returns (%s, error): the default value must have the same type, got %s", method.Name, method.ReturnType, method.ParamType)
+			}
+		}
+		if _, ok := method.Directive("layout"); ok && method.Kind != KindTime {
+			return fmt.Errorf("method %s is annotated with ggconfig:layout but returns %s (supported: time.Time)", method.Name, method.ReturnType)
+		}
+		if format, ok := method.Directive("format"); ok {
+			if format != runtime.FormatUnix && format != runtime.FormatUnixMs {
+				return fmt.Errorf("method %s: unknown ggconfig:format=%q (supported: %s, %s)", method.Name, format, runtime.FormatUnix, runtime.FormatUnixMs)
+			}
+			if method.ReturnType != "int64" {
+				return fmt.Errorf("method %s is annotated with ggconfig:format but returns %s (supported: int64)", method.Name, method.ReturnType)
+			}
+			if opts.NoDeps {
+				return fmt.Errorf("method %s: ggconfig:format is not supported with --no-deps", method.Name)
+			}
+		}
+		if _, ok := method.Directive("text"); ok && method.Kind != KindText {
+			return fmt.Errorf("method %s is annotated with ggconfig:text but returns %s (supported: named types implementing encoding.TextUnmarshaler)", method.Name, method.ReturnType)
+		}
+		if method.Kind == KindText && opts.NoDeps {
+			return fmt.Errorf("method %s returns %s (encoding.TextUnmarshaler), which is not supported with --no-deps", method.Name, method.ReturnType)
+		}
+		if method.Kind == KindUnmarshaler && opts.NoDeps {
+			return fmt.Errorf("method %s returns %s (yaml.Unmarshaler), which is not supported with --no-deps", method.Name, method.ReturnType)
+		}
+		if (method.Kind == KindIP || method.Kind == KindCIDR) && opts.NoDeps {
+			// Разбор выполняют runtime.ParseIP и runtime.ParseCIDR
+			return fmt.Errorf("method %s returns %s, which is not supported with --no-deps", method.Name, method.ReturnType)
+		}
+		if method.Kind != KindRaw && method.Kind != KindNode {
+			continue
+		}
+		// Raw-значения разбираются через runtime (yaml.v3)
+		if opts.NoDeps {
+			return fmt.Errorf("method %s returns %s, which is not supported with --no-deps", method.Name, method.ReturnType)
+		}
+		if method.Kind == KindRaw && opts.VendorRuntime {
+			return fmt.Errorf("method %s returns %s, which cannot be satisfied by a vendored runtime copy; use yaml.Node instead", method.Name, method.ReturnType)
+		}
+	}
+
+	// Прежние имена (ggconfig:was) не должны совпадать с методами и друг с другом: их ключи читаются как ключи метода
+	wasNames := map[string]string{}
+	for _, method := range info.Methods {
+		wasNames[strings.ToLower(method.Name)] = method.Name
+	}
+	for _, method := range info.Methods {
+		if _, ok := method.Directive("was"); !ok {
+			continue
+		}
+		if len(method.Was()) == 0 {
+			return fmt.Errorf("method %s: ggconfig:was requires the former method name, e.g. ggconfig:was=Host", method.Name)
+		}
+		if opts.NoDeps {
+			// Уведомление о прежнем ключе выдает runtime.ReportDeprecated
+			return fmt.Errorf("method %s: ggconfig:was is not supported with --no-deps", method.Name)
+		}
+		for _, old := range method.Was() {
+			if !token.IsIdentifier(old) {
+				return fmt.Errorf("method %s: ggconfig:was=%s is not a method name", method.Name, old)
+			}
+			if other, ok := wasNames[strings.ToLower(old)]; ok {
+				return fmt.Errorf("method %s: ggconfig:was=%s conflicts with method %s", method.Name, old, other)
+			}
+			wasNames[strings.ToLower(old)] = method.Name
+		}
+	}
+
+	if opts.OptionalSection {
+		for _, method := range info.Methods {
+			// Ключ enabled и метод Enabled() принадлежат переключателю секции
+			if strings.EqualFold(method.Name, "enabled") {
+				return fmt.Errorf("method %s conflicts with the Enabled() helper generated by --optional-section", method.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// path разрешает путь относительно Options.Dir
+func (g *Generator) path(p string) string {
+	if g.opts.Dir == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(g.opts.Dir, p)
+}
+
+// checkOnly сообщает, что файлы не записываются, а сравниваются (--check и --dry-run)
+func (g *Generator) checkOnly() bool {
+	return g.opts.Check || g.opts.DryRun
+}
+
+// log возвращает Options.Log или io.Discard
+func (g *Generator) log() io.Writer {
+	if g.opts.Log == nil {
+		return io.Discard
+	}
+	return g.opts.Log
+}
+
+func (g *Generator) logf(format string, args ...any) {
+	fmt.Fprintf(g.log(), format, args...)
+}
+
+// loadTemplate разбирает шаблон с учетом Options.TemplateDir (см. LoadTemplate)
+func (g *Generator) loadTemplate(name, file string, funcs template.FuncMap, associated ...string) (*template.Template, error) {
+	dir := g.opts.TemplateDir
+	if dir != "" {
+		dir = g.path(dir)
+	}
+	return LoadTemplate(dir, name, file, funcs, associated...)
+}
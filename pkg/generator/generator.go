@@ -0,0 +1,3004 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// Version - версия ggconfig: печатается --version и --print-templates
+const Version = "1.0.4"
+
+type Method struct {
+	Name       string
+	ParamType  string
+	ReturnType string // value type (first return value)
+	Comment    string // Добавляем поле для комментария
+	IsSlice    bool   // Является ли возвращаемый тип массивом
+	ElemType   string // Тип элемента массива (если IsSlice == true)
+	Kind       string // Особый вид значения (KindRaw, KindNode, KindDuration, KindTime, KindURL, KindIP, KindCIDR, KindText, KindStruct, KindUnmarshaler), пусто для обычных типов
+	// Метод объявлен как (T, error): отсутствие значения и ошибка разбора возвращаются как error
+	ReturnsError bool
+	// Метод объявлен как (*T, bool): ParamType и ReturnType - это T, nil означает, что значение не задано
+	Pointer bool
+	// Служебный метод чтения ключей прежнего имени (Name) метода WasOf с ggconfig:was
+	WasOf string
+	// Директивы из комментариев метода: // ggconfig:flag, // ggconfig:flag=new-checkout
+	Directives map[string]string
+}
+
+// DeclaredType возвращает тип значения из объявления интерфейса: *int для методов с указателем
+func (m Method) DeclaredType() string {
+	if m.Pointer {
+		return "*" + m.ReturnType
+	}
+	return m.ReturnType
+}
+
+// isPointerValueType сообщает, является ли тип указателем на скаляр: *string, *bool, *int, ...
+func isPointerValueType(typeName string) bool {
+	elem, ok := strings.CutPrefix(typeName, "*")
+	return ok && (elem == "string" || elem == "bool" || IsIntegerType(elem))
+}
+
+// Directive возвращает значение директивы ggconfig:<name> и признак ее наличия
+func (m Method) Directive(name string) (string, bool) {
+	v, ok := m.Directives[name]
+	return v, ok
+}
+
+// boolLiterals - значения bool, которые принимают ENV и YAML реализации (strconv.ParseBool)
+const boolLiterals = "true or false, also 1/0, t/f, TRUE/FALSE, True/False"
+
+// BoolDefault возвращает значение по умолчанию bool метода для примеров и документации:
+// ggconfig:default=true или false. Сгенерированный код по-прежнему возвращает defaultValue вызова
+func (m Method) BoolDefault() string {
+	if v, ok := m.Directive("default"); ok {
+		return v
+	}
+	return "false"
+}
+
+// Was возвращает прежние имена метода из ggconfig:was=Host,Addr (nil - директивы нет)
+func (m Method) Was() []string {
+	v, ok := m.Directive("was")
+	if !ok || m.WasOf != "" {
+		return nil
+	}
+	var names []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+// readMethods возвращает методы и служебные методы чтения ключей их прежних имен (ggconfig:was):
+// ENV и YAML реализации генерируют чтение для каждого из них
+func readMethods(methods []Method) []Method {
+	var out []Method
+	for _, m := range methods {
+		out = append(out, m)
+		for _, old := range m.Was() {
+			w := m
+			w.Name, w.WasOf = old, m.Name
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// lookupName - имя bool-формы метода в реализациях: lookup<Name> для методов (T, error) и (*T, bool)
+func lookupName(m Method) string {
+	if m.ReturnsError || m.Pointer {
+		return "lookup" + m.Name
+	}
+	return m.Name
+}
+
+// readName - имя функции чтения метода в ENV и YAML реализациях: для методов с ggconfig:was
+// новые ключи читает current<Name>, ключи прежнего имени - was<Name><Old>
+func readName(m Method) string {
+	if m.WasOf != "" {
+		return "was" + m.WasOf + m.Name
+	}
+	if len(m.Was()) > 0 {
+		return "current" + m.Name
+	}
+	return lookupName(m)
+}
+
+// reportsErrors сообщает, передают ли ENV и YAML реализации метода некорректные значения
+// дальше (--strict и методы (T, error)), а не пропускают их
+func reportsErrors(m Method, opts GenerateOptions) bool {
+	return (opts.Strict || m.ReturnsError) && !opts.NoDeps
+}
+
+// bodyName - имя функции чтения метода в ENV и YAML реализациях с учетом --strict: функции
+// методов, которые сообщают о некорректных значениях, принимают report и называются read<Name>
+// (readCurrent<Name> и readWas<Name><Old> для ggconfig:was)
+func bodyName(m Method, opts GenerateOptions) string {
+	if !reportsErrors(m, opts) {
+		return readName(m)
+	}
+	if m.WasOf != "" {
+		return "readWas" + m.WasOf + m.Name
+	}
+	if len(m.Was()) > 0 {
+		return "readCurrent" + m.Name
+	}
+	return "read" + m.Name
+}
+
+// OneOf возвращает допустимые значения из ggconfig:oneof=debug,info,warn,error (nil - директивы нет)
+func (m Method) OneOf() []string {
+	v, ok := m.Directive("oneof")
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// Особые виды возвращаемых значений
+const (
+	KindRaw  = "raw"  // runtime.Raw - необработанное поддерево
+	KindNode = "node" // yaml.Node - необработанный YAML-узел
+	// time.Duration: ENV через time.ParseDuration, в YAML - строка длительности или целые секунды
+	KindDuration = "duration"
+	// time.Time: строка в формате ggconfig:layout (по умолчанию RFC3339)
+	KindTime = "time"
+	// *url.URL: строка, разобранная url.Parse
+	KindURL = "url"
+	// net.IP: адрес, разобранный net.ParseIP
+	KindIP = "ip"
+	// *net.IPNet: сеть, разобранная net.ParseCIDR
+	KindCIDR = "cidr"
+	// Тип с методом UnmarshalText (encoding.TextUnmarshaler): строка передается в UnmarshalText
+	KindText = "text"
+	// Структура: в ENV JSON объект, в YAML поддерево декодируется в тип метода (теги yaml)
+	KindStruct = "struct"
+	// Тип с методом UnmarshalYAML (yaml.Unmarshaler): YAML значение передается в UnmarshalYAML,
+	// ENV строка - в UnmarshalText, если он есть, иначе разбирается как YAML
+	KindUnmarshaler = "unmarshaler"
+)
+
+type InterfaceInfo struct {
+	PackageName       string // Оригинальное имя пакета (для обратной совместимости)
+	UniquePackageName string // Уникальное имя на основе пути
+	SourcePackage     string // Имя из package clause исходников: может не совпадать с директорией (cmd/api - main)
+	InterfaceName     string
+	Methods           []Method
+	ImportPath        string   // Путь для импорта пакета (если генерация в другой пакет)
+	NeedImport        bool     // Нужен ли импорт оригинального пакета
+	TypeImports       []string // Импорты пакетов квалифицированных типов (yaml.Node, time.Duration, ...)
+	EnvPrefix         string   // Префикс ENV ключей вместо имени пакета (--env-prefix)
+	TypePrefix        string   // Префикс имен типов и конструкторов (--type-prefix)
+	TypeSuffix        string   // Суффикс имен типов и конструкторов (--type-suffix)
+}
+
+// TypeName возвращает основу имен сгенерированных типов (<TypeName>EnvConfig, ...), конструкторов
+// и файла: уникальное имя пакета с --type-prefix и --type-suffix. Секции YAML и ключи ENV от нее
+// не зависят
+func (info *InterfaceInfo) TypeName() string {
+	return info.TypePrefix + info.UniquePackageName + info.TypeSuffix
+}
+
+// EnvKeyPrefix возвращает префикс ENV ключей: --env-prefix или имя пакета
+func (info *InterfaceInfo) EnvKeyPrefix() string {
+	if info.EnvPrefix != "" {
+		return info.EnvPrefix
+	}
+	return info.PackageName
+}
+
+// sourcePackageName возвращает имя исходного пакета в Go коде: им квалифицируются его типы и
+// называется пакет при генерации рядом с интерфейсом. Ключи ENV и YAML по-прежнему строятся
+// из PackageName (имени директории)
+func (info *InterfaceInfo) sourcePackageName() string {
+	if info.SourcePackage != "" {
+		return info.SourcePackage
+	}
+	return info.PackageName
+}
+
+// Настройки алиасов, передаваемые через --alias
+type AliasSettings struct {
+	// ENV: MethodName -> []EnvVarAlias (полные имена переменных окружения)
+	Env map[string][]string
+	// YAML: алиасы имени секции (например, server_name, svc)
+	YAMLSection []string
+	// YAML: MethodName -> []KeyAlias внутри секции
+	YAMLKey map[string][]string
+}
+
+// Параметры генерации реализаций
+type GenerateOptions struct {
+	OutputPath    string      // Путь для генерации (пусто - текущий пакет)
+	Registry      bool        // Генерировать registry.gen.go и init() саморегистрацию
+	NoDeps        bool        // Без внешних зависимостей: без YAML и runtime
+	VendorRuntime bool        // Копировать runtime в выходной пакет вместо импорта
+	Strict        bool        // Сообщать о некорректных значениях вместо тихого возврата default
+	FileMode      os.FileMode // Права создаваемых файлов (0 - права по умолчанию)
+	// Записать <package>.descriptor.json и встроить его в сгенерированный код (go:embed)
+	Descriptor bool
+	// Отклонять YAML с якорями, алиасами и ключами слияния (runtime.ParseYAMLNoAnchors)
+	NoYAMLAnchors bool
+	// Секцию можно выключить ключом enabled: false (генерируется Enabled())
+	OptionalSection bool
+	// Записать <package>_example_test.go с Example функциями конструкторов
+	DocExamples bool
+	// Имя сгенерированного файла (пусто - <package>.gen.go по уникальному имени)
+	OutFile string
+	// Имя выходного пакета (пусто - имя выходной директории)
+	OutPackage string
+	// Генерируемые источники в порядке sourceProviders (selectSources)
+	Sources []string
+}
+
+// parseYAMLIdent - функция разбора YAML файлов в сгенерированном коде
+func parseYAMLIdent(opts GenerateOptions) string {
+	if opts.NoYAMLAnchors {
+		return runtimeIdent("ParseYAMLNoAnchors", opts.VendorRuntime)
+	}
+	return runtimeIdent("ParseYAML", opts.VendorRuntime)
+}
+
+// Поддержка повторяющегося флага --alias
+type AliasFlags []string
+
+func (a *AliasFlags) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *AliasFlags) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// ParseInterface разбирает интерфейс interfaceName в пакете из директории packagePath.
+// Если задан sourceFile (относительно packagePath), интерфейс ищется только в этом файле:
+// остальные файлы пакета могут временно не разбираться (середина рефакторинга)
+func ParseInterface(packagePath, sourceFile, packageName, uniquePackageName, interfaceName string) (*InterfaceInfo, error) {
+	fset := token.NewFileSet()
+	var files, siblings []*ast.File
+	if sourceFile != "" {
+		path := sourceFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(packagePath, path)
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse source file %s: %w", sourceFile, err)
+		}
+		files = []*ast.File{file}
+		// Остальные файлы нужны только для поиска UnmarshalText: неразбираемые пропускаются
+		siblings = parseSiblingFiles(packagePath, path)
+	} else {
+		pkgs, err := parser.ParseDir(fset, packagePath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse package %s: %w", packagePath, err)
+		}
+		// Файлы - в порядке имен: обход map давал бы разный результат от запуска к запуску
+		files = sortedFiles(pkgs)
+	}
+
+	// Обобщенный интерфейс задается с аргументами типа: Config[int64]
+	interfaceName, typeArgs, err := parseInterfaceInstance(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []Method
+	typeImports := map[string]bool{}
+	var instanceErr error
+	var sourcePackage string
+	types := &sourceTypes{local: append(append([]*ast.File{}, files...), siblings...), dir: packagePath, pkgs: map[string][]*ast.File{}}
+
+	// Ищем интерфейс во всех файлах пакета
+	for _, file := range files {
+		imports := fileImports(file)
+		ast.Inspect(file, func(n ast.Node) bool {
+			if typeDecl, ok := n.(*ast.TypeSpec); ok {
+				if typeDecl.Name.Name == interfaceName {
+					if interfaceType, ok := typeDecl.Type.(*ast.InterfaceType); ok {
+						sourcePackage = file.Name.Name
+						subst, err := typeParamSubstitutions(typeDecl, typeArgs)
+						if err != nil {
+							instanceErr = err
+							return false
+						}
+						for _, method := range interfaceType.Methods.List {
+							if funcType, ok := method.Type.(*ast.FuncType); ok {
+								methodName := method.Names[0].Name
+								substituteTypeParams(funcType, subst)
+
+								// Извлекаем комментарий и директивы ggconfig: из документации
+								comment, directives := parseMethodDoc(method.Doc)
+
+								// Типы с UnmarshalText (или с директивой ggconfig:text) разбираются из строки
+								_, forcedText := directives["text"]
+								isText := func(typeName string) bool {
+									return forcedText || types.implements(typeName, imports, "UnmarshalText")
+								}
+								// Типы с UnmarshalYAML разбирают себя сами (ggconfig:text важнее)
+								isUnmarshaler := func(typeName string) bool {
+									return !forcedText && types.implements(typeName, imports, "UnmarshalYAML")
+								}
+								isStruct := func(typeName string) bool { return types.isStruct(typeName, imports) }
+								paramType, returnType, err := getMethodSignature(funcType, imports, func(typeName string) bool {
+									return isText(typeName) || isUnmarshaler(typeName) || isStruct(typeName)
+								})
+								if err != nil {
+									// Fail fast: new ggconfig requires (T, bool) or (T, error) return signature
+									instanceErr = fmt.Errorf("bad method signature %s.%s: %w", interfaceName, methodName, err)
+									return false
+								}
+								// *T: значение разбирается как T, публичный метод возвращает указатель
+								pointer := isPointerValueType(returnType)
+								if pointer {
+									if paramType != returnType || getReturnTypes(funcType)[1].TypeName == "error" {
+										instanceErr = fmt.Errorf("bad method signature %s.%s: pointer methods must be declared as (defaultValue %s) (%s, bool)", interfaceName, methodName, returnType, returnType)
+										return false
+									}
+									paramType, returnType = returnType[1:], returnType[1:]
+								}
+								kind := valueKind(returnType, imports)
+								if kind == "" && !isBuiltinType(returnType) && !strings.HasPrefix(returnType, "[]") && isUnmarshaler(returnType) {
+									kind = KindUnmarshaler
+								}
+								if kind == "" && !isBuiltinType(returnType) && !strings.HasPrefix(returnType, "[]") && isText(returnType) {
+									kind = KindText
+								}
+								if kind == "" && isStruct(returnType) {
+									kind = KindStruct
+								}
+
+								// Определяем, является ли тип массивом
+								isSlice := strings.HasPrefix(returnType, "[]")
+								elemType := ""
+								if isSlice {
+									elemType = strings.TrimPrefix(returnType, "[]")
+								}
+
+								// Квалифицированные типы (pkg.Type) требуют импорта их пакета
+								for _, q := range typeQualifiers(paramType, returnType) {
+									path, ok := imports[q]
+									if !ok {
+										instanceErr = fmt.Errorf("bad method signature %s.%s: unknown package %q", interfaceName, methodName, q)
+										return false
+									}
+									typeImports[path] = true
+								}
+
+								methods = append(methods, Method{
+									Name:       methodName,
+									ParamType:  paramType,
+									ReturnType: returnType,
+									Comment:    comment,
+									IsSlice:    isSlice,
+									ElemType:   elemType,
+									Kind:       kind,
+									Directives: directives,
+									// getMethodSignature уже проверил второе значение: bool или error
+									ReturnsError: getReturnTypes(funcType)[1].TypeName == "error",
+									Pointer:      pointer,
+								})
+							}
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	if instanceErr != nil {
+		return nil, instanceErr
+	}
+	if len(methods) == 0 {
+		if sourceFile != "" {
+			return nil, fmt.Errorf("interface %s not found in %s", interfaceName, sourceFile)
+		}
+		return nil, fmt.Errorf("interface %s not found in package %s", interfaceName, packageName)
+	}
+
+	importList := sortedKeys(typeImports)
+
+	return &InterfaceInfo{
+		PackageName:       packageName,
+		UniquePackageName: uniquePackageName,
+		SourcePackage:     sourcePackage,
+		InterfaceName:     interfaceName,
+		Methods:           methods,
+		TypeImports:       importList,
+	}, nil
+}
+
+// parseInterfaceInstance делит "Config[int64, string]" на имя интерфейса и аргументы типа
+func parseInterfaceInstance(name string) (string, []ast.Expr, error) {
+	base, rest, ok := strings.Cut(name, "[")
+	if !ok {
+		return name, nil, nil
+	}
+	expr, err := parser.ParseExpr("_[" + rest)
+	if err != nil || !strings.HasSuffix(rest, "]") {
+		return "", nil, fmt.Errorf("invalid interface instantiation %q (want Name[Type, ...])", name)
+	}
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return base, []ast.Expr{e.Index}, nil
+	case *ast.IndexListExpr:
+		return base, e.Indices, nil
+	}
+	return "", nil, fmt.Errorf("invalid interface instantiation %q (want Name[Type, ...])", name)
+}
+
+// typeParamSubstitutions сопоставляет параметры типа интерфейса с аргументами из --interface
+func typeParamSubstitutions(typeDecl *ast.TypeSpec, typeArgs []ast.Expr) (map[string]ast.Expr, error) {
+	var params []string
+	var constraints []string
+	if typeDecl.TypeParams != nil {
+		for _, field := range typeDecl.TypeParams.List {
+			for _, name := range field.Names {
+				params = append(params, name.Name)
+				constraints = append(constraints, name.Name+" "+exprString(field.Type))
+			}
+		}
+	}
+	if len(params) == 0 {
+		if len(typeArgs) > 0 {
+			return nil, fmt.Errorf("interface %s is not generic, remove the type arguments", typeDecl.Name.Name)
+		}
+		return nil, nil
+	}
+	if len(typeArgs) == 0 {
+		return nil, fmt.Errorf("interface %s is generic [%s]: instantiate it, e.g. --interface=%s[int]",
+			typeDecl.Name.Name, strings.Join(constraints, ", "), typeDecl.Name.Name)
+	}
+	if len(typeArgs) != len(params) {
+		return nil, fmt.Errorf("interface %s has %d type parameters [%s], got %d type arguments",
+			typeDecl.Name.Name, len(params), strings.Join(constraints, ", "), len(typeArgs))
+	}
+	subst := map[string]ast.Expr{}
+	for i, name := range params {
+		subst[name] = typeArgs[i]
+	}
+	return subst, nil
+}
+
+// substituteTypeParams заменяет параметры типа в сигнатуре метода на аргументы инстанцирования
+func substituteTypeParams(funcType *ast.FuncType, subst map[string]ast.Expr) {
+	if len(subst) == 0 {
+		return
+	}
+	var replace func(expr ast.Expr) ast.Expr
+	replace = func(expr ast.Expr) ast.Expr {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			if arg, ok := subst[t.Name]; ok {
+				return arg
+			}
+		case *ast.ArrayType:
+			t.Elt = replace(t.Elt)
+		case *ast.StarExpr:
+			t.X = replace(t.X)
+		case *ast.IndexExpr:
+			t.Index = replace(t.Index)
+		case *ast.IndexListExpr:
+			for i := range t.Indices {
+				t.Indices[i] = replace(t.Indices[i])
+			}
+		}
+		return expr
+	}
+	for _, list := range []*ast.FieldList{funcType.Params, funcType.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			field.Type = replace(field.Type)
+		}
+	}
+}
+
+// exprString печатает выражение типа для сообщений об ошибках
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		return "?"
+	}
+	return buf.String()
+}
+
+// directivePrefix - префикс строк комментария с директивами генератора
+const directivePrefix = "ggconfig:"
+
+// defaultDocPrefix - строка документации "// default: 8080", то же, что ggconfig:default=8080
+const defaultDocPrefix = "default:"
+
+// parseMethodDoc возвращает первую строку документации метода (не директиву)
+// и директивы вида "// ggconfig:name" или "// ggconfig:name=value". В одной строке
+// может быть несколько директив через пробел. Строка "// default: 8080" задает
+// ggconfig:default, если директивы нет.
+func parseMethodDoc(doc *ast.CommentGroup) (string, map[string]string) {
+	comment := ""
+	directives := map[string]string{}
+	if doc == nil {
+		return comment, directives
+	}
+	docDefault, hasDocDefault := "", false
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if len(text) >= len(defaultDocPrefix) && strings.EqualFold(text[:len(defaultDocPrefix)], defaultDocPrefix) {
+			docDefault = strings.TrimSpace(text[len(defaultDocPrefix):])
+			if unquoted, err := strconv.Unquote(docDefault); err == nil {
+				docDefault = unquoted
+			}
+			hasDocDefault = true
+			continue
+		}
+		if rest, ok := strings.CutPrefix(text, directivePrefix); ok {
+			for _, field := range DirectiveFields(rest) {
+				name, value, _ := strings.Cut(field, "=")
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					value = unquoted
+				}
+				directives[name] = value
+			}
+			continue
+		}
+		if comment == "" {
+			comment = text
+		}
+	}
+	if _, ok := directives["default"]; !ok && hasDocDefault {
+		directives["default"] = docDefault
+	}
+	return comment, directives
+}
+
+// DirectiveFields делит строку директив по пробелам, не разрывая значения в кавычках:
+// secret="op://Prod/My Item/password" остается одним полем
+func DirectiveFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// fileImports возвращает импорты файла: имя пакета в файле -> путь импорта
+func fileImports(file *ast.File) map[string]string {
+	out := map[string]string{}
+	for _, imp := range file.Imports {
+		path := strings.Trim(imp.Path.Value, "`\"")
+		name := importName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		out[name] = path
+	}
+	return out
+}
+
+// importName угадывает имя пакета по пути импорта: gopkg.in/yaml.v3 -> yaml, example.com/pkg/v2 -> pkg
+func importName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// typeQualifiers возвращает имена пакетов, которыми квалифицированы типы (yaml из []yaml.Node)
+func typeQualifiers(types ...string) []string {
+	var out []string
+	for _, t := range types {
+		t = strings.TrimLeft(t, "[]*")
+		if i := strings.Index(t, "."); i > 0 {
+			out = append(out, t[:i])
+		}
+	}
+	return out
+}
+
+// valueKind определяет особый вид возвращаемого значения по типу и импортам файла
+func valueKind(returnType string, imports map[string]string) string {
+	pkg, name, ok := strings.Cut(returnType, ".")
+	if !ok {
+		return ""
+	}
+	switch {
+	case imports[pkg] == RuntimeImportPath && name == "Raw":
+		return KindRaw
+	case imports[pkg] == "gopkg.in/yaml.v3" && name == "Node":
+		return KindNode
+	case imports[pkg] == "time" && name == "Duration":
+		return KindDuration
+	case imports[pkg] == "time" && name == "Time":
+		return KindTime
+	case strings.HasPrefix(pkg, "*") && imports[pkg[1:]] == "net/url" && name == "URL":
+		return KindURL
+	case imports[pkg] == "net" && name == "IP":
+		return KindIP
+	case strings.HasPrefix(pkg, "*") && imports[pkg[1:]] == "net" && name == "IPNet":
+		return KindCIDR
+	}
+	return ""
+}
+
+// sourceTypes находит типы с методами UnmarshalText и UnmarshalYAML и структуры: локальные - в файлах пакета
+// интерфейса, квалифицированные (pkg.Type) - в исходниках пакета импорта (каталог из go list).
+// Методы, полученные встраиванием, не находятся: для таких типов есть директива ggconfig:text
+type sourceTypes struct {
+	local []*ast.File
+	dir   string
+	pkgs  map[string][]*ast.File // import path -> файлы пакета (nil - пакет не удалось загрузить)
+}
+
+// implements сообщает, объявлен ли у typeName метод method (UnmarshalText, UnmarshalYAML)
+func (t *sourceTypes) implements(typeName string, imports map[string]string, method string) bool {
+	files, name, ok := t.files(typeName, imports)
+	return ok && hasMethod(files, name, method)
+}
+
+// isStruct сообщает, объявлен ли typeName как структура (type Limits struct{...})
+func (t *sourceTypes) isStruct(typeName string, imports map[string]string) bool {
+	files, name, ok := t.files(typeName, imports)
+	return ok && hasStructType(files, name)
+}
+
+// files возвращает файлы пакета, в котором объявлен именованный тип typeName, и имя типа без квалификатора
+func (t *sourceTypes) files(typeName string, imports map[string]string) ([]*ast.File, string, bool) {
+	if isBuiltinType(typeName) || strings.ContainsAny(typeName, "[]*") {
+		return nil, "", false
+	}
+	q, name, ok := strings.Cut(typeName, ".")
+	if !ok {
+		return t.local, typeName, true
+	}
+	path, ok := imports[q]
+	if !ok {
+		return nil, "", false
+	}
+	files, loaded := t.pkgs[path]
+	if !loaded {
+		files = loadPackageFiles(t.dir, path)
+		t.pkgs[path] = files
+	}
+	return files, name, true
+}
+
+// parseSiblingFiles разбирает по одному остальные .go файлы директории dir (кроме skip и _test.go),
+// пропуская файлы с ошибками разбора
+func parseSiblingFiles(dir, skip string) []*ast.File {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Clean(path) == filepath.Clean(skip) {
+			continue
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution); err == nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// packageDir находит директорию пакета importPath через go list из директории dir: с учетом
+// go.mod этой директории, replace и go.work
+func packageDir(dir, importPath string) (string, error) {
+	cmd := exec.Command("go", "list", "-find", "-f", "{{.Dir}}", importPath)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("go list: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("go list: %w", err)
+	}
+	pkgDir := strings.TrimSpace(string(out))
+	if pkgDir == "" {
+		return "", fmt.Errorf("package %s has no source directory", importPath)
+	}
+	return pkgDir, nil
+}
+
+// loadPackageFiles разбирает исходники пакета importPath, найденного go list из директории dir
+func loadPackageFiles(dir, importPath string) []*ast.File {
+	pkgDir, err := packageDir(dir, importPath)
+	if err != nil {
+		return nil
+	}
+	pkgs, err := parser.ParseDir(token.NewFileSet(), pkgDir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	return sortedFiles(pkgs)
+}
+
+// sortedFiles возвращает файлы пакетов pkgs (результата parser.ParseDir) в порядке имен файлов
+func sortedFiles(pkgs map[string]*ast.Package) []*ast.File {
+	byName := map[string]*ast.File{}
+	for _, pkg := range pkgs {
+		for name, f := range pkg.Files {
+			byName[name] = f
+		}
+	}
+	files := make([]*ast.File, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		files = append(files, byName[name])
+	}
+	return files
+}
+
+// sortedKeys возвращает ключи m по возрастанию. Порядок обхода map в Go случаен, а вывод
+// генератора не должен зависеть от запуска: все map, влияющие на вывод, обходятся через sortedKeys
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendUnique добавляет к list значения, которых в нем еще нет, сохраняя порядок: один и тот же
+// алиас из директивы, манифеста и --alias читается один раз, на месте первого упоминания
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// hasMethod сообщает, объявлен ли в files метод method типа typeName (с получателем T или *T)
+func hasMethod(files []*ast.File, typeName, method string) bool {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 || fd.Name.Name != method {
+				continue
+			}
+			recv := fd.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if id, ok := recv.(*ast.Ident); ok && id.Name == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasStructType сообщает, объявлен ли в files тип typeName со структурой в основе
+func hasStructType(files []*ast.File, typeName string) bool {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == typeName && ts.TypeParams == nil {
+					_, ok := ts.Type.(*ast.StructType)
+					return ok
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isLocalType сообщает, является ли тип пользовательским типом исходного пакета (без квалификатора)
+func isLocalType(typeName string) bool {
+	t := strings.TrimLeft(typeName, "[]*")
+	return t != "" && !isBuiltinType(t) && !strings.Contains(t, ".")
+}
+
+// FindModuleRoot находит корень модуля Go, ища go.mod файл
+func FindModuleRoot(startDir string) (string, error) {
+	dir := startDir
+	for {
+		goModPath := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(goModPath); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Дошли до корня файловой системы
+			return "", fmt.Errorf("go.mod not found from %s", startDir)
+		}
+		dir = parent
+	}
+}
+
+// ModuleName читает имя модуля из go.mod
+func ModuleName(moduleRoot string) (string, error) {
+	goModPath := filepath.Join(moduleRoot, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
+	}
+	return "", fmt.Errorf("module name not found in %s", goModPath)
+}
+
+// goPackage - пакет директории, как его видит go list
+type goPackage struct {
+	Dir        string // Директория пакета
+	ImportPath string // Путь импорта
+	UniqueName string // Уникальное имя по пути от корня модуля (server, internal_server)
+	ModuleDir  string // Корень модуля пакета
+}
+
+// resolvePackage находит пакет директории dir через go list: так учитываются вложенные модули,
+// go.work и пакеты вне стандартной раскладки (cmd/*, корень модуля). Зависимости не
+// разрешаются (-find), поэтому еще не сгенерированный выходной пакет не мешает. Без go в PATH
+// и вне модуля путь вычисляется по ближайшему go.mod
+func resolvePackage(dir string) (*goPackage, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("go", "list", "-e", "-find", "-json", ".")
+	cmd.Dir = abs
+	if out, err := cmd.Output(); err == nil {
+		var pkg struct {
+			Dir        string
+			ImportPath string
+			Module     *struct{ Dir string }
+		}
+		if err := json.Unmarshal(out, &pkg); err == nil && pkg.Module != nil && pkg.Module.Dir != "" && pkg.ImportPath != "" {
+			if pkg.Dir == "" {
+				pkg.Dir = abs
+			}
+			if relPath, err := filepath.Rel(pkg.Module.Dir, pkg.Dir); err == nil {
+				return &goPackage{Dir: pkg.Dir, ImportPath: pkg.ImportPath, UniqueName: pathToUniqueName(relPath), ModuleDir: pkg.Module.Dir}, nil
+			}
+		}
+	}
+
+	moduleRoot, err := FindModuleRoot(abs)
+	if err != nil {
+		return nil, err
+	}
+	moduleName, err := ModuleName(moduleRoot)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(moduleRoot, abs)
+	if err != nil {
+		return nil, err
+	}
+	importPath := moduleName
+	if relPath != "." {
+		// Путь импорта всегда через /, в том числе на Windows
+		importPath = moduleName + "/" + filepath.ToSlash(relPath)
+	}
+	return &goPackage{Dir: abs, ImportPath: importPath, UniqueName: pathToUniqueName(relPath), ModuleDir: moduleRoot}, nil
+}
+
+// ImportPaths вычисляет import path директорий по ближайшему go.mod (в монорепозитории модулей может быть несколько)
+type ImportPaths struct {
+	modules map[string]string // корень модуля -> имя модуля
+}
+
+// NewImportPaths возвращает ImportPaths с пустым кешем модулей
+func NewImportPaths() *ImportPaths {
+	return &ImportPaths{modules: map[string]string{}}
+}
+
+func (p *ImportPaths) Of(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	moduleRoot, err := FindModuleRoot(abs)
+	if err != nil {
+		return "", err
+	}
+	name, ok := p.modules[moduleRoot]
+	if !ok {
+		if name, err = ModuleName(moduleRoot); err != nil {
+			return "", err
+		}
+		p.modules[moduleRoot] = name
+	}
+	rel, err := filepath.Rel(moduleRoot, abs)
+	if err != nil || rel == "." {
+		return name, err
+	}
+	return name + "/" + filepath.ToSlash(rel), nil
+}
+
+// PackageUniqueName возвращает уникальное имя пакета pkgDir для директивы из directiveDir:
+// путь от корня модуля, а для пакета другого модуля (--package) - полный путь импорта, чтобы
+// одноименные пакеты разных модулей не конфликтовали в общем выходном пакете
+func PackageUniqueName(directiveDir, pkgDir string) (string, error) {
+	pkg, err := resolvePackage(pkgDir)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(directiveDir) == filepath.Clean(pkgDir) {
+		return pkg.UniqueName, nil
+	}
+	directive, err := resolvePackage(directiveDir)
+	if err != nil {
+		return "", err
+	}
+	if directive.ModuleDir != pkg.ModuleDir {
+		return pathToUniqueName(pkg.ImportPath), nil
+	}
+	return pkg.UniqueName, nil
+}
+
+// pathToUniqueName преобразует путь в уникальное имя, заменяя / на _
+func pathToUniqueName(path string) string {
+	// Нормализуем путь (убираем ./ в начале)
+	path = strings.TrimPrefix(path, "./")
+	path = strings.TrimPrefix(path, ".")
+
+	// Заменяем все разделители на _
+	path = strings.ReplaceAll(path, string(filepath.Separator), "_")
+	path = strings.ReplaceAll(path, "/", "_")
+	// Имя входит в идентификаторы: директории вида go-store или api.v2 дают go_store и api_v2
+	path = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, path)
+
+	// Убираем повторяющиеся подчеркивания
+	for strings.Contains(path, "__") {
+		path = strings.ReplaceAll(path, "__", "_")
+	}
+
+	// Убираем подчеркивания в начале и конце
+	path = strings.Trim(path, "_")
+
+	// Если путь пустой (корень модуля), используем "root"
+	if path == "" {
+		path = "root"
+	}
+
+	return path
+}
+
+// IntTypeInfo описывает целочисленный тип: знаковый ли он и разрядность для strconv (0 - размер int)
+type IntTypeInfo struct {
+	Signed  bool
+	BitSize int
+}
+
+// Поддерживаемые целочисленные типы
+var IntegerTypes = map[string]IntTypeInfo{
+	"int":    {Signed: true, BitSize: 0},
+	"int8":   {Signed: true, BitSize: 8},
+	"int16":  {Signed: true, BitSize: 16},
+	"int32":  {Signed: true, BitSize: 32},
+	"int64":  {Signed: true, BitSize: 64},
+	"uint":   {Signed: false, BitSize: 0},
+	"uint8":  {Signed: false, BitSize: 8},
+	"uint16": {Signed: false, BitSize: 16},
+	"uint32": {Signed: false, BitSize: 32},
+	"uint64": {Signed: false, BitSize: 64},
+}
+
+func IsIntegerType(typeName string) bool {
+	_, ok := IntegerTypes[typeName]
+	return ok
+}
+
+// isBuiltinType сообщает, является ли тип встроенным (не требует квалификации пакетом)
+func isBuiltinType(typeName string) bool {
+	switch typeName {
+	case "string", "bool", "float32", "float64", "byte", "rune", "any":
+		return true
+	}
+	return IsIntegerType(typeName)
+}
+
+type ReturnTypeInfo struct {
+	TypeName string
+	IsSlice  bool
+	ElemType string
+}
+
+func getTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		// For qualified types like pkg.Type
+		return fmt.Sprintf("%s.%s", getTypeName(t.X), t.Sel.Name)
+	case *ast.ArrayType:
+		return "[]" + getTypeName(t.Elt)
+	case *ast.StarExpr:
+		return "*" + getTypeName(t.X)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// Инстанцированный обобщенный тип (Optional[int]) - только для сообщения об ошибке
+		return exprString(t)
+	default:
+		return ""
+	}
+}
+
+func getReturnTypes(funcType *ast.FuncType) []ReturnTypeInfo {
+	if funcType.Results == nil || len(funcType.Results.List) == 0 {
+		return nil
+	}
+	var out []ReturnTypeInfo
+	for _, r := range funcType.Results.List {
+		typeName := getTypeName(r.Type)
+		isSlice := strings.HasPrefix(typeName, "[]")
+		elemType := ""
+		if isSlice {
+			elemType = strings.TrimPrefix(typeName, "[]")
+		}
+		out = append(out, ReturnTypeInfo{
+			TypeName: typeName,
+			IsSlice:  isSlice,
+			ElemType: elemType,
+		})
+	}
+	return out
+}
+
+// envParse описывает разбор строкового значения ENV (переменная value) в тип метода:
+// v - имя переменной результата разбора, parse - выражение (результат, error), result - возвращаемое выражение.
+// Пустой parse означает, что value возвращается как есть.
+type envParse struct {
+	v, parse, result string
+}
+
+func getEnvParse(m Method, vendored bool) envParse {
+	if m.Kind == KindRaw || m.Kind == KindNode {
+		return envParse{v: "raw", parse: runtimeIdent("ParseRaw", vendored) + "([]byte(value))", result: rawResultExpr(m)}
+	}
+	if m.Kind == KindDuration {
+		return envParse{v: "durationValue", parse: "time.ParseDuration(value)", result: "durationValue"}
+	}
+	if m.Kind == KindTime {
+		return envParse{v: "timeValue", parse: fmt.Sprintf("time.Parse(%s, value)", timeLayoutExpr(m)), result: "timeValue"}
+	}
+	if m.Kind == KindURL {
+		return envParse{v: "urlValue", parse: "url.Parse(value)", result: "urlValue"}
+	}
+	if m.Kind == KindIP {
+		return envParse{v: "ipValue", parse: runtimeIdent("ParseIP", vendored) + "(value)", result: "ipValue"}
+	}
+	if m.Kind == KindCIDR {
+		return envParse{v: "netValue", parse: runtimeIdent("ParseCIDR", vendored) + "(value)", result: "netValue"}
+	}
+	if m.Kind == KindText {
+		// Тип значения выводится из defaultValue
+		return envParse{v: "textValue", parse: runtimeIdent("ParseText", vendored) + "(defaultValue, value)", result: "textValue"}
+	}
+	if m.Kind == KindUnmarshaler {
+		return envParse{v: "unmarshaledValue", parse: runtimeIdent("ParseUnmarshaler", vendored) + "(defaultValue, value)", result: "unmarshaledValue"}
+	}
+	if values := m.OneOf(); len(values) > 0 {
+		return envParse{v: "enumValue", parse: fmt.Sprintf("%s(value, %s)", runtimeIdent("ParseOneOf", vendored), quoteList(values)), result: "enumValue"}
+	}
+	if format, ok := m.Directive("format"); ok {
+		return envParse{v: "intValue", parse: fmt.Sprintf("%s(value, %q)", runtimeIdent("ParseUnixTime", vendored), format), result: "intValue"}
+	}
+	if m.ReturnType == "int" {
+		return envParse{v: "intValue", parse: "strconv.Atoi(value)", result: "intValue"}
+	}
+	if info, ok := IntegerTypes[m.ReturnType]; ok {
+		return envParse{v: "intValue", parse: parseIntExpr(info), result: m.ReturnType + "(intValue)"}
+	}
+	if m.ReturnType == "bool" {
+		return envParse{v: "boolValue", parse: "strconv.ParseBool(value)", result: "boolValue"}
+	}
+	return envParse{}
+}
+
+// getEnvValue generates snippet to read env by expression (envKeyExpr) without quoting.
+// envKeyExpr must be a valid Go expression producing a string.
+func getEnvValue(envKeyExpr, defaultValue string, m Method, opts GenerateOptions) string {
+	p := getEnvParse(m, opts.VendorRuntime)
+	if p.parse == "" {
+		return fmt.Sprintf(`if %s {
+		%s
+	}
+	return %s, false`, getEnvLookup(envKeyExpr, m, opts), getUnsetReturn(m, "value", "value", "\t\t"), defaultValue)
+	}
+	return fmt.Sprintf(`if %s {
+		if %s, err := %s; err == nil {
+			%s
+		}%s
+	}
+	return %s, false`, getEnvLookup(envKeyExpr, m, opts), p.v, p.parse, getUnsetReturn(m, p.v, p.result, "\t\t\t"), getEnvInvalid(envKeyExpr, m, opts, "\t\t"), defaultValue)
+}
+
+// Генерирует фрагмент кода проверки ENV по конкретному ключу без возврата default
+func getEnvCheckSnippet(envKeyExpr string, m Method, opts GenerateOptions) string {
+	p := getEnvParse(m, opts.VendorRuntime)
+	if p.parse == "" {
+		return fmt.Sprintf(`if %s {
+    %s
+}`, getEnvLookup(envKeyExpr, m, opts), getUnsetReturn(m, "value", "value", "    "))
+	}
+	return fmt.Sprintf(`if %s {
+    if %s, err := %s; err == nil {
+        %s
+    }%s
+}`, getEnvLookup(envKeyExpr, m, opts), p.v, p.parse, getUnsetReturn(m, p.v, p.result, "        "), getEnvInvalid(envKeyExpr, m, opts, "    "))
+}
+
+// getEnvLookup - условие чтения переменной: по умолчанию пустое значение считается отсутствием,
+// с ggconfig:allow-empty заданная пустая переменная - значение
+func getEnvLookup(envKeyExpr string, m Method, opts GenerateOptions) string {
+	if _, ok := m.Directive("allow-empty"); ok {
+		return fmt.Sprintf("value, ok := c.lookupEnv(%s%s); ok", envKeyExpr, envReportArg(m, opts))
+	}
+	return fmt.Sprintf(`value := c.getenv(%s%s); value != ""`, envKeyExpr, envReportArg(m, opts))
+}
+
+// envReportArg - аргумент report для lookupEnv и getenv: метод, сообщающий об ошибках, передает
+// свой report, остальные - nil (ошибка чтения <KEY>_FILE уходит наблюдателю); с --no-deps
+// параметра нет
+func envReportArg(m Method, opts GenerateOptions) string {
+	switch {
+	case opts.NoDeps:
+		return ""
+	case reportsErrors(m, opts):
+		return ", report"
+	}
+	return ", nil"
+}
+
+// getEnvFileInvalid генерирует обработку ошибки чтения файла <KEY>_FILE в lookupEnv так же, как
+// некорректного значения: report или наблюдатель, с --no-deps и --strict - паника
+func getEnvFileInvalid(opts GenerateOptions) string {
+	if opts.NoDeps {
+		if opts.Strict {
+			return `
+			panic("ggconfig: cannot read env " + key + "_FILE: " + err.Error())`
+		}
+		return `
+			return "", false`
+	}
+	return fmt.Sprintf(`
+			if report != nil {
+				report(&%s{Source: "env", Key: key + "_FILE", Value: path, Type: "file", Err: err})
+			} else {
+				%s("env", key+"_FILE", path, "file", err)
+			}
+			return "", false`, runtimeIdent("ParseError", opts.VendorRuntime), runtimeIdent("ObserveParseError", opts.VendorRuntime))
+}
+
+// getEnvStrings генерирует чтение []string из ENV: JSON массив (так пишет export-env) или
+// элементы через разделитель ggconfig:separator (по умолчанию запятая) без пустых элементов
+func getEnvStrings(envKeyExpr string, m Method, opts GenerateOptions) string {
+	return fmt.Sprintf(`if value := c.getenv(%s%s); value != "" {
+		var result []string
+		if !strings.HasPrefix(value, "[") || json.Unmarshal([]byte(value), &result) != nil {
+			result = nil
+			for _, s := range strings.Split(value, %q) {
+				if s = strings.TrimSpace(s); s != "" {
+					result = append(result, s)
+				}
+			}
+		}
+		if len(result) > 0 {
+			return result, true
+		}
+	}`, envKeyExpr, envReportArg(m, opts), ListSeparator(m))
+}
+
+// IsNumberSliceType сообщает, является ли тип списком чисел: []int, []uint16, []float64, ...
+func IsNumberSliceType(typeName string) bool {
+	elem, ok := strings.CutPrefix(typeName, "[]")
+	return ok && (IsIntegerType(elem) || elem == "float32" || elem == "float64")
+}
+
+// isListType сообщает, задается ли тип в ENV списком через разделитель: []string и списки чисел
+func isListType(typeName string) bool {
+	return typeName == "[]string" || IsNumberSliceType(typeName)
+}
+
+// listElemParse возвращает разбор элемента списка чисел из переменной value
+func listElemParse(elemType string) envParse {
+	switch elemType {
+	case "float64":
+		return envParse{v: "floatValue", parse: "strconv.ParseFloat(value, 64)", result: "floatValue"}
+	case "float32":
+		return envParse{v: "floatValue", parse: "strconv.ParseFloat(value, 32)", result: "float32(floatValue)"}
+	}
+	return getEnvParse(Method{ReturnType: elemType}, false)
+}
+
+// getEnvNumbers генерирует чтение списка чисел из ENV: JSON массив или элементы через разделитель,
+// каждый разбирается strconv. Если не разбирается хотя бы один элемент, значение пропускается целиком
+func getEnvNumbers(envKeyExpr string, m Method, opts GenerateOptions) string {
+	p := listElemParse(m.ElemType)
+	invalid := strings.Replace(getEnvInvalid(envKeyExpr, m, opts, "\t\t"), " else {", " else if err != nil {", 1)
+	return fmt.Sprintf(`if value := c.getenv(%s%s); value != "" {
+		var result %s
+		var err error
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			err = json.Unmarshal([]byte(value), &result)
+		} else {
+			for _, value := range strings.Split(value, %q) {
+				if value = strings.TrimSpace(value); value == "" {
+					continue
+				}
+				%s, parseErr := %s
+				if parseErr != nil {
+					err = parseErr
+					break
+				}
+				result = append(result, %s)
+			}
+		}
+		if err == nil && len(result) > 0 {
+			return result, true
+		}%s
+	}`, envKeyExpr, envReportArg(m, opts), m.ReturnType, ListSeparator(m), p.v, p.parse, p.result, invalid)
+}
+
+// getYAMLNumbers генерирует поэлементное преобразование результата runtime.YAML.GetSlice (переменная v)
+// в список чисел: элемент, который не разбирается, отклоняет весь список
+func getYAMLNumbers(m Method) string {
+	p := listElemParse(m.ElemType)
+	return fmt.Sprintf(`var result %s
+		valid := true
+		for _, item := range v {
+			value := strings.TrimSpace(fmt.Sprint(item))
+			%s, err := %s
+			if err != nil {
+				valid = false
+				break
+			}
+			result = append(result, %s)
+		}
+		if valid && len(result) > 0 {
+			return result, true
+		}`, m.ReturnType, p.v, p.parse, p.result)
+}
+
+// exampleTime - значение time.Time в примере конфига
+var exampleTime = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// TimeLayout возвращает формат time.Time метода: ggconfig:layout или RFC3339
+func TimeLayout(m Method) string {
+	if layout, ok := m.Directive("layout"); ok && layout != "" {
+		return layout
+	}
+	return time.RFC3339
+}
+
+// timeLayoutExpr - формат time.Time метода как выражение Go: time.RFC3339 или строковый литерал
+func timeLayoutExpr(m Method) string {
+	if layout := TimeLayout(m); layout != time.RFC3339 {
+		return strconv.Quote(layout)
+	}
+	return "time.RFC3339"
+}
+
+// ListSeparator возвращает разделитель элементов []string в ENV и строковых значениях YAML
+func ListSeparator(m Method) string {
+	if sep, ok := m.Directive("separator"); ok && sep != "" {
+		return sep
+	}
+	return ","
+}
+
+// getUnsetReturn генерирует возврат прочитанного значения v (результат result) с проверкой
+// заглушки ggconfig:unset: ключ со значением-заглушкой считается отсутствующим, и чтение
+// продолжается со следующего ключа или секции. Без директивы - обычный return.
+func getUnsetReturn(m Method, v, result, indent string) string {
+	lit, err := unsetLiteral(m)
+	if err != nil || lit == "" {
+		return fmt.Sprintf("return %s, true", result)
+	}
+	return fmt.Sprintf("if %s != %s {\n%s\treturn %s, true\n%s}", v, lit, indent, result, indent)
+}
+
+// cacheableMethod сообщает, можно ли кэшировать значение метода (ggconfig:cache): закэшированное
+// значение получают все вызывающие, поэтому изменяемые типы (срезы, map, указатели, net.IP,
+// необработанный YAML) не кэшируются
+func cacheableMethod(m Method) bool {
+	switch m.Kind {
+	case KindRaw, KindNode, KindURL, KindIP, KindCIDR:
+		return false
+	}
+	return !m.IsSlice && !strings.HasPrefix(m.ReturnType, "[]") && !strings.HasPrefix(m.ReturnType, "map[") && !strings.HasPrefix(m.ReturnType, "*")
+}
+
+// unsetLiteral возвращает значение директивы ggconfig:unset как литерал Go типа метода
+// (пустая строка - директивы нет)
+func unsetLiteral(m Method) (string, error) {
+	v, ok := m.Directive("unset")
+	if !ok {
+		return "", nil
+	}
+	switch {
+	case m.IsSlice || m.Kind != "":
+		return "", fmt.Errorf("ggconfig:unset is not supported for %s (supported: string, bool, integer types)", m.ReturnType)
+	case m.ReturnType == "string":
+		return strconv.Quote(v), nil
+	case m.ReturnType == "bool":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("ggconfig:unset=%q is not a valid bool", v)
+		}
+		return strconv.FormatBool(b), nil
+	case IsIntegerType(m.ReturnType):
+		info := IntegerTypes[m.ReturnType]
+		if info.Signed {
+			n, err := strconv.ParseInt(v, 10, info.BitSize)
+			if err != nil {
+				return "", fmt.Errorf("ggconfig:unset=%q is not a valid %s", v, m.ReturnType)
+			}
+			return strconv.FormatInt(n, 10), nil
+		}
+		n, err := strconv.ParseUint(v, 10, info.BitSize)
+		if err != nil {
+			return "", fmt.Errorf("ggconfig:unset=%q is not a valid %s", v, m.ReturnType)
+		}
+		return strconv.FormatUint(n, 10), nil
+	}
+	return "", fmt.Errorf("ggconfig:unset is not supported for %s", m.ReturnType)
+}
+
+// getEnvInvalid генерирует ветку else для ошибки разбора ENV (переменные value и err).
+// С --strict (и для методов (T, error)) значение передается в report функции чтения, без него - наблюдателю ObserveParseError
+// (значение пропускается). С --no-deps runtime недоступен: в строгом режиме сразу panic,
+// иначе ветки нет.
+func getEnvInvalid(envKeyExpr string, m Method, opts GenerateOptions, indent string) string {
+	if !opts.Strict && !m.ReturnsError {
+		if opts.NoDeps {
+			return ""
+		}
+		return fmt.Sprintf(` else {
+%s	%s("env", %s, value, %q, err)
+%s}`, indent, runtimeIdent("ObserveParseError", opts.VendorRuntime), envKeyExpr, m.ReturnType, indent)
+	}
+	if opts.NoDeps {
+		return fmt.Sprintf(` else {
+%s	panic("ggconfig: invalid %s value in env " + %s + ": " + err.Error())
+%s}`, indent, m.ReturnType, envKeyExpr, indent)
+	}
+	return fmt.Sprintf(` else {
+%s	report(&%s{Source: "env", Key: %s, Value: value, Type: %q, Err: err})
+%s}`, indent, runtimeIdent("ParseError", opts.VendorRuntime), envKeyExpr, m.ReturnType, indent)
+}
+
+// methodKeys возвращает ENV переменные и YAML ключи (section.key), которые читают реализации
+// метода name, в порядке чтения: сначала алиасы
+func methodKeys(info *InterfaceInfo, aliases AliasSettings, name string) (env, yamlKeys []string) {
+	env = append(append([]string{}, aliases.Env[name]...), EnvKey(info.EnvKeyPrefix(), name))
+	for _, section := range append(append([]string{}, aliases.YAMLSection...), info.PackageName) {
+		for _, k := range append(append([]string{}, aliases.YAMLKey[name]...), strings.ToLower(name)) {
+			yamlKeys = append(yamlKeys, section+"."+k)
+		}
+	}
+	return env, yamlKeys
+}
+
+// keysDoc описывает ключи для комментария: "the ENV variable A" или "the first set of the
+// ENV variables A, B"
+func keysDoc(what string, keys []string) string {
+	if len(keys) == 1 {
+		return "the " + what + " " + keys[0]
+	}
+	return "the first set of the " + what + "s " + strings.Join(keys, ", ")
+}
+
+// writeDescriptor записывает JSON описание ключей интерфейса (runtime.Descriptor):
+// ключи читаются в том же порядке, что и в сгенерированных реализациях (сначала алиасы)
+func (g *Generator) writeDescriptor(info *InterfaceInfo, aliases AliasSettings, path string, mode os.FileMode) error {
+	pkg := info.PackageName
+	if dir, err := filepath.Abs(g.path(".")); err == nil {
+		if p, err := NewImportPaths().Of(dir); err == nil {
+			pkg = p
+		}
+	}
+	d := runtime.Descriptor{Package: pkg, Interface: info.InterfaceName, Keys: []runtime.DescriptorKey{}}
+	for _, m := range info.Methods {
+		key := runtime.DescriptorKey{
+			Key:         info.PackageName + "." + strings.ToLower(m.Name),
+			Method:      m.Name,
+			Type:        m.DeclaredType(),
+			Description: m.Comment,
+			Directives:  m.Directives,
+		}
+		_, key.Secret = m.Directive("secret")
+		key.Env, key.YAML = methodKeys(info, aliases, m.Name)
+		// Ключи прежних имен (ggconfig:was) читаются после новых
+		for _, old := range m.Was() {
+			env, yamlKeys := methodKeys(info, aliases, old)
+			key.Env, key.YAML = append(key.Env, env...), append(key.YAML, yamlKeys...)
+		}
+		d.Keys = append(d.Keys, key)
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := g.writeFile(path, append(data, '\n'), mode); err != nil {
+		return fmt.Errorf("write descriptor %s: %w", path, err)
+	}
+	return nil
+}
+
+// getErrorMethods генерирует для методов, объявленных как (T, error), метод интерфейса поверх
+// lookupErr<Name>: runtime.LookupValue переводит отсутствие значения в ErrNotSet, а некорректное
+// значение возвращает как *runtime.ParseError. Для методов (*T, bool) метод интерфейса
+// разыменовывает default и возвращает указатель на найденное значение (nil default - не задано)
+func getErrorMethods(info *InterfaceInfo, typeName string, opts GenerateOptions) string {
+	var b strings.Builder
+	for _, m := range info.Methods {
+		comment := ""
+		if m.Comment != "" {
+			comment = "\n//\n// " + m.Comment
+		}
+		if m.Pointer {
+			fmt.Fprintf(&b, `
+// %s returns a pointer to the value of lookup%s, or defaultValue (nil - not set) and false.%s
+func (c *%s) %s(defaultValue *%s) (*%s, bool) {
+	var d %s
+	if defaultValue != nil {
+		d = *defaultValue
+	}
+	if v, ok := c.lookup%s(d); ok {
+		return &v, true
+	}
+	return defaultValue, false
+}
+`, m.Name, m.Name, comment, typeName, m.Name, m.ReturnType, m.ReturnType, m.ReturnType, m.Name)
+			continue
+		}
+		if !m.ReturnsError {
+			continue
+		}
+		typ := m.ReturnType
+		if info.NeedImport {
+			typ = qualifyTypeName(typ, info.sourcePackageName())
+		}
+		fmt.Fprintf(&b, `
+// %s returns the value of lookupErr%s; without one it returns defaultValue and runtime.ErrNotSet,
+// or a *runtime.ParseError for a malformed value.%s
+func (c *%s) %s(defaultValue %s) (%s, error) {
+	return %s(%q, defaultValue, c.lookupErr%s)
+}
+`, m.Name, m.Name, comment, typeName, m.Name, typ, typ, runtimeIdent("LookupValue", opts.VendorRuntime), info.PackageName+"."+strings.ToLower(m.Name), m.Name)
+	}
+	return b.String()
+}
+
+// lookupErrSig возвращает сигнатуру lookupErr<Name> для проверки источника на интерфейс:
+// lookupErrPort(int) (int, bool, error)
+func lookupErrSig(info *InterfaceInfo, m Method) string {
+	param, ret := m.ParamType, m.ReturnType
+	if info.NeedImport {
+		param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+	}
+	return fmt.Sprintf("lookupErr%s(%s) (%s, bool, error)", m.Name, param, ret)
+}
+
+// publicSig возвращает сигнатуру метода интерфейса, которую принимают конструкторы композита и
+// оберток: Port(defaultValue int) (int, bool), Name(defaultValue *string) (*string, bool),
+// Limit(defaultValue int64) (int64, error)
+func publicSig(info *InterfaceInfo, m Method) string {
+	param, ret, second := m.ParamType, m.ReturnType, "bool"
+	if info.NeedImport {
+		param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+	}
+	if m.Pointer {
+		param, ret = "*"+ret, "*"+ret
+	}
+	if m.ReturnsError {
+		second = "error"
+	}
+	return fmt.Sprintf("%s(defaultValue %s) (%s, %s)", m.Name, param, ret, second)
+}
+
+// hasLookups сообщает, есть ли у интерфейса методы, bool-форма которых (lookup<Name>) не совпадает
+// с методом интерфейса: тогда реализации интерфейса вне пакета оборачиваются в <Type>Adapter
+func hasLookups(methods []Method) bool {
+	for _, m := range methods {
+		if lookupName(m) != m.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// getAdapter генерирует <Type>Adapter: bool-формы и lookupErr<Name> поверх методов интерфейса
+// реализации, написанной вне пакета, и adapt<Type>, которая оборачивает только такие реализации.
+// Отсутствие значения метода (T, error) - ошибка runtime.ErrNotSet
+func getAdapter(info *InterfaceInfo, opts GenerateOptions) string {
+	typeName := info.TypeName()
+	var public, lookups strings.Builder
+	for _, m := range info.Methods {
+		param, ret := m.ParamType, m.ReturnType
+		if info.NeedImport {
+			param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+		}
+		public.WriteString("\n\t" + publicSig(info, m))
+		lookups.WriteString(fmt.Sprintf("\n\t%s(defaultValue %s) (%s, bool)", lookupName(m), param, ret))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `
+// ===== Adapter =====
+
+// %sAdapter reads an implementation of %s.%s written outside this package, e.g. a test fake,
+// through the forms the composite and the wrappers call. An error wrapping runtime.ErrNotSet
+// means the value is absent.
+type %sAdapter struct {
+	src interface{%s
+	}
+}
+
+// adapt%s returns s as is when it is a source of this package and wraps it in %sAdapter otherwise.
+func adapt%s(s interface{%s
+}) interface{%s
+} {
+	if l, ok := s.(interface{%s
+	}); ok {
+		return l
+	}
+	return %sAdapter{src: s}
+}
+`, typeName, info.sourcePackageName(), info.InterfaceName, typeName, indentLines(public.String()),
+		typeName, typeName, typeName, public.String(), lookups.String(), indentLines(lookups.String()), typeName)
+	if !opts.NoDeps {
+		fmt.Fprintf(&b, `
+// SourceName names the wrapped source in reports and resolution stats.
+func (a %sAdapter) SourceName() string {
+	return %s(a.src)
+}
+`, typeName, runtimeIdent("SourceName", opts.VendorRuntime))
+	}
+	for _, m := range info.Methods {
+		param, ret := m.ParamType, m.ReturnType
+		if info.NeedImport {
+			param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+		}
+		switch {
+		case m.Pointer:
+			fmt.Fprintf(&b, `
+// %s reads %s: a nil pointer means the value is absent.
+func (a %sAdapter) %s(defaultValue %s) (%s, bool) {
+	if v, ok := a.src.%s(&defaultValue); ok && v != nil {
+		return *v, true
+	}
+	return defaultValue, false
+}
+`, lookupName(m), m.Name, typeName, lookupName(m), param, ret, m.Name)
+		case m.ReturnsError:
+			fmt.Fprintf(&b, `
+// %s reads %s; any error means the value is absent.
+func (a %sAdapter) %s(defaultValue %s) (%s, bool) {
+	v, ok, _ := a.lookupErr%s(defaultValue)
+	return v, ok
+}
+
+// lookupErr%s reads %s: runtime.ErrNotSet means the value is absent, other errors are returned.
+func (a %sAdapter) lookupErr%s(defaultValue %s) (%s, bool, error) {
+	v, err := a.src.%s(defaultValue)
+	if errors.Is(err, %s) {
+		return defaultValue, false, nil
+	}
+	if err != nil {
+		return defaultValue, false, err
+	}
+	return v, true, nil
+}
+`, lookupName(m), m.Name, typeName, lookupName(m), param, ret, m.Name,
+				m.Name, m.Name, typeName, m.Name, param, ret, m.Name, runtimeIdent("ErrNotSet", opts.VendorRuntime))
+		default:
+			fmt.Fprintf(&b, `
+// %s reads the method of the wrapped source.
+func (a %sAdapter) %s(defaultValue %s) (%s, bool) {
+	return a.src.%s(defaultValue)
+}
+`, m.Name, typeName, m.Name, param, ret, m.Name)
+		}
+	}
+	return b.String()
+}
+
+// indentLines добавляет табуляцию к каждой строке s, начинающейся с перевода строки
+func indentLines(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n\t")
+}
+
+// lookupErrOf генерирует возврат значения метода из base с ошибкой: lookupErr<Name>, если base -
+// источник этого пакета, иначе bool-форма (сторонний источник, значения которого не разбираются)
+func lookupErrOf(info *InterfaceInfo, m Method, base string) string {
+	return fmt.Sprintf(`if e, ok := %s.(interface{ %s }); ok {
+		return e.lookupErr%s(defaultValue)
+	}
+	v, ok := %s.%s(defaultValue)
+	return v, ok, nil`, base, lookupErrSig(info, m), m.Name, base, lookupName(m))
+}
+
+// getReportMethods генерирует для ENV и YAML реализаций методов, которые сообщают о некорректных
+// значениях, формы поверх функции чтения read<Name>: bool-форма передает значения обработчику
+// runtime.SetParseErrorHandler, lookupErr<Name> возвращает первое из них ошибкой без глобального
+// состояния. keysDoc описывает читаемые ключи метода, methodComment - его документацию
+func getReportMethods(info *InterfaceInfo, typeName string, opts GenerateOptions, methodComment, keysDoc func(Method) string) string {
+	var b strings.Builder
+	for _, m := range info.Methods {
+		if !reportsErrors(m, opts) {
+			continue
+		}
+		param, ret := m.ParamType, m.ReturnType
+		if info.NeedImport {
+			param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+		}
+		comment := ""
+		if !m.ReturnsError && !m.Pointer {
+			comment = methodComment(m) // Документация метода интерфейса
+		}
+		keys := keysDoc(m)
+		if olds := m.Was(); len(olds) > 0 {
+			keys += ", then the keys of the former names " + strings.Join(olds, ", ") + " (ggconfig:was)"
+		}
+		parseError := runtimeIdent("ParseError", opts.VendorRuntime)
+		fmt.Fprintf(&b, `
+// %s reads %s; without a valid value it returns defaultValue and false.
+// A malformed value is passed to the parse error handler (runtime.SetParseErrorHandler).%s
+func (c *%s) %s(defaultValue %s) (%s, bool) {
+	return c.read%s(defaultValue, %s)
+}
+
+// lookupErr%s is %s returning the first malformed value as a *runtime.ParseError
+// instead of passing it to the parse error handler.
+func (c *%s) lookupErr%s(defaultValue %s) (%s, bool, error) {
+	var perr *%s
+	v, ok := c.read%s(defaultValue, func(err *%s) {
+		if perr == nil {
+			perr = err
+		}
+	})
+	if perr != nil {
+		return defaultValue, false, perr
+	}
+	return v, ok, nil
+}
+`, lookupName(m), keys, comment, typeName, lookupName(m), param, ret, m.Name, runtimeIdent("HandleParseError", opts.VendorRuntime),
+			m.Name, lookupName(m), typeName, m.Name, param, ret, parseError, m.Name, parseError)
+	}
+	return b.String()
+}
+
+// getLookupErrMethods генерирует lookupErr<Name> источника, который не разбирает значения
+// (mock, flag, secret): его bool-форма не сообщает об ошибках
+func getLookupErrMethods(info *InterfaceInfo, typeName string, opts GenerateOptions) string {
+	var b strings.Builder
+	for _, m := range info.Methods {
+		if !reportsErrors(m, opts) {
+			continue
+		}
+		param, ret := m.ParamType, m.ReturnType
+		if info.NeedImport {
+			param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+		}
+		fmt.Fprintf(&b, `
+// lookupErr%s returns the value of %s: this source does not parse values, so the error is always nil.
+func (c *%s) lookupErr%s(defaultValue %s) (%s, bool, error) {
+	v, ok := c.%s(defaultValue)
+	return v, ok, nil
+}
+`, m.Name, lookupName(m), typeName, m.Name, param, ret, lookupName(m))
+	}
+	return b.String()
+}
+
+// getWasMethods генерирует для методов с ggconfig:was bool-форму источника source (env или yaml):
+// сначала читаются новые ключи (current<Name>), затем ключи прежних имен (was<Name><Old>);
+// значение по прежнему ключу возвращается с уведомлением runtime.ReportDeprecated
+func getWasMethods(info *InterfaceInfo, typeName, source string, opts GenerateOptions) string {
+	var b strings.Builder
+	for _, m := range info.Methods {
+		olds := m.Was()
+		if len(olds) == 0 {
+			continue
+		}
+		param, ret := m.ParamType, m.ReturnType
+		if info.NeedImport {
+			param, ret = qualifyTypeName(param, info.sourcePackageName()), qualifyTypeName(ret, info.sourcePackageName())
+		}
+		// Вариант с report передает его функциям чтения новых и прежних ключей
+		name, current, was, reportParam, report := lookupName(m), "current", "was", "", ""
+		if reportsErrors(m, opts) {
+			name, current, was = "read"+m.Name, "readCurrent", "readWas"
+			reportParam, report = ", report func(*"+runtimeIdent("ParseError", opts.VendorRuntime)+")", ", report"
+		}
+		fmt.Fprintf(&b, `
+// %s reads the keys of the current name first, then those of the former names %s
+// (ggconfig:was), reporting a value found under a former name with runtime.ReportDeprecated.
+func (c *%s) %s(defaultValue %s%s) (%s, bool) {
+	if v, ok := c.%s%s(defaultValue%s); ok {
+		return v, true
+	}
+`, name, strings.Join(olds, ", "), typeName, name, param, reportParam, ret, current, m.Name, report)
+		for _, old := range olds {
+			oldKey, newKey := strconv.Quote(info.PackageName+"."+strings.ToLower(old)), strconv.Quote(info.PackageName+"."+strings.ToLower(m.Name))
+			if source == "env" {
+				oldKey = fmt.Sprintf("c.mapKey(%q)", EnvKey(info.EnvKeyPrefix(), old))
+				newKey = fmt.Sprintf("c.mapKey(%q)", EnvKey(info.EnvKeyPrefix(), m.Name))
+			}
+			fmt.Fprintf(&b, `	if v, ok := c.%s%s%s(defaultValue%s); ok {
+		%s(%q, %s, %s)
+		return v, true
+	}
+`, was, m.Name, old, report, runtimeIdent("ReportDeprecated", opts.VendorRuntime), source, oldKey, newKey)
+		}
+		b.WriteString("\treturn defaultValue, false\n}\n")
+	}
+	return b.String()
+}
+
+// secretRef возвращает ссылку на секрет метода с директивой ggconfig:secret
+func secretRef(info *InterfaceInfo, m Method) string {
+	if ref, _ := m.Directive("secret"); ref != "" {
+		return ref
+	}
+	// Без ссылки хранилище получает ENV-ключ метода
+	return EnvKey(info.EnvKeyPrefix(), m.Name)
+}
+
+// quoteList возвращает строки как список аргументов Go: "a", "b"
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// rawResultExpr возвращает выражение результата для raw-методов из переменной raw (runtime.Raw)
+func rawResultExpr(m Method) string {
+	if m.Kind == KindNode {
+		return "*raw.Node"
+	}
+	return "raw"
+}
+
+// parseIntExpr возвращает выражение strconv для разбора value с проверкой диапазона типа
+func parseIntExpr(info IntTypeInfo) string {
+	if info.Signed {
+		return fmt.Sprintf("strconv.ParseInt(value, 10, %d)", info.BitSize)
+	}
+	return fmt.Sprintf("strconv.ParseUint(value, 10, %d)", info.BitSize)
+}
+
+// Парсинг повторяющихся флагов --alias
+// Допустимые формы:
+// - env.<Method>=ALIAS1,ALIAS2
+// - yaml.section=ALIAS1,ALIAS2
+// - yaml.key.<Method>=ALIAS1,ALIAS2
+// MethodAliases возвращает алиасы с ключами из директив методов: ggconfig:env=DATABASE_URL,DB_URL
+// и ggconfig:yaml=db_url читаются как --alias env.<Method>=... и yaml.key.<Method>=... и идут
+// перед алиасами флагов и манифеста; производный ключ метода в алиасы не попадает. aliases не меняются
+func MethodAliases(info *InterfaceInfo, aliases AliasSettings) AliasSettings {
+	out := AliasSettings{Env: map[string][]string{}, YAMLSection: aliases.YAMLSection, YAMLKey: map[string][]string{}}
+	add := func(dst map[string][]string, method, value, derived string) {
+		for _, key := range strings.Split(value, ",") {
+			if key = strings.TrimSpace(key); key != "" && key != derived {
+				dst[method] = appendUnique(dst[method], key)
+			}
+		}
+	}
+	for _, m := range info.Methods {
+		if v, ok := m.Directive("env"); ok {
+			add(out.Env, m.Name, v, EnvKey(info.EnvKeyPrefix(), m.Name))
+		}
+		if v, ok := m.Directive("yaml"); ok {
+			add(out.YAMLKey, m.Name, v, strings.ToLower(m.Name))
+		}
+	}
+	for _, method := range sortedKeys(aliases.Env) {
+		out.Env[method] = appendUnique(out.Env[method], aliases.Env[method]...)
+	}
+	for _, method := range sortedKeys(aliases.YAMLKey) {
+		out.YAMLKey[method] = appendUnique(out.YAMLKey[method], aliases.YAMLKey[method]...)
+	}
+	return out
+}
+
+func ParseAliasSettings(flags AliasFlags) AliasSettings {
+	settings := AliasSettings{
+		Env:         map[string][]string{},
+		YAMLSection: []string{},
+		YAMLKey:     map[string][]string{},
+	}
+
+	for _, item := range flags {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		left := strings.TrimSpace(parts[0])
+		right := strings.TrimSpace(parts[1])
+		var values []string
+		if right != "" {
+			for _, v := range strings.Split(right, ",") {
+				vv := strings.TrimSpace(v)
+				if vv != "" {
+					values = append(values, vv)
+				}
+			}
+		}
+
+		segs := strings.Split(left, ".")
+		if len(segs) == 0 {
+			continue
+		}
+		switch segs[0] {
+		case "env":
+			if len(segs) == 2 {
+				method := segs[1]
+				if len(values) > 0 {
+					settings.Env[method] = appendUnique(settings.Env[method], values...)
+				}
+			}
+		case "yaml":
+			if len(segs) >= 2 {
+				switch segs[1] {
+				case "section":
+					settings.YAMLSection = appendUnique(settings.YAMLSection, values...)
+				case "key":
+					if len(segs) == 3 {
+						method := segs[2]
+						if len(values) > 0 {
+							settings.YAMLKey[method] = appendUnique(settings.YAMLKey[method], values...)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return settings
+}
+
+func getMethodSignature(funcType *ast.FuncType, imports map[string]string, isNamed func(typeName string) bool) (string, string, error) {
+	// Получаем тип параметра (для простоты берем первый)
+	var paramType string
+	if funcType.Params != nil && len(funcType.Params.List) > 0 {
+		paramType = getTypeName(funcType.Params.List[0].Type)
+	}
+
+	rets := getReturnTypes(funcType)
+	if len(rets) != 2 {
+		return "", "", fmt.Errorf("expected 2 return values (T, bool) or (T, error), got %d", len(rets))
+	}
+	if rets[1].TypeName != "bool" && rets[1].TypeName != "error" {
+		return "", "", fmt.Errorf("second return value must be bool or error, got %q", rets[1].TypeName)
+	}
+	// Разрешаем: string, int, []Type
+	if rets[0].TypeName == "" {
+		return "", "", fmt.Errorf("could not parse return type")
+	}
+	if strings.Contains(rets[0].TypeName, "[") && !rets[0].IsSlice || strings.Contains(rets[0].ElemType, "[") {
+		return "", "", fmt.Errorf("generic type %q is not supported: return the value type directly or use runtime.Raw", rets[0].TypeName)
+	}
+	if !rets[0].IsSlice && rets[0].TypeName != "string" && rets[0].TypeName != "bool" && !IsIntegerType(rets[0].TypeName) && !isPointerValueType(rets[0].TypeName) && valueKind(rets[0].TypeName, imports) == "" && !isNamed(rets[0].TypeName) {
+		return "", "", fmt.Errorf("unsupported value return type %q (supported: string, bool, int, int8-int64, uint, uint8-uint64, *string, *bool, *int (and other integer types), time.Duration, time.Time, *url.URL, net.IP, *net.IPNet, types implementing encoding.TextUnmarshaler or yaml.Unmarshaler, structs, runtime.Raw, yaml.Node, []Type)", rets[0].TypeName)
+	}
+	return paramType, rets[0].TypeName, nil
+}
+
+// qualifyTypeName добавляет к локальному типу (и элементу среза) префикс исходного пакета
+func qualifyTypeName(typeName, pkgName string) string {
+	// Проверяем, является ли тип примитивным или уже квалифицированным (pkg.Type)
+	if isBuiltinType(typeName) || strings.Contains(typeName, ".") {
+		return typeName
+	}
+	// Если это слайс, обрабатываем элемент
+	if strings.HasPrefix(typeName, "[]") {
+		elemType := strings.TrimPrefix(typeName, "[]")
+		if isBuiltinType(elemType) || strings.Contains(elemType, ".") {
+			return typeName
+		}
+		return "[]" + pkgName + "." + elemType
+	}
+	// Добавляем префикс пакета
+	return pkgName + "." + typeName
+}
+
+func (g *Generator) generateImplementation(info *InterfaceInfo, aliases AliasSettings, opts GenerateOptions) error {
+	// Определяем путь для генерации
+	var fullOutputPath string
+	var packageName string
+	var isSamePackage bool
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		// По умолчанию - создаем в текущем пакете
+		fullOutputPath = g.path(".")
+		packageName = info.sourcePackageName()
+		isSamePackage = true
+	} else {
+		// Пользователь указал свой путь
+		fullOutputPath = g.path(outputPath)
+		packageName = filepath.Base(outputPath)
+		if abs, err := filepath.Abs(fullOutputPath); err == nil && (packageName == "." || packageName == "..") {
+			// --package без --output: генерация в текущую директорию
+			packageName = filepath.Base(abs)
+		}
+		isSamePackage = false
+	}
+	if opts.Sources == nil {
+		// Вызов не из main (фасад, тесты): источники по умолчанию
+		var err error
+		if opts.Sources, err = selectSources("", info, opts); err != nil {
+			return err
+		}
+	}
+	if opts.Registry {
+		if err := requireSources("--registry", opts.Sources, "env", "yaml", "composite"); err != nil {
+			return err
+		}
+	}
+	if opts.DocExamples {
+		required := []string{"env", "mock", "composite"}
+		if !opts.NoDeps {
+			required = append(required, "yaml")
+		}
+		if err := requireSources("--doc-examples", opts.Sources, required...); err != nil {
+			return err
+		}
+	}
+	if opts.OutPackage != "" {
+		if isSamePackage && opts.OutPackage != packageName {
+			return fmt.Errorf("--out-package=%s: the code is generated into the interface package %s (use --output to generate into another package)", opts.OutPackage, packageName)
+		}
+		packageName = opts.OutPackage
+	}
+
+	// Неэкспортируемые методы можно реализовать только в пакете интерфейса
+	if !isSamePackage {
+		for _, m := range info.Methods {
+			if !ast.IsExported(m.Name) {
+				return fmt.Errorf("method %s.%s is unexported: generate into the interface package (remove --output) or export the method", info.InterfaceName, m.Name)
+			}
+		}
+	}
+
+	// Создаем директорию если не существует
+	if err := g.mkdirOutput(fullOutputPath); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	unlock, err := g.lockOutputDir(fullOutputPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if opts.Registry {
+		if err := g.ensureRegistryFile(fullOutputPath, packageName, opts); err != nil {
+			return err
+		}
+	}
+
+	var descriptorFile string
+	if opts.Descriptor {
+		descriptorFile = info.TypeName() + ".descriptor.json"
+		if err := g.writeDescriptor(info, aliases, filepath.Join(fullOutputPath, descriptorFile), opts.FileMode); err != nil {
+			return err
+		}
+	}
+
+	// Генерируем один файл со всеми реализациями
+	// Используем уникальное имя для избежания конфликтов
+	fileName := fmt.Sprintf("%s.gen.go", info.TypeName())
+	if opts.OutFile != "" {
+		fileName = opts.OutFile
+	}
+	filePath := filepath.Join(fullOutputPath, fileName)
+
+	if err := g.guardOverwrite(filePath, GeneratedHeader); err != nil {
+		return err
+	}
+	sources := map[string]bool{}
+	for _, name := range opts.Sources {
+		sources[name] = true
+	}
+	// Шаблон для генерации всех реализаций: config.go.tmpl и шаблоны источников
+	var tmpl *template.Template
+	// Документация метода интерфейса отдельным абзацем комментария
+	methodComment := func(m Method) string {
+		comment := ""
+		if m.Comment != "" {
+			comment = "\n//\n// " + m.Comment
+		}
+		if m.ReturnType == "bool" {
+			// Строка без точки перед следующим абзацем go doc показал бы заголовком
+			if m.Comment != "" && !strings.ContainsAny(m.Comment[len(m.Comment)-1:], ".!?:") {
+				comment += "."
+			}
+			comment += "\n//\n// Accepted values: " + boolLiterals + "."
+			if v, ok := m.Directive("default"); ok {
+				comment += " Documented default: " + v + "."
+			}
+		} else if v, ok := m.Directive("default"); ok {
+			if m.Comment != "" && !strings.ContainsAny(m.Comment[len(m.Comment)-1:], ".!?:") {
+				comment += "."
+			}
+			// Значение в записи JSON: строки в кавычках, числа и длительности как есть
+			if encoded, err := encodeProfileValue(m, v); err == nil {
+				v = encoded
+			}
+			comment += "\n//\n// Documented default: " + v + "."
+		}
+		return comment
+	}
+	tmpl, err = g.loadTemplate("config", "config.go.tmpl", template.FuncMap{
+		// Реализация источника из templates/sources/<name>.go.tmpl
+		"renderSource": func(name string, data any) (string, error) {
+			var buf strings.Builder
+			err := tmpl.ExecuteTemplate(&buf, "sources/"+name+".go.tmpl", data)
+			return buf.String(), err
+		},
+		"hasSource": func(name string) bool { return sources[name] },
+		"title":     TitleName,
+		"envKey":    func(methodName string) string { return EnvKey(info.EnvKeyPrefix(), methodName) },
+		// Имя конструктора: New<Package><Interface>; для неэкспортируемого интерфейса в том же пакете - new<Package><Interface>
+		"ctor": func(prefix string) string {
+			if isSamePackage && !ast.IsExported(info.InterfaceName) {
+				prefix = strings.ToLower(prefix[:1]) + prefix[1:]
+			}
+			return prefix + TitleName(info.TypeName()) + TitleName(info.InterfaceName)
+		},
+		// Чтение []string из ENV: JSON массив или список через разделитель
+		"envStrings": func(m Method, key string) string { return getEnvStrings(key, m, opts) },
+		// Аргумент report для c.getenv и c.lookupEnv и обработка ошибки чтения <KEY>_FILE
+		"envReport":      func(m Method) string { return envReportArg(m, opts) },
+		"envFileInvalid": func() string { return getEnvFileInvalid(opts) },
+		// Чтение списка чисел из ENV: JSON массив или элементы через разделитель
+		"envNumbers": func(m Method, key string) string { return getEnvNumbers(key, m, opts) },
+		// Преобразование элементов GetSlice в список чисел
+		"yamlNumbers": getYAMLNumbers,
+		// Проверка ENV по ключу без возврата default
+		"envCheck": func(m Method, key string) string { return getEnvCheckSnippet(key, m, opts) },
+		// Возврат ENV по основному ключу с fallback на default
+		"envReturn": func(m Method, key string) string { return getEnvValue(key, "defaultValue", m, opts) },
+		// Ветка else с ошибкой разбора ENV в режиме --strict
+		"envInvalid": func(m Method, key string) string { return getEnvInvalid(key, m, opts, "\t\t") },
+		// Возврат значения из YAML с проверкой заглушки ggconfig:unset
+		"unsetReturn": func(m Method, v, result string) string { return getUnsetReturn(m, v, result, "\t\t") },
+		"isOneOf":     func(m Method) bool { return len(m.OneOf()) > 0 },
+		// Литерал []string допустимых значений ggconfig:oneof
+		"oneOfLiteral": func(m Method) string { return fmt.Sprintf("%#v", m.OneOf()) },
+		// Сообщение о некорректном значении в YAML в режиме --strict (перед возвратом default)
+		"yamlInvalid": func(m Method) string {
+			if !reportsErrors(m, opts) {
+				return ""
+			}
+			sections := append(append([]string{}, aliases.YAMLSection...), info.PackageName)
+			keys := append(append([]string{}, aliases.YAMLKey[m.Name]...), strings.ToLower(m.Name))
+			// Ключи со значением-заглушкой ggconfig:unset отсутствуют, а не содержат некорректное значение
+			lit, _ := unsetLiteral(m)
+			if lit != "" && m.ReturnType != "string" {
+				lit = strconv.Quote(lit)
+			}
+			var check string
+			switch values := m.OneOf(); {
+			case len(values) > 0 && lit != "":
+				check = fmt.Sprintf("c.y.NotOneOfValueUnset(%#v, %s, %#v, %s)", values, lit, sections, quoteList(keys))
+			case len(values) > 0:
+				check = fmt.Sprintf("c.y.NotOneOfValue(%#v, %#v, %s)", values, sections, quoteList(keys))
+			case lit != "":
+				check = fmt.Sprintf("c.y.InvalidValueUnset(%q, %s, %#v, %s)", m.ReturnType, lit, sections, quoteList(keys))
+			default:
+				check = fmt.Sprintf("c.y.InvalidValue(%q, %#v, %s)", m.ReturnType, sections, quoteList(keys))
+			}
+			return fmt.Sprintf("if perr := %s; perr != nil {\n\t\treport(perr)\n\t}\n\t", check)
+		},
+		"hasIntType": func(methods []Method) bool {
+			for _, method := range methods {
+				if _, ok := method.Directive("format"); ok {
+					continue // Метки времени разбираются через runtime.ParseUnixTime
+				}
+				if IsIntegerType(method.ReturnType) || method.ReturnType == "bool" || IsNumberSliceType(method.ReturnType) {
+					return true
+				}
+			}
+			return false
+		},
+		"hasListType": func(methods []Method) bool {
+			for _, method := range methods {
+				if isListType(method.ReturnType) {
+					return true
+				}
+			}
+			return false
+		},
+		"hasSliceType": func(methods []Method) bool {
+			for _, method := range methods {
+				// Массивы и структуры читаются из ENV как JSON
+				if method.IsSlice || method.Kind == KindStruct {
+					return true
+				}
+			}
+			return false
+		},
+		"isSlice": func(m Method) bool {
+			return m.IsSlice
+		},
+		"isSigned": func(typeName string) bool { return IntegerTypes[typeName].Signed },
+		"baseType": func(returnType string) string {
+			if strings.HasPrefix(returnType, "[]") {
+				return strings.TrimPrefix(returnType, "[]")
+			}
+			return returnType
+		},
+		"qualifyType": func(typeName string, needImport bool, pkgName string) string {
+			// Если тип не примитивный и нужен импорт, добавляем префикс пакета
+			if !needImport {
+				return typeName
+			}
+			return qualifyTypeName(typeName, pkgName)
+		},
+		"toLower": strings.ToLower,
+		// Имя bool-формы метода: для методов (T, error) - lookup<Name>, ее вызывают композит, снимки и обертки
+		"lookup":      lookupName,
+		"readName":    func(m Method) string { return bodyName(m, opts) },
+		"readMethods": func() []Method { return readMethods(info.Methods) },
+		// Параметр report функции чтения метода, который сообщает о некорректных значениях
+		"reportParam": func(m Method) string {
+			if !reportsErrors(m, opts) {
+				return ""
+			}
+			return ", report func(*" + runtimeIdent("ParseError", opts.VendorRuntime) + ")"
+		},
+		"reportsErrors": func(m Method) bool { return reportsErrors(m, opts) },
+		"hasReporting": func(methods []Method) bool {
+			for _, m := range methods {
+				if reportsErrors(m, opts) {
+					return true
+				}
+			}
+			return false
+		},
+		// Сигнатура lookupErr<Name> для проверки источника: lookupErrPort(int) (int, bool, error)
+		"lookupErrSig": func(m Method) string { return lookupErrSig(info, m) },
+		// Сигнатура метода интерфейса: параметры конструкторов композита и оберток
+		"publicSig":  func(m Method) string { return publicSig(info, m) },
+		"hasLookups": hasLookups,
+		"hasErrorMethods": func(methods []Method) bool {
+			for _, m := range methods {
+				if m.ReturnsError {
+					return true
+				}
+			}
+			return false
+		},
+		"adapter": func() string { return getAdapter(info, opts) },
+		// Возврат lookupErr<Name> обертки из base (c.base, s)
+		"lookupErrOf": func(m Method, base string) string { return lookupErrOf(info, m, base) },
+		// Bool-формы методов с ggconfig:was поверх current<Name> и was<Name><Old>
+		"wasMethods": func(typeName, source string) string {
+			return getWasMethods(info, info.TypeName()+typeName, source, opts)
+		},
+		// Методы (T, error) и (*T, bool) типа источника поверх bool-формы
+		"errorMethods": func(typeName string) string { return getErrorMethods(info, info.TypeName()+typeName, opts) },
+		// Bool-формы и lookupErr<Name> ENV и YAML реализаций поверх read<Name>
+		"reportMethods": func(typeName, source string) string {
+			return getReportMethods(info, info.TypeName()+typeName, opts, methodComment, func(m Method) string {
+				env, yamlKeys := methodKeys(info, aliases, m.Name)
+				if source == "env" {
+					return keysDoc("ENV variable", env)
+				}
+				return keysDoc("YAML key", yamlKeys)
+			})
+		},
+		// lookupErr<Name> источников, которые не разбирают значения сами (mock, flag, secret)
+		"lookupErrMethods": func(typeName string) string { return getLookupErrMethods(info, info.TypeName()+typeName, opts) },
+		// Имя из пакета runtime: runtime.YAML или runtimeYAML при --vendor-runtime
+		"rt":        func(name string) string { return runtimeIdent(name, opts.VendorRuntime) },
+		"parseYAML": func() string { return parseYAMLIdent(opts) },
+		// Алиасы
+		"envAliasKeys": func(methodName string) []string {
+			if aliases.Env == nil {
+				return nil
+			}
+			return aliases.Env[methodName]
+		},
+		"yamlSectionAliases": func() []string { return aliases.YAMLSection },
+		// Ключи метода для документации в порядке чтения
+		"envKeysDoc": func(m Method) string {
+			env, _ := methodKeys(info, aliases, m.Name)
+			return keysDoc("ENV variable", env)
+		},
+		"yamlKeysDoc": func(m Method) string {
+			_, yamlKeys := methodKeys(info, aliases, m.Name)
+			return keysDoc("YAML key", yamlKeys)
+		},
+		"methodComment": methodComment,
+		// Секции и ключи, которые читает YAML реализация метода (для runtime.RemapYAML)
+		"yamlFieldSections": func() string {
+			return quoteList(append(append([]string{}, aliases.YAMLSection...), info.PackageName))
+		},
+		"yamlFieldKeys": func(methodName string) string {
+			return quoteList(append(append([]string{}, aliases.YAMLKey[methodName]...), strings.ToLower(methodName)))
+		},
+		// Элементы карты "ENV ключ": "метод" для runtime.EnvKeys; ключ, общий для нескольких методов, достается первому
+		"envKeyTable": func() []string {
+			seen := map[string]bool{}
+			var entries []string
+			for _, m := range info.Methods {
+				for _, key := range append(append([]string{}, aliases.Env[m.Name]...), EnvKey(info.EnvKeyPrefix(), m.Name)) {
+					if !seen[key] {
+						seen[key] = true
+						entries = append(entries, fmt.Sprintf("%q: %q", key, m.Name))
+					}
+				}
+			}
+			return entries
+		},
+		// Элементы карты "ENV ключ": "ключ" для SQL реализации: ENV ключи метода (алиасы и производный)
+		// читают строку его ключа в секции пакета
+		"sqlKeyTable": func() []string {
+			seen := map[string]bool{}
+			var entries []string
+			add := func(envKey, key string) {
+				if !seen[envKey] {
+					seen[envKey] = true
+					entries = append(entries, fmt.Sprintf("%q: %q", envKey, key))
+				}
+			}
+			for _, m := range readMethods(info.Methods) {
+				for _, key := range append(append([]string{}, aliases.Env[m.Name]...), EnvKey(info.EnvKeyPrefix(), m.Name)) {
+					add(key, strings.ToLower(m.Name))
+				}
+			}
+			if opts.OptionalSection {
+				add(EnvKey(info.EnvKeyPrefix(), "Enabled"), "enabled")
+			}
+			return entries
+		},
+		"yamlKeyAliases": func(methodName string) []string {
+			if aliases.YAMLKey == nil {
+				return nil
+			}
+			return aliases.YAMLKey[methodName]
+		},
+		"isInteger": IsIntegerType,
+		"isFlag": func(m Method) bool {
+			_, ok := m.Directive("flag")
+			return ok && (m.ReturnType == "bool" || m.ReturnType == "string")
+		},
+		"hasDirective": func(methods []Method, name string) bool {
+			for _, m := range methods {
+				if _, ok := m.Directive(name); ok {
+					return true
+				}
+			}
+			return false
+		},
+		"isCached": func(m Method) bool {
+			_, ok := m.Directive("cache")
+			return ok
+		},
+		"isSecret": func(m Method) bool {
+			_, ok := m.Directive("secret")
+			return ok && m.ReturnType == "string"
+		},
+		"secretRef": func(m Method) string { return secretRef(info, m) },
+		"flagKey": func(m Method) string {
+			if key, _ := m.Directive("flag"); key != "" {
+				return key
+			}
+			// По умолчанию ключ флага - ENV-ключ в kebab-case: SERVER_NEW_CHECKOUT -> server-new-checkout
+			return strings.ReplaceAll(strings.ToLower(EnvKey(info.EnvKeyPrefix(), m.Name)), "_", "-")
+		},
+		"isRaw":         func(m Method) bool { return m.Kind == KindRaw || m.Kind == KindNode },
+		"isDuration":    func(m Method) bool { return m.Kind == KindDuration },
+		"isTime":        func(m Method) bool { return m.Kind == KindTime },
+		"isURL":         func(m Method) bool { return m.Kind == KindURL },
+		"isIP":          func(m Method) bool { return m.Kind == KindIP },
+		"isCIDR":        func(m Method) bool { return m.Kind == KindCIDR },
+		"isText":        func(m Method) bool { return m.Kind == KindText },
+		"isUnmarshaler": func(m Method) bool { return m.Kind == KindUnmarshaler },
+		"isStruct":      func(m Method) bool { return m.Kind == KindStruct },
+		"timeLayout":    timeLayoutExpr,
+		"isStringSlice": func(m Method) bool { return m.ReturnType == "[]string" },
+		"isNumberSlice": func(m Method) bool { return IsNumberSliceType(m.ReturnType) },
+		"listSeparator": func(m Method) string { return strconv.Quote(ListSeparator(m)) },
+		"rawResult":     rawResultExpr,
+		// Вызов runtime.YAML для целочисленного типа с проверкой разрядности: GetIntN(16, / GetUintN(8,
+		// С ggconfig:format - GetUnixTime("unixms", : целое число или время RFC3339
+		"yamlIntGetter": func(m Method) string {
+			if format, ok := m.Directive("format"); ok {
+				return fmt.Sprintf("GetUnixTime(%q, ", format)
+			}
+			info := IntegerTypes[m.ReturnType]
+			if info.Signed {
+				return fmt.Sprintf("GetIntN(%d, ", info.BitSize)
+			}
+			return fmt.Sprintf("GetUintN(%d, ", info.BitSize)
+		},
+	}, sourceTemplates()...)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		UniquePackageName string // Уникальное имя на основе пути
+		TypeName          string // Основа имен типов: уникальное имя с --type-prefix и --type-suffix
+		InterfaceName     string
+		Methods           []Method
+		GenPackageName    string
+		IsSamePackage     bool
+		EnableRegistry    bool
+		NeedImport        bool
+		ImportPath        string
+		SourcePackageName string   // Имя исходного пакета для квалификации типов
+		NoDeps            bool     // Без внешних зависимостей: без YAML и runtime
+		VendorRuntime     bool     // runtime скопирован в выходной пакет
+		TypeImports       []string // Импорты пакетов квалифицированных типов
+		DescriptorFile    string   // Имя встраиваемого JSON описания ключей (--descriptor)
+		OptionalSection   bool     // Секция выключается ключом enabled: false
+		Sources           []string // Генерируемые источники (--sources)
+	}{
+		UniquePackageName: info.UniquePackageName,
+		TypeName:          info.TypeName(),
+		InterfaceName:     info.InterfaceName,
+		Methods:           info.Methods,
+		GenPackageName:    packageName,
+		IsSamePackage:     isSamePackage,
+		EnableRegistry:    opts.Registry,
+		NeedImport:        info.NeedImport,
+		ImportPath:        info.ImportPath,
+		SourcePackageName: info.sourcePackageName(),
+		NoDeps:            opts.NoDeps,
+		VendorRuntime:     opts.VendorRuntime,
+		TypeImports:       typeImportsExcept(info.TypeImports, RuntimeImportPath),
+		DescriptorFile:    descriptorFile,
+		OptionalSection:   opts.OptionalSection,
+		Sources:           opts.Sources,
+	}
+
+	out, err := g.renderTemplate(filePath, tmpl, data)
+	if err != nil {
+		return err
+	}
+	// Имена типов другого интерфейса в том же пакете дали бы код, который не компилируется
+	if err := g.checkRedeclarations(filePath, out); err != nil {
+		return err
+	}
+	if err := g.writeFile(filePath, out, opts.FileMode); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if opts.DocExamples {
+		if err := g.generateDocExamples(info, aliases, fullOutputPath, packageName, opts); err != nil {
+			return err
+		}
+	}
+	// Копия runtime - последней: в нее попадает то, на что ссылаются остальные файлы пакета
+	if opts.VendorRuntime {
+		return g.ensureVendoredRuntime(fullOutputPath, packageName, opts.FileMode)
+	}
+	return nil
+}
+
+// typeImportsExcept возвращает импорты типов без указанного пути (например, уже импортированного runtime)
+func typeImportsExcept(imports []string, except string) []string {
+	var out []string
+	for _, p := range imports {
+		if p != except {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (g *Generator) ensureRegistryFile(outputDir string, genPackageName string, opts GenerateOptions) error {
+	filePath := filepath.Join(outputDir, "registry.gen.go")
+	if err := g.guardOverwrite(filePath, GeneratedHeader); err != nil {
+		return err
+	}
+	// Registry API: package self-registration via init() in each generated file.
+	// GlobalConfig loads YAML once (optional) and provides typed access via Get().
+	tmpl, err := g.loadTemplate("registry", "registry.go.tmpl", template.FuncMap{
+		"rt":        func(name string) string { return runtimeIdent(name, opts.VendorRuntime) },
+		"parseYAML": func() string { return parseYAMLIdent(opts) },
+	})
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		GenPackageName string
+		VendorRuntime  bool
+	}{
+		GenPackageName: genPackageName,
+		VendorRuntime:  opts.VendorRuntime,
+	}
+	if err := g.writeTemplate(filePath, opts.FileMode, tmpl, data); err != nil {
+		return fmt.Errorf("write registry file: %w", err)
+	}
+	return nil
+}
+
+// checkExampleInModule отклоняет относительный --example, который выходит за корень модуля.
+// Раньше путь отсчитывался от пакета, и директивы вида --example=../../configs теперь указывали
+// бы выше модуля: ошибка подсказывает путь от корня модуля для той же директории
+func checkExampleInModule(pkg *goPackage, examplePath string) error {
+	rel, err := filepath.Rel(pkg.ModuleDir, filepath.Join(pkg.ModuleDir, examplePath))
+	if err != nil || !escapesDir(rel) {
+		return err
+	}
+	hint := ""
+	if old, err := filepath.Rel(pkg.ModuleDir, filepath.Join(pkg.Dir, examplePath)); err == nil && !escapesDir(old) {
+		hint = fmt.Sprintf(" (for the directory %s relative to the package use --example=%s)", examplePath, filepath.ToSlash(old))
+	}
+	return fmt.Errorf("--example=%s is outside the module root %s: relative --example paths are resolved from the module root, not the package%s", examplePath, pkg.ModuleDir, hint)
+}
+
+// escapesDir сообщает, выходит ли относительный путь rel за свою директорию
+func escapesDir(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (g *Generator) generateExampleConfig(info *InterfaceInfo, examplePath, format string, mode os.FileMode, profiles []exampleProfile) error {
+	formats := map[string]bool{}
+	for _, f := range strings.Split(format, ",") {
+		f = strings.TrimSpace(f)
+		if f != "yaml" && f != "json" && f != "env" {
+			return fmt.Errorf("unknown example format %q (supported: yaml, json, env)", f)
+		}
+		formats[f] = true
+	}
+
+	// Создаем директорию если не существует
+	var fullOutputPath string
+	if examplePath == "" {
+		// По умолчанию - создаем в текущем пакете
+		fullOutputPath = g.path(".")
+	} else if filepath.IsAbs(examplePath) {
+		fullOutputPath = examplePath
+	} else {
+		// Путь относительно корня модуля пакета, на любой глубине вложенности пакета
+		pkg, err := resolvePackage(g.path("."))
+		if err != nil {
+			return fmt.Errorf("failed to resolve module root for --example: %w", err)
+		}
+		if err := checkExampleInModule(pkg, examplePath); err != nil {
+			return err
+		}
+		// Путь остается относительным: его печатает --check
+		rel, err := filepath.Rel(pkg.Dir, filepath.Join(pkg.ModuleDir, examplePath))
+		if err != nil {
+			return err
+		}
+		fullOutputPath = g.path(rel)
+	}
+
+	if err := g.mkdirOutput(fullOutputPath); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Значения профиля, для которого пишется пример (nil - базовый пример)
+	var profileValues map[string]string
+	funcs := template.FuncMap{
+		"title":  TitleName,
+		"envKey": func(methodName string) string { return EnvKey(info.EnvKeyPrefix(), methodName) },
+		// Ключ метода в секции, который читает YAML реализация (без алиасов)
+		"yamlKey": func(m Method) string { return strings.ToLower(m.Name) },
+		"profileValue": func(m Method) string {
+			return profileValues[m.Name]
+		},
+		// Для секретов в пример попадает ссылка на хранилище вместо значения
+		"secretPlaceholder": func(m Method) string {
+			if _, ok := m.Directive("secret"); !ok {
+				return ""
+			}
+			return strconv.Quote(runtime.SecretPlaceholder(secretRef(info, m)))
+		},
+		// Ссылка на секрет без значения профиля: без SecretResolver она не разрешается, поэтому
+		// в YAML и .env ключ закомментирован, а в JSON не пишется - пример загружается и с --strict
+		"secretOnly": func(m Method) bool {
+			_, ok := m.Directive("secret")
+			return ok && profileValues[m.Name] == ""
+		},
+		"join": strings.Join,
+		// Для перечислений в пример попадает первое допустимое значение
+		"oneOfExample": func(m Method) string {
+			if values := m.OneOf(); len(values) > 0 {
+				return strconv.Quote(values[0])
+			}
+			return ""
+		},
+		"structExample": func(m Method) string {
+			if m.Kind == KindStruct {
+				return "{}"
+			}
+			return ""
+		},
+		// Пример метки времени в формате метода; unix timestamp (ggconfig:format) принимает и RFC3339
+		"timeExample": func(m Method) string {
+			if _, ok := m.Directive("format"); ok {
+				return strconv.Quote(exampleTime.Format(time.RFC3339))
+			}
+			if m.Kind != KindTime {
+				return ""
+			}
+			return strconv.Quote(exampleTime.Format(TimeLayout(m)))
+		},
+		// Пример значения типа, который не принимает пустую строку: strict Validate отклонил бы ""
+		"kindExample": func(m Method) string {
+			switch m.Kind {
+			case KindURL:
+				return strconv.Quote("https://example.com")
+			case KindIP:
+				return strconv.Quote("127.0.0.1")
+			case KindCIDR:
+				return strconv.Quote("10.0.0.0/8")
+			}
+			return ""
+		},
+		// Документированное значение ggconfig:default=localhost
+		"defaultExample": func(m Method) string {
+			v, ok := m.Directive("default")
+			if !ok {
+				return ""
+			}
+			encoded, err := encodeProfileValue(m, v)
+			if err != nil {
+				return ""
+			}
+			return encoded
+		},
+		// bool: явное true/false (ggconfig:default) и допустимые значения в комментарии
+		"boolExample": func(m Method) string {
+			if m.ReturnType != "bool" {
+				return ""
+			}
+			return m.BoolDefault()
+		},
+		"boolDoc": func(m Method) string {
+			if m.ReturnType != "bool" {
+				return ""
+			}
+			return fmt.Sprintf("(%s; default: %s)", boolLiterals, m.BoolDefault())
+		},
+		// Значение для .env: JSON массивы и объекты в одинарных кавычках, остальное - как в YAML
+		"envValue": func(v string) string {
+			if strings.HasPrefix(v, "[") || strings.HasPrefix(v, "{") {
+				return "'" + v + "'"
+			}
+			return v
+		},
+		"defaultValue": func(paramType string) string {
+			if IsIntegerType(paramType) {
+				return "0"
+			}
+			if paramType == "bool" {
+				return "false"
+			}
+			if strings.HasPrefix(paramType, "[]") {
+				return "[]"
+			}
+			switch paramType {
+			case "time.Duration":
+				return "\"0s\""
+			case "string":
+				return "\"\""
+			default:
+				return "\"\""
+			}
+		},
+	}
+
+	// Пример со ссылками на секреты по умолчанию доступен только владельцу
+	if mode == 0 {
+		for _, m := range info.Methods {
+			if _, ok := m.Directive("secret"); ok {
+				mode = 0600
+				break
+			}
+		}
+	}
+
+	// Базовый пример и по файлу на профиль манифеста: <package>_example.<profile>.yaml
+	variants := append([]exampleProfile{{}}, profiles...)
+	for _, p := range variants {
+		profileValues = p.Values
+		suffix := ""
+		if p.Name != "" {
+			suffix = "." + p.Name
+		}
+		data := struct {
+			UniquePackageName string
+			InterfaceName     string
+			Section           string // Секция, которую читает YAML реализация (без алиасов)
+			Methods           []Method
+			Profile           string
+		}{
+			UniquePackageName: info.UniquePackageName,
+			Section:           info.PackageName,
+			InterfaceName:     info.InterfaceName,
+			Methods:           info.Methods,
+			Profile:           p.Name,
+		}
+
+		if formats["yaml"] {
+			// Генерируем файл с именованием originalfile.yaml.go
+			filePath := filepath.Join(fullOutputPath, fmt.Sprintf("%s_example%s.yaml", info.TypeName(), suffix))
+			tmpl, err := g.loadTemplate("example", "example.yaml.tmpl", funcs)
+			if err != nil {
+				return err
+			}
+			if err := g.guardOverwrite(filePath, exampleHeader); err != nil {
+				return err
+			}
+			if err := g.writeTemplate(filePath, mode, tmpl, data); err != nil {
+				return err
+			}
+		}
+		if formats["json"] {
+			// JSON без комментариев, но с той же структурой, что и YAML пример
+			var buf bytes.Buffer
+			tmpl, err := g.loadTemplate("example-json", "example.json.tmpl", funcs)
+			if err != nil {
+				return err
+			}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return err
+			}
+			var out bytes.Buffer
+			if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+				return fmt.Errorf("invalid JSON example: %w", err)
+			}
+			out.WriteByte('\n')
+			filePath := filepath.Join(fullOutputPath, fmt.Sprintf("%s_example%s.json", info.TypeName(), suffix))
+			if err := g.writeFile(filePath, out.Bytes(), mode); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", filePath, err)
+			}
+		}
+		if formats["env"] {
+			filePath := filepath.Join(fullOutputPath, fmt.Sprintf("%s_example%s.env", info.TypeName(), suffix))
+			tmpl, err := g.loadTemplate("example-env", "example.env.tmpl", funcs)
+			if err != nil {
+				return err
+			}
+			if err := g.guardOverwrite(filePath, exampleHeader); err != nil {
+				return err
+			}
+			if err := g.writeTemplate(filePath, mode, tmpl, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) writeTemplate(filePath string, mode os.FileMode, tmpl *template.Template, data any) error {
+	out, err := g.renderTemplate(filePath, tmpl, data)
+	if err != nil {
+		return err
+	}
+	if err := g.writeFile(filePath, out, mode); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// renderTemplate выполняет шаблон файла filePath; Go код форматируется
+func (g *Generator) renderTemplate(filePath string, tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	if strings.HasSuffix(filePath, ".go") {
+		return g.formatGoSource(filePath, out)
+	}
+	return out, nil
+}
+
+// Первые строки файлов, которые ggconfig считает своими и перезаписывает без --force
+const (
+	GeneratedHeader = "// Code generated by ggconfig. DO NOT EDIT."
+	exampleHeader   = "# Example configuration for "
+)
+
+// guardOverwrite отказывается перезаписывать существующий непустой файл, который начинается
+// не с header: например, вручную написанный файл, переименованный в <package>.gen.go,
+// или собственный YAML по пути примера. В режиме --check файлы не записываются и проверка
+// не нужна, а в режиме --dry-run отказ сообщается так же, как при записи
+func (g *Generator) guardOverwrite(filePath, header string) error {
+	if g.opts.Force || (g.opts.Check && !g.opts.DryRun) {
+		return nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte(header)) {
+		return nil
+	}
+	return fmt.Errorf("refusing to overwrite %s: it was not generated by ggconfig (no %q header); move it away or pass --force", filePath, strings.TrimSpace(header))
+}
+
+// contentHashPrefix - вторая строка сгенерированных Go файлов: sha256 вывода генератора без
+// этой строки. Если заголовок существующего файла совпадает с новым, а хеш - с его телом
+// (см. fileUpToDate), перезапись ничего не изменит
+const contentHashPrefix = "// Content hash: sha256:"
+
+// checkFile сравнивает содержимое файла с существующим в режимах --check и --dry-run:
+// устаревшие и отсутствующие файлы собираются в Result.Stale, а в режиме --dry-run
+// разница печатается в Options.Log
+func (g *Generator) checkFile(filePath string, data []byte) {
+	g.result.Files = append(g.result.Files, filePath)
+	existing, err := os.ReadFile(filePath)
+	if err != nil || !bytes.Equal(existing, data) {
+		g.result.Stale = append(g.result.Stale, filePath)
+		if g.opts.DryRun {
+			fmt.Fprint(g.log(), unifiedDiff(filePath, existing, data, err == nil))
+		}
+	}
+}
+
+// createFile создает временный файл для записи filePath в той же директории. Если mode задан,
+// права выставляются явно, без учета umask; 0 - права существующего файла, для нового -
+// как у os.Create.
+func createFile(filePath string, mode os.FileMode) (*os.File, error) {
+	perm, explicit := os.FileMode(0666), mode != 0
+	if explicit {
+		perm = mode
+	} else if st, err := os.Stat(filePath); err == nil {
+		perm, explicit = st.Mode().Perm(), true
+	}
+	dir, base := filepath.Split(filePath)
+	for i := 0; ; i++ {
+		// Имя с точкой в начале: go build игнорирует файл, если запуск прервется
+		tmp := filepath.Join(dir, fmt.Sprintf(".%s.%d-%d.tmp", base, os.Getpid(), i))
+		f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if explicit {
+			if err := f.Chmod(perm); err != nil {
+				f.Close()
+				os.Remove(tmp)
+				return nil, err
+			}
+		}
+		return f, nil
+	}
+}
+
+// mkdirOutput создает директорию для сгенерированных файлов (в режиме --check ничего не создается)
+func (g *Generator) mkdirOutput(dir string) error {
+	if g.checkOnly() {
+		return nil
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// writeFile - аналог os.WriteFile с правами из createFile. Содержимое записывается во временный
+// файл и переименовывается: параллельные запуски в одну директорию (make -j) и читатели видят
+// файл целиком - прежний или новый, а не перемешанные записи
+func (g *Generator) writeFile(filePath string, data []byte, mode os.FileMode) error {
+	// Одинаковый вывод на всех платформах: \r\n из исходников и шаблонов не попадает в файлы
+	data = withContentHash(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")))
+	if strings.HasSuffix(filePath, ".go") {
+		if g.goSources == nil {
+			g.goSources = map[string][]byte{}
+		}
+		g.goSources[filePath] = data
+	}
+	if g.checkOnly() {
+		g.checkFile(filePath, data)
+		return nil
+	}
+	g.result.Files = append(g.result.Files, filePath)
+	// Актуальный файл не перезаписывается: время изменения не меняется, и go build не пересобирает
+	// пакет. --force перезаписывает всегда
+	if !g.opts.Force && fileUpToDate(filePath, data, mode) {
+		g.result.Unchanged = append(g.result.Unchanged, filePath)
+		return nil
+	}
+	f, err := createFile(filePath, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// withContentHash добавляет после заголовка GeneratedHeader строку contentHashPrefix с хешем
+// содержимого. Файлы без заголовка (примеры конфигураций, JSON описания) не меняются
+func withContentHash(data []byte) []byte {
+	header := []byte(GeneratedHeader + "\n")
+	if !bytes.HasPrefix(data, header) {
+		return data
+	}
+	sum := sha256.Sum256(data)
+	out := make([]byte, 0, len(data)+len(contentHashPrefix)+2*len(sum)+1)
+	out = append(out, header...)
+	out = append(out, contentHashPrefix+hex.EncodeToString(sum[:])+"\n"...)
+	return append(out, data[len(header):]...)
+}
+
+// fileUpToDate сообщает, что filePath уже содержит data и права не нужно менять (mode 0 - права
+// не задаются). У файлов с хешем содержимого заголовок должен совпасть с новым, а хеш - с телом
+// существующего файла: ручная правка того же размера иначе осталась бы незамеченной. Остальные
+// файлы сравниваются целиком
+func fileUpToDate(filePath string, data []byte, mode os.FileMode) bool {
+	st, err := os.Stat(filePath)
+	if err != nil || !st.Mode().IsRegular() || st.Size() != int64(len(data)) || (mode != 0 && st.Mode().Perm() != mode) {
+		return false
+	}
+	existing, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+	if !bytes.HasPrefix(data, []byte(GeneratedHeader+"\n"+contentHashPrefix)) {
+		return bytes.Equal(existing, data)
+	}
+	// Первые две строки: заголовок и хеш
+	n := len(GeneratedHeader) + 1
+	n += bytes.IndexByte(data[n:], '\n') + 1
+	return bytes.Equal(existing[:n], data[:n]) && contentHashValid(existing)
+}
+
+// contentHashValid пересчитывает хеш содержимого файла без строки contentHashPrefix и сравнивает
+// его с записанным во второй строке
+func contentHashValid(data []byte) bool {
+	header := []byte(GeneratedHeader + "\n" + contentHashPrefix)
+	if !bytes.HasPrefix(data, header) {
+		return false
+	}
+	end := bytes.IndexByte(data[len(header):], '\n')
+	if end < 0 {
+		return false
+	}
+	recorded := string(data[len(header) : len(header)+end])
+	body := append([]byte(GeneratedHeader+"\n"), data[len(header)+end+1:]...)
+	sum := sha256.Sum256(body)
+	return recorded == hex.EncodeToString(sum[:])
+}
+
+func toEnvKey(methodName string) string {
+	// Преобразуем имя метода в ключ переменной окружения
+	// Например: Host -> HOST, SSLMode -> SSL_MODE, UserName -> USER_NAME, ÜberName -> ÜBER_NAME.
+	// Работаем с рунами: имена методов могут содержать не-ASCII буквы
+	runes := []rune(methodName)
+	var result strings.Builder
+	for i, r := range runes {
+		// Подчеркивание перед заглавной буквой, за которой идет строчная: начало слова,
+		// в том числе после аббревиатуры (SSLMode -> SSL_MODE)
+		if i > 0 && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			result.WriteByte('_')
+		}
+		result.WriteRune(unicode.ToUpper(r))
+	}
+	return result.String()
+}
+
+// TitleName убирает подчеркивания и переводит в верхний регистр первую букву каждой части:
+// internal_db -> InternalDb, über_db -> ÜberDb. Правила те же, что у устаревшего strings.Title,
+// которым строились имена конструкторов раньше: буква после цифры не меняется
+// (http2server -> Http2server), а после ASCII знаков вроде '-' и '.' - меняется. Поэтому имена
+// конструкторов в уже сгенерированном коде остаются прежними
+func TitleName(s string) string {
+	var result strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		prev := ' '
+		for _, r := range part {
+			if isTitleSeparator(prev) {
+				r = unicode.ToTitle(r)
+			}
+			result.WriteRune(r)
+			prev = r
+		}
+	}
+	return result.String()
+}
+
+// isTitleSeparator - граница слова для TitleName, как в strings.Title: ASCII буквы, цифры и
+// подчеркивание слово продолжают, остальные ASCII символы его завершают; из не-ASCII
+// символов границей служат только пробелы
+func isTitleSeparator(r rune) bool {
+	if r <= 0x7F {
+		switch {
+		case '0' <= r && r <= '9', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_':
+			return false
+		}
+		return true
+	}
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
+func EnvKey(packageName, methodName string) string {
+	// Добавляем префикс пакета к ключу
+	prefix := strings.ToUpper(packageName)
+	return prefix + "_" + toEnvKey(methodName)
+}
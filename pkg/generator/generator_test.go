@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileUpToDateDetectsSameSizeEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.gen.go")
+	data := withContentHash([]byte(GeneratedHeader + "\n\npackage server\n\nconst key = \"SERVER_PORT\"\n"))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !fileUpToDate(path, data, 0) {
+		t.Fatal("unchanged file reported as stale")
+	}
+
+	// Правка того же размера: заголовок и хеш прежние, тело другое
+	edited := bytes.Replace(data, []byte("SERVER_PORT"), []byte("SERVER_PORX"), 1)
+	if len(edited) != len(data) {
+		t.Fatal("edit changed the size")
+	}
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fileUpToDate(path, data, 0) {
+		t.Fatal("same-size hand edit reported as up to date")
+	}
+}
+
+func TestFileUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	hashed := withContentHash([]byte(GeneratedHeader + "\n\npackage server\n"))
+	tests := []struct {
+		name     string
+		existing []byte
+		mode     os.FileMode
+		data     []byte
+		want     bool
+	}{
+		{"hashed same", hashed, 0, hashed, true},
+		{"hashed other content", hashed, 0, withContentHash([]byte(GeneratedHeader + "\n\npackage client\n")), false},
+		{"plain same", []byte("a: 1\n"), 0, []byte("a: 1\n"), true},
+		{"plain same size edit", []byte("a: 2\n"), 0, []byte("a: 1\n"), false},
+		{"other mode", hashed, 0600, hashed, false},
+		{"missing", nil, 0, hashed, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if tt.existing != nil {
+				if err := os.WriteFile(path, tt.existing, 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(path, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := fileUpToDate(path, tt.data, tt.mode); got != tt.want {
+				t.Errorf("case %d: fileUpToDate = %v, want %v", i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFileRewritesCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.gen.go")
+	src := []byte(GeneratedHeader + "\n\npackage server\n\nconst key = \"SERVER_PORT\"\n")
+	g := &Generator{result: &Result{}}
+	if err := g.writeFile(path, src, 0); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, bytes.Replace(written, []byte("SERVER_PORT"), []byte("SERVER_PORX"), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.writeFile(path, src, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.result.Unchanged) != 0 {
+		t.Fatalf("corrupted file skipped as unchanged: %v", g.result.Unchanged)
+	}
+	repaired, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(repaired, written) {
+		t.Fatal("corrupted file was not rewritten")
+	}
+}
+
+func TestCheckExampleInModule(t *testing.T) {
+	root := filepath.FromSlash("/src/app")
+	pkg := &goPackage{Dir: filepath.Join(root, "internal", "server"), ModuleDir: root}
+	tests := []struct {
+		example string
+		err     string
+	}{
+		{"configs", ""},
+		{"deploy/../configs", ""},
+		{"..", "outside the module root"},
+		// Путь от пакета из старых директив: подсказка указывает тот же каталог от корня модуля
+		{"../../configs", "use --example=configs"},
+		{"../../../configs", "resolved from the module root"},
+	}
+	for _, tt := range tests {
+		err := checkExampleInModule(pkg, filepath.FromSlash(tt.example))
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.example, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error = %v, want %q", tt.example, err, tt.err)
+		}
+	}
+}
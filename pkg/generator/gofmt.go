@@ -0,0 +1,284 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatGoSource приводит сгенерированный код к виду gofmt и убирает неиспользуемые импорты:
+// шаблон не отслеживает, какие пакеты нужны при каждом сочетании типов методов. Если код не
+// разбирается, исходный вывод шаблона сохраняется в <filePath>.bad для отладки
+func (g *Generator) formatGoSource(filePath string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err == nil {
+		return format.Source(pruneImports(fset, file, src))
+	}
+	bad := filePath + ".bad"
+	if g.checkOnly() {
+		return nil, fmt.Errorf("generated code for %s does not parse (this is a ggconfig bug, please report it): %w", filePath, err)
+	}
+	if werr := os.WriteFile(bad, src, 0o644); werr != nil {
+		return nil, fmt.Errorf("generated code for %s does not parse (this is a ggconfig bug, please report it): %w (saving the raw output: %v)", filePath, err, werr)
+	}
+	return nil, fmt.Errorf("generated code for %s does not parse (this is a ggconfig bug, please report it; raw output saved to %s): %w", filePath, bad, err)
+}
+
+// pruneImports возвращает src файла file, в котором объявления импортов заменены одним блоком
+// используемых импортов, сгруппированных как у goimports: стандартная библиотека, пустая строка,
+// остальные пакеты, внутри групп - по пути. Имя пакета без алиаса берется по пути импорта
+// (yaml.v3 - yaml, go-foo - foo); импорт удаляется, только если не используется ни одно из
+// возможных имен, поэтому пакет с непредсказуемым именем не теряется
+func pruneImports(fset *token.FileSet, file *ast.File, src []byte) []byte {
+	used := packageRefs(file)
+	var kept []*ast.ImportSpec
+	var decls []*ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		decls = append(decls, gen)
+		for _, spec := range gen.Specs {
+			if imp := spec.(*ast.ImportSpec); importUsed(imp, used) {
+				kept = append(kept, imp)
+			}
+		}
+	}
+	if len(decls) == 0 {
+		return src
+	}
+
+	// Объявления импортов вырезаются с конца, чтобы смещения предыдущих не сдвигались;
+	// новый блок встает на место первого
+	out := append([]byte{}, src...)
+	for i := len(decls) - 1; i >= 0; i-- {
+		start, end := fset.Position(decls[i].Pos()).Offset, fset.Position(decls[i].End()).Offset
+		var block []byte
+		if i == 0 {
+			block = importBlock(src, fset, kept)
+		}
+		out = append(out[:start], append(block, out[end:]...)...)
+	}
+	return out
+}
+
+// importBlock записывает импорты specs блоком: стандартная библиотека, затем остальные пакеты
+func importBlock(src []byte, fset *token.FileSet, specs []*ast.ImportSpec) []byte {
+	if len(specs) == 0 {
+		return nil
+	}
+	path := func(imp *ast.ImportSpec) string {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return imp.Path.Value
+		}
+		return p
+	}
+	sorted := append([]*ast.ImportSpec{}, specs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return path(sorted[i]) < path(sorted[j]) })
+	text := func(imp *ast.ImportSpec) string {
+		return string(src[fset.Position(imp.Pos()).Offset:fset.Position(imp.End()).Offset])
+	}
+	if len(sorted) == 1 {
+		return []byte("import " + text(sorted[0]))
+	}
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, std := range []bool{true, false} {
+		group := 0
+		for _, imp := range sorted {
+			if isStdImport(path(imp)) != std {
+				continue
+			}
+			if !std && group == 0 && b.Len() > len("import (\n") {
+				b.WriteString("\n")
+			}
+			group++
+			b.WriteString("\t" + text(imp) + "\n")
+		}
+	}
+	b.WriteString(")")
+	return []byte(b.String())
+}
+
+// packageRefs собирает имена X селекторов X.Sel, которые ссылаются на импортированные пакеты:
+// X не объявлен в охватывающей области видимости (параметр, локальная переменная, ...).
+// Области видимости отслеживаются по блокам, как у goimports, без устаревшего ast.Ident.Obj.
+// Объявления верхнего уровня не учитываются: совпасть с именем импорта они не могут
+func packageRefs(file *ast.File) map[string]bool {
+	used := map[string]bool{}
+	var walk func(n ast.Node, sc *scope)
+	// walkFunc обходит функцию: типы сигнатуры - во внешней области, тело - в области параметров
+	walkFunc := func(recv *ast.FieldList, typ *ast.FuncType, body *ast.BlockStmt, sc *scope) {
+		inner := sc.open()
+		for _, list := range []*ast.FieldList{recv, typ.TypeParams, typ.Params, typ.Results} {
+			if list == nil {
+				continue
+			}
+			for _, f := range list.List {
+				walk(f.Type, sc)
+				inner.declare(f.Names...)
+			}
+		}
+		if body != nil {
+			walk(body, inner)
+		}
+	}
+	walk = func(n ast.Node, sc *scope) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if id, ok := n.X.(*ast.Ident); ok {
+					if !sc.has(id.Name) {
+						used[id.Name] = true
+					}
+					return false
+				}
+			case *ast.FuncDecl:
+				walkFunc(n.Recv, n.Type, n.Body, sc)
+				return false
+			case *ast.FuncLit:
+				walkFunc(nil, n.Type, n.Body, sc)
+				return false
+			case *ast.BlockStmt:
+				inner := sc.open()
+				for _, stmt := range n.List {
+					walk(stmt, inner)
+				}
+				return false
+			case *ast.AssignStmt:
+				// x := x.Method(): правая часть видит внешнее x
+				for _, e := range n.Rhs {
+					walk(e, sc)
+				}
+				for _, e := range n.Lhs {
+					if id, ok := e.(*ast.Ident); ok && n.Tok == token.DEFINE {
+						sc.declare(id)
+					} else {
+						walk(e, sc)
+					}
+				}
+				return false
+			case *ast.ValueSpec:
+				if n.Type != nil {
+					walk(n.Type, sc)
+				}
+				for _, e := range n.Values {
+					walk(e, sc)
+				}
+				sc.declare(n.Names...)
+				return false
+			case *ast.TypeSpec:
+				sc.declare(n.Name)
+				walk(n.Type, sc)
+				return false
+			case *ast.RangeStmt:
+				walk(n.X, sc)
+				inner := sc.open()
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if id, ok := e.(*ast.Ident); ok && n.Tok == token.DEFINE {
+						inner.declare(id)
+					} else if e != nil {
+						walk(e, sc)
+					}
+				}
+				walk(n.Body, inner)
+				return false
+			case *ast.IfStmt, *ast.ForStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.CaseClause, *ast.CommClause:
+				// Переменные из init и case видны только внутри оператора
+				inner := sc.open()
+				ast.Inspect(n, func(child ast.Node) bool {
+					if child == n {
+						return true
+					}
+					if child != nil {
+						walk(child, inner)
+					}
+					return false
+				})
+				return false
+			}
+			return true
+		})
+	}
+	for _, decl := range file.Decls {
+		walk(decl, nil)
+	}
+	return used
+}
+
+// scope - область видимости локальных имен для packageRefs
+type scope struct {
+	outer *scope
+	names map[string]bool
+}
+
+func (s *scope) open() *scope {
+	return &scope{outer: s, names: map[string]bool{}}
+}
+
+func (s *scope) declare(idents ...*ast.Ident) {
+	if s == nil {
+		// Объявления верхнего уровня
+		return
+	}
+	for _, id := range idents {
+		if id.Name != "_" {
+			s.names[id.Name] = true
+		}
+	}
+}
+
+func (s *scope) has(name string) bool {
+	for ; s != nil; s = s.outer {
+		if s.names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// importUsed сообщает, используется ли импорт: пустые и точечные импорты сохраняются всегда
+func importUsed(imp *ast.ImportSpec, used map[string]bool) bool {
+	if imp.Name != nil {
+		return imp.Name.Name == "_" || imp.Name.Name == "." || used[imp.Name.Name]
+	}
+	path, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return true
+	}
+	for _, name := range importNames(path) {
+		if used[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// importNames перечисляет возможные имена пакета без алиаса по пути импорта
+func importNames(path string) []string {
+	elems := strings.Split(path, "/")
+	last := elems[len(elems)-1]
+	// Суффикс мажорной версии модуля: example.com/mod/v2 - пакет mod
+	if len(elems) > 1 && len(last) > 1 && last[0] == 'v' && strings.Trim(last[1:], "0123456789") == "" {
+		last = elems[len(elems)-2]
+	}
+	names := []string{last}
+	if base, _, ok := strings.Cut(last, "."); ok {
+		// gopkg.in/yaml.v3 - yaml
+		names = append(names, base)
+	}
+	for _, n := range append([]string{}, names...) {
+		n = strings.TrimPrefix(strings.TrimSuffix(n, "-go"), "go-")
+		names = append(names, n, strings.ReplaceAll(n, "-", ""), strings.ReplaceAll(n, "-", "_"))
+	}
+	return names
+}
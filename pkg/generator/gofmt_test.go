@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestPruneImports(t *testing.T) {
+	src := `package svc
+
+import (
+	"context"
+	"github.com/apopov-app/ggconfig/runtime"
+	"net/url"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"strings"
+	"time"
+	str "strconv"
+)
+
+func parse(raw string) (*url.URL, error) {
+	url, err := url.Parse(raw)
+	return url, err
+}
+
+func first(strings []string) string {
+	// strings - параметр, пакет strings не используется
+	return strings[0]
+}
+
+func node(n yaml.Node) string {
+	if os := n.Value; os != "" {
+		return os
+	}
+	for _, runtime := range []string{"a"} {
+		_ = runtime
+	}
+	return str.Quote(os.Getenv("X"))
+}
+
+func wait(d time.Duration) {
+	var context = d.String()
+	_ = context
+	_ = runtime.ErrNotSet
+}
+`
+	want := `package svc
+
+import (
+	"net/url"
+	"os"
+	str "strconv"
+	"time"
+
+	"github.com/apopov-app/ggconfig/runtime"
+	"gopkg.in/yaml.v3"
+)
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := format.Source(pruneImports(fset, file, []byte(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out[:len(want)]); got != want {
+		t.Errorf("imports:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Единственный импорт пишется без скобок, без используемых импортов блок удаляется
+	for src, want := range map[string]string{
+		"package svc\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nvar _ = os.Args\n": "package svc\n\nimport \"os\"\n\nvar _ = os.Args\n",
+		"package svc\n\nimport (\n\t\"os\"\n)\n\nvar x = 1\n":                  "package svc\n\nvar x = 1\n",
+	} {
+		file, err := parser.ParseFile(fset, "svc.go", src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := format.Source(pruneImports(fset, file, []byte(src)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != want {
+			t.Errorf("pruneImports(%q) = %q, want %q", src, out, want)
+		}
+	}
+}
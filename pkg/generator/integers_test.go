@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// integerBounds - границы целочисленных типов: значения на границе принимаются, за ней - нет
+var integerBounds = []struct {
+	typ                 string
+	min, max            string
+	underflow, overflow string
+}{
+	{"int", "-9223372036854775808", "9223372036854775807", "-9223372036854775809", "9223372036854775808"},
+	{"int8", "-128", "127", "-129", "128"},
+	{"int16", "-32768", "32767", "-32769", "32768"},
+	{"int32", "-2147483648", "2147483647", "-2147483649", "2147483648"},
+	{"int64", "-9223372036854775808", "9223372036854775807", "-9223372036854775809", "9223372036854775808"},
+	{"uint", "0", "18446744073709551615", "-1", "18446744073709551616"},
+	{"uint8", "0", "255", "-1", "256"},
+	{"uint16", "0", "65535", "-1", "65536"},
+	{"uint32", "0", "4294967295", "-1", "4294967296"},
+	{"uint64", "0", "18446744073709551615", "-1", "18446744073709551616"},
+}
+
+func TestIntegerDirectiveRange(t *testing.T) {
+	// ggconfig:unset, ggconfig:default и значения профилей проверяются по разрядности типа метода
+	for _, b := range integerBounds {
+		for _, directive := range []string{"unset", "default"} {
+			for _, v := range []string{b.min, b.max, b.underflow, b.overflow} {
+				m := Method{Name: "Port", ParamType: b.typ, ReturnType: b.typ, Directives: map[string]string{directive: v}}
+				err := checkMethods(&InterfaceInfo{Methods: []Method{m}}, Options{})
+				valid := v == b.min || v == b.max
+				if valid && err != nil {
+					t.Errorf("%s ggconfig:%s=%s: %v", b.typ, directive, v, err)
+				}
+				if !valid && (err == nil || !strings.Contains(err.Error(), b.typ)) {
+					t.Errorf("%s ggconfig:%s=%s: error = %v, want an out of range error", b.typ, directive, v, err)
+				}
+			}
+		}
+		m := Method{Name: "Port", ParamType: b.typ, ReturnType: b.typ}
+		if v, err := ParseValue(m, b.max); err != nil || fmt.Sprint(v) != b.max {
+			t.Errorf("ParseValue(%s, %s) = %v, %v", b.typ, b.max, v, err)
+		}
+		if _, err := ParseValue(m, b.overflow); err == nil {
+			t.Errorf("ParseValue(%s, %s) accepted the overflow", b.typ, b.overflow)
+		}
+		if _, err := ParseValue(m, b.underflow); err == nil {
+			t.Errorf("ParseValue(%s, %s) accepted the underflow", b.typ, b.underflow)
+		}
+	}
+}
+
+// integerConfig объявляет по методу на каждый целочисленный тип (Int, Int8, ..., Uint64) и списки Int8s, Uint16s
+func integerConfig() string {
+	var b strings.Builder
+	b.WriteString("package svc\n\ntype Config interface {\n")
+	for _, bound := range integerBounds {
+		fmt.Fprintf(&b, "\t%s(defaultValue %s) (%s, bool)\n", strings.ToUpper(bound.typ[:1])+bound.typ[1:], bound.typ, bound.typ)
+	}
+	b.WriteString("\tInt8s(defaultValue []int8) ([]int8, bool)\n\tUint16s(defaultValue []uint16) ([]uint16, bool)\n}\n")
+	return b.String()
+}
+
+// integerConfigTest проверяет сгенерированные ENV и YAML реализации на границах типов: значение
+// за границей - ошибка разбора (Validate), метод возвращает default
+func integerConfigTest() string {
+	var cases strings.Builder
+	for _, b := range integerBounds {
+		name := strings.ToUpper(b.typ[:1]) + b.typ[1:]
+		fmt.Fprintf(&cases, "\t{%q, %q, %q, %q, %q, func(c Config) (string, bool) { v, ok := c.%s(7); return fmt.Sprint(v), ok }},\n",
+			name, b.min, b.max, b.underflow, b.overflow, name)
+	}
+	return `package svc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+var bounds = []struct {
+	name                string
+	min, max            string
+	underflow, overflow string
+	get                 func(Config) (string, bool)
+}{
+` + cases.String() + `}
+
+func TestIntegerBounds(t *testing.T) {
+	// Обработчик, который не паникует: некорректное значение пропускается
+	prev := runtime.SetParseErrorHandler(func(*runtime.ParseError) {})
+	defer runtime.SetParseErrorHandler(prev)
+
+	for _, b := range bounds {
+		key := strings.ToLower(b.name)
+		for _, v := range []string{b.min, b.max, b.underflow, b.overflow} {
+			valid := v == b.min || v == b.max
+			y, err := runtime.ParseYAML([]byte("svc:\n  " + key + ": " + v + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			envKey := "SVC_" + strings.ToUpper(b.name)
+			env := NewSvcConfigEnvConfigWithLookup(nil, func(k string) (string, bool) {
+				if k == envKey {
+					return v, true
+				}
+				return "", false
+			})
+			for source, cfg := range map[string]*svcAllConfig{
+				envKey:       NewSvcConfigAll(env),
+				"svc." + key: NewSvcConfigAll(NewSvcConfigYAMLConfigParsed(y)),
+			} {
+				got, ok := b.get(cfg)
+				if valid && (!ok || got != v) {
+					t.Errorf("%s=%s: %s() = %s, %v; want the value", source, v, b.name, got, ok)
+				}
+				if !valid && (ok || got != "7") {
+					t.Errorf("%s=%s: %s() = %s, %v; want the default", source, v, b.name, got, ok)
+				}
+				err := cfg.Validate()
+				var perr *runtime.ParseError
+				if valid && err != nil {
+					t.Errorf("%s=%s: Validate = %v", source, v, err)
+				}
+				if !valid && (!errors.As(err, &perr) || perr.Key != source) {
+					t.Errorf("%s=%s: Validate = %v, want a *runtime.ParseError for %s", source, v, err, source)
+				}
+			}
+		}
+	}
+}
+
+func TestIntegerListBounds(t *testing.T) {
+	prev := runtime.SetParseErrorHandler(func(*runtime.ParseError) {})
+	defer runtime.SetParseErrorHandler(prev)
+
+	// Список с элементом за границей типа пропускается целиком
+	tests := []struct {
+		env, yaml string
+		int8s     string
+		uint16s   string
+	}{
+		{"-128,127", "[-128, 127]", "[-128 127]", "[7]"},
+		{"1,128", "[1, 128]", "[7]", "[1 128]"},
+		{"0,65535", "[0, 65535]", "[7]", "[0 65535]"},
+		{"0,65536", "[0, 65536]", "[7]", "[7]"},
+		{"1,-1", "[1, -1]", "[1 -1]", "[7]"},
+	}
+	for _, tt := range tests {
+		y, err := runtime.ParseYAML([]byte("svc:\n  int8s: " + tt.yaml + "\n  uint16s: " + tt.yaml + "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		env := NewSvcConfigEnvConfigWithLookup(nil, func(k string) (string, bool) {
+			if k == "SVC_INT8S" || k == "SVC_UINT16S" {
+				return tt.env, true
+			}
+			return "", false
+		})
+		for source, cfg := range map[string]Config{"env": env, "yaml": NewSvcConfigYAMLConfigParsed(y)} {
+			i8, _ := cfg.Int8s([]int8{7})
+			u16, _ := cfg.Uint16s([]uint16{7})
+			if fmt.Sprint(i8) != tt.int8s || fmt.Sprint(u16) != tt.uint16s {
+				t.Errorf("%s %s: Int8s = %v, Uint16s = %v; want %s, %s", source, tt.env, i8, u16, tt.int8s, tt.uint16s)
+			}
+		}
+	}
+}
+`
+}
+
+func TestIntegerBounds(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{"svc/config.go": integerConfig()})
+	opts := Options{Dir: filepath.Join(dir, "svc"), Interface: "Config", Sources: []string{"env", "yaml", "composite"}, Strict: true}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "svc", "integers_test.go"), []byte(integerConfigTest()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Блокировка выходной директории: go:generate нескольких пакетов с общим --output
+// (make -j, go generate в параллельных процессах) пишут общие registry.gen.go и
+// ggconfig_runtime.gen.go. Запуски выполняются по очереди, и директория всегда содержит
+// файлы одного завершенного запуска.
+const (
+	outputLockFile = ".ggconfig.lock"
+	// Генерация занимает доли секунды: файл старше outputLockStale оставлен прерванным запуском
+	outputLockStale   = time.Minute
+	outputLockTimeout = 2 * time.Minute
+)
+
+// lockOutputDir ждет и берет блокировку директории dir; возвращаемая функция ее снимает.
+// Блокировка - файл, созданный с O_EXCL: так она работает одинаково на всех ОС и файловых
+// системах. В файле - метка владельца: запуск снимает только свою блокировку, даже если ее
+// успели признать устаревшей
+func (g *Generator) lockOutputDir(dir string) (func(), error) {
+	if g.checkOnly() {
+		return func() {}, nil
+	}
+	path := filepath.Join(dir, outputLockFile)
+	owner := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	deadline := time.Now().Add(outputLockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintln(f, owner)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("lock output directory %s: %w", dir, err)
+			}
+			return func() {
+				if lockOwner(path) == owner {
+					os.Remove(path)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("lock output directory %s: %w", dir, err)
+		}
+		if lockStale(path) && breakStaleLock(path) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("output directory %s is locked by another ggconfig run (%s); remove the file if no generation is running", dir, path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// breakStaleLock удаляет блокировку path, оставленную прерванным запуском. Устаревший файл
+// могут одновременно увидеть несколько запусков: без защиты второй удалил бы свежую
+// блокировку, которую первый успел создать вместо устаревшей. Поэтому файл удаляется
+// только под отдельной блокировкой (<path>.break) и после повторной проверки
+func breakStaleLock(path string) bool {
+	guard := path + ".break"
+	f, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		// Защита держится на время одной проверки: устаревшую оставил запуск, прерванный в этот момент
+		if lockStale(guard) {
+			os.Remove(guard)
+		}
+		return false
+	}
+	f.Close()
+	defer os.Remove(guard)
+	if !lockStale(path) {
+		return false
+	}
+	return os.Remove(path) == nil
+}
+
+// lockStale сообщает, что файл блокировки оставлен прерванным запуском
+func lockStale(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && time.Since(st.ModTime()) > outputLockStale
+}
+
+// lockOwner возвращает метку владельца из файла блокировки (пустая строка - файла нет)
+func lockOwner(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
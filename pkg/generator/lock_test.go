@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockOutputDirStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, outputLockFile)
+	if err := os.WriteFile(path, []byte("1-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * outputLockStale)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Устаревшую блокировку видят все запуски сразу, но держит ее всегда один
+	g := New(Options{})
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := g.lockOutputDir(dir)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&active, 1)
+			for m := atomic.LoadInt32(&maxActive); n > m && !atomic.CompareAndSwapInt32(&maxActive, m, n); m = atomic.LoadInt32(&maxActive) {
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+	if maxActive != 1 {
+		t.Errorf("%d runs held the lock at once, want one", maxActive)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left after all runs: %v", err)
+	}
+	if _, err := os.Stat(path + ".break"); !os.IsNotExist(err) {
+		t.Errorf("break guard left after all runs: %v", err)
+	}
+
+	// Запуск, который увидел устаревший файл до его замены свежей блокировкой, ее не удаляет
+	unlock, err := g.lockOutputDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	if breakStaleLock(path) || lockOwner(path) == "" {
+		t.Error("a fresh lock was broken as stale")
+	}
+}
+
+func TestLockOutputDirOwner(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, outputLockFile)
+	unlock, err := New(Options{}).lockOutputDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Блокировку признали устаревшей и взял другой запуск: снятие своей не удаляет чужую
+	if err := os.WriteFile(path, []byte("1-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if owner := lockOwner(path); owner != "1-1" {
+		t.Errorf("lock owner = %q after unlock, want the other run kept", owner)
+	}
+}
@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifest - файл --manifest, общий для всех пакетов сервиса:
+//
+//	aliases:
+//	  - yaml.section=jwt
+//	profiles:
+//	  dev:
+//	    server:
+//	      port: 8080
+//	  prod:
+//	    server:
+//	      port: 80
+//
+// aliases дополняют флаги --alias, profiles задают значения примеров по окружениям, packages -
+// настройки отдельных пакетов и интерфейсов вместо флагов директив:
+//
+//	packages:
+//	  internal/server:           # директория пакета относительно корня модуля
+//	    output: internal/gconfig # тоже относительно корня модуля
+//	    registry: true
+//	    aliases: [env.Host=SERVER_ADDR]
+//	  internal/server.Admin:     # только интерфейс Admin пакета
+//	    env_prefix: ADMIN
+//
+// Без --manifest читается ggconfig.yaml в корне модуля, если он есть
+type manifest struct {
+	Aliases  []string                             `yaml:"aliases"`
+	Profiles map[string]map[string]map[string]any `yaml:"profiles"`
+	Packages map[string]packageSettings           `yaml:"packages"`
+
+	path string // Файл манифеста для сообщений об ошибках
+}
+
+// ProjectManifestFile - манифест в корне модуля, который читается без --manifest
+const ProjectManifestFile = "ggconfig.yaml"
+
+// packageSettings - настройки пакета или интерфейса из манифеста; флаги директивы важнее
+type packageSettings struct {
+	Output    string   `yaml:"output"`
+	Registry  *bool    `yaml:"registry"`
+	Example   string   `yaml:"example"`
+	Sources   []string `yaml:"sources"`
+	EnvPrefix string   `yaml:"env_prefix"`
+	Aliases   []string `yaml:"aliases"`
+}
+
+// exampleProfile - значения одного профиля для примера пакета: метод -> значение в JSON
+// (JSON - допустимый YAML, поэтому подходит для обоих форматов примера)
+type exampleProfile struct {
+	Name   string
+	Values map[string]string
+}
+
+func loadManifest(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.path = path
+	// Ошибки сообщаются об одной и той же записи при каждом запуске
+	for _, name := range sortedKeys(m.Profiles) {
+		if name == "" || strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789_-") != "" {
+			return nil, fmt.Errorf("%s: profile name %q must be lowercase letters, digits, '-' or '_'", path, name)
+		}
+	}
+	for _, key := range sortedKeys(m.Packages) {
+		s := m.Packages[key]
+		if key == "" || filepath.IsAbs(key) {
+			return nil, fmt.Errorf("%s: packages: key %q must be a package directory relative to the module root", path, key)
+		}
+		if s.Output != "" && filepath.IsAbs(s.Output) {
+			return nil, fmt.Errorf("%s: packages: %s: output %q must be relative to the module root", path, key, s.Output)
+		}
+		if err := checkEnvPrefix(s.EnvPrefix); err != nil {
+			return nil, fmt.Errorf("%s: packages: %s: env_prefix: %w", path, key, err)
+		}
+		for _, name := range s.Sources {
+			if findSource(name) == nil {
+				return nil, fmt.Errorf("%s: packages: %s: unknown source %q (sources: %s)", path, key, name, strings.Join(sourceNames(), ", "))
+			}
+		}
+	}
+	return &m, nil
+}
+
+// loadProjectManifest загружает манифест директивы в dir: path из --manifest относительно dir
+// или ggconfig.yaml в корне модуля. Без файла возвращает nil; moduleDir - корень модуля, от
+// которого отсчитываются ключи и пути packages (без go.mod - директория манифеста)
+func loadProjectManifest(dir, path string) (mf *manifest, moduleDir string, err error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	moduleDir, err = FindModuleRoot(abs)
+	if path == "" {
+		if err != nil {
+			return nil, "", nil
+		}
+		path = filepath.Join(moduleDir, ProjectManifestFile)
+		if _, err := os.Stat(path); err != nil {
+			return nil, "", nil
+		}
+	} else {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(abs, path)
+		}
+		if err != nil {
+			moduleDir = filepath.Dir(path)
+		}
+	}
+	if mf, err = loadManifest(path); err != nil {
+		return nil, "", err
+	}
+	return mf, moduleDir, nil
+}
+
+// settings возвращает настройки интерфейса iface пакета pkgDir: запись "<dir>.<Interface>",
+// иначе запись пакета "<dir>" (корень модуля - ".")
+func (mf *manifest) settings(moduleDir, pkgDir, iface string) packageSettings {
+	abs, err := filepath.Abs(pkgDir)
+	if err != nil {
+		return packageSettings{}
+	}
+	rel, err := filepath.Rel(moduleDir, abs)
+	if err != nil {
+		return packageSettings{}
+	}
+	rel = filepath.ToSlash(rel)
+	name, _, _ := strings.Cut(iface, "[")
+	if s, ok := mf.Packages[rel+"."+name]; ok {
+		return s
+	}
+	return mf.Packages[rel]
+}
+
+// outputFrom возвращает output настроек относительно директории dir, из которой запускается генерация
+func (s packageSettings) outputFrom(moduleDir, dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(abs, filepath.Join(moduleDir, filepath.FromSlash(s.Output)))
+}
+
+// checkEnvPrefix проверяет префикс ENV ключей (--env-prefix, env_prefix): к нему добавляется
+// _<KEY>, поэтому он состоит из букв, цифр и подчеркиваний и не заканчивается подчеркиванием
+func checkEnvPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if strings.HasSuffix(prefix, "_") || strings.Trim(strings.ToUpper(prefix), "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "" || prefix[0] >= '0' && prefix[0] <= '9' {
+		return fmt.Errorf("%q must be letters, digits and '_', not starting with a digit or ending with '_' (e.g. APP_SERVER)", prefix)
+	}
+	return nil
+}
+
+// exampleProfiles выбирает из профилей манифеста секцию пакета и проверяет значения по
+// типам методов. Секции других пакетов пропускаются: манифест общий для сервиса
+func (mf *manifest) exampleProfiles(info *InterfaceInfo, aliases AliasSettings) ([]exampleProfile, error) {
+	// Секции - в порядке чтения YAML реализацией (алиасы, затем имя пакета), ключи секции - по
+	// алфавиту. Если метод задан несколько раз (в секции пакета и под алиасом), в пример
+	// попадает первое значение: результат не зависит от порядка обхода map
+	sections := appendUnique(append([]string{}, aliases.YAMLSection...), info.PackageName, info.UniquePackageName)
+	var profiles []exampleProfile
+	for _, name := range sortedKeys(mf.Profiles) {
+		p := exampleProfile{Name: name, Values: map[string]string{}}
+		for _, section := range sections {
+			values := mf.Profiles[name][section]
+			for _, key := range sortedKeys(values) {
+				m, ok := findProfileMethod(info.Methods, aliases, key)
+				if !ok {
+					return nil, fmt.Errorf("profile %s: unknown key %s.%s in interface %s", name, section, key, info.InterfaceName)
+				}
+				if _, ok := p.Values[m.Name]; ok {
+					continue
+				}
+				encoded, err := encodeProfileValue(m, values[key])
+				if err != nil {
+					return nil, fmt.Errorf("profile %s: %s.%s: %w", name, section, key, err)
+				}
+				p.Values[m.Name] = encoded
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// findProfileMethod ищет метод по ключу так же, как YAML реализация: имя метода в нижнем
+// регистре или алиас yaml.key
+func findProfileMethod(methods []Method, aliases AliasSettings, key string) (Method, bool) {
+	for _, m := range methods {
+		if strings.EqualFold(m.Name, key) || slices.Contains(aliases.YAMLKey[m.Name], key) {
+			return m, true
+		}
+	}
+	return Method{}, false
+}
+
+// encodeProfileValue проверяет значение профиля по типу метода (как ggconfig set)
+// и возвращает его в JSON
+func encodeProfileValue(m Method, value any) (string, error) {
+	raw := ""
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case time.Time:
+		// Метки времени без кавычек YAML разбирает сам
+		layout := time.RFC3339Nano
+		if m.Kind == KindTime {
+			layout = TimeLayout(m)
+		}
+		raw = v.Format(layout)
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		raw = string(b)
+	default:
+		raw = fmt.Sprint(v)
+	}
+	parsed, err := ParseValue(m, raw)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
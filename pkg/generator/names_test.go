@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTitleName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"internal_db", "InternalDb"},
+		{"server", "Server"},
+		{"go_store", "GoStore"},
+		{"_leading__double_", "LeadingDouble"},
+		// Цифры: буква после цифры не меняется, как в strings.Title
+		{"http2server", "Http2server"},
+		{"v2_api", "V2Api"},
+		{"cmd_2fa", "Cmd2fa"},
+		// Аббревиатуры и смешанный регистр сохраняются
+		{"internal_HTTPServer", "InternalHTTPServer"},
+		{"api_v1_OAuth", "ApiV1OAuth"},
+		// Не-ASCII
+		{"über_db", "ÜberDb"},
+		{"конфиг_сервер", "КонфигСервер"},
+		{"ǆemal", "ǅemal"},
+		{"αβ2γ", "Αβ2γ"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := TitleName(tt.in); got != tt.want {
+			t.Errorf("TitleName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// Имена конструкторов раньше строились через strings.Title по частям имени: результат
+// не должен меняться, иначе сломается код, который их вызывает
+func TestTitleNameMatchesStringsTitle(t *testing.T) {
+	for _, in := range []string{
+		"internal_db", "http2server", "h2c", "x9y_z8w", "github_com_org_billing_internal_server",
+		"über_db", "ǆemal", "naïve_αβ2γ", "db-x", "a.b", "v2api", "MixedCase_x",
+	} {
+		var want strings.Builder
+		for _, part := range strings.Split(in, "_") {
+			want.WriteString(strings.Title(part)) //nolint:staticcheck // эталон прежнего поведения
+		}
+		if got := TitleName(in); got != want.String() {
+			t.Errorf("TitleName(%q) = %q, strings.Title gives %q", in, got, want.String())
+		}
+	}
+}
+
+func TestToEnvKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Host", "HOST"},
+		{"UserName", "USER_NAME"},
+		{"DBHost", "DB_HOST"},
+		{"SSLMode", "SSL_MODE"},
+		{"APIKey", "API_KEY"},
+		{"URL", "URL"},
+		// Аббревиатура в конце не отделяется: так ключи строились всегда, менять их нельзя
+		{"MaxRPS", "MAXRPS"},
+		// Цифры не начинают слово
+		{"HTTP2Server", "HTTP2_SERVER"},
+		{"Port2", "PORT2"},
+		{"OAuth2Token", "O_AUTH2_TOKEN"},
+		{"Ipv6Enabled", "IPV6_ENABLED"},
+		// Не-ASCII буквы переводятся в верхний регистр и делят слова так же
+		{"ÜberName", "ÜBER_NAME"},
+		{"ИмяСервера", "ИМЯ_СЕРВЕРА"},
+		{"MaxÄrger", "MAX_ÄRGER"},
+		{"ÇAVA", "ÇAVA"},
+	}
+	for _, tt := range tests {
+		if got := toEnvKey(tt.in); got != tt.want {
+			t.Errorf("toEnvKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	tests := []struct {
+		prefix, method, want string
+	}{
+		{"server", "Port", "SERVER_PORT"},
+		{"http2server", "ReadTimeout", "HTTP2SERVER_READ_TIMEOUT"},
+		{"APP_SERVER", "SSLMode", "APP_SERVER_SSL_MODE"},
+		{"über", "Name", "ÜBER_NAME"},
+	}
+	for _, tt := range tests {
+		if got := EnvKey(tt.prefix, tt.method); got != tt.want {
+			t.Errorf("EnvKey(%q, %q) = %q, want %q", tt.prefix, tt.method, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const registryConfig = `package svc
+
+type Config interface {
+	Port(defaultValue int) (int, bool)
+}
+`
+
+// registryPathTest проверяет порядок поиска YAML файла GlobalYamlConfig: явный путь, ENV,
+// затем ./config.yaml и /etc/<app>/config.yaml
+const registryPathTest = `package svc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func path(t *testing.T, c *GlobalYamlConfig) string {
+	t.Helper()
+	p, err := c.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestGlobalYamlConfigPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(DefaultConfigFileEnv, "")
+
+	want := []string{"./config.yaml", filepath.Join("/etc", filepath.Base(os.Args[0]), "config.yaml")}
+	if got := DefaultConfigFallbacks(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("DefaultConfigFallbacks() = %v, want %v", got, want)
+	}
+	if p := path(t, NewGlobalYamlConfig("")); p != "" {
+		t.Errorf("no config file: Path = %q, want none", p)
+	}
+	if err := os.WriteFile("config.yaml", []byte("svc:\n  port: 9090\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if p := path(t, NewGlobalYamlConfig("")); p != "./config.yaml" {
+		t.Errorf("Path = %q, want the default ./config.yaml", p)
+	}
+	if p := path(t, NewGlobalYamlConfig("other.yaml")); p != "other.yaml" {
+		t.Errorf("explicit path: Path = %q, want other.yaml", p)
+	}
+	t.Setenv(DefaultConfigFileEnv, "env.yaml")
+	if p := path(t, NewGlobalYamlConfig("")); p != "env.yaml" {
+		t.Errorf("ENV path: Path = %q, want env.yaml", p)
+	}
+	t.Setenv(DefaultConfigFileEnv, "")
+	if p := path(t, NewGlobalYamlConfig("").WithFallbacks()); p != "" {
+		t.Errorf("WithFallbacks(): Path = %q, want none", p)
+	}
+	if p := path(t, NewGlobalYamlConfig("").WithFallbacks("missing.yaml", "config.yaml")); p != "config.yaml" {
+		t.Errorf("WithFallbacks: Path = %q, want config.yaml", p)
+	}
+
+	global, err := NewGlobalConfig(NewGlobalYamlConfig(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := global.ConfigFile(); f != "./config.yaml" {
+		t.Errorf("ConfigFile() = %q, want ./config.yaml", f)
+	}
+}
+`
+
+func TestGlobalYamlConfigDefaultPaths(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{
+		"svc/config.go":    registryConfig,
+		"svc/path_test.go": registryPathTest,
+	})
+	registry := true
+	opts := Options{Dir: filepath.Join(dir, "svc"), Interface: "Config", Registry: &registry}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SourceProvider - источник конфигурации в сгенерированном файле: реализация интерфейса и ее
+// конструкторы в шаблоне templates/sources/<Name>.go.tmpl. Шаблон получает те же данные и функции,
+// что и config.go.tmpl, и может вызывать hasSource, чтобы ссылаться на другие выбранные источники
+type SourceProvider interface {
+	// Name - имя источника в --sources и имя шаблона
+	Name() string
+	// Requires - источники, на типах которых построен этот (JSON читает ключи YAML реализации)
+	Requires() []string
+	// Check сообщает, почему источник нельзя сгенерировать для интерфейса с этими опциями;
+	// без явного --sources такие источники пропускаются
+	Check(info *InterfaceInfo, opts GenerateOptions) error
+	// Default сообщает, генерируется ли источник без --sources; остальные включаются явно
+	Default() bool
+}
+
+// builtinSource - встроенный источник ggconfig
+type builtinSource struct {
+	name      string
+	requires  []string
+	runtime   bool   // Нужен пакет runtime: недоступен с --no-deps
+	directive string // Генерируется только для интерфейсов с методами ggconfig:<directive>
+	byDefault bool   // Генерируется без --sources
+}
+
+func (s builtinSource) Name() string       { return s.name }
+func (s builtinSource) Requires() []string { return s.requires }
+func (s builtinSource) Default() bool      { return s.byDefault }
+
+func (s builtinSource) Check(info *InterfaceInfo, opts GenerateOptions) error {
+	if s.runtime && opts.NoDeps {
+		return fmt.Errorf("source %s needs the ggconfig runtime (remove --no-deps)", s.name)
+	}
+	if s.directive != "" {
+		for _, m := range info.Methods {
+			if _, ok := m.Directive(s.directive); ok {
+				return nil
+			}
+		}
+		return fmt.Errorf("source %s needs methods annotated with ggconfig:%s", s.name, s.directive)
+	}
+	return nil
+}
+
+// sourceProviders - источники в порядке генерации; источник идет после тех, от которых зависит.
+// По умолчанию генерируются env, yaml, mock и composite, а flag и secret - для интерфейсов с их
+// директивами (директива и есть явный выбор); остальные источники и обертки включает --sources
+var sourceProviders = []SourceProvider{
+	builtinSource{name: "env", byDefault: true},
+	builtinSource{name: "dotenv", requires: []string{"env"}, runtime: true},
+	builtinSource{name: "sql", requires: []string{"env"}, runtime: true},
+	builtinSource{name: "credentials", requires: []string{"env"}, runtime: true},
+	builtinSource{name: "yaml", runtime: true, byDefault: true},
+	builtinSource{name: "json", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "hcl", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "cue", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "mount", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "http", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "git", requires: []string{"yaml"}, runtime: true},
+	builtinSource{name: "flag", runtime: true, directive: "flag", byDefault: true},
+	builtinSource{name: "secret", runtime: true, directive: "secret", byDefault: true},
+	builtinSource{name: "mock", byDefault: true},
+	builtinSource{name: "composite", byDefault: true},
+	// Обертки над готовым конфигом: переопределения из context.Context, сбои для тестов
+	// устойчивости и сборка composite по строке цепочки
+	builtinSource{name: "context", runtime: true},
+	builtinSource{name: "chaos", runtime: true},
+	builtinSource{name: "chain", requires: []string{"composite"}, runtime: true},
+}
+
+// registeredTemplates - шаблоны источников, добавленных RegisterSource, по имени файла
+// (sources/<name>.go.tmpl); встроенные шаблоны читаются из templates/
+var registeredTemplates = map[string]string{}
+
+// RegisterSource добавляет источник после встроенных, например из init инструмента, который
+// встраивает генератор: p выбирается по имени в --sources (или Options.Sources), а text - его
+// шаблон sources/<name>.go.tmpl с теми же данными и функциями, что у config.go.tmpl. Шаблон можно
+// заменить через --template-dir, как встроенный. Повторное имя - паника, как в flag.Var
+func RegisterSource(p SourceProvider, text string) {
+	if findSource(p.Name()) != nil {
+		panic("ggconfig: source " + p.Name() + " registered twice")
+	}
+	sourceProviders = append(sourceProviders, p)
+	registeredTemplates["sources/"+p.Name()+".go.tmpl"] = normalizeNewlines(text)
+}
+
+func findSource(name string) SourceProvider {
+	for _, p := range sourceProviders {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func sourceNames() []string {
+	names := make([]string, len(sourceProviders))
+	for i, p := range sourceProviders {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// selectSources возвращает источники для генерации в порядке sourceProviders. Пустой spec -
+// источники по умолчанию (Default), "all" - все источники, доступные интерфейсу; в явном списке
+// (--sources=env,yaml,mock) неизвестное имя, недоступный источник или отсутствие источника,
+// от которого он зависит, - ошибка
+func selectSources(spec string, info *InterfaceInfo, opts GenerateOptions) ([]string, error) {
+	selected := map[string]bool{}
+	if all := strings.ToLower(strings.TrimSpace(spec)); all == "" || all == "all" {
+		for _, p := range sourceProviders {
+			if (all == "all" || p.Default()) && p.Check(info, opts) == nil {
+				selected[p.Name()] = true
+			}
+		}
+	} else {
+		for _, name := range strings.Split(spec, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			p := findSource(name)
+			if p == nil {
+				return nil, fmt.Errorf("--sources: unknown source %q (sources: %s)", name, strings.Join(sourceNames(), ", "))
+			}
+			if err := p.Check(info, opts); err != nil {
+				return nil, fmt.Errorf("--sources: %w", err)
+			}
+			selected[name] = true
+		}
+	}
+
+	var names []string
+	for _, p := range sourceProviders {
+		if !selected[p.Name()] {
+			continue
+		}
+		for _, req := range p.Requires() {
+			if !selected[req] {
+				return nil, fmt.Errorf("--sources: source %s requires %s", p.Name(), req)
+			}
+		}
+		names = append(names, p.Name())
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--sources: no sources selected (sources: %s)", strings.Join(sourceNames(), ", "))
+	}
+	return names, nil
+}
+
+// requireSources проверяет, что выбраны источники, на которых построена функция генератора
+func requireSources(feature string, sources []string, required ...string) error {
+	have := map[string]bool{}
+	for _, s := range sources {
+		have[s] = true
+	}
+	var missing []string
+	for _, r := range required {
+		if !have[r] {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("%s needs sources %s (add them to --sources)", feature, strings.Join(missing, ", "))
+}
+
+// sourceTemplates - имена шаблонов источников для loadTemplate
+func sourceTemplates() []string {
+	files := make([]string, len(sourceProviders))
+	for i, p := range sourceProviders {
+		files[i] = "sources/" + p.Name() + ".go.tmpl"
+	}
+	return files
+}
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectSources(t *testing.T) {
+	plain := &InterfaceInfo{Methods: []Method{{Name: "Port", ParamType: "int", ReturnType: "int"}}}
+	flagged := &InterfaceInfo{Methods: []Method{{Name: "Beta", ParamType: "bool", ReturnType: "bool", Directives: map[string]string{"flag": ""}}}}
+	tests := []struct {
+		name string
+		spec string
+		info *InterfaceInfo
+		opts GenerateOptions
+		want string
+		err  string
+	}{
+		// Без --sources дополнительные источники и обертки не генерируются
+		{"default", "", plain, GenerateOptions{}, "env,yaml,mock,composite", ""},
+		{"default with a directive", "", flagged, GenerateOptions{}, "env,yaml,flag,mock,composite", ""},
+		{"default without runtime", "", plain, GenerateOptions{NoDeps: true}, "env,mock,composite", ""},
+		{"all", "all", plain, GenerateOptions{}, "env,dotenv,sql,credentials,yaml,json,hcl,cue,mount,http,git,mock,composite,context,chaos,chain", ""},
+		{"explicit", "composite, Chaos,env", plain, GenerateOptions{}, "env,composite,chaos", ""},
+		{"unknown", "env,consul", plain, GenerateOptions{}, "", `unknown source "consul"`},
+		{"missing requirement", "env,chain", plain, GenerateOptions{}, "", "source chain requires composite"},
+		{"unavailable", "env,chaos", plain, GenerateOptions{NoDeps: true}, "", "source chaos needs the ggconfig runtime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSources(tt.spec, tt.info, tt.opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("sources = %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
+
+// staticSource - источник вне генератора: каждый ключ равен 8080
+type staticSource struct{}
+
+func (staticSource) Name() string                                { return "static" }
+func (staticSource) Requires() []string                          { return nil }
+func (staticSource) Check(*InterfaceInfo, GenerateOptions) error { return nil }
+func (staticSource) Default() bool                               { return false }
+
+const staticTemplate = `// ===== Static Implementation =====
+
+type {{.TypeName}}StaticConfig struct{}
+{{range .Methods}}
+func (c {{$.TypeName}}StaticConfig) {{lookup .}}(defaultValue {{.ParamType}}) ({{.ReturnType}}, bool) {
+	return 8080, true
+}
+{{end}}
+func {{ctor "New"}}Static() {{.TypeName}}StaticConfig {
+	return {{.TypeName}}StaticConfig{}
+}
+`
+
+const staticConfigTest = `package svc
+
+import "testing"
+
+func TestStatic(t *testing.T) {
+	if v, ok := NewSvcConfigAll(NewSvcConfigStatic()).Port(1); !ok || v != 8080 {
+		t.Errorf("Port = %d, %v; want 8080 from the registered source", v, ok)
+	}
+}
+`
+
+func TestRegisterSource(t *testing.T) {
+	providers := sourceProviders
+	t.Cleanup(func() {
+		sourceProviders = providers
+		delete(registeredTemplates, "sources/static.go.tmpl")
+	})
+	RegisterSource(staticSource{}, staticTemplate)
+
+	// Без --sources источник не генерируется: Default() == false
+	plain := &InterfaceInfo{Methods: []Method{{Name: "Port", ParamType: "int", ReturnType: "int"}}}
+	if got, err := selectSources("", plain, GenerateOptions{}); err != nil || strings.Join(got, ",") != "env,yaml,mock,composite" {
+		t.Errorf("default sources = %v, %v", got, err)
+	}
+
+	dir := writeRuntimeModule(t, map[string]string{
+		"svc/config.go":      registryConfig,
+		"svc/static_test.go": staticConfigTest,
+	})
+	opts := Options{Dir: filepath.Join(dir, "svc"), Interface: "Config", Sources: []string{"env", "static", "mock", "composite"}}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("second RegisterSource did not panic")
+		}
+	}()
+	RegisterSource(staticSource{}, staticTemplate)
+}
@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writeRuntimeModule создает модуль, который использует runtime этого репозитория (replace на
+// корень модуля), с файлами files
+func writeRuntimeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	all := map[string]string{
+		"go.mod": "module example.com/strict\n\ngo 1.21\n\nrequire (\n\tgithub.com/apopov-app/ggconfig v0.0.0\n\tgopkg.in/yaml.v3 v3.0.1\n)\n\nreplace github.com/apopov-app/ggconfig => " + root + "\n",
+		"go.sum": string(sum),
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	for name, content := range all {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runGoTest запускает go test в модуле dir без сети
+func runGoTest(t *testing.T, dir string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("runs go test on the generated package")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not available")
+	}
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+}
+
+const strictConfig = `package svc
+
+import "time"
+
+type Config interface {
+	Port(defaultValue int) (int, bool)
+	// ggconfig:was=Addr
+	Host(defaultValue string) (string, error)
+	Limit(defaultValue int64) (int64, error)
+	// ggconfig:oneof=debug,info
+	Level(defaultValue string) (string, error)
+	// ggconfig:cache
+	Timeout(defaultValue time.Duration) (time.Duration, error)
+}
+`
+
+// strictConfigTest проверяет методы (T, error), Validate и Report сгенерированного пакета: ошибка
+// разбора не зависит от обработчика runtime.SetParseErrorHandler и параллельного runtime.Validate
+const strictConfigTest = `package svc
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+func env(values map[string]string) *svcEnvConfig {
+	return NewSvcConfigEnvConfigWithLookup(nil, func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	})
+}
+
+func yamlConfig(t *testing.T, src string) *svcYAMLConfig {
+	y, err := runtime.ParseYAML([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSvcConfigYAMLConfigParsed(y)
+}
+
+func parseError(t *testing.T, err error, key string) {
+	t.Helper()
+	var perr *runtime.ParseError
+	if !errors.As(err, &perr) || perr.Key != key {
+		t.Fatalf("error = %v, want a *runtime.ParseError for %s", err, key)
+	}
+}
+
+func TestErrorMethodsIgnoreHandler(t *testing.T) {
+	// Обработчик, который не паникует, не превращает ошибку разбора в ErrNotSet
+	prev := runtime.SetParseErrorHandler(func(*runtime.ParseError) {})
+	defer runtime.SetParseErrorHandler(prev)
+
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_LIMIT": "abc"}), yamlConfig(t, "svc:\n  limit: 10\n  level: trace\n  timeout: soon\n"))
+	if v, err := cfg.Limit(5); v != 5 {
+		t.Errorf("Limit = %d, want the default 5", v)
+	} else {
+		parseError(t, err, "SVC_LIMIT")
+	}
+	_, err := cfg.Level("info")
+	parseError(t, err, "svc.level")
+	_, err = cfg.Timeout(time.Second)
+	parseError(t, err, "svc.timeout")
+	if _, err := cfg.Host("localhost"); !errors.Is(err, runtime.ErrNotSet) {
+		t.Errorf("Host error = %v, want ErrNotSet", err)
+	}
+}
+
+func TestErrorMethodsInsideValidate(t *testing.T) {
+	// Validate в другой горутине подменяет обработчик: ошибка метода (T, error) достается
+	// вызывающему и не попадает в Validate
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_LIMIT": "abc"}))
+	var got error
+	if err := runtime.Validate(func() { _, got = cfg.Limit(5) }); err != nil {
+		t.Errorf("Validate = %v, want nil", err)
+	}
+	parseError(t, got, "SVC_LIMIT")
+}
+
+func TestErrorMethodsFormerName(t *testing.T) {
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_ADDR": "db"}))
+	if v, err := cfg.Host("localhost"); err != nil || v != "db" {
+		t.Errorf("Host = %q, %v; want db from the former name", v, err)
+	}
+}
+
+func TestStrictBoolMethodsUseHandler(t *testing.T) {
+	var reported []string
+	prev := runtime.SetParseErrorHandler(func(err *runtime.ParseError) { reported = append(reported, err.Key) })
+	defer runtime.SetParseErrorHandler(prev)
+
+	// Обработчик, который не паникует, пропускает значение, и чтение продолжается следующим источником
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_PORT": "80a"}), yamlConfig(t, "svc:\n  port: 8080\n"))
+	if v, ok := cfg.Port(1); !ok || v != 8080 {
+		t.Errorf("Port = %d, %v; want 8080 from YAML", v, ok)
+	}
+	if len(reported) != 1 || reported[0] != "SVC_PORT" {
+		t.Errorf("reported = %v, want [SVC_PORT]", reported)
+	}
+}
+
+func TestErrorMethodsThroughWrappers(t *testing.T) {
+	base := NewSvcConfigAll(env(map[string]string{"SVC_LIMIT": "abc"}))
+	for name, cfg := range map[string]interface {
+		Limit(int64) (int64, error)
+	}{
+		"override": NewSvcConfigOverride(base, map[string]any{"Port": 1}),
+		"chaos":    NewSvcConfigChaos(base, runtime.NewChaos(runtime.ChaosOptions{})),
+	} {
+		_, err := cfg.Limit(5)
+		if err == nil {
+			t.Errorf("%s: Limit error = nil, want a *runtime.ParseError", name)
+			continue
+		}
+		parseError(t, err, "SVC_LIMIT")
+	}
+	if v, err := NewSvcConfigOverride(base, map[string]any{"Limit": 7}).Limit(5); err != nil || v != 7 {
+		t.Errorf("overridden Limit = %d, %v; want 7", v, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_PORT": "80a"}), yamlConfig(t, "svc:\n  limit: x\n  host: db\n"))
+	other := NewSvcConfigAll(env(map[string]string{"SVC_LEVEL": "trace"}))
+
+	// Обработчик по умолчанию вызывает panic: Validate его не использует, а ошибки другого
+	// конфига не попадают ни в cfg.Validate, ни в охватывающий runtime.Validate
+	var errCfg, errOther error
+	if err := runtime.Validate(func() {
+		errCfg = cfg.Validate()
+		errOther = other.Validate()
+	}); err != nil {
+		t.Errorf("runtime.Validate = %v, want nil", err)
+	}
+	parseError(t, errCfg, "SVC_PORT")
+	if !strings.Contains(errCfg.Error(), "svc.limit") || strings.Contains(errCfg.Error(), "SVC_LEVEL") {
+		t.Errorf("cfg.Validate = %v, want SVC_PORT and svc.limit only", errCfg)
+	}
+	parseError(t, errOther, "SVC_LEVEL")
+	if err := NewSvcConfigAll(yamlConfig(t, "svc:\n  port: 1\n")).Validate(); err != nil {
+		t.Errorf("Validate of a valid config = %v", err)
+	}
+}
+
+func TestUnreadableEnvFile(t *testing.T) {
+	// Заданный <KEY>_FILE, который не читается, - некорректное значение, а не отсутствующий ключ
+	missing := filepath.Join(t.TempDir(), "missing")
+	cfg := NewSvcConfigAll(env(map[string]string{"SVC_PORT_FILE": missing, "SVC_LIMIT_FILE": missing}), yamlConfig(t, "svc:\n  port: 8080\n"))
+	_, err := cfg.Limit(5)
+	parseError(t, err, "SVC_LIMIT_FILE")
+	err = cfg.Validate()
+	parseError(t, err, "SVC_PORT_FILE")
+	if !strings.Contains(err.Error(), missing) {
+		t.Errorf("Validate = %v, want the file name", err)
+	}
+	r := cfg.Report()
+	if len(r.Warnings) == 0 || !strings.Contains(strings.Join(r.Warnings, "\n"), "SVC_PORT_FILE") {
+		t.Errorf("warnings = %v, want the SVC_PORT_FILE error", r.Warnings)
+	}
+}
+
+func TestReportStrict(t *testing.T) {
+	r := NewSvcConfigAll(env(map[string]string{"SVC_PORT": "80a"}), yamlConfig(t, "svc:\n  port: 8080\n")).Report()
+	if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "SVC_PORT") {
+		t.Errorf("warnings = %v, want the SVC_PORT error", r.Warnings)
+	}
+	for _, k := range r.Keys {
+		if k.Key == "svc.port" && (k.Source != "yaml" || k.Value != 8080) {
+			t.Errorf("svc.port = %+v, want 8080 from yaml", k)
+		}
+	}
+}
+`
+
+func TestStrictErrorMethods(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{"svc/config.go": strictConfig})
+	opts := Options{Dir: filepath.Join(dir, "svc"), Interface: "Config", Sources: []string{"env", "yaml", "mock", "composite", "chaos"}, Strict: true}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "svc", "strict_test.go"), []byte(strictConfigTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
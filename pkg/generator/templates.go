@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Шаблоны генерации встраиваются в бинарник: генератору не нужны файлы вне проекта и сеть,
+// а вывод зависит только от версии ggconfig и входных файлов.
+//
+//go:embed templates/*.tmpl templates/sources/*.tmpl
+var templateFiles embed.FS
+
+// templateText возвращает встроенный шаблон templates/<name> (name может быть вида sources/env.go.tmpl). Переводы строк приводятся к \n:
+// при checkout с autocrlf на Windows шаблоны иначе давали бы другой сгенерированный код
+func templateText(name string) string {
+	data, err := templateFiles.ReadFile("templates/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return normalizeNewlines(string(data))
+}
+
+// LoadTemplate разбирает шаблон file с функциями funcs: из templateDir (--template-dir), если
+// там есть файл с этим именем, иначе встроенный; пустой templateDir - только встроенные шаблоны.
+// Шаблоны associated разбираются в тот же набор под своими именами (sources/env.go.tmpl),
+// основной шаблон вызывает их через ExecuteTemplate. Ошибка разбора указывает на файл шаблона
+func LoadTemplate(templateDir, name, file string, funcs template.FuncMap, associated ...string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(funcs)
+	for i, f := range append([]string{file}, associated...) {
+		text, source, err := readTemplate(templateDir, f)
+		if err != nil {
+			return nil, err
+		}
+		t := tmpl
+		if i > 0 {
+			t = tmpl.New(f)
+		}
+		if _, err := t.Parse(text); err != nil {
+			return nil, fmt.Errorf("template %s: %w", source, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// readTemplate возвращает текст шаблона file и его источник для сообщений об ошибках
+func readTemplate(templateDir, file string) (text, source string, err error) {
+	if templateDir != "" {
+		path := filepath.Join(templateDir, filepath.FromSlash(file))
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			return normalizeNewlines(string(data)), path, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", "", err
+		}
+	}
+	if text, ok := registeredTemplates[file]; ok {
+		return text, file + " (RegisterSource)", nil
+	}
+	return templateText(file), "templates/" + file, nil
+}
+
+// embeddedTemplates перечисляет встроенные шаблоны относительно templates/, по порядку обхода
+func embeddedTemplates() ([]string, error) {
+	var names []string
+	err := fs.WalkDir(templateFiles, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		names = append(names, strings.TrimPrefix(path, "templates/"))
+		return nil
+	})
+	return names, err
+}
+
+// CheckTemplateDir проверяет, что в --template-dir нет шаблонов с неизвестными именами: файл с
+// опечаткой в имени иначе молча игнорировался бы
+func CheckTemplateDir(dir string) error {
+	if _, err := os.ReadDir(dir); err != nil {
+		return fmt.Errorf("--template-dir: %w", err)
+	}
+	known, err := embeddedTemplates()
+	if err != nil {
+		return err
+	}
+	for name := range registeredTemplates {
+		known = append(known, name)
+	}
+	names := map[string]bool{}
+	for _, k := range known {
+		names[k] = true
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || names[filepath.ToSlash(rel)] {
+			return err
+		}
+		list := append([]string{}, known...)
+		sort.Strings(list)
+		return fmt.Errorf("--template-dir: unknown template %s (templates: %s)", path, strings.Join(list, ", "))
+	})
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// PrintTemplates печатает встроенные шаблоны (--print-templates) с контрольными суммами:
+// снимок можно сохранить в репозитории и сверять при обновлении ggconfig
+func PrintTemplates(w io.Writer) error {
+	names, err := embeddedTemplates()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "# ggconfig v%s templates\n", Version)
+	for _, name := range names {
+		text := templateText(name)
+		fmt.Fprintf(w, "\n==> templates/%s sha256:%x <==\n", name, sha256.Sum256([]byte(text)))
+		io.WriteString(w, text)
+		if !strings.HasSuffix(text, "\n") {
+			io.WriteString(w, "\n")
+		}
+	}
+	return nil
+}
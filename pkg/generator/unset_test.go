@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const unsetConfig = `package svc
+
+type Config interface {
+	// ggconfig:unset=0
+	Port(defaultValue int) (int, bool)
+	// ggconfig:unset=0
+	Workers(defaultValue uint16) (uint16, bool)
+	// ggconfig:unset="none"
+	Host(defaultValue string) (string, bool)
+	// ggconfig:unset=false
+	Debug(defaultValue bool) (bool, bool)
+	// ggconfig:unset=off
+	// ggconfig:oneof=debug,info,off
+	Level(defaultValue string) (string, bool)
+}
+`
+
+const unsetConfigTest = `package svc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+func TestUnset(t *testing.T) {
+	prev := runtime.SetParseErrorHandler(func(*runtime.ParseError) {})
+	defer runtime.SetParseErrorHandler(prev)
+
+	// Заглушка в алиасной секции или алиасной переменной - отсутствие ключа: чтение
+	// продолжается со следующей секции, переменной и источника, как после основной секции
+	tests := []struct {
+		name     string
+		yaml     string
+		env      map[string]string
+		get      func(Config) (string, bool)
+		want     string
+		ok       bool
+		invalid  string // Ключ ошибки Validate
+	}{
+		{"alias section int", "legacy:\n  port: 0\nsvc:\n  port: 8080\n", nil, port, "8080", true, ""},
+		{"alias section only", "legacy:\n  port: 0\n", nil, port, "7", false, ""},
+		{"primary section only", "svc:\n  port: 0\n", nil, port, "7", false, ""},
+		{"malformed after the sentinel", "legacy:\n  port: 0\nsvc:\n  port: x\n", nil, port, "7", false, "svc.port"},
+		{"alias env", "", map[string]string{"PORT": "0", "SVC_PORT": "9090"}, port, "9090", true, ""},
+		{"env then yaml", "svc:\n  port: 8080\n", map[string]string{"PORT": "0"}, port, "8080", true, ""},
+		{"alias section uint16", "legacy:\n  workers: 0\nsvc:\n  workers: 4\n", nil, workers, "4", true, ""},
+		{"alias section string", "legacy:\n  host: none\nsvc:\n  host: db\n", nil, host, "db", true, ""},
+		{"alias env string", "", map[string]string{"HOST": "none", "SVC_HOST": "db"}, host, "db", true, ""},
+		{"alias section bool", "legacy:\n  debug: \"0\"\nsvc:\n  debug: true\n", nil, debug, "true", true, ""},
+		{"malformed bool after the sentinel", "legacy:\n  debug: false\nsvc:\n  debug: \"no\"\n", nil, debug, "true", false, "svc.debug"},
+		{"alias section oneof", "legacy:\n  level: off\nsvc:\n  level: info\n", nil, level, "info", true, ""},
+		{"oneof sentinel only", "legacy:\n  level: off\n", nil, level, "debug", false, ""},
+		{"oneof after the sentinel", "legacy:\n  level: off\nsvc:\n  level: trace\n", nil, level, "debug", false, "svc.level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y, err := runtime.ParseYAML([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			env := NewSvcConfigEnvConfigWithLookup(nil, func(k string) (string, bool) {
+				v, ok := tt.env[k]
+				return v, ok
+			})
+			cfg := NewSvcConfigAll(env, NewSvcConfigYAMLConfigParsed(y))
+			if got, ok := tt.get(cfg); got != tt.want || ok != tt.ok {
+				t.Errorf("got %s, %v; want %s, %v", got, ok, tt.want, tt.ok)
+			}
+			err = cfg.Validate()
+			var perr *runtime.ParseError
+			if tt.invalid == "" && err != nil {
+				t.Errorf("Validate = %v", err)
+			}
+			if tt.invalid != "" && (!errors.As(err, &perr) || perr.Key != tt.invalid) {
+				t.Errorf("Validate = %v, want a *runtime.ParseError for %s", err, tt.invalid)
+			}
+		})
+	}
+}
+
+func port(c Config) (string, bool)    { v, ok := c.Port(7); return fmt.Sprint(v), ok }
+func workers(c Config) (string, bool) { v, ok := c.Workers(7); return fmt.Sprint(v), ok }
+func host(c Config) (string, bool)    { return c.Host("localhost") }
+func debug(c Config) (string, bool)   { v, ok := c.Debug(true); return fmt.Sprint(v), ok }
+func level(c Config) (string, bool)   { return c.Level("debug") }
+`
+
+func TestUnsetFallsThrough(t *testing.T) {
+	dir := writeRuntimeModule(t, map[string]string{"svc/config.go": unsetConfig})
+	opts := Options{
+		Dir:       filepath.Join(dir, "svc"),
+		Interface: "Config",
+		Sources:   []string{"env", "yaml", "composite"},
+		Strict:    true,
+		Aliases:   []string{"env.Port=PORT", "env.Host=HOST", "yaml.section=legacy"},
+	}
+	if _, err := New(opts).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "svc", "unset_test.go"), []byte(unsetConfigTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGoTest(t, dir)
+}
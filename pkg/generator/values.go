@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// ParseValue проверяет значение по типу метода и возвращает его в виде для записи в YAML
+// (ggconfig set, значения профилей манифеста)
+func ParseValue(m Method, raw string) (any, error) {
+	switch {
+	case len(m.OneOf()) > 0:
+		return runtime.ParseOneOf(raw, m.OneOf()...)
+	case m.ReturnType == "string":
+		return raw, nil
+	case m.ReturnType == "bool":
+		return strconv.ParseBool(raw)
+	case m.Kind == KindDuration:
+		// Строка длительности или целые секунды, как принимает YAML реализация
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n, nil
+		}
+		if _, err := time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("invalid duration %q (want e.g. 30s, 1m30s or integer seconds)", raw)
+		}
+		return raw, nil
+	case m.Kind == KindURL:
+		if _, err := url.Parse(raw); err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		return raw, nil
+	case m.Kind == KindIP:
+		if _, err := runtime.ParseIP(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	case m.Kind == KindCIDR:
+		if _, err := runtime.ParseCIDR(raw); err != nil {
+			return nil, fmt.Errorf("invalid network %q (want CIDR notation, e.g. 10.0.0.0/8)", raw)
+		}
+		return raw, nil
+	case m.Kind == KindText:
+		// Разбирается UnmarshalText типа при чтении: проверить значение генератор не может
+		return raw, nil
+	case m.Kind == KindUnmarshaler:
+		// Разбирается UnmarshalYAML типа при чтении; значение записывается как YAML
+		var v any
+		if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", m.ReturnType, err)
+		}
+		return v, nil
+	case m.Kind == KindTime:
+		if _, err := time.Parse(TimeLayout(m), raw); err != nil {
+			return nil, fmt.Errorf("invalid time %q (want layout %s)", raw, TimeLayout(m))
+		}
+		return raw, nil
+	case m.ReturnType == "float64" || m.ReturnType == "float32":
+		// Элементы списков чисел
+		bitSize := 64
+		if m.ReturnType == "float32" {
+			bitSize = 32
+		}
+		v, err := strconv.ParseFloat(raw, bitSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", m.ReturnType, raw)
+		}
+		return v, nil
+	case IsIntegerType(m.ReturnType):
+		if format, ok := m.Directive("format"); ok {
+			// Время RFC3339 записывается как есть, чтобы конфиг оставался читаемым
+			if _, err := runtime.ParseUnixTime(raw, format); err != nil {
+				return nil, fmt.Errorf("invalid %s timestamp: %w", format, err)
+			}
+			if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return n, nil
+			}
+			return raw, nil
+		}
+		info := IntegerTypes[m.ReturnType]
+		if info.Signed {
+			v, err := strconv.ParseInt(raw, 10, info.BitSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", m.ReturnType, raw)
+			}
+			return v, nil
+		}
+		v, err := strconv.ParseUint(raw, 10, info.BitSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", m.ReturnType, raw)
+		}
+		return v, nil
+	case IsNumberSliceType(m.ReturnType) && !strings.HasPrefix(strings.TrimSpace(raw), "["):
+		// Числа через разделитель, как в ENV: 8080,8081
+		var items []any
+		for _, s := range strings.Split(raw, ListSeparator(m)) {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			v, err := ParseValue(Method{ReturnType: m.ElemType}, s)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case m.ReturnType == "[]string" && !strings.HasPrefix(strings.TrimSpace(raw), "["):
+		// Список через разделитель, как в ENV: a,b,c
+		var items []string
+		for _, s := range strings.Split(raw, ListSeparator(m)) {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+		return items, nil
+	}
+	// Массивы и необработанные значения передаются как YAML или JSON
+	var v any
+	if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", m.ReturnType, err)
+	}
+	if m.Kind == KindStruct {
+		if _, ok := v.(map[string]any); !ok {
+			return nil, fmt.Errorf("%s expects an object, e.g. '{\"limit\": 10}'", m.ReturnType)
+		}
+	}
+	if m.IsSlice {
+		if _, ok := v.([]any); !ok {
+			return nil, fmt.Errorf("%s expects a list, e.g. '[{\"id\": \"a\"}]'", m.ReturnType)
+		}
+	}
+	return v, nil
+}
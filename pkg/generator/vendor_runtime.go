@@ -0,0 +1,410 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const RuntimeImportPath = "github.com/apopov-app/ggconfig/runtime"
+
+// VendoredRuntimeFile - имя файла с локальной копией runtime в выходном пакете
+const VendoredRuntimeFile = "ggconfig_runtime.gen.go"
+
+// runtimeIdent возвращает имя идентификатора runtime для использования в сгенерированном коде:
+// runtime.YAML при обычной генерации и runtimeYAML при --vendor-runtime.
+func runtimeIdent(name string, vendored bool) string {
+	if vendored {
+		return vendoredName(name)
+	}
+	return "runtime." + name
+}
+
+// vendoredName превращает имя верхнего уровня runtime в неэкспортируемое: YAML -> runtimeYAML
+func vendoredName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return "runtime" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// ensureVendoredRuntime записывает в outputDir копию пакета runtime, в которой
+// все идентификаторы верхнего уровня переименованы в неэкспортируемые (runtimeYAML, runtimeParseYAML, ...).
+// Файл общий для всех интерфейсов, генерируемых в этот пакет (как registry.gen.go), поэтому в
+// копию попадает то, что нужно всем Go файлам пакета: файлам этого запуска и уже существующим.
+// Вызывается после записи остальных файлов пакета
+func (g *Generator) ensureVendoredRuntime(outputDir, genPackageName string, mode os.FileMode) error {
+	if g.opts.RuntimeSources == nil {
+		return fmt.Errorf("vendor runtime: Options.RuntimeSources is not set (the ggconfig command embeds the runtime sources)")
+	}
+	filePath := filepath.Join(outputDir, VendoredRuntimeFile)
+	refs, err := g.packageRefs(outputDir, genPackageName, filePath)
+	if err != nil {
+		return fmt.Errorf("vendor runtime: %w", err)
+	}
+	src, err := vendorRuntimeSource(g.opts.RuntimeSources, genPackageName, refs)
+	if err != nil {
+		return fmt.Errorf("vendor runtime: %w", err)
+	}
+	if err := g.guardOverwrite(filePath, GeneratedHeader); err != nil {
+		return err
+	}
+	if err := g.writeFile(filePath, src, mode); err != nil {
+		return fmt.Errorf("write vendored runtime %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// packageRefs собирает идентификаторы Go файлов пакета genPackageName в outputDir: файлы этого
+// запуска берутся из памяти (в режиме --check они не записываются), остальные - с диска.
+// Сама копия runtime (except) не учитывается
+func (g *Generator) packageRefs(outputDir, genPackageName, except string) (map[string]bool, error) {
+	sources := map[string][]byte{}
+	for path, data := range g.goSources {
+		if filepath.Dir(path) == filepath.Clean(outputDir) {
+			sources[path] = data
+		}
+	}
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		path := filepath.Join(outputDir, e.Name())
+		if _, ok := sources[path]; ok || e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources[path] = data
+	}
+
+	fset := token.NewFileSet()
+	refs := map[string]bool{}
+	for path, data := range sources {
+		if path == except {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, data, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name != genPackageName {
+			continue // Внешние тесты (package x_test) и чужие файлы копию не используют
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				refs[id.Name] = true
+			}
+			return true
+		})
+	}
+	return refs, nil
+}
+
+// runtimeDecl - объявление верхнего уровня runtime: единица, которая целиком попадает в копию
+type runtimeDecl struct {
+	file  *ast.File
+	decl  ast.Decl
+	recv  string // Тип получателя метода
+	order int
+}
+
+// vendorRuntimeSource собирает копию runtime из исходников пакета src (корень fs - директория
+// runtime). В копию попадают только объявления, достижимые из refs - идентификаторов файлов
+// выходного пакета (runtimeYAML, runtimeParseYAML, ...): функции и переменные, на которые
+// они ссылаются, типы со всеми методами (методы могут реализовывать интерфейсы). Группы
+// объявлений (const (...) с iota, var (...)) копируются целиком
+func vendorRuntimeSource(src fs.FS, genPackageName string, refs map[string]bool) ([]byte, error) {
+	entries, err := fs.ReadDir(src, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, name, data, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+
+	// Собираем имена верхнего уровня (типы, функции, переменные, константы) и их объявления.
+	// Методы не переименовываются: они принадлежат уже переименованным типам.
+	topLevel := map[string]bool{}
+	values := map[string]bool{}
+	var decls []*runtimeDecl
+	declOf := map[string]*runtimeDecl{}
+	methods := map[string][]*runtimeDecl{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			d := &runtimeDecl{file: f, decl: decl, order: len(decls)}
+			switch t := decl.(type) {
+			case *ast.FuncDecl:
+				if t.Recv != nil {
+					d.recv = receiverType(t.Recv.List[0].Type)
+					methods[d.recv] = append(methods[d.recv], d)
+				} else if t.Name.Name != "init" {
+					topLevel[t.Name.Name] = true
+					values[t.Name.Name] = true
+					declOf[t.Name.Name] = d
+				}
+			case *ast.GenDecl:
+				if t.Tok == token.IMPORT {
+					continue
+				}
+				for _, spec := range t.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						topLevel[s.Name.Name] = true
+						declOf[s.Name.Name] = d
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							if n.Name != "_" {
+								topLevel[n.Name] = true
+								values[n.Name] = true
+								declOf[n.Name] = d
+							}
+						}
+					}
+				}
+			}
+			decls = append(decls, d)
+		}
+	}
+
+	// Обход от имен, на которые ссылается выходной пакет. Селекторы и имена полей тоже считаются
+	// ссылками: лишнее объявление только увеличивает копию, а пропущенное ломает сборку.
+	// Метод достижимого типа копируется, если его имя встречается в скопированном коде или в
+	// пакете (вызов, метод интерфейса runtime) либо это метод интерфейса стандартной библиотеки
+	keep := map[*runtimeDecl]bool{}
+	used := map[string]bool{}
+	for name := range refs {
+		used[name] = true
+	}
+	var queue, types []*runtimeDecl
+	add := func(d *runtimeDecl) {
+		if d != nil && !keep[d] {
+			keep[d] = true
+			queue = append(queue, d)
+		}
+	}
+	for name := range topLevel {
+		if refs[vendoredName(name)] {
+			add(declOf[name])
+		}
+	}
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			d := queue[0]
+			queue = queue[1:]
+			ast.Inspect(d.decl, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					used[id.Name] = true
+					if topLevel[id.Name] {
+						add(declOf[id.Name])
+					}
+				}
+				return true
+			})
+			if gd, ok := d.decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				types = append(types, d)
+			}
+		}
+		for _, d := range types {
+			for _, spec := range d.decl.(*ast.GenDecl).Specs {
+				for _, m := range methods[spec.(*ast.TypeSpec).Name.Name] {
+					name := m.decl.(*ast.FuncDecl).Name.Name
+					if used[name] || stdInterfaceMethods[name] {
+						add(m)
+					}
+				}
+			}
+		}
+	}
+
+	imports := map[string]string{} // path -> local name
+	var body bytes.Buffer
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			local := ""
+			if imp.Name != nil {
+				local = imp.Name.Name
+			}
+			imports[p] = local
+		}
+		renameRuntimeIdents(f, topLevel, values)
+	}
+	for _, d := range decls {
+		if !keep[d] {
+			continue
+		}
+		if err := printer.Fprint(&body, fset, &printer.CommentedNode{Node: d.decl, Comments: d.file.Comments}); err != nil {
+			return nil, err
+		}
+		body.WriteString("\n\n")
+	}
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var out bytes.Buffer
+	out.WriteString(GeneratedHeader + "\n\n")
+	out.WriteString("// This file is a vendored copy of the parts of " + RuntimeImportPath + " used by this\n// package (--vendor-runtime).\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", genPackageName)
+	if len(paths) > 0 {
+		out.WriteString("import (\n")
+		// Сначала стандартная библиотека, затем внешние пакеты
+		for _, std := range []bool{true, false} {
+			for _, p := range paths {
+				if isStdImport(p) != std {
+					continue
+				}
+				if local := imports[p]; local != "" {
+					fmt.Fprintf(&out, "\t%s %q\n", local, p)
+				} else {
+					fmt.Fprintf(&out, "\t%q\n", p)
+				}
+			}
+			if std {
+				out.WriteString("\n")
+			}
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(body.Bytes())
+
+	// Импорты всех файлов runtime: неиспользуемые оставшимися объявлениями удаляются
+	file, err := parser.ParseFile(fset, VendoredRuntimeFile, out.Bytes(), parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(pruneImports(fset, file, out.Bytes()))
+}
+
+// stdInterfaceMethods - методы интерфейсов стандартной библиотеки и yaml.v3 (error,
+// fmt.Stringer, encoding.TextUnmarshaler, yaml.Unmarshaler, sort.Interface, io.Reader, ...):
+// их вызывают не по имени, поэтому у достижимых типов они копируются всегда
+var stdInterfaceMethods = map[string]bool{
+	"Error": true, "Unwrap": true, "Is": true, "As": true,
+	"String": true, "GoString": true, "Format": true,
+	"MarshalText": true, "UnmarshalText": true, "MarshalJSON": true, "UnmarshalJSON": true,
+	"MarshalYAML": true, "UnmarshalYAML": true, "MarshalBinary": true, "UnmarshalBinary": true,
+	"Len": true, "Less": true, "Swap": true,
+	"Read": true, "Write": true, "Close": true, "ReadAt": true, "Seek": true,
+	"RoundTrip": true, "ServeHTTP": true, "Scan": true, "Value": true,
+	"Set": true, "Get": true, "IsBoolFlag": true,
+	"Deadline": true, "Done": true, "Err": true,
+}
+
+// receiverType возвращает имя типа получателя: T для T, *T и T[K, V]
+func receiverType(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// isStdImport сообщает, относится ли путь импорта к стандартной библиотеке
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// renameRuntimeIdents переименовывает обращения к идентификаторам верхнего уровня.
+// Не трогаются селекторы (x.Name), имена полей и методов, а также ключи составных
+// литералов, если это не значение верхнего уровня (ключи struct-литералов - имена полей).
+func renameRuntimeIdents(f *ast.File, topLevel, values map[string]bool) {
+	skip := map[*ast.Ident]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.SelectorExpr:
+			skip[t.Sel] = true
+		case *ast.Field:
+			for _, name := range t.Names {
+				skip[name] = true
+			}
+		case *ast.FuncDecl:
+			if t.Recv != nil {
+				skip[t.Name] = true
+			}
+		case *ast.KeyValueExpr:
+			if id, ok := t.Key.(*ast.Ident); ok && !values[id.Name] {
+				skip[id] = true
+			}
+		}
+		return true
+	})
+	// Doc-комментарии начинаются с имени объявления - обновляем и их
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				renameDocName(d.Doc, d.Name.Name, topLevel)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					doc := ts.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					renameDocName(doc, ts.Name.Name, topLevel)
+				}
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || skip[id] || !topLevel[id.Name] {
+			return true
+		}
+		id.Name = vendoredName(id.Name)
+		return true
+	})
+}
+
+func renameDocName(doc *ast.CommentGroup, name string, topLevel map[string]bool) {
+	if doc == nil || len(doc.List) == 0 || !topLevel[name] {
+		return
+	}
+	c := doc.List[0]
+	if prefix := "// " + name + " "; strings.HasPrefix(c.Text, prefix) {
+		c.Text = "// " + vendoredName(name) + " " + strings.TrimPrefix(c.Text, prefix)
+	}
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeVendorModule создает модуль без зависимости от ggconfig: копия runtime должна
+// собираться только с yaml.v3
+func writeVendorModule(t *testing.T) string {
+	t.Helper()
+	sum, err := os.ReadFile("../../go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/vendored\n\ngo 1.21\n\nrequire gopkg.in/yaml.v3 v3.0.1\n",
+		"go.sum": string(sum),
+		"svc/config.go": `package svc
+
+import "time"
+
+type Config interface {
+	Host(defaultValue string) (string, bool)
+	Port(defaultValue int) (int, bool)
+	Debug(defaultValue bool) (bool, bool)
+	Timeout(defaultValue time.Duration) (time.Duration, bool)
+	Tags(defaultValue []string) ([]string, bool)
+	Limit(defaultValue int64) (int64, error)
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestVendorRuntimeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated package")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not available")
+	}
+	registry := true
+	tests := []struct {
+		name string
+		opts Options
+		// Пакеты, которых в копии быть не должно: их используют только невыбранные источники
+		absent []string
+	}{
+		{"env and yaml", Options{Sources: []string{"env", "yaml", "mock", "composite"}}, []string{`"os/exec"`, `"database/sql"`, `"crypto/hmac"`, `"net/http"`}},
+		{"registry and strict", Options{Sources: []string{"env", "yaml", "mock", "composite"}, Registry: &registry, Strict: true}, []string{`"os/exec"`, `"database/sql"`}},
+		{"all sources", Options{Sources: []string{"all"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeVendorModule(t)
+			opts := tt.opts
+			opts.Dir, opts.Interface = filepath.Join(dir, "svc"), "Config"
+			opts.Output, opts.OutPackage = "../gconfig", "gconfig"
+			opts.VendorRuntime = true
+			opts.RuntimeSources = os.DirFS("../../runtime")
+			if _, err := New(opts).Generate(); err != nil {
+				t.Fatal(err)
+			}
+			vendored, err := os.ReadFile(filepath.Join(dir, "gconfig", VendoredRuntimeFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, imp := range tt.absent {
+				if strings.Contains(string(vendored), imp) {
+					t.Errorf("vendored runtime imports %s, which the generated code does not need", imp)
+				}
+			}
+
+			cmd := exec.Command("go", "vet", "./...")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go vet: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestVendorRuntimeSourceReachable(t *testing.T) {
+	src := os.DirFS("../../runtime")
+	all, err := vendorRuntimeSource(src, "gconfig", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(all), "func ") {
+		t.Errorf("copy without references declares functions:\n%s", all)
+	}
+
+	// Методы копируются по именам, которые встречаются в пакете
+	out, err := vendorRuntimeSource(src, "gconfig", map[string]bool{"runtimeParseYAML": true, "GetString": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"func runtimeParseYAML(", "type runtimeYAML struct", "func (y *runtimeYAML) GetString("} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("copy for runtimeParseYAML lacks %q", want)
+		}
+	}
+	for _, unwanted := range []string{"func (y *runtimeYAML) GetURL(", "runtimeParseCUE", "runtimeNewSQLSource", "runtimeSignAWSV4", `"os/exec"`} {
+		if strings.Contains(string(out), unwanted) {
+			t.Errorf("copy for runtimeParseYAML contains unreachable %s", unwanted)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// runProbe реализует команду probe: без генерации кода ключи интерфейсов (директивы
+// //go:generate ggconfig) разрешаются по живым источникам так же, как это сделают
+// сгенерированные реализации, и печатается список промахов и ошибок типов.
+// Ненулевой код выхода - для проверки перед выкаткой в пайплайне
+func runProbe(args []string) error {
+	fs := flag.NewFlagSet("probe", flag.ExitOnError)
+	sources := fs.String("sources", "env,yaml=config.yaml", "sources in priority order, highest first: env, dotenv=<file>, yaml=<file>, json=<file>, hcl=<file>, cue=<file>, mount=<dir>, consul=<prefix>")
+	var pkgs generator.AliasFlags
+	fs.Var(&pkgs, "pkg", "package dir with //go:generate ggconfig directives (repeatable, default: all packages under the current directory)")
+	iface := fs.String("interface", "", "probe only this interface (default: all interfaces found)")
+	failOnMissing := fs.Bool("fail-on-missing", false, "also fail when a key is not set in any source (the default value would be used)")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of remote sources")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig probe [--sources=env,yaml=config.yaml,consul=prefix] [--pkg=dir] [--interface=Config] [--fail-on-missing]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	order, loaded, err := loadProbeSources(ctx, *sources)
+	if err != nil {
+		return err
+	}
+
+	var directives []generator.Directive
+	if len(pkgs) == 0 {
+		if directives, err = generator.WalkDirectives("."); err != nil {
+			return err
+		}
+	}
+	for _, dir := range pkgs {
+		found, err := generator.FindDirectives(dir)
+		if err != nil {
+			return err
+		}
+		directives = append(directives, found...)
+	}
+
+	var rows []explainRow
+	for _, d := range directives {
+		if *iface != "" && d.Interface != *iface {
+			continue
+		}
+		abs, err := filepath.Abs(d.Dir)
+		if err != nil {
+			return err
+		}
+		packageName := filepath.Base(abs)
+		info, err := generator.ParseInterface(d.Dir, d.SourceFile, packageName, packageName, d.Interface)
+		if err != nil {
+			return err
+		}
+		d.Aliases = generator.MethodAliases(info, d.Aliases)
+		for _, m := range info.Methods {
+			rows = append(rows, resolveExplainRow(packageName, d, m, order, loaded))
+		}
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("no //go:generate ggconfig directives found")
+	}
+
+	writeExplainTable(os.Stdout, order, rows, isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "")
+	invalid, missing := writeProbeProblems(os.Stdout, order, rows)
+	fmt.Printf("\n%d keys: %d resolved, %d missing, %d type errors\n", len(rows), len(rows)-missing, missing, invalid)
+	if invalid > 0 || (*failOnMissing && missing > 0) {
+		return fmt.Errorf("preflight check failed")
+	}
+	return nil
+}
+
+// writeProbeProblems печатает значения, которые не разбираются типом метода (источник их
+// пропустит, а с --strict вернет ошибку), и ключи, не заданные ни в одном источнике
+func writeProbeProblems(w io.Writer, order []string, rows []explainRow) (invalid, missing int) {
+	var lines []string
+	for _, r := range rows {
+		for _, source := range order {
+			for _, c := range r.Candidates[source] {
+				if c.State == explainInvalid {
+					invalid++
+					lines = append(lines, fmt.Sprintf("✗ %s: %s %s = %s is not a valid %s", r.Key, source, c.Where, r.displayValue(c.Value), r.Method.DeclaredType()))
+				}
+			}
+		}
+		if r.Winner == "" {
+			missing++
+			lines = append(lines, fmt.Sprintf("- %s: not set in any source, the default value is used", r.Key))
+		}
+	}
+	if len(lines) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, strings.Join(lines, "\n"))
+	}
+	return invalid, missing
+}
+
+// loadProbeSources разбирает --sources и загружает источники: порядок и данные по имени
+// ("env" читается из окружения при разрешении ключей)
+func loadProbeSources(ctx context.Context, spec string) ([]string, map[string]explainSource, error) {
+	var order []string
+	sources := map[string]explainSource{}
+	for _, part := range strings.Split(spec, ",") {
+		kind, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		kind = strings.ToLower(kind)
+		if containsString(order, kind) {
+			return nil, nil, fmt.Errorf("source %q is listed twice", kind)
+		}
+		switch kind {
+		case "env":
+			if arg != "" {
+				return nil, nil, fmt.Errorf("source env takes no argument")
+			}
+			sources[kind] = explainSource{env: os.LookupEnv}
+		case "dotenv":
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source dotenv: %w", err)
+			}
+			vars, err := runtime.ParseDotEnv(data)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source dotenv: %w", err)
+			}
+			sources[kind] = explainSource{env: func(key string) (string, bool) {
+				value, ok := vars[key]
+				return value, ok
+			}}
+		case "yaml", "json", "hcl", "cue":
+			if arg == "" {
+				return nil, nil, fmt.Errorf("source %s requires a file: %s=<file>", kind, kind)
+			}
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source %s: %w", kind, err)
+			}
+			y, err := parseConfigData("."+kind, data)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source %s: parse %s: %w", kind, arg, err)
+			}
+			sources[kind] = explainSource{y: y}
+		case "mount":
+			if arg == "" {
+				return nil, nil, fmt.Errorf("source mount requires a directory: mount=<dir>")
+			}
+			src, err := runtime.NewMountSource(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source mount: %w", err)
+			}
+			sources[kind] = explainSource{y: src.YAML()}
+		case "consul":
+			y, err := loadConsulKV(ctx, arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("source consul: %w", err)
+			}
+			sources[kind] = explainSource{y: y}
+		default:
+			return nil, nil, fmt.Errorf("unknown source %q (supported: env, dotenv=<file>, yaml=<file>, json=<file>, hcl=<file>, cue=<file>, mount=<dir>, consul=<prefix>)", kind)
+		}
+		order = append(order, kind)
+	}
+	return order, sources, nil
+}
+
+// loadConsulKV читает ключи Consul KV под prefix (агент из CONSUL_HTTP_ADDR, токен из
+// CONSUL_HTTP_TOKEN, как у consul CLI). Поддерживаются обе раскладки: YAML документ в самом
+// ключе prefix или отдельные ключи prefix/section/key
+func loadConsulKV(ctx context.Context, prefix string) (*runtime.YAML, error) {
+	addr := os.Getenv("CONSUL_HTTP_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:8500"
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("CONSUL_HTTP_ADDR: %w", err)
+	}
+	prefix = strings.Trim(prefix, "/")
+	u.Path, u.RawQuery = "/v1/kv/"+prefix, "recurse=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no keys under %q", prefix)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /v1/kv/%s: %s", prefix, resp.Status)
+	}
+	var pairs []struct {
+		Key   string
+		Value string // base64
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	props := map[string]string{}
+	for _, p := range pairs {
+		value, err := base64.StdEncoding.DecodeString(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", p.Key, err)
+		}
+		rel := strings.Trim(strings.TrimPrefix(p.Key, prefix), "/")
+		if rel == "" {
+			return runtime.ParseYAML(value)
+		}
+		props[strings.ReplaceAll(rel, "/", ".")] = string(value)
+	}
+	return runtime.FromProperties(props), nil
+}
@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrYAMLAnchor is returned by ParseYAMLNoAnchors for documents with anchors, aliases or merge keys.
+var ErrYAMLAnchor = errors.New("yaml anchors, aliases and merge keys are not allowed")
+
+// ParseYAMLNoAnchors parses a YAML document like ParseYAML, but rejects anchors (&name),
+// aliases (*name) and merge keys (<<) for teams that consider them a foot-gun: a value
+// changed in one place silently changes every key that refers to it. The error wraps
+// ErrYAMLAnchor and names the line. Generated code uses it with --no-yaml-anchors.
+func ParseYAMLNoAnchors(data []byte) (*YAML, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("yaml unmarshal: %w", err)
+	}
+	if n, what := findAnchor(&doc); n != nil {
+		return nil, fmt.Errorf("yaml: line %d: %s: %w", n.Line, what, ErrYAMLAnchor)
+	}
+	return fromDocument(&doc)
+}
+
+// findAnchor возвращает первый узел с якорем, алиасом или ключом слияния и его описание
+func findAnchor(n *yaml.Node) (*yaml.Node, string) {
+	switch {
+	case n.Kind == yaml.AliasNode:
+		return n, "alias *" + n.Value
+	case n.Anchor != "":
+		return n, "anchor &" + n.Anchor
+	}
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content); i += 2 {
+			if isMergeKey(n.Content[i]) {
+				return n.Content[i], "merge key " + n.Content[i].Value
+			}
+		}
+	}
+	for _, c := range n.Content {
+		if found, what := findAnchor(c); found != nil {
+			return found, what
+		}
+	}
+	return nil, ""
+}
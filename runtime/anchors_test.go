@@ -0,0 +1,237 @@
+package runtime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseYAML(t *testing.T, src string) *YAML {
+	t.Helper()
+	y, err := ParseYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+	return y
+}
+
+func TestParseYAMLAnchors(t *testing.T) {
+	y := mustParseYAML(t, `
+defaults:
+  host: &host db.internal
+  timeout: &timeout 5s
+  tags: &tags [a, b]
+server:
+  host: *host
+  timeout: *timeout
+  tags: *tags
+`)
+	if v, ok := y.GetString("server", "host"); !ok || v != "db.internal" {
+		t.Errorf("server.host = %q, %v; want db.internal", v, ok)
+	}
+	if v, ok := y.GetDuration("server", "timeout"); !ok || v != 5*time.Second {
+		t.Errorf("server.timeout = %v, %v; want 5s", v, ok)
+	}
+	if v, ok := y.GetStrings(",", "server", "tags"); !ok || strings.Join(v, ",") != "a,b" {
+		t.Errorf("server.tags = %v, %v; want [a b]", v, ok)
+	}
+}
+
+func TestParseYAMLAliasedSection(t *testing.T) {
+	y := mustParseYAML(t, `
+primary: &db
+  host: db1
+  port: 5432
+replica: *db
+`)
+	if v, ok := y.GetString("replica", "host"); !ok || v != "db1" {
+		t.Errorf("replica.host = %q, %v; want db1", v, ok)
+	}
+	if v, ok := y.GetInt("replica", "port"); !ok || v != 5432 {
+		t.Errorf("replica.port = %d, %v; want 5432", v, ok)
+	}
+}
+
+func TestParseYAMLMergeKey(t *testing.T) {
+	y := mustParseYAML(t, `
+base: &base
+  host: localhost
+  port: 8080
+  debug: false
+server:
+  <<: *base
+  port: 9090
+`)
+	tests := []struct {
+		key, want string
+	}{
+		{"host", "localhost"}, // из base
+		{"port", "9090"},      // ключ секции важнее ключа слияния
+		{"debug", "false"},
+	}
+	for _, tt := range tests {
+		raw, ok := y.GetRaw("server", tt.key)
+		if !ok || raw.Node.Value != tt.want {
+			t.Errorf("server.%s = %v, %v; want %s", tt.key, raw.Node, ok, tt.want)
+		}
+	}
+	if v, ok := y.GetInt("server", "port"); !ok || v != 9090 {
+		t.Errorf("GetInt(server.port) = %d, %v; want 9090", v, ok)
+	}
+	if v, ok := y.GetString("server", "host"); !ok || v != "localhost" {
+		t.Errorf("GetString(server.host) = %q, %v; want localhost", v, ok)
+	}
+	// Слияние не меняет исходную секцию
+	if v, ok := y.GetInt("base", "port"); !ok || v != 8080 {
+		t.Errorf("base.port = %d, %v; want 8080", v, ok)
+	}
+}
+
+func TestParseYAMLMergeSequencePrecedence(t *testing.T) {
+	// В последовательности слияния первое отображение важнее следующих, а ключи секции - всех
+	y := mustParseYAML(t, `
+a: &a
+  host: a-host
+  port: 1
+b: &b
+  host: b-host
+  port: 2
+  user: b-user
+server:
+  <<: [*a, *b]
+  port: 3
+`)
+	tests := []struct {
+		key, want string
+	}{
+		{"host", "a-host"},
+		{"port", "3"},
+		{"user", "b-user"},
+	}
+	for _, tt := range tests {
+		if raw, ok := y.GetRaw("server", tt.key); !ok || raw.Node.Value != tt.want {
+			t.Errorf("GetRaw(server.%s) = %v, %v; want %s", tt.key, raw.Node, ok, tt.want)
+		}
+	}
+	if v, ok := y.GetString("server", "host"); !ok || v != "a-host" {
+		t.Errorf("GetString(server.host) = %q, %v; want a-host", v, ok)
+	}
+	if v, ok := y.GetInt("server", "port"); !ok || v != 3 {
+		t.Errorf("server.port = %d, %v; want 3", v, ok)
+	}
+}
+
+func TestParseYAMLNestedMerge(t *testing.T) {
+	y := mustParseYAML(t, `
+common: &common
+  region: eu
+  timeout: 1s
+base: &base
+  <<: *common
+  host: base-host
+  timeout: 2s
+server:
+  <<: *base
+  host: server-host
+  pool:
+    <<: *common
+    size: 10
+`)
+	for key, want := range map[string]string{
+		"region":  "eu",          // common через base
+		"timeout": "2s",          // base важнее common
+		"host":    "server-host", // секция важнее base
+	} {
+		if v, ok := y.GetString("server", key); !ok || v != want {
+			t.Errorf("server.%s = %q, %v; want %s", key, v, ok, want)
+		}
+		if raw, ok := y.GetRaw("server", key); !ok || raw.Node.Value != want {
+			t.Errorf("GetRaw(server.%s) = %v, %v; want %s", key, raw.Node, ok, want)
+		}
+	}
+	raw, ok := y.GetRaw("server", "pool")
+	if !ok {
+		t.Fatal("server.pool not found")
+	}
+	var pool struct {
+		Region string `yaml:"region"`
+		Size   int    `yaml:"size"`
+	}
+	if err := raw.Node.Decode(&pool); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Region != "eu" || pool.Size != 10 {
+		t.Errorf("server.pool = %+v; want region eu, size 10", pool)
+	}
+}
+
+func TestParseYAMLAnchorErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"undefined alias", "server:\n  host: *missing\n", "unknown anchor 'missing'"},
+		{"undefined merge", "server:\n  <<: *missing\n  port: 1\n", "unknown anchor 'missing'"},
+		{"self-referencing alias", "server: &s\n  self: *s\n", "anchor 's' value contains itself"},
+		{"self-referencing merge", "server: &s\n  <<: *s\n  port: 1\n", "anchor 's' value contains itself"},
+		{"merge of a scalar", "base: &b 1\nserver:\n  <<: *b\n", "map merge requires map or sequence of maps"},
+		{"excessive aliasing", billionLaughs(), "excessive aliasing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("ParseYAML panicked: %v", r)
+				}
+			}()
+			_, err := ParseYAML([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// billionLaughs строит документ, в котором алиасы раскрываются в экспоненциальное число узлов
+func billionLaughs() string {
+	var b strings.Builder
+	b.WriteString("a: &a [x, x, x, x, x, x, x, x, x]\n")
+	prev := "a"
+	for _, name := range []string{"b", "c", "d", "e", "f", "g", "h", "i"} {
+		b.WriteString(name + ": &" + name + " [")
+		for i := 0; i < 9; i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("*" + prev)
+		}
+		b.WriteString("]\n")
+		prev = name
+	}
+	return b.String()
+}
+
+func TestParseYAMLNoAnchors(t *testing.T) {
+	tests := []struct {
+		name, src, what string
+	}{
+		{"anchor", "base: &b\n  port: 1\n", "anchor &b"},
+		{"alias", "a: &x 1\nb: *x\n", "anchor &x"},
+		{"merge", "server:\n  <<: {port: 1}\n", "merge key <<"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAMLNoAnchors([]byte(tt.src))
+			if !errors.Is(err, ErrYAMLAnchor) || !strings.Contains(err.Error(), tt.what) {
+				t.Fatalf("error = %v, want %q wrapping ErrYAMLAnchor", err, tt.what)
+			}
+		})
+	}
+	y, err := ParseYAMLNoAnchors([]byte("server:\n  port: 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := y.GetInt("server", "port"); !ok || v != 1 {
+		t.Errorf("server.port = %d, %v; want 1", v, ok)
+	}
+}
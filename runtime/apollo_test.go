@@ -0,0 +1,172 @@
+package runtime
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeApollo - config service Apollo: namespace с releaseKey и long polling уведомлений
+type fakeApollo struct {
+	mu             sync.Mutex
+	configurations map[string]string
+	releaseKey     string
+	notificationID int64
+}
+
+func (a *fakeApollo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Подпись покрывает путь с запросом и время из заголовка Timestamp
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(r.Header.Get("Timestamp") + "\n" + r.URL.RequestURI()))
+	if r.Header.Get("Authorization") != "Apollo app:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/configs/"):
+		if r.URL.Path == "/configs/app/prod/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("releaseKey") == a.releaseKey {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"configurations": a.configurations, "releaseKey": a.releaseKey})
+	case r.URL.Path == "/notifications/v2":
+		var notifications []struct {
+			NamespaceName  string `json:"namespaceName"`
+			NotificationID int64  `json:"notificationId"`
+		}
+		q := r.URL.Query()
+		if err := json.Unmarshal([]byte(q.Get("notifications")), &notifications); err != nil || q.Get("appId") != "app" || q.Get("cluster") != "prod" || len(notifications) != 1 {
+			http.Error(w, "bad notifications request", http.StatusBadRequest)
+			return
+		}
+		if notifications[0].NotificationID == a.notificationID {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{{"namespaceName": notifications[0].NamespaceName, "notificationId": a.notificationID}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *fakeApollo) set(configurations map[string]string, releaseKey string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.configurations, a.releaseKey = configurations, releaseKey
+	a.notificationID++
+}
+
+func TestApolloSource(t *testing.T) {
+	apollo := &fakeApollo{}
+	apollo.set(map[string]string{"server.port": "8080", "server.host": "db"}, "r1")
+	srv := httptest.NewServer(apollo)
+	defer srv.Close()
+
+	ctx := context.Background()
+	src, err := NewApolloSource(ctx, ApolloOptions{Server: srv.URL + "/", AppID: "app", Cluster: "prod", Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+
+	// Первое уведомление (-1) отличается от текущего; далее - только после публикации
+	if changed, err := src.poll(ctx); err != nil || !changed {
+		t.Errorf("first poll = %v, %v; want a change", changed, err)
+	}
+	if changed, err := src.poll(ctx); err != nil || changed {
+		t.Errorf("poll without a release = %v, %v; want no change", changed, err)
+	}
+	// Тот же releaseKey - 304, дерево не меняется
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := src.YAML().GetString("server", "host"); v != "db" {
+		t.Errorf("server.host after 304 = %q, want db", v)
+	}
+
+	apollo.set(map[string]string{"server.port": "9090"}, "r2")
+	if changed, err := src.poll(ctx); err != nil || !changed {
+		t.Errorf("poll after a release = %v, %v; want a change", changed, err)
+	}
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 9090 {
+		t.Errorf("server.port after release = %d, want 9090", v)
+	}
+	if _, ok := src.YAML().GetString("server", "host"); ok {
+		t.Error("server.host removed by the release is still set")
+	}
+}
+
+func TestApolloNamespaceFormats(t *testing.T) {
+	tests := []struct {
+		namespace string
+		content   string
+	}{
+		{"application", ""},
+		{"server.yaml", "server:\n  port: 8080\n"},
+		{"server.yml", "server:\n  port: 8080\n"},
+		{"server.json", `{"server": {"port": 8080}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			configurations := map[string]string{"server.port": "8080"}
+			if tt.content != "" {
+				configurations = map[string]string{"content": tt.content}
+			}
+			apollo := &fakeApollo{}
+			apollo.set(configurations, "r1")
+			srv := httptest.NewServer(apollo)
+			defer srv.Close()
+			src, err := NewApolloSource(context.Background(), ApolloOptions{Server: srv.URL, AppID: "app", Cluster: "prod", Namespace: tt.namespace, Secret: "secret"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+				t.Errorf("server.port = %d, %v; want 8080", v, ok)
+			}
+		})
+	}
+}
+
+func TestApolloSourceErrors(t *testing.T) {
+	apollo := &fakeApollo{}
+	apollo.set(map[string]string{"content": "server: [\n"}, "r1")
+	srv := httptest.NewServer(apollo)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts ApolloOptions
+		err  string
+	}{
+		{"required", ApolloOptions{Server: srv.URL}, "Server and AppID are required"},
+		{"signature", ApolloOptions{Server: srv.URL, AppID: "app", Cluster: "prod", Secret: "wrong"}, "unexpected status 401"},
+		{"missing namespace", ApolloOptions{Server: srv.URL, AppID: "app", Cluster: "prod", Namespace: "missing", Secret: "secret"}, "GET /configs/app/prod/missing: unexpected status 404"},
+		{"invalid document", ApolloOptions{Server: srv.URL, AppID: "app", Cluster: "prod", Namespace: "server.yaml", Secret: "secret"}, "apollo: namespace server.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewApolloSource(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AppConfigOptions configures a source backed by an AWS AppConfig freeform configuration profile.
+type AppConfigOptions struct {
+	// Region is the AWS region (default: AWS_REGION / AWS_DEFAULT_REGION).
+	Region string
+	// Application, Environment and Profile identify the configuration (names or ids).
+	Application string
+	Environment string
+	Profile     string
+	// AccessKeyID, SecretAccessKey and SessionToken are the AWS credentials used to sign
+	// requests (default: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN).
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides the AppConfig Data endpoint (default "https://appconfigdata.<region>.amazonaws.com").
+	Endpoint string
+	// MinPollInterval is the minimum poll interval requested for the session (default and minimum 15s).
+	MinPollInterval time.Duration
+	// Client is the HTTP client (default: a client with a 30s timeout).
+	Client *http.Client
+}
+
+// AppConfigSource loads a freeform YAML or JSON configuration profile from AWS AppConfig
+// through the AppConfig Data session API (StartConfigurationSession + GetLatestConfiguration).
+// The configuration is replaced only when AppConfig serves new content, which also covers
+// rollbacks: a rolled back deployment serves the previous version again and it is applied
+// like any other change. Content that fails to parse is rejected and the current tree is kept.
+type AppConfigSource struct {
+	opts AppConfigOptions
+	y    *YAML
+
+	mu       sync.Mutex
+	token    string
+	interval time.Duration
+	version  string
+}
+
+// NewAppConfigSource starts a configuration session, fetches the configuration once and returns the source.
+func NewAppConfigSource(ctx context.Context, opts AppConfigOptions) (*AppConfigSource, error) {
+	if opts.Application == "" || opts.Environment == "" || opts.Profile == "" {
+		return nil, errors.New("appconfig: Application, Environment and Profile are required")
+	}
+	if opts.Region == "" {
+		opts.Region = os.Getenv("AWS_REGION")
+	}
+	if opts.Region == "" {
+		opts.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if opts.AccessKeyID == "" {
+		opts.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		opts.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		opts.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if opts.Region == "" || opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("appconfig: region and credentials are required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://appconfigdata." + opts.Region + ".amazonaws.com"
+	}
+	if opts.MinPollInterval < 15*time.Second {
+		opts.MinPollInterval = 15 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	opts.Endpoint = strings.TrimRight(opts.Endpoint, "/")
+
+	s := &AppConfigSource{opts: opts, y: &YAML{}, interval: opts.MinPollInterval}
+	if err := s.startSession(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *AppConfigSource) YAML() *YAML {
+	return s.y
+}
+
+// Version returns the version label of the applied configuration version
+// (empty when the profile does not use version labels).
+func (s *AppConfigSource) Version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Reload fetches the latest deployed configuration and replaces the configuration tree
+// when it changed. An expired session is restarted transparently.
+func (s *AppConfigSource) Reload(ctx context.Context) error {
+	err := s.getLatest(ctx)
+	if errors.Is(err, errAppConfigSessionExpired) {
+		if err := s.startSession(ctx); err != nil {
+			return err
+		}
+		err = s.getLatest(ctx)
+	}
+	return err
+}
+
+// Watch polls AppConfig at the interval requested by the service until ctx is cancelled.
+// Transient errors are retried with backoff; onError (optional) receives them for logging.
+func (s *AppConfigSource) Watch(ctx context.Context, onError func(error)) error {
+	wait := func(ctx context.Context) (bool, error) {
+		s.mu.Lock()
+		interval := s.interval
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+			return true, nil
+		}
+	}
+	return watchLoop(ctx, wait, s.Reload, onError)
+}
+
+// errAppConfigSessionExpired - токен сессии истек (действует 24 часа), нужна новая сессия
+var errAppConfigSessionExpired = errors.New("appconfig: configuration session expired")
+
+func (s *AppConfigSource) startSession(ctx context.Context) error {
+	body, _ := json.Marshal(map[string]any{
+		"ApplicationIdentifier":                s.opts.Application,
+		"EnvironmentIdentifier":                s.opts.Environment,
+		"ConfigurationProfileIdentifier":       s.opts.Profile,
+		"RequiredMinimumPollIntervalInSeconds": int(s.opts.MinPollInterval / time.Second),
+	})
+	resp, err := s.do(ctx, http.MethodPost, "/configurationsessions", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("appconfig: start session: %s", appConfigError(resp))
+	}
+	var out struct {
+		InitialConfigurationToken string `json:"InitialConfigurationToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("appconfig: decode session: %w", err)
+	}
+	s.mu.Lock()
+	s.token = out.InitialConfigurationToken
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *AppConfigSource) getLatest(ctx context.Context) error {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	resp, err := s.do(ctx, http.MethodGet, "/configuration?configuration_token="+url.QueryEscape(token), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusBadRequest {
+		// Истекший или уже использованный токен - начинаем сессию заново
+		return fmt.Errorf("%w: %s", errAppConfigSessionExpired, appConfigError(resp))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("appconfig: get latest configuration: %s", appConfigError(resp))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("appconfig: read configuration: %w", err)
+	}
+
+	// Следующий запрос обязан использовать новый токен, даже если сейчас произошла ошибка разбора
+	s.mu.Lock()
+	if next := resp.Header.Get("Next-Poll-Configuration-Token"); next != "" {
+		s.token = next
+	}
+	if sec, err := strconv.Atoi(resp.Header.Get("Next-Poll-Interval-In-Seconds")); err == nil && sec > 0 {
+		s.interval = time.Duration(sec) * time.Second
+	}
+	s.mu.Unlock()
+
+	// Пустое тело - конфигурация не менялась с прошлого запроса
+	if len(data) == 0 {
+		return nil
+	}
+	// JSON - подмножество YAML, поэтому оба формата разбираются одинаково
+	y, err := ParseYAML(data)
+	if err != nil {
+		return fmt.Errorf("appconfig: %s/%s/%s: %w", s.opts.Application, s.opts.Environment, s.opts.Profile, err)
+	}
+	s.y.Replace(y)
+
+	s.mu.Lock()
+	s.version = resp.Header.Get("Version-Label")
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *AppConfigSource) do(ctx context.Context, method, pathWithQuery string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.opts.Endpoint+pathWithQuery, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	signAWSV4(req, body, s.opts.Region, "appconfig", s.opts.AccessKeyID, s.opts.SecretAccessKey, s.opts.SessionToken, time.Now())
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("appconfig: %w", err)
+	}
+	return resp, nil
+}
+
+func appConfigError(resp *http.Response) string {
+	var body struct {
+		Message string `json:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &body) == nil && body.Message != "" {
+		return resp.Status + ": " + body.Message
+	}
+	return "unexpected status " + resp.Status
+}
+
+// signAWSV4 подписывает запрос по AWS Signature Version 4 (заголовок Authorization)
+func signAWSV4(req *http.Request, body []byte, region, service, accessKey, secretKey, sessionToken string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	canonical, signedHeaders := awsV4CanonicalRequest(req, sha256Hex(body))
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	signature := awsV4Signature(secretKey, date, region, service, awsV4StringToSign(amzDate, scope, canonical))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// awsV4CanonicalRequest строит канонический запрос и список подписанных заголовков.
+// Подписываются все заголовки запроса, кроме самой подписи Authorization, и host
+func awsV4CanonicalRequest(req *http.Request, payloadHash string) (canonical, signedHeaders string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		if strings.EqualFold(k, "Authorization") {
+			continue // Повторная подпись запроса не должна включать прежнюю
+		}
+		// Значения повторного заголовка идут через запятую, пробелы по краям убираются,
+		// а последовательные пробелы внутри схлопываются
+		values := make([]string, len(v))
+		for i, value := range v {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(k)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical = strings.Join([]string{req.Method, path, awsV4Query(req.URL.Query()), canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	return canonical, signedHeaders
+}
+
+// awsV4Query кодирует параметры запроса по RFC 3986 и сортирует их по имени, а
+// одинаковые имена - по значению
+func awsV4Query(query url.Values) string {
+	// В каноническом запросе пробел кодируется как %20, а не "+"
+	escape := func(s string) string { return strings.ReplaceAll(url.QueryEscape(s), "+", "%20") }
+	type pair struct{ k, v string }
+	pairs := make([]pair, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, pair{escape(k), escape(v)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].k != pairs[j].k {
+			return pairs[i].k < pairs[j].k
+		}
+		return pairs[i].v < pairs[j].v
+	})
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.k + "=" + p.v
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsV4StringToSign возвращает строку для подписи: алгоритм, время, область и хеш
+// канонического запроса
+func awsV4StringToSign(amzDate, scope, canonical string) string {
+	return "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+}
+
+// awsV4Signature выводит ключ подписи из секретного ключа и области и подписывает строку
+func awsV4Signature(secretKey, date, region, service, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package runtime
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ключи и время из набора тестов AWS Signature Version 4 (aws-sig-v4-test-suite)
+const (
+	awsTestAccessKey = "AKIDEXAMPLE"
+	awsTestSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	awsTestToken     = "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTflfKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2ICCR/oLxBA=="
+)
+
+var awsTestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignAWSV4TestSuite(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		headers   [][2]string
+		body      string
+		token     string
+		canonical string
+		signed    string
+		signature string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET", url: "https://example.amazonaws.com/",
+			canonical: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET", url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			canonical: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "get-vanilla-query-order-value",
+			method: "GET", url: "https://example.amazonaws.com/?Param1=value2&Param1=value1",
+			canonical: "GET\n/\nParam1=value1&Param1=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date",
+			signature: "5772eed61e12b33fae39ee5e7012498b51d56abc0abb7c60486157bd471c4694",
+		},
+		{
+			name:   "get-vanilla-utf8-query",
+			method: "GET", url: "https://example.amazonaws.com/?ሴ=bar",
+			canonical: "GET\n/\n%E1%88%B4=bar\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date",
+			signature: "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04",
+		},
+		{
+			name:   "get-header-value-trim",
+			method: "GET", url: "https://example.amazonaws.com/",
+			headers:   [][2]string{{"My-Header1", " value1"}, {"My-Header2", ` "a   b   c"`}},
+			canonical: "GET\n/\n\nhost:example.amazonaws.com\nmy-header1:value1\nmy-header2:\"a b c\"\nx-amz-date:20150830T123600Z\n\nhost;my-header1;my-header2;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;my-header1;my-header2;x-amz-date",
+			signature: "acc3ed3afb60bb290fc8d2dd0098b9911fcaa05412b367055dee359757a9c736",
+		},
+		{
+			name:   "post-vanilla",
+			method: "POST", url: "https://example.amazonaws.com/",
+			canonical: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date",
+			signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "post-x-www-form-urlencoded",
+			method: "POST", url: "https://example.amazonaws.com/",
+			headers:   [][2]string{{"Content-Type", "application/x-www-form-urlencoded"}},
+			body:      "Param1=value1",
+			canonical: "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\ncontent-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			signed:    "content-type;host;x-amz-date",
+			signature: "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			// Токен сессии подписывается вместе с остальными заголовками
+			name:   "post-sts-header-before",
+			method: "POST", url: "https://example.amazonaws.com/",
+			token:     awsTestToken,
+			canonical: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\nx-amz-security-token:" + awsTestToken + "\n\nhost;x-amz-date;x-amz-security-token\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			signed:    "host;x-amz-date;x-amz-security-token",
+			signature: "85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, h := range tt.headers {
+				req.Header.Add(h[0], h[1])
+			}
+			signAWSV4(req, []byte(tt.body), "us-east-1", "service", awsTestAccessKey, awsTestSecretKey, tt.token, awsTestTime)
+
+			canonical, signed := awsV4CanonicalRequest(req, sha256Hex([]byte(tt.body)))
+			if canonical != tt.canonical {
+				t.Errorf("canonical request:\n%s\nwant:\n%s", canonical, tt.canonical)
+			}
+			if signed != tt.signed {
+				t.Errorf("signed headers = %s, want %s", signed, tt.signed)
+			}
+			if got := req.Header.Get("X-Amz-Security-Token"); got != tt.token {
+				t.Errorf("X-Amz-Security-Token = %q, want %q", got, tt.token)
+			}
+			stringToSign := awsV4StringToSign("20150830T123600Z", "20150830/us-east-1/service/aws4_request", tt.canonical)
+			if got := awsV4Signature(awsTestSecretKey, "20150830", "us-east-1", "service", stringToSign); got != tt.signature {
+				t.Errorf("signature = %s, want %s", got, tt.signature)
+			}
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=" + tt.signed + ", Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization = %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestAWSV4StringToSign(t *testing.T) {
+	// Строки для подписи (.sts) из набора тестов AWS
+	tests := []struct {
+		name, canonical, want string
+	}{
+		{
+			"get-vanilla",
+			"GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\nbb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+		},
+		{
+			"get-vanilla-query-order-key-case",
+			"GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n816cd5b414d056048ba4f7c5386d6e0533120fb1fcfa93762cf0fc39e2cf19e0",
+		},
+		{
+			"post-vanilla",
+			"POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n553f88c9e4d10fc9e109e2aeb65f030801b70c2f6468faca261d401ae622fc87",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := awsV4StringToSign("20150830T123600Z", "20150830/us-east-1/service/aws4_request", tt.canonical); got != tt.want {
+				t.Errorf("string to sign:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignAWSV4Resign(t *testing.T) {
+	// Повторная подпись (например, при повторе запроса) не включает прежний Authorization:
+	// результат совпадает с post-sts-header-before
+	req, err := http.NewRequest("POST", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signAWSV4(req, nil, "us-east-1", "service", awsTestAccessKey, awsTestSecretKey, awsTestToken, awsTestTime.Add(-time.Hour))
+	signAWSV4(req, nil, "us-east-1", "service", awsTestAccessKey, awsTestSecretKey, awsTestToken, awsTestTime)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+}
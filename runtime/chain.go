@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChainLink is one element of a source chain spec: the source kind and its argument.
+// "env" has no argument, "file:/etc/app/config.yaml" is kind "file" with the path,
+// "consul://app/prod" is kind "consul" with "app/prod".
+type ChainLink struct {
+	Kind string
+	Arg  string
+}
+
+func (l ChainLink) String() string {
+	if l.Arg == "" {
+		return l.Kind
+	}
+	return l.Kind + ":" + l.Arg
+}
+
+// ParseChain parses a comma-separated source chain spec, highest priority first:
+//
+//	flag,env,file:/etc/app/config.yaml,consul://prefix
+//
+// Kinds are case-insensitive; "kind:arg" and "kind://arg" are equivalent.
+func ParseChain(spec string) ([]ChainLink, error) {
+	var links []ChainLink
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, arg, _ := strings.Cut(part, ":")
+		arg = strings.TrimPrefix(arg, "//")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "" {
+			return nil, fmt.Errorf("source chain %q: missing source kind in %q", spec, part)
+		}
+		links = append(links, ChainLink{Kind: kind, Arg: arg})
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("source chain %q is empty", spec)
+	}
+	return links, nil
+}
+
+// BuildChain parses spec with ParseChain and creates a source for every link with the
+// factory registered for its kind, preserving the order. The result is meant for the
+// generated New...All; generated New...Chain functions wrap it with the built-in kinds
+// env and file, so the source topology can come from configuration:
+//
+//	cfg, err := gconfig.NewInternalServerConfigChain(os.Getenv("CONFIG_CHAIN"), nil)
+func BuildChain[S any](spec string, factories map[string]func(arg string) (S, error)) ([]S, error) {
+	links, err := ParseChain(spec)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]S, 0, len(links))
+	for _, l := range links {
+		factory, ok := factories[l.Kind]
+		if !ok {
+			kinds := make([]string, 0, len(factories))
+			for k := range factories {
+				kinds = append(kinds, k)
+			}
+			sort.Strings(kinds)
+			return nil, fmt.Errorf("source chain: unknown source %q (known: %s)", l.Kind, strings.Join(kinds, ", "))
+		}
+		s, err := factory(l.Arg)
+		if err != nil {
+			return nil, fmt.Errorf("source chain: %s: %w", l, err)
+		}
+		sources = append(sources, s)
+	}
+	return sources, nil
+}
@@ -0,0 +1,119 @@
+package runtime
+
+import (
+	mathrand "math/rand"
+	"sync"
+	"time"
+)
+
+// ChaosOptions configures the faults a Chaos injects into config lookups.
+type ChaosOptions struct {
+	// Seed makes the faults reproducible: the same seed and lookup order give the same faults.
+	// 0 picks a random seed, reported by Chaos.Seed for replaying a failed run.
+	Seed int64
+	// FailureRate is the share of lookups (0..1) answered as absent, as if the backend had
+	// lost the key: the generated config returns the default (an error for (T, error) methods).
+	FailureRate float64
+	// LatencyRate is the share of lookups (0..1) delayed by up to Latency.
+	LatencyRate float64
+	// Latency is the maximum injected delay; delays are uniform in [0, Latency].
+	Latency time.Duration
+	// Keys limits the faults to these keys ("section.key", e.g. "server.readtimeout");
+	// empty - all keys.
+	Keys []string
+	// Sleep waits for an injected delay (default time.Sleep); tests can record delays instead.
+	Sleep func(time.Duration)
+}
+
+// ChaosStats counts the lookups seen and the faults injected by a Chaos.
+type ChaosStats struct {
+	Lookups  int64
+	Failures int64
+	Delays   int64
+}
+
+// Chaos decides which config lookups fail or slow down, for resilience tests that check how
+// a service behaves when its config backend degrades. The generated New<Package><Interface>Chaos
+// wraps any implementation with it:
+//
+//	chaos := runtime.NewChaos(runtime.ChaosOptions{Seed: 42, FailureRate: 0.2, LatencyRate: 0.5, Latency: 50 * time.Millisecond})
+//	cfg := gconfig.NewInternalServerConfigChaos(realCfg, chaos)
+//
+// A Chaos is safe for concurrent use and may be shared by the configs of several packages.
+type Chaos struct {
+	mu      sync.Mutex
+	opts    ChaosOptions
+	seed    int64
+	rng     *mathrand.Rand
+	keys    map[string]bool
+	enabled bool
+	stats   ChaosStats
+}
+
+// NewChaos returns an enabled Chaos.
+func NewChaos(opts ChaosOptions) *Chaos {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if opts.Sleep == nil {
+		opts.Sleep = time.Sleep
+	}
+	c := &Chaos{opts: opts, seed: seed, rng: mathrand.New(mathrand.NewSource(seed)), enabled: true}
+	if len(opts.Keys) > 0 {
+		c.keys = make(map[string]bool, len(opts.Keys))
+		for _, k := range opts.Keys {
+			c.keys[k] = true
+		}
+	}
+	return c
+}
+
+// Seed returns the seed in use, e.g. to log it and replay a failed run with ChaosOptions.Seed.
+func (c *Chaos) Seed() int64 {
+	return c.seed
+}
+
+// SetEnabled turns fault injection on or off, e.g. to check that a service recovers once the
+// backend is healthy again. A disabled Chaos passes lookups through unchanged.
+func (c *Chaos) SetEnabled(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = enabled
+}
+
+// Stats returns the counters so far.
+func (c *Chaos) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Lookup decides the fate of one lookup of key: it waits for the injected delay, if any, and
+// reports whether the lookup fails. Generated Chaos configs call it before every read.
+func (c *Chaos) Lookup(key string) (fail bool) {
+	c.mu.Lock()
+	if !c.enabled || (c.keys != nil && !c.keys[key]) {
+		c.mu.Unlock()
+		return false
+	}
+	c.stats.Lookups++
+	// Случайные числа берутся в одном порядке при любом исходе: одно зерно - одни и те же сбои
+	delayRoll, delay, failRoll := c.rng.Float64(), c.rng.Float64(), c.rng.Float64()
+	var wait time.Duration
+	if delayRoll < c.opts.LatencyRate && c.opts.Latency > 0 {
+		wait = time.Duration(delay * float64(c.opts.Latency))
+		c.stats.Delays++
+	}
+	fail = failRoll < c.opts.FailureRate
+	if fail {
+		c.stats.Failures++
+	}
+	sleep := c.opts.Sleep
+	c.mu.Unlock()
+
+	if wait > 0 {
+		sleep(wait)
+	}
+	return fail
+}
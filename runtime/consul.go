@@ -0,0 +1,219 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsulOptions configures a secret resolver backed by the Consul KV store.
+type ConsulOptions struct {
+	// Address is the Consul agent address (default: CONSUL_HTTP_ADDR, then "http://127.0.0.1:8500").
+	Address string
+	// Token is the ACL token (default: CONSUL_HTTP_TOKEN).
+	Token string
+	// Prefix is prepended to every reference, e.g. "my-service/prod" for SERVER_DB_PASSWORD (optional).
+	Prefix string
+	// Datacenter selects the datacenter to read from (default: the agent's).
+	Datacenter string
+	// CacheTTL is how long resolved keys are cached (default 5 minutes, negative disables caching).
+	CacheTTL time.Duration
+	// OnError (optional) receives resolution errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+	// Client is the HTTP client (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// ConsulSource resolves ggconfig:secret methods from Consul KV: a reference is the key,
+// relative to ConsulOptions.Prefix. PrefetchSecrets reads the common prefix of all references
+// with one recursive request instead of one request per key.
+type ConsulSource struct {
+	opts ConsulOptions
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+type consulPair struct {
+	Key   string
+	Value []byte // base64 в JSON, encoding/json декодирует сам
+}
+
+// NewConsulSource returns a resolver for the given agent. Keys are fetched lazily.
+func NewConsulSource(opts ConsulOptions) (*ConsulSource, error) {
+	if opts.Address == "" {
+		opts.Address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if opts.Address == "" {
+		opts.Address = "127.0.0.1:8500"
+	}
+	if !strings.Contains(opts.Address, "://") {
+		opts.Address = "http://" + opts.Address
+	}
+	if _, err := url.Parse(opts.Address); err != nil {
+		return nil, fmt.Errorf("consul: address: %w", err)
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+	return &ConsulSource{opts: opts, cache: map[string]cachedSecret{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *ConsulSource) ResolveSecret(ref string) (string, bool) {
+	s.mu.Lock()
+	c, ok := s.cache[ref]
+	s.mu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.value, true
+	}
+
+	v, err := s.Lookup(context.Background(), ref)
+	if err != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(ref, err)
+		}
+		return "", false
+	}
+	s.store(map[string]string{ref: v})
+	return v, true
+}
+
+// Lookup fetches a key by reference, bypassing the cache.
+func (s *ConsulSource) Lookup(ctx context.Context, ref string) (string, error) {
+	key := s.key(ref)
+	pairs, err := s.get(ctx, key, false)
+	if err != nil {
+		return "", err
+	}
+	if len(pairs) == 0 {
+		return "", fmt.Errorf("consul: %s: key %q not found", ref, key)
+	}
+	return string(pairs[0].Value), nil
+}
+
+// PrefetchSecrets implements SecretPrefetcher: the keys under the longest common prefix of
+// refs are read with one recursive request and the requested ones are cached for CacheTTL;
+// a missing key is an error. Without caching it does nothing.
+func (s *ConsulSource) PrefetchSecrets(ctx context.Context, refs []string) error {
+	if s.opts.CacheTTL <= 0 || len(refs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(refs))
+	for i, ref := range refs {
+		keys[i] = s.key(ref)
+	}
+	pairs, err := s.get(ctx, consulCommonPrefix(keys), true)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		found[p.Key] = string(p.Value)
+	}
+	values := make(map[string]string, len(refs))
+	for i, ref := range refs {
+		v, ok := found[keys[i]]
+		if !ok {
+			return fmt.Errorf("consul: %s: key %q not found", ref, keys[i])
+		}
+		values[ref] = v
+	}
+	s.store(values)
+	return nil
+}
+
+// Invalidate drops the cached keys, e.g. after a change reported by a Consul watch.
+func (s *ConsulSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[string]cachedSecret{}
+}
+
+func (s *ConsulSource) store(values map[string]string) {
+	if s.opts.CacheTTL <= 0 {
+		return
+	}
+	expires := time.Now().Add(s.opts.CacheTTL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ref, v := range values {
+		s.cache[ref] = cachedSecret{value: v, expires: expires}
+	}
+}
+
+// key возвращает ключ KV ссылки под Prefix
+func (s *ConsulSource) key(ref string) string {
+	ref = strings.Trim(ref, "/")
+	if s.opts.Prefix == "" {
+		return ref
+	}
+	return s.opts.Prefix + "/" + ref
+}
+
+// get читает ключ или, с recurse, все ключи под префиксом; отсутствие ключей - пустой результат
+func (s *ConsulSource) get(ctx context.Context, key string, recurse bool) ([]consulPair, error) {
+	q := url.Values{}
+	if recurse {
+		q.Set("recurse", "true")
+	}
+	if s.opts.Datacenter != "" {
+		q.Set("dc", s.opts.Datacenter)
+	}
+	u := s.opts.Address + "/v1/kv/" + (&url.URL{Path: key}).EscapedPath()
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.Token != "" {
+		req.Header.Set("X-Consul-Token", s.opts.Token)
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul: GET /v1/kv/%s: unexpected status %s", key, resp.Status)
+	}
+	var pairs []consulPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("consul: decode /v1/kv/%s: %w", key, err)
+	}
+	return pairs, nil
+}
+
+// consulCommonPrefix возвращает общий префикс ключей по границе сегментов "/", без
+// завершающего "/": recurse по нему находит и ключ, совпадающий с префиксом
+func consulCommonPrefix(keys []string) string {
+	prefix := strings.Split(keys[0], "/")
+	for _, key := range keys[1:] {
+		parts := strings.Split(key, "/")
+		n := 0
+		for n < len(prefix) && n < len(parts) && prefix[n] == parts[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return strings.Join(prefix, "/")
+}
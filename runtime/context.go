@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type overridesKey struct{}
+
+// WithOverrides returns a copy of ctx carrying key overrides, keyed "section.key" like the YAML
+// layout (server.readtimeout), for per-test and per-request tweaks such as a canary timeout:
+//
+//	ctx = runtime.WithOverrides(ctx, map[string]any{"server.readtimeout": "2s"})
+//	srv.Handle(ctx, cfg.WithContext(ctx))
+//
+// Overrides of an outer context stay visible unless the same key is overridden again; a nil
+// value makes the key absent. Sources are not changed: only the context-aware configs returned
+// by the generated WithContext methods consult the overrides.
+func WithOverrides(ctx context.Context, overrides map[string]any) context.Context {
+	merged := map[string]any{}
+	if outer, ok := ctx.Value(overridesKey{}).(map[string]any); ok {
+		for k, v := range outer {
+			merged[k] = v
+		}
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, overridesKey{}, merged)
+}
+
+// ContextOverrides returns a copy of the overrides attached to ctx by WithOverrides.
+func ContextOverrides(ctx context.Context) map[string]any {
+	outer, _ := ctx.Value(overridesKey{}).(map[string]any)
+	out := make(map[string]any, len(outer))
+	for k, v := range outer {
+		out[k] = v
+	}
+	return out
+}
+
+// ContextOverride returns the override of key attached to ctx. overridden reports whether the
+// key is overridden at all, ok whether it is overridden with a value (nil makes it absent).
+// A value of another type is converted through YAML, so "2s" and 2000000000 both override a
+// time.Duration key and a string from a request header overrides an int key; a value that
+// does not convert is ignored (overridden is false) and the sources are read as usual.
+func ContextOverride[T any](ctx context.Context, key string) (value T, ok, overridden bool) {
+	if ctx == nil {
+		return value, false, false
+	}
+	overrides, _ := ctx.Value(overridesKey{}).(map[string]any)
+	v, found := overrides[key]
+	if !found {
+		return value, false, false
+	}
+	if v == nil {
+		return value, false, true
+	}
+	if t, isT := v.(T); isT {
+		return t, true, true
+	}
+	converted, err := convertOverride[T](v)
+	if err != nil {
+		return value, false, false
+	}
+	return converted, true, true
+}
+
+func convertOverride[T any](v any) (T, error) {
+	var out T
+	if s, isString := v.(string); isString {
+		// Строки разбираются как YAML значение: "8080" - число, "2s" - длительность
+		if strings.TrimSpace(s) == "" {
+			return out, fmt.Errorf("empty override")
+		}
+		err := yaml.Unmarshal([]byte(s), &out)
+		return out, err
+	}
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return out, err
+	}
+	if err := n.Decode(&out); err != nil {
+		return out, fmt.Errorf("override %v: %w", v, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialsDirectoryEnv is the variable systemd sets to the directory of the service credentials.
+const CredentialsDirectoryEnv = "CREDENTIALS_DIRECTORY"
+
+// Credentials reads systemd service credentials: files that LoadCredential=, LoadCredentialEncrypted=
+// and SetCredential= place in $CREDENTIALS_DIRECTORY, readable only by the service, so secrets
+// reach it without environment variables (which leak into child processes and /proc):
+//
+//	[Service]
+//	LoadCredential=SERVER_DB_PASSWORD:/etc/my-service/db-password
+//	SetCredential=SERVER_PORT:8080
+//
+// The generated New<Package><Interface>CredentialsConfig reads every method from the credential
+// named like its ENV variable; Credentials is also a SecretResolver for ggconfig:secret methods,
+// with the credential name as the reference. The content is the value, without one trailing newline.
+type Credentials struct {
+	dir string
+}
+
+// NewCredentials returns the credentials in dir, or in $CREDENTIALS_DIRECTORY if dir is empty;
+// it is an error if the service was started without credentials.
+func NewCredentials(dir string) (*Credentials, error) {
+	if dir == "" {
+		dir = os.Getenv(CredentialsDirectoryEnv)
+	}
+	if dir == "" {
+		return nil, errors.New("credentials: " + CredentialsDirectoryEnv + " is not set (no LoadCredential= or SetCredential= in the unit)")
+	}
+	return &Credentials{dir: dir}, nil
+}
+
+// Dir returns the credentials directory.
+func (c *Credentials) Dir() string {
+	return c.dir
+}
+
+// Lookup reads the credential name and reports whether it exists.
+func (c *Credentials) Lookup(name string) (string, bool) {
+	// Имя - один элемент пути: ссылка не может выйти за пределы директории
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), true
+}
+
+// ResolveSecret implements SecretResolver: ref is the credential name.
+func (c *Credentials) ResolveSecret(ref string) (string, bool) {
+	return c.Lookup(ref)
+}
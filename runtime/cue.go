@@ -0,0 +1,1277 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseCUE evaluates a CUE document into the section/key structure of a YAML one, so that the
+// data is validated against the schema written next to it before the generated getters see it:
+//
+//	#Server: {
+//		host:     string | *"0.0.0.0"
+//		port:     int & >=1 & <=65535 | *8080
+//		mode:     "debug" | "release"
+//		timeout!: string // required
+//		tags?:    [...string]
+//	}
+//
+//	server: #Server & {
+//		port:    9090
+//		mode:    "release"
+//		timeout: "30s"
+//	}
+//
+// Supported is the data and schema subset of CUE used for configuration: fields (with ?
+// optional and ! required markers and the a: b: c short form), repeated fields that unify,
+// definitions (#Name, closed) referenced and embedded by name, & and | with * defaults,
+// the types null, bool, int, float, number, string and _, the bounds >=, >, <=, <, !=, =~
+// and !~, lists (open [...T]), strings (with escapes, """ multi-line), numbers and // comments.
+// Everything else is rejected with an error naming the construct: references to fields other
+// than definitions (b: a), arithmetic (8000 + 80), logical operators, string interpolation
+// ("\(host)"), comprehensions and let, dynamic and pattern fields ((k): v, [string]: v),
+// imports, builtin calls and packages (len(x), strings.ToUpper). For full CUE evaluate the file
+// with the cue command (cue export --out yaml) and load the result as YAML.
+//
+// A conflict (a value outside its constraints, a field not allowed by a closed definition) is
+// an error; a field left without a concrete value (port: int with no data) is absent, so the
+// getter returns its default, unless it is required.
+func ParseCUE(data []byte) (*YAML, error) {
+	p := &cueParser{src: string(data), line: 1}
+	file, err := p.file()
+	if err != nil {
+		return nil, err
+	}
+	ev := &cueEvaluator{defs: map[string]*cueFieldNode{}, done: map[string]cueExpr{}, active: map[string]bool{}}
+	for _, f := range file.fields {
+		if f.def {
+			if prev, ok := ev.defs[f.label]; ok {
+				// Повторное объявление определения объединяется с первым: поля обоих разрешены
+				ev.defs[f.label] = &cueFieldNode{label: f.label, def: true, line: prev.line,
+					value: &cueBinaryNode{op: '&', x: prev.value, y: f.value, line: f.line}}
+				continue
+			}
+			ev.defs[f.label] = f
+		}
+	}
+	root, err := ev.eval(file, false)
+	if err != nil {
+		return nil, err
+	}
+	v := root.resolve()
+	if v == nil || v.kind&cueStruct == 0 {
+		return nil, fmt.Errorf("cue: document must be a struct")
+	}
+	node, err := v.toNode("")
+	if err != nil {
+		return nil, err
+	}
+	return fromDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}})
+}
+
+// ===== Значения =====
+
+// cueKind - множество допустимых видов значения (битовая маска), 0 - конфликт
+type cueKind uint8
+
+const (
+	cueNull cueKind = 1 << iota
+	cueBool
+	cueInt
+	cueFloat
+	cueString
+	cueStruct
+	cueList
+
+	cueNumber = cueInt | cueFloat
+	cueTop    = cueNull | cueBool | cueNumber | cueString | cueStruct | cueList
+)
+
+func (k cueKind) String() string {
+	names := []string{"null", "bool", "int", "float", "string", "struct", "list"}
+	var out []string
+	for i, name := range names {
+		if k&(1<<i) != 0 {
+			out = append(out, name)
+		}
+	}
+	if len(out) == 0 {
+		return "_|_"
+	}
+	return strings.Join(out, "|")
+}
+
+// cueBound - ограничение скаляра: >=, >, <=, <, != (число или строка), =~ и !~ (регулярное выражение)
+type cueBound struct {
+	op    string
+	value any
+	re    *regexp.Regexp
+}
+
+// cueField - поле структуры; mod - '?' (необязательное), '!' (обязательное) или 0
+type cueField struct {
+	label string
+	mod   byte
+	value cueExpr
+}
+
+// cueVal - одно значение решетки CUE: вид, конкретное значение скаляра, ограничения, поля
+// структуры или элементы списка. Значения не изменяются: unify создает новые
+type cueVal struct {
+	kind     cueKind
+	concrete bool
+	scalar   any // nil (null), bool, int64, float64, string
+	bounds   []cueBound
+
+	fields []*cueField
+	closed bool // структура определения: другие поля запрещены
+
+	elems    []cueExpr
+	rest     cueExpr // тип элементов открытого списка [...T]
+	openList bool
+}
+
+// cueAlt - вариант дизъюнкции; def - вариант помечен * (значение по умолчанию)
+type cueAlt struct {
+	v   *cueVal
+	def bool
+}
+
+// cueExpr - дизъюнкция вариантов; marked - в ней есть варианты по умолчанию
+type cueExpr struct {
+	alts   []cueAlt
+	marked bool
+}
+
+func single(v *cueVal) cueExpr { return cueExpr{alts: []cueAlt{{v: v}}} }
+
+func (e cueExpr) isBottom() bool { return len(e.alts) == 0 }
+
+// unifyExpr объединяет дизъюнкции: каждый вариант с каждым, варианты-конфликты отбрасываются.
+// Значение по умолчанию результата - объединение значений по умолчанию (у дизъюнкции без * им
+// считается она сама)
+func unifyExpr(a, b cueExpr) (cueExpr, error) {
+	out := cueExpr{marked: a.marked || b.marked}
+	var firstErr error
+	for _, x := range a.alts {
+		for _, y := range b.alts {
+			v, err := unifyVal(x.v, y.v)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			out.alts = append(out.alts, cueAlt{v: v, def: (x.def || !a.marked) && (y.def || !b.marked)})
+		}
+	}
+	if out.isBottom() {
+		return out, firstErr
+	}
+	return out, nil
+}
+
+func unifyVal(a, b *cueVal) (*cueVal, error) {
+	kind := a.kind & b.kind
+	if kind == 0 {
+		return nil, fmt.Errorf("conflicting values %s and %s", a, b)
+	}
+	v := &cueVal{kind: kind, bounds: append(append([]cueBound{}, a.bounds...), b.bounds...)}
+	switch {
+	case a.concrete && b.concrete:
+		if !cueScalarEqual(a.scalar, b.scalar) {
+			return nil, fmt.Errorf("conflicting values %s and %s", a, b)
+		}
+		v.concrete, v.scalar = true, a.scalar
+	case a.concrete:
+		v.concrete, v.scalar = true, a.scalar
+	case b.concrete:
+		v.concrete, v.scalar = true, b.scalar
+	}
+	if v.concrete {
+		for _, bound := range v.bounds {
+			if err := bound.check(v.scalar); err != nil {
+				return nil, err
+			}
+		}
+		v.bounds = nil
+	}
+	if kind&cueStruct != 0 {
+		fields, err := unifyFields(a, b)
+		if err != nil {
+			return nil, err
+		}
+		v.fields, v.closed = fields, a.closed || b.closed
+	}
+	if kind&cueList != 0 {
+		if err := unifyLists(v, a, b); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// unifyFields объединяет поля структур; поля закрытой структуры (определения) ограничивают другую
+func unifyFields(a, b *cueVal) ([]*cueField, error) {
+	index := map[string]int{}
+	var out []*cueField
+	if a.fields != nil || b.fields != nil {
+		out = []*cueField{}
+	}
+	for _, f := range a.fields {
+		index[f.label] = len(out)
+		out = append(out, f)
+	}
+	for _, f := range b.fields {
+		i, ok := index[f.label]
+		if !ok {
+			if a.closed && a.kind == cueStruct {
+				return nil, fmt.Errorf("field %s not allowed", f.label)
+			}
+			index[f.label] = len(out)
+			out = append(out, f)
+			continue
+		}
+		value, err := unifyExpr(out[i].value, f.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.label, err)
+		}
+		out[i] = &cueField{label: f.label, mod: unifyMod(out[i].mod, f.mod), value: value}
+	}
+	if b.closed && b.kind == cueStruct {
+		for _, f := range a.fields {
+			if _, ok := fieldIndex(b.fields, f.label); !ok {
+				return nil, fmt.Errorf("field %s not allowed", f.label)
+			}
+		}
+	}
+	return out, nil
+}
+
+func fieldIndex(fields []*cueField, label string) (int, bool) {
+	for i, f := range fields {
+		if f.label == label {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// unifyMod: обычное поле сильнее необязательного, обязательное - пока не получит значение
+func unifyMod(a, b byte) byte {
+	if a == '!' || b == '!' {
+		return '!'
+	}
+	if a == '?' && b == '?' {
+		return '?'
+	}
+	return 0
+}
+
+// unifyLists объединяет списки поэлементно; элементы сверх длины открытого списка
+// проверяются его типом [...T]. Список без элементов и без ... (значение _) длину не ограничивает
+func unifyLists(v, a, b *cueVal) error {
+	open := func(l *cueVal) bool { return l.openList || l.elems == nil }
+	elem := func(l *cueVal, i int) (cueExpr, error) {
+		if i < len(l.elems) {
+			return l.elems[i], nil
+		}
+		if !open(l) {
+			return cueExpr{}, fmt.Errorf("incompatible list lengths (%d and %d)", len(a.elems), len(b.elems))
+		}
+		if l.rest.isBottom() {
+			return single(&cueVal{kind: cueTop}), nil
+		}
+		return l.rest, nil
+	}
+	n := len(a.elems)
+	if len(b.elems) > n {
+		n = len(b.elems)
+	}
+	if a.elems != nil || b.elems != nil {
+		v.elems = []cueExpr{}
+	}
+	for i := 0; i < n; i++ {
+		x, err := elem(a, i)
+		if err != nil {
+			return err
+		}
+		y, err := elem(b, i)
+		if err != nil {
+			return err
+		}
+		e, err := unifyExpr(x, y)
+		if err != nil {
+			return fmt.Errorf("list element %d: %w", i, err)
+		}
+		v.elems = append(v.elems, e)
+	}
+	v.openList = open(a) && open(b)
+	switch {
+	case !v.openList:
+	case a.rest.isBottom():
+		v.rest = b.rest
+	case b.rest.isBottom():
+		v.rest = a.rest
+	default:
+		rest, err := unifyExpr(a.rest, b.rest)
+		if err != nil {
+			return err
+		}
+		v.rest = rest
+	}
+	return nil
+}
+
+func (b cueBound) check(value any) error {
+	switch b.op {
+	case "=~", "!~":
+		s, ok := value.(string)
+		if !ok || b.re.MatchString(s) != (b.op == "=~") {
+			return fmt.Errorf("invalid value %s (does not satisfy %s%q)", cueFormat(value), b.op, b.re.String())
+		}
+		return nil
+	case "!=":
+		if cueScalarEqual(value, b.value) {
+			return fmt.Errorf("invalid value %s (does not satisfy !=%s)", cueFormat(value), cueFormat(b.value))
+		}
+		return nil
+	}
+	var ok bool
+	if x, isNum := cueNumberValue(value); isNum {
+		y, _ := cueNumberValue(b.value)
+		switch b.op {
+		case ">=":
+			ok = x >= y
+		case ">":
+			ok = x > y
+		case "<=":
+			ok = x <= y
+		case "<":
+			ok = x < y
+		}
+	} else if x, isString := value.(string); isString {
+		y, _ := b.value.(string)
+		switch b.op {
+		case ">=":
+			ok = x >= y
+		case ">":
+			ok = x > y
+		case "<=":
+			ok = x <= y
+		case "<":
+			ok = x < y
+		}
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %s (out of bound %s%s)", cueFormat(value), b.op, cueFormat(b.value))
+	}
+	return nil
+}
+
+func cueNumberValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func cueScalarEqual(a, b any) bool {
+	if x, ok := cueNumberValue(a); ok {
+		y, isNum := cueNumberValue(b)
+		return isNum && x == y
+	}
+	return a == b
+}
+
+func cueFormat(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(t)
+	}
+	return fmt.Sprint(v)
+}
+
+func (v *cueVal) String() string {
+	if v.concrete {
+		return cueFormat(v.scalar)
+	}
+	return v.kind.String()
+}
+
+// resolve выбирает значение дизъюнкции: единственный вариант или единственное значение по
+// умолчанию; nil - конкретного значения нет (поле отсутствует)
+func (e cueExpr) resolve() *cueVal {
+	alts := dedupeAlts(e.alts)
+	if len(alts) == 1 {
+		return alts[0].v
+	}
+	var defaults []cueAlt
+	for _, a := range alts {
+		if a.def && e.marked {
+			defaults = append(defaults, a)
+		}
+	}
+	if len(defaults) == 1 {
+		return defaults[0].v
+	}
+	return nil
+}
+
+// dedupeAlts убирает одинаковые конкретные варианты ("a" | "a" - одно значение)
+func dedupeAlts(alts []cueAlt) []cueAlt {
+	var out []cueAlt
+	for _, a := range alts {
+		dup := false
+		for i, b := range out {
+			if a.v.concrete && b.v.concrete && a.v.kind == b.v.kind && cueScalarEqual(a.v.scalar, b.v.scalar) {
+				out[i].def = b.def || a.def
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// toNode переводит значение в узел yaml.v3; поля без конкретного значения пропускаются
+func (v *cueVal) toNode(path string) (*yaml.Node, error) {
+	switch {
+	case v.concrete:
+		switch s := v.scalar.(type) {
+		case nil:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		case bool:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(s)}, nil
+		case int64:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(s, 10)}, nil
+		case float64:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(s, 'g', -1, 64)}, nil
+		case string:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}, nil
+		}
+	case v.kind == cueStruct || (v.kind&cueStruct != 0 && v.fields != nil):
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, f := range v.fields {
+			fieldPath := strings.TrimPrefix(path+"."+f.label, ".")
+			if f.mod == '?' {
+				continue
+			}
+			fv := f.value.resolve()
+			if fv == nil || !fv.isConcrete() {
+				if f.mod == '!' {
+					return nil, fmt.Errorf("cue: field %s is required", fieldPath)
+				}
+				continue
+			}
+			child, err := fv.toNode(fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.label}, child)
+		}
+		return node, nil
+	case v.kind == cueList || (v.kind&cueList != 0 && v.elems != nil):
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, e := range v.elems {
+			ev := e.resolve()
+			if ev == nil || !ev.isConcrete() {
+				return nil, fmt.Errorf("cue: %s[%d]: incomplete value", path, i)
+			}
+			child, err := ev.toNode(fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("cue: %s: incomplete value %s", path, v)
+}
+
+// isConcrete - у значения есть данные: скаляр, структура или список без неопределенных элементов
+func (v *cueVal) isConcrete() bool {
+	if v.concrete {
+		return true
+	}
+	if v.kind == cueStruct || (v.kind&cueStruct != 0 && v.fields != nil) {
+		return true
+	}
+	if v.kind == cueList || (v.kind&cueList != 0 && v.elems != nil) {
+		for _, e := range v.elems {
+			ev := e.resolve()
+			if ev == nil || !ev.isConcrete() {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ===== Вычисление =====
+
+type cueEvaluator struct {
+	defs   map[string]*cueFieldNode
+	done   map[string]cueExpr
+	active map[string]bool
+}
+
+func (ev *cueEvaluator) eval(n cueNode, closed bool) (cueExpr, error) {
+	switch t := n.(type) {
+	case *cueLitNode:
+		return single(t.v), nil
+	case *cueDefaultNode:
+		e, err := ev.eval(t.x, closed)
+		if err != nil {
+			return e, err
+		}
+		out := cueExpr{marked: true}
+		for _, a := range e.alts {
+			out.alts = append(out.alts, cueAlt{v: a.v, def: true})
+		}
+		return out, nil
+	case *cueBinaryNode:
+		if t.op == '&' && closed {
+			return ev.closedConj(t)
+		}
+		x, err := ev.eval(t.x, closed)
+		if err != nil {
+			return x, err
+		}
+		y, err := ev.eval(t.y, closed)
+		if err != nil {
+			return y, err
+		}
+		if t.op == '|' {
+			// Значения по умолчанию: варианты без * в помеченной дизъюнкции ими не являются
+			out := cueExpr{marked: x.marked || y.marked}
+			for _, side := range []cueExpr{x, y} {
+				for _, a := range side.alts {
+					out.alts = append(out.alts, cueAlt{v: a.v, def: a.def && side.marked})
+				}
+			}
+			return out, nil
+		}
+		e, err := unifyExpr(x, y)
+		if err != nil {
+			return e, fmt.Errorf("cue: line %d: %w", t.line, err)
+		}
+		return e, nil
+	case *cueRefNode:
+		return ev.ref(t)
+	case *cueStructNode:
+		return ev.evalStruct(t, closed)
+	case *cueListNode:
+		v := &cueVal{kind: cueList, elems: []cueExpr{}}
+		for _, elemNode := range t.elems {
+			e, err := ev.eval(elemNode, closed)
+			if err != nil {
+				return e, err
+			}
+			v.elems = append(v.elems, e)
+		}
+		if t.open {
+			v.openList = true
+			v.rest = single(&cueVal{kind: cueTop})
+			if t.rest != nil {
+				rest, err := ev.eval(t.rest, closed)
+				if err != nil {
+					return rest, err
+				}
+				v.rest = rest
+			}
+		}
+		return single(v), nil
+	}
+	return cueExpr{}, fmt.Errorf("cue: unexpected node %T", n)
+}
+
+// closedConj вычисляет a & b & ... внутри определения: структуры, записанные в нем, объединяются
+// открытыми и закрываются вместе (#D: {a: int} & {b: int} разрешает a и b), а ссылки на другие
+// определения остаются закрытыми
+func (ev *cueEvaluator) closedConj(t *cueBinaryNode) (cueExpr, error) {
+	var leaves []cueNode
+	var collect func(n cueNode)
+	collect = func(n cueNode) {
+		if b, ok := n.(*cueBinaryNode); ok && b.op == '&' {
+			collect(b.x)
+			collect(b.y)
+			return
+		}
+		leaves = append(leaves, n)
+	}
+	collect(t)
+	var result cueExpr
+	literal := false
+	for i, leaf := range leaves {
+		e, err := ev.eval(leaf, true)
+		if err != nil {
+			return e, err
+		}
+		if _, ok := leaf.(*cueStructNode); ok {
+			e, literal = setClosed(e, false), true
+		}
+		if i == 0 {
+			result = e
+			continue
+		}
+		if result, err = unifyExpr(result, e); err != nil {
+			return result, fmt.Errorf("cue: line %d: %w", t.line, err)
+		}
+	}
+	if literal {
+		result = setClosed(result, true)
+	}
+	return result, nil
+}
+
+func (ev *cueEvaluator) evalStruct(t *cueStructNode, closed bool) (cueExpr, error) {
+	// Структура собирается открытой и закрывается в конце: иначе первое же поле определения
+	// оказалось бы "не разрешено"
+	result := single(&cueVal{kind: cueStruct, fields: []*cueField{}})
+	for _, f := range t.fields {
+		if f.def {
+			continue
+		}
+		value, err := ev.eval(f.value, closed)
+		if err != nil {
+			return value, err
+		}
+		part := single(&cueVal{kind: cueStruct, fields: []*cueField{{label: f.label, mod: f.mod, value: value}}})
+		if result, err = unifyExpr(result, part); err != nil {
+			return result, fmt.Errorf("cue: line %d: %w", f.line, err)
+		}
+	}
+	for _, embed := range t.embeds {
+		e, err := ev.eval(embed, closed)
+		if err != nil {
+			return e, err
+		}
+		// Встроенное определение закрывает структуру: разрешены его поля и поля самой структуры
+		embedClosed := false
+		for _, a := range e.alts {
+			embedClosed = embedClosed || a.v.closed
+		}
+		if result, err = unifyExpr(setClosed(e, false), result); err != nil {
+			return result, fmt.Errorf("cue: line %d: %w", t.line, err)
+		}
+		closed = closed || embedClosed
+	}
+	if closed {
+		result = setClosed(result, true)
+	}
+	return result, nil
+}
+
+// setClosed возвращает копию дизъюнкции с измененной закрытостью структур
+func setClosed(e cueExpr, closed bool) cueExpr {
+	out := cueExpr{marked: e.marked, alts: make([]cueAlt, len(e.alts))}
+	for i, a := range e.alts {
+		v := *a.v
+		v.closed = closed && v.kind&cueStruct != 0
+		out.alts[i] = cueAlt{v: &v, def: a.def}
+	}
+	return out
+}
+
+// ref вычисляет определение #Name один раз; циклические ссылки - ошибка
+func (ev *cueEvaluator) ref(t *cueRefNode) (cueExpr, error) {
+	if e, ok := ev.done[t.name]; ok {
+		return e, nil
+	}
+	def, ok := ev.defs[t.name]
+	if !ok {
+		return cueExpr{}, fmt.Errorf("cue: line %d: reference %q not found (only definitions can be referenced)", t.line, t.name)
+	}
+	if ev.active[t.name] {
+		return cueExpr{}, fmt.Errorf("cue: line %d: cyclic reference to %s", t.line, t.name)
+	}
+	ev.active[t.name] = true
+	e, err := ev.eval(def.value, true)
+	delete(ev.active, t.name)
+	if err != nil {
+		return e, err
+	}
+	ev.done[t.name] = e
+	return e, nil
+}
+
+// ===== Разбор =====
+
+type cueNode interface{}
+
+type cueLitNode struct{ v *cueVal }
+
+type cueRefNode struct {
+	name string
+	line int
+}
+
+type cueBinaryNode struct {
+	op   byte // '&' или '|'
+	x, y cueNode
+	line int
+}
+
+type cueDefaultNode struct{ x cueNode }
+
+type cueFieldNode struct {
+	label string
+	def   bool // #Name
+	mod   byte
+	value cueNode
+	line  int
+}
+
+type cueStructNode struct {
+	fields []*cueFieldNode
+	embeds []cueNode
+	line   int
+}
+
+type cueListNode struct {
+	elems []cueNode
+	open  bool
+	rest  cueNode
+}
+
+// cueParser - разбор подмножества CUE в дерево узлов
+type cueParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *cueParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("cue: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *cueParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *cueParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *cueParser) hasPrefix(s string) bool { return strings.HasPrefix(p.src[p.pos:], s) }
+
+// skipInline пропускает пробелы и комментарии до конца строки; skipSpace - и переводы строк
+func (p *cueParser) skipInline() {
+	for !p.eof() {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case p.hasPrefix("//"):
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *cueParser) skipSpace() {
+	for {
+		p.skipInline()
+		if p.peek() != '\n' {
+			return
+		}
+		p.line++
+		p.pos++
+	}
+}
+
+func (p *cueParser) ident() string {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (p.pos > start && unicode.IsDigit(r))) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
+
+// file читает документ: необязательное объявление package и поля верхнего уровня
+func (p *cueParser) file() (*cueStructNode, error) {
+	p.skipSpace()
+	if p.hasPrefix("package ") || p.hasPrefix("package\t") {
+		p.pos += len("package")
+		p.skipInline()
+		if p.ident() == "" {
+			return nil, p.errorf("expected a package name")
+		}
+	}
+	p.skipSpace()
+	if p.hasPrefix("import ") || p.hasPrefix("import(") || p.hasPrefix("import\t") {
+		return nil, p.errorf("imports are not supported")
+	}
+	return p.fields(0)
+}
+
+// fields читает поля до закрывающей скобки end (0 - до конца документа). Поля разделяются
+// запятыми или переводами строк
+func (p *cueParser) fields(end byte) (*cueStructNode, error) {
+	s := &cueStructNode{line: p.line}
+	for {
+		p.skipSpace()
+		if end == 0 && p.eof() {
+			return s, nil
+		}
+		if end != 0 && p.peek() == end {
+			p.pos++
+			return s, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("unexpected end of document, expected %q", end)
+		}
+		if err := p.field(s); err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		switch c := p.peek(); {
+		case c == ',':
+			p.pos++
+		case c == '\n' || p.eof() || (end != 0 && c == end):
+		default:
+			return nil, p.errorf("unexpected %q after a field", c)
+		}
+	}
+}
+
+// field читает поле (a: 1, a: b: 1, #Def: {...}, "quoted": 1, a?: int, a!: int) или
+// встроенное определение (#Def без двоеточия)
+func (p *cueParser) field(s *cueStructNode) error {
+	line := p.line
+	label, def, mod, err := p.label()
+	if err != nil {
+		return err
+	}
+	p.skipInline()
+	if p.peek() != ':' {
+		if isCueClause(label) {
+			return p.errorf("comprehensions and %s clauses are not supported", label)
+		}
+		if def && mod == 0 {
+			s.embeds = append(s.embeds, &cueRefNode{name: label, line: line})
+			return nil
+		}
+		return p.errorf("expected ':' after %s", label)
+	}
+	p.pos++
+	p.skipInline()
+	var value cueNode
+	if c := p.peek(); c == '"' || c == '#' || isCueIdentStart(c) {
+		// Короткая форма a: b: 1 - поле с вложенной структурой
+		save, saveLine := p.pos, p.line
+		if nested, _, _, err := p.label(); err == nil && nested != "" && !isCueKeyword(nested) {
+			p.skipInline()
+			if p.peek() == ':' && !p.hasPrefix("::") {
+				p.pos, p.line = save, saveLine
+				inner := &cueStructNode{line: p.line}
+				if err := p.field(inner); err != nil {
+					return err
+				}
+				value = inner
+			}
+		}
+		if value == nil {
+			p.pos, p.line = save, saveLine
+		}
+	}
+	if value == nil {
+		if value, err = p.expr(); err != nil {
+			return err
+		}
+	}
+	s.fields = append(s.fields, &cueFieldNode{label: label, def: def, mod: mod, value: value, line: line})
+	return nil
+}
+
+func isCueIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= utf8.RuneSelf
+}
+
+// isCueClause - ключевые слова comprehension и let, которые подмножество не поддерживает
+func isCueClause(s string) bool {
+	return s == "for" || s == "if" || s == "let"
+}
+
+func isCueKeyword(s string) bool {
+	switch s {
+	case "null", "true", "false", "bool", "int", "float", "number", "string", "bytes", "_":
+		return true
+	}
+	return false
+}
+
+func (p *cueParser) label() (label string, def bool, mod byte, err error) {
+	switch c := p.peek(); {
+	case c == '"':
+		if label, err = p.stringLit(); err != nil {
+			return "", false, 0, err
+		}
+	case c == '#':
+		p.pos++
+		def = true
+		if label = p.ident(); label == "" {
+			return "", false, 0, p.errorf("expected a definition name after #")
+		}
+		label = "#" + label
+	case c == '(' || c == '[':
+		return "", false, 0, p.errorf("dynamic and pattern fields are not supported")
+	default:
+		if label = p.ident(); label == "" {
+			return "", false, 0, p.errorf("expected a field")
+		}
+	}
+	if c := p.peek(); c == '?' || c == '!' {
+		mod = c
+		p.pos++
+	}
+	return label, def, mod, nil
+}
+
+// expr := conj ('|' conj)*
+func (p *cueParser) expr() (cueNode, error) {
+	x, err := p.disjunct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipInline()
+		if p.peek() != '|' {
+			return x, nil
+		}
+		line := p.line
+		p.pos++
+		p.skipSpace()
+		y, err := p.disjunct()
+		if err != nil {
+			return nil, err
+		}
+		x = &cueBinaryNode{op: '|', x: x, y: y, line: line}
+	}
+}
+
+// disjunct := ['*'] conj
+func (p *cueParser) disjunct() (cueNode, error) {
+	if p.peek() == '*' {
+		p.pos++
+		p.skipInline()
+		x, err := p.conj()
+		if err != nil {
+			return nil, err
+		}
+		return &cueDefaultNode{x: x}, nil
+	}
+	return p.conj()
+}
+
+// conj := unary ('&' unary)*
+func (p *cueParser) conj() (cueNode, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipInline()
+		if c := p.peek(); strings.IndexByte("+-*/", c) >= 0 {
+			return nil, p.errorf("arithmetic (%c) is not supported", c)
+		}
+		if p.hasPrefix("&&") || p.hasPrefix("||") {
+			return nil, p.errorf("logical operators (%s) are not supported", p.src[p.pos:p.pos+2])
+		}
+		if p.peek() != '&' {
+			return x, nil
+		}
+		line := p.line
+		p.pos++
+		p.skipSpace()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = &cueBinaryNode{op: '&', x: x, y: y, line: line}
+	}
+}
+
+// unary читает границу (>=1, =~"^a") или первичное выражение
+func (p *cueParser) unary() (cueNode, error) {
+	for _, op := range []string{">=", "<=", "!=", "=~", "!~", ">", "<"} {
+		if !p.hasPrefix(op) {
+			continue
+		}
+		p.pos += len(op)
+		p.skipInline()
+		operand, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		lit, ok := operand.(*cueLitNode)
+		if !ok || !lit.v.concrete {
+			return nil, p.errorf("bound %s requires a literal", op)
+		}
+		b := cueBound{op: op, value: lit.v.scalar}
+		kind := lit.v.kind
+		switch op {
+		case "=~", "!~":
+			s, isString := lit.v.scalar.(string)
+			if !isString {
+				return nil, p.errorf("%s requires a string pattern", op)
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, p.errorf("invalid pattern %q: %v", s, err)
+			}
+			b.re, kind = re, cueString
+		case "!=":
+			kind = cueTop
+		default:
+			if kind&cueNumber != 0 {
+				kind = cueNumber
+			} else if kind != cueString {
+				return nil, p.errorf("bound %s requires a number or a string", op)
+			}
+		}
+		return &cueLitNode{v: &cueVal{kind: kind, bounds: []cueBound{b}}}, nil
+	}
+	return p.primary()
+}
+
+func (p *cueParser) primary() (cueNode, error) {
+	line := p.line
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		p.skipSpace()
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return x, nil
+	case c == '{':
+		p.pos++
+		return p.fields('}')
+	case c == '[':
+		p.pos++
+		return p.list()
+	case c == '"':
+		s, err := p.stringLit()
+		if err != nil {
+			return nil, err
+		}
+		return &cueLitNode{v: &cueVal{kind: cueString, concrete: true, scalar: s}}, nil
+	case c == '#':
+		p.pos++
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("expected a definition name after #")
+		}
+		return &cueRefNode{name: "#" + name, line: line}, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case isCueIdentStart(c):
+		name := p.ident()
+		switch name {
+		case "null":
+			return &cueLitNode{v: &cueVal{kind: cueNull, concrete: true}}, nil
+		case "true", "false":
+			return &cueLitNode{v: &cueVal{kind: cueBool, concrete: true, scalar: name == "true"}}, nil
+		case "_":
+			return &cueLitNode{v: &cueVal{kind: cueTop}}, nil
+		case "bool":
+			return &cueLitNode{v: &cueVal{kind: cueBool}}, nil
+		case "int":
+			return &cueLitNode{v: &cueVal{kind: cueInt}}, nil
+		case "float":
+			return &cueLitNode{v: &cueVal{kind: cueFloat}}, nil
+		case "number":
+			return &cueLitNode{v: &cueVal{kind: cueNumber}}, nil
+		case "string":
+			return &cueLitNode{v: &cueVal{kind: cueString}}, nil
+		}
+		if isCueClause(name) {
+			return nil, p.errorf("comprehensions and %s clauses are not supported", name)
+		}
+		if p.peek() == '(' || p.peek() == '.' {
+			return nil, p.errorf("builtin calls and package references (%s) are not supported", name)
+		}
+		return nil, p.errorf("reference %q is not supported (only definitions #Name can be referenced)", name)
+	}
+	if p.eof() {
+		return nil, p.errorf("unexpected end of document, expected a value")
+	}
+	return nil, p.errorf("unexpected %q, expected a value", p.peek())
+}
+
+// list читает [a, b], [...T] и [a, ...T]
+func (p *cueParser) list() (cueNode, error) {
+	l := &cueListNode{}
+	for {
+		p.skipSpace()
+		if p.peek() == ']' {
+			p.pos++
+			return l, nil
+		}
+		if p.hasPrefix("...") {
+			p.pos += 3
+			p.skipSpace()
+			l.open = true
+			if p.peek() != ']' {
+				rest, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				l.rest = rest
+				p.skipSpace()
+			}
+			if p.peek() == ',' {
+				p.pos++
+				p.skipSpace()
+			}
+			if p.peek() != ']' {
+				return nil, p.errorf("expected ']' after ...")
+			}
+			p.pos++
+			return l, nil
+		}
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		l.elems = append(l.elems, e)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in a list")
+		}
+	}
+}
+
+func (p *cueParser) number() (cueNode, error) {
+	start := p.pos
+	if c := p.peek(); c == '-' || c == '+' {
+		p.pos++
+	}
+	for !p.eof() {
+		c := p.peek()
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '.' ||
+			((c == '-' || c == '+') && (p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	text := p.src[start:p.pos]
+	if i, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return &cueLitNode{v: &cueVal{kind: cueInt, concrete: true, scalar: i}}, nil
+	}
+	if !strings.ContainsAny(text, "xXoObB") {
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil {
+			return &cueLitNode{v: &cueVal{kind: cueFloat, concrete: true, scalar: f}}, nil
+		}
+	}
+	return nil, p.errorf("invalid number %q", text)
+}
+
+// stringLit читает "строку" с экранированием или многострочную """строку""" (отступ
+// закрывающих кавычек убирается из всех строк); интерполяция \( ) не поддерживается
+func (p *cueParser) stringLit() (string, error) {
+	if p.hasPrefix(`"""`) {
+		p.pos += 3
+		end := strings.Index(p.src[p.pos:], `"""`)
+		if end < 0 {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		raw := p.src[p.pos : p.pos+end]
+		p.line += strings.Count(raw, "\n")
+		p.pos += end + 3
+		if !strings.HasPrefix(raw, "\n") {
+			return "", p.errorf(`multi-line string must start with a newline after """`)
+		}
+		lines := strings.Split(raw[1:], "\n")
+		indent := lines[len(lines)-1]
+		if strings.TrimLeft(indent, " \t") != "" {
+			return "", p.errorf(`closing """ must be on its own line`)
+		}
+		lines = lines[:len(lines)-1]
+		for i, l := range lines {
+			lines[i] = strings.TrimPrefix(l, indent)
+		}
+		return p.unescape(strings.Join(lines, "\n"))
+	}
+	p.pos++
+	start := p.pos
+	for !p.eof() {
+		switch p.peek() {
+		case '\\':
+			p.pos += 2
+			continue
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '"':
+			s := p.src[start:p.pos]
+			p.pos++
+			return p.unescape(s)
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *cueParser) unescape(s string) (string, error) {
+	if strings.Contains(s, `\(`) {
+		return "", p.errorf("string interpolation is not supported")
+	}
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		// Кавычки без экранирования встречаются только в многострочных строках
+		if s[0] == '"' {
+			b.WriteByte('"')
+			s = s[1:]
+			continue
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(s, '"')
+		if err != nil {
+			return "", p.errorf("invalid escape in string")
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			b.WriteByte(byte(r))
+		} else {
+			b.WriteRune(r)
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
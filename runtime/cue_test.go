@@ -0,0 +1,324 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseCUE(t *testing.T, src string) *YAML {
+	t.Helper()
+	y, err := ParseCUE([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseCUE: %v", err)
+	}
+	return y
+}
+
+func TestParseCUEDocExample(t *testing.T) {
+	y := mustParseCUE(t, `
+package config
+
+#Server: {
+	host:     string | *"0.0.0.0"
+	port:     int & >=1 & <=65535 | *8080
+	mode:     "debug" | "release"
+	timeout!: string // required
+	tags?:    [...string]
+}
+
+server: #Server & {
+	port:    9090
+	mode:    "release"
+	timeout: "30s"
+}
+`)
+	if v, ok := y.GetString("server", "host"); !ok || v != "0.0.0.0" {
+		t.Errorf("host = %q, %v; want the default 0.0.0.0", v, ok)
+	}
+	if v, ok := y.GetInt("server", "port"); !ok || v != 9090 {
+		t.Errorf("port = %d, %v; want 9090", v, ok)
+	}
+	if v, ok := y.GetString("server", "mode"); !ok || v != "release" {
+		t.Errorf("mode = %q, %v; want release", v, ok)
+	}
+	if v, ok := y.GetDuration("server", "timeout"); !ok || v != 30*time.Second {
+		t.Errorf("timeout = %v, %v; want 30s", v, ok)
+	}
+	if _, ok := y.GetSlice("server", "tags"); ok {
+		t.Error("optional tags without a value must be absent")
+	}
+}
+
+func TestParseCUEValues(t *testing.T) {
+	y := mustParseCUE(t, `
+s: {
+	str:    "a\tbé"
+	quoted: "x\"y"
+	multi: """
+		line1
+		  line2
+		"""
+	int:   42
+	neg:   -7
+	hex:   0x1F
+	under: 1_000
+	float: 2.5
+	exp:   1e3
+	bool:  true
+	null:  null
+	list:  [1, 2, 3]
+	"key with spaces": "v"
+	a: b: c: "short form"
+}
+`)
+	texts := map[string]string{
+		"str":             "a\tbé",
+		"quoted":          `x"y`,
+		"multi":           "line1\n  line2",
+		"key with spaces": "v",
+	}
+	for key, want := range texts {
+		if v, ok := y.GetString("s", key); !ok || v != want {
+			t.Errorf("s.%s = %q, %v; want %q", key, v, ok, want)
+		}
+	}
+	ints := map[string]int{"int": 42, "neg": -7, "hex": 31, "under": 1000}
+	for key, want := range ints {
+		if v, ok := y.GetInt("s", key); !ok || v != want {
+			t.Errorf("s.%s = %d, %v; want %d", key, v, ok, want)
+		}
+	}
+	for key, want := range map[string]string{"float": "2.5", "exp": "1000", "null": "null", "bool": "true"} {
+		if raw, ok := y.GetRaw("s", key); !ok || raw.Node.Value != want {
+			t.Errorf("s.%s = %v, %v; want %s", key, raw.Node, ok, want)
+		}
+	}
+	if v, ok := y.GetSlice("s", "list"); !ok || len(v) != 3 {
+		t.Errorf("s.list = %v, %v; want 3 elements", v, ok)
+	}
+	raw, ok := y.GetRaw("s", "a")
+	if !ok {
+		t.Fatal("s.a not found")
+	}
+	var short struct {
+		B struct {
+			C string `yaml:"c"`
+		} `yaml:"b"`
+	}
+	if err := raw.Node.Decode(&short); err != nil || short.B.C != "short form" {
+		t.Errorf("s.a.b.c = %q, %v; want short form", short.B.C, err)
+	}
+}
+
+func TestParseCUEBounds(t *testing.T) {
+	tests := []struct {
+		name, schema, value string
+		err                 string // пусто - значение допустимо
+	}{
+		{"int in range", "int & >=1 & <=10", "5", ""},
+		{"int at lower bound", "int & >=1 & <=10", "1", ""},
+		{"int below", "int & >=1 & <=10", "0", "invalid value 0 (out of bound >=1)"},
+		{"int above", "int & >=1 & <=10", "11", "invalid value 11 (out of bound <=10)"},
+		{"exclusive", ">0 & <1", "0.5", ""},
+		{"exclusive lower", ">0 & <1", "0", "invalid value 0 (out of bound >0)"},
+		{"exclusive upper", ">0 & <1", "1", "invalid value 1 (out of bound <1)"},
+		{"not equal", "!=0", "0", "invalid value 0 (does not satisfy !=0)"},
+		{"string not equal", `!=""`, `""`, `invalid value "" (does not satisfy !="")`},
+		{"regexp match", `=~"^[a-z]+$"`, `"abc"`, ""},
+		{"regexp mismatch", `=~"^[a-z]+$"`, `"ABC"`, `invalid value "ABC" (does not satisfy =~"^[a-z]+$")`},
+		{"regexp exclusion", `!~"^tmp"`, `"tmp1"`, `invalid value "tmp1" (does not satisfy !~"^tmp")`},
+		{"string bound", `>="b"`, `"a"`, `invalid value "a" (out of bound >="b")`},
+		{"kind", "int", `"8080"`, `conflicting values int and "8080"`},
+		{"int is not float", "int", "1.5", "conflicting values int and 1.5"},
+		{"number accepts int", "number & >=0", "3", ""},
+		{"string is not bool", "bool", `"true"`, `conflicting values bool and "true"`},
+		{"concrete", "8080", "8081", "conflicting values 8080 and 8081"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCUE([]byte("s: v: " + tt.schema + "\ns: v: " + tt.value + "\n"))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseCUEDisjunctions(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      string // пусто - поле отсутствует
+		err       string
+	}{
+		{"default", `v: string | *"x"`, "x", ""},
+		{"default overridden", "v: string | *\"x\"\nv: \"y\"", "y", ""},
+		{"enum value", "v: \"a\" | \"b\"\nv: \"b\"", "b", ""},
+		{"enum conflict", "v: \"a\" | \"b\"\nv: \"c\"", "", `conflicting values "a" and "c"`},
+		{"enum without value", `v: "a" | "b"`, "", ""},
+		{"bounded default", "v: int & >0 | *8080", "8080", ""},
+		{"default of a unified disjunction", "v: *1 | 2 | 3\nv: 2 | 3", "", ""},
+		{"defaults intersect", "v: *1 | 2\nv: *1 | 3", "1", ""},
+		{"duplicate alternatives", "v: \"a\" | \"a\"", "a", ""},
+		{"unified away default", "v: *\"x\" | string\nv: \"y\" | \"z\"", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y, err := ParseCUE([]byte("s: {\n" + tt.src + "\n}\n"))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, ok := y.GetRaw("s", "v")
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("s.v = %v, want absent (no single concrete value)", raw.Node.Value)
+				}
+				return
+			}
+			if !ok || raw.Node.Value != tt.want {
+				t.Fatalf("s.v = %v, %v; want %s", raw.Node, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCUEClosedDefinitions(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"allowed fields", "#D: {a: int, b?: string}\nx: #D & {a: 1}", ""},
+		{"extra field", "#D: {a: int}\nx: #D & {a: 1, c: 2}", "field c not allowed"},
+		{"extra field on the left", "#D: {a: int}\nx: {a: 1, c: 2} & #D", "field c not allowed"},
+		{"embedded definition adds own fields", "#D: {a: int}\nx: {#D, b: 2}\nx: a: 1", ""},
+		{"embedded definition closes", "#D: {a: int}\nx: {#D, b: 2}\nx: c: 3", "field c not allowed"},
+		{"nested definition", "#In: {p: int}\n#Out: {in: #In}\nx: #Out & {in: {p: 1, q: 2}}", "field q not allowed"},
+		{"definition reference", "#Port: int & >0\nx: p: #Port & -1", "invalid value -1 (out of bound >0)"},
+		{"definition redeclared", "#D: {a: int}\n#D: {b?: int}\nx: #D & {a: 1, b: 2}", ""},
+		{"conjunction of literals", "#D: {a: int} & {b?: int}\nx: #D & {a: 1, b: 2}", ""},
+		{"conjunction keeps references closed", "#B: {a: int}\n#D: #B & {c: int}\nx: #D & {a: 1}", "field c not allowed"},
+		{"embedding extends a definition", "#B: {a: int}\n#D: {#B, c?: int}\nx: #D & {a: 1, c: 2}", ""},
+		{"required missing", "#D: {a!: int}\nx: #D & {}", "field x.a is required"},
+		{"required set", "#D: {a!: int}\nx: #D & {a: 1}", ""},
+		{"undefined definition", "x: #Missing", `reference "#Missing" not found`},
+		{"cyclic definitions", "#A: #B\n#B: #A\nx: #A", "cyclic reference to #A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCUE([]byte(tt.src))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseCUEUnification(t *testing.T) {
+	y := mustParseCUE(t, `
+server: host: string
+server: port: int | *80
+server: {
+	host: "example.com"
+}
+server: tags: [...string]
+server: tags: ["a", "b"]
+server: pair: [int, string]
+server: pair: [1, "x"]
+`)
+	if v, ok := y.GetString("server", "host"); !ok || v != "example.com" {
+		t.Errorf("host = %q, %v", v, ok)
+	}
+	if v, ok := y.GetInt("server", "port"); !ok || v != 80 {
+		t.Errorf("port = %d, %v; want the default 80", v, ok)
+	}
+	if v, ok := y.GetStrings(",", "server", "tags"); !ok || len(v) != 2 {
+		t.Errorf("tags = %v, %v", v, ok)
+	}
+
+	conflicts := []struct {
+		name, src, err string
+	}{
+		{"scalars", "a: 1\na: 2", "conflicting values 1 and 2"},
+		{"kinds", "a: {}\na: 1", "conflicting values struct and 1"},
+		{"nested field", "a: b: 1\na: b: \"x\"", `b: conflicting values 1 and "x"`},
+		{"closed list length", "a: [1, 2]\na: [1]", "incompatible list lengths (2 and 1)"},
+		{"open list element type", "a: [...int]\na: [1, \"x\"]", `list element 1: conflicting values int and "x"`},
+		{"bottom reported with line", "a: 1\n\nb: 2 & 3", "line 3"},
+		{"not a struct", "1", "expected a field"},
+	}
+	for _, tt := range conflicts {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCUE([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseCUEIncompleteFieldsAreAbsent(t *testing.T) {
+	y := mustParseCUE(t, "server: {\n\tport: int\n\thost: string | *\"h\"\n\topt?: int\n\tlist: [int]\n}\n")
+	if _, ok := y.GetInt("server", "port"); ok {
+		t.Error("port: int without data must be absent")
+	}
+	if _, ok := y.GetInt("server", "opt"); ok {
+		t.Error("optional field without data must be absent")
+	}
+	if _, ok := y.GetSlice("server", "list"); ok {
+		t.Error("list with an incomplete element must be absent")
+	}
+	if v, ok := y.GetString("server", "host"); !ok || v != "h" {
+		t.Errorf("host = %q, %v; want the default h", v, ok)
+	}
+}
+
+func TestParseCUEUnsupported(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"field reference", "a: 1\nb: a", `line 2: reference "a" is not supported (only definitions #Name can be referenced)`},
+		{"arithmetic +", "port: 8000 + 80", "line 1: arithmetic (+) is not supported"},
+		{"arithmetic *", "n: 2 * 3", "arithmetic (*) is not supported"},
+		{"arithmetic -", "n: 10 - 1", "arithmetic (-) is not supported"},
+		{"arithmetic /", "n: 10 / 2", "arithmetic (/) is not supported"},
+		{"logical operator", "ok: true && false", "logical operators (&&) are not supported"},
+		{"interpolation", `url: "http://\(host)"`, "string interpolation is not supported"},
+		{"list comprehension", "l: [for x in [1, 2] {x}]", "comprehensions and for clauses are not supported"},
+		{"struct comprehension", "s: {\n\tif true {a: 1}\n}", "comprehensions and if clauses are not supported"},
+		{"let", "let x = 1\na: 1", "comprehensions and let clauses are not supported"},
+		{"import", "import \"strings\"\na: 1", "imports are not supported"},
+		{"builtin call", "n: len([1])", "builtin calls and package references (len) are not supported"},
+		{"package reference", "s: strings.ToUpper(\"a\")", "builtin calls and package references (strings) are not supported"},
+		{"pattern field", "s: {[string]: int}", "dynamic and pattern fields are not supported"},
+		{"dynamic field", "s: {(k): 1}", "dynamic and pattern fields are not supported"},
+		{"bound on a type", "a: >=int", "bound >= requires a literal"},
+		{"invalid regexp", `a: =~"("`, `invalid pattern "("`},
+		{"unterminated string", "a: \"x\n", "unterminated string"},
+		{"unterminated struct", "a: {b: 1", `unexpected end of document, expected '}'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCUE([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// DeprecatedKey reports a value read from the key of a method's former name (a method
+// renamed from Host to BindAddress and annotated with ggconfig:was=Host): the configuration
+// still sets SERVER_HOST or server.host and should be migrated to the new key.
+type DeprecatedKey struct {
+	Source string // "env" or "yaml"
+	Key    string // Old ENV variable or "section.key"
+	NewKey string
+}
+
+func (d DeprecatedKey) String() string {
+	return fmt.Sprintf("ggconfig: %s %s is deprecated, use %s", d.Source, d.Key, d.NewKey)
+}
+
+var (
+	deprecationMu      sync.RWMutex
+	deprecationHandler = logDeprecationOnce
+	deprecationLogged  sync.Map // DeprecatedKey -> struct{}
+)
+
+// logDeprecationOnce - обработчик по умолчанию: одно сообщение в стандартный log на ключ,
+// чтобы чтение в горячем пути не засоряло журнал
+func logDeprecationOnce(d DeprecatedKey) {
+	if _, loaded := deprecationLogged.LoadOrStore(d, struct{}{}); !loaded {
+		log.Print(d)
+	}
+}
+
+// SetDeprecationHandler replaces the handler of values read from deprecated keys and returns
+// the previous one. The default handler logs each key once with the standard log package;
+// fn is called on every such read (count them in a metric to see when a migration is done).
+// nil disables notifications.
+func SetDeprecationHandler(fn func(DeprecatedKey)) func(DeprecatedKey) {
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+	prev := deprecationHandler
+	deprecationHandler = fn
+	return prev
+}
+
+// ReportDeprecated passes a value read from a deprecated key to the current handler.
+// It is called by generated code for methods annotated with ggconfig:was.
+func ReportDeprecated(source, key, newKey string) {
+	deprecationMu.RLock()
+	handler := deprecationHandler
+	deprecationMu.RUnlock()
+	if handler != nil {
+		handler(DeprecatedKey{Source: source, Key: key, NewKey: newKey})
+	}
+}
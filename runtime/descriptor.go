@@ -0,0 +1,71 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Descriptor is the machine-readable configuration surface of a generated config: every key
+// with its type and the ENV variables and YAML paths it is read from. The generator writes it
+// with --descriptor as <package>.descriptor.json next to the generated code, which embeds it;
+// an inventory service can scrape it from every deployed service (see DescriptorHandler).
+// Values are not included.
+type Descriptor struct {
+	Package   string          `json:"package"` // Import path of the interface package
+	Interface string          `json:"interface"`
+	Keys      []DescriptorKey `json:"keys"`
+}
+
+// DescriptorKey describes one key of a Descriptor.
+type DescriptorKey struct {
+	Key         string            `json:"key"` // "section.key", as in snapshots and reports
+	Method      string            `json:"method"`
+	Type        string            `json:"type"`
+	Env         []string          `json:"env"`  // ENV variables in lookup order (aliases first)
+	YAML        []string          `json:"yaml"` // "section.key" paths in lookup order (aliases first)
+	Secret      bool              `json:"secret,omitempty"`
+	Description string            `json:"description,omitempty"` // Doc comment of the method
+	Directives  map[string]string `json:"directives,omitempty"`  // ggconfig: directives of the method
+}
+
+// MustParseDescriptor decodes an embedded descriptor. It is called by generated code and
+// panics on malformed data, which means the descriptor file was edited by hand.
+func MustParseDescriptor(data []byte) Descriptor {
+	var d Descriptor
+	if err := json.Unmarshal(data, &d); err != nil {
+		panic(fmt.Sprintf("ggconfig: invalid config descriptor: %v", err))
+	}
+	return d
+}
+
+// DescriptorHandler serves descriptors as JSON ({"configs": [...]}, sorted by package and
+// interface) for an admin endpoint:
+//
+//	mux.Handle("/admin/config/descriptor", runtime.DescriptorHandler(gconfig.Descriptors()...))
+func DescriptorHandler(descriptors ...Descriptor) http.Handler {
+	sorted := append([]Descriptor{}, descriptors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Package != sorted[j].Package {
+			return sorted[i].Package < sorted[j].Package
+		}
+		return sorted[i].Interface < sorted[j].Interface
+	})
+	body, err := json.Marshal(struct {
+		Configs []Descriptor `json:"configs"`
+	}{Configs: sorted})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
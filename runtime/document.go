@@ -0,0 +1,185 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a YAML config file opened for editing. Unlike YAML, which is a read-only
+// view, it keeps the yaml.Node tree, so Set and Delete change values in place and Save
+// writes the file back with comments and key order preserved:
+//
+//	doc, err := runtime.OpenDocument("config.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	if err := doc.Set("server", "port", 9090); err != nil {
+//		return err
+//	}
+//	return doc.Save()
+type Document struct {
+	path string
+	root *yaml.Node // узел документа (DocumentNode)
+}
+
+// OpenDocument reads a YAML config file for editing. A missing file yields an empty
+// document that Save creates.
+func OpenDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	d, err := ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	d.path = path
+	return d, nil
+}
+
+// ParseDocument parses YAML data for editing. Save requires a path, use SaveAs or Bytes instead.
+func ParseDocument(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		// Пустой файл - создаем документ с пустой картой
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("top-level mapping expected")
+	}
+	return &Document{root: &root}, nil
+}
+
+// Set stores value under section.key, creating the section and the key if needed.
+// An existing key keeps its position and comments; a string keeps its quoting style.
+func (d *Document) Set(section, key string, value any) error {
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return fmt.Errorf("encode %s.%s: %w", section, key, err)
+	}
+
+	sec := documentSection(d.root.Content[0], section)
+	if sec == nil {
+		d.root.Content[0].Content = append(d.root.Content[0].Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: section},
+			&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		sec = d.root.Content[0].Content[len(d.root.Content[0].Content)-1]
+	}
+	if sec.Kind == yaml.AliasNode {
+		return fmt.Errorf("section %s is a YAML alias and cannot be edited in place", section)
+	}
+	if sec.Kind != yaml.MappingNode {
+		return fmt.Errorf("section %s is not a mapping", section)
+	}
+
+	for i := 0; i+1 < len(sec.Content); i += 2 {
+		if sec.Content[i].Value != key {
+			continue
+		}
+		old := sec.Content[i+1]
+		n.HeadComment, n.LineComment, n.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		n.Anchor = old.Anchor
+		if old.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode && n.Tag == old.Tag {
+			n.Style = old.Style
+		}
+		sec.Content[i+1] = &n
+		return nil
+	}
+	sec.Content = append(sec.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &n)
+	return nil
+}
+
+// Delete removes section.key and reports whether it existed.
+func (d *Document) Delete(section, key string) bool {
+	sec := documentSection(d.root.Content[0], section)
+	if sec == nil || sec.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(sec.Content); i += 2 {
+		if sec.Content[i].Value == key {
+			sec.Content = append(sec.Content[:i], sec.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// YAML returns a read-only view of the current document contents.
+func (d *Document) YAML() (*YAML, error) {
+	data, err := d.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return ParseYAML(data)
+}
+
+// Bytes encodes the document with two-space indentation.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Save writes the document back to the file it was opened from.
+func (d *Document) Save() error {
+	if d.path == "" {
+		return errors.New("document has no path, use SaveAs")
+	}
+	return d.SaveAs(d.path)
+}
+
+// SaveAs writes the document to path atomically (temporary file + rename),
+// keeping the permissions of an existing file.
+func (d *Document) SaveAs(path string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if st, err := os.Stat(path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// documentSection находит значение секции без разрешения алиасов: правка через алиас
+// изменила бы и все остальные ссылки на тот же якорь
+func documentSection(m *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == name {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DopplerOptions configures a secret resolver backed by the Doppler secrets platform.
+type DopplerOptions struct {
+	// Token is a Doppler access token. A service token (dp.st.…) is scoped to one project
+	// and config, which then need not be set; personal and service account tokens need them.
+	Token string
+	// Project and Config select the config whose secrets plain references read (optional
+	// with a service token).
+	Project string
+	Config  string
+	// Server is the API address (default "https://api.doppler.com").
+	Server string
+	// CacheTTL is how long downloaded secrets are cached (default 5 minutes, negative disables caching).
+	CacheTTL time.Duration
+	// OnError (optional) receives resolution errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+	// Client is the HTTP client (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// DopplerSource resolves ggconfig:secret methods from Doppler. References are a secret name,
+// read from the configured project and config, or "doppler://<project>/<config>/<NAME>";
+// without a directive value the name is the ENV key of the method, which matches the way
+// Doppler names secrets. All secrets of a config are downloaded with one request and cached
+// for CacheTTL, so resolving every secret method of a service costs a single round trip.
+type DopplerSource struct {
+	opts DopplerOptions
+
+	mu      sync.Mutex
+	configs map[[2]string]dopplerConfig
+}
+
+type dopplerConfig struct {
+	secrets map[string]string
+	expires time.Time
+}
+
+// NewDopplerSource returns a resolver for the given token. Secrets are fetched lazily.
+func NewDopplerSource(opts DopplerOptions) (*DopplerSource, error) {
+	if opts.Token == "" {
+		return nil, errors.New("doppler: Token is required")
+	}
+	if (opts.Project == "") != (opts.Config == "") {
+		return nil, errors.New("doppler: Project and Config must be set together")
+	}
+	if opts.Server == "" {
+		opts.Server = "https://api.doppler.com"
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+	return &DopplerSource{opts: opts, configs: map[[2]string]dopplerConfig{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *DopplerSource) ResolveSecret(ref string) (string, bool) {
+	v, err := s.Lookup(context.Background(), ref)
+	if err != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(ref, err)
+		}
+		return "", false
+	}
+	return v, true
+}
+
+// Lookup returns a secret by reference, downloading its config unless it is cached.
+func (s *DopplerSource) Lookup(ctx context.Context, ref string) (string, error) {
+	project, config, name, err := s.parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	secrets, err := s.secrets(ctx, project, config)
+	if err != nil {
+		return "", err
+	}
+	v, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("doppler: %s: secret %q not found", ref, name)
+	}
+	return v, nil
+}
+
+// PrefetchSecrets implements SecretPrefetcher: each config referenced by refs is downloaded
+// once and cached for CacheTTL; a reference to a missing secret is an error. Without caching
+// it does nothing.
+func (s *DopplerSource) PrefetchSecrets(ctx context.Context, refs []string) error {
+	if s.opts.CacheTTL <= 0 {
+		return nil
+	}
+	for _, ref := range refs {
+		if _, err := s.Lookup(ctx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate drops the cached secrets, e.g. after a rotation announced by a Doppler webhook.
+func (s *DopplerSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs = map[[2]string]dopplerConfig{}
+}
+
+// secrets возвращает секреты конфига из кеша или загружает их одним запросом. Блокировка
+// удерживается на время загрузки: параллельные чтения ждут один запрос, а не делают свои
+func (s *DopplerSource) secrets(ctx context.Context, project, config string) (map[string]string, error) {
+	k := [2]string{project, config}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.configs[k]; ok && time.Now().Before(c.expires) {
+		return c.secrets, nil
+	}
+
+	q := url.Values{"format": {"json"}}
+	if project != "" {
+		q.Set("project", project)
+		q.Set("config", config)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.Server+"/v3/configs/config/secrets/download?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.opts.Token)
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doppler: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doppler: download secrets of %s: unexpected status %s", dopplerConfigName(project, config), resp.Status)
+	}
+	var secrets map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&secrets); err != nil {
+		return nil, fmt.Errorf("doppler: decode secrets of %s: %w", dopplerConfigName(project, config), err)
+	}
+	if s.opts.CacheTTL > 0 {
+		s.configs[k] = dopplerConfig{secrets: secrets, expires: time.Now().Add(s.opts.CacheTTL)}
+	}
+	return secrets, nil
+}
+
+// parseRef разбирает ссылку NAME или doppler://project/config/NAME
+func (s *DopplerSource) parseRef(ref string) (project, config, name string, err error) {
+	rest, ok := strings.CutPrefix(ref, "doppler://")
+	if !ok {
+		if ref == "" || strings.Contains(ref, "/") {
+			return "", "", "", fmt.Errorf("doppler: invalid secret reference %q (want NAME or doppler://project/config/NAME)", ref)
+		}
+		return s.opts.Project, s.opts.Config, ref, nil
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("doppler: invalid secret reference %q (want NAME or doppler://project/config/NAME)", ref)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+func dopplerConfigName(project, config string) string {
+	if project == "" {
+		return "the token config"
+	}
+	return project + "/" + config
+}
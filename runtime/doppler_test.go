@@ -0,0 +1,150 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDoppler - API Doppler: выгрузка секретов конфига, токен сервиса привязан к svc/prd
+type fakeDoppler struct {
+	mu        sync.Mutex
+	downloads int
+}
+
+func (d *fakeDoppler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	d.downloads++
+	d.mu.Unlock()
+	q := r.URL.Query()
+	if r.URL.Path != "/v3/configs/config/secrets/download" || q.Get("format") != "json" || r.Header.Get("Accept") != "application/json" {
+		http.NotFound(w, r)
+		return
+	}
+	var secrets map[string]string
+	switch project, config := q.Get("project"), q.Get("config"); {
+	case r.Header.Get("Authorization") == "Bearer dp.st.prd" && project == "":
+		secrets = map[string]string{"DB_PASSWORD": "token config"}
+	case r.Header.Get("Authorization") != "Bearer dp.pt.personal":
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	case project == "svc" && config == "prd":
+		secrets = map[string]string{"DB_PASSWORD": "s3cr3t", "API_KEY": "key"}
+	case project == "svc" && config == "dev":
+		secrets = map[string]string{"DB_PASSWORD": "dev"}
+	case project == "broken":
+		w.Write([]byte("[]"))
+		return
+	default:
+		http.Error(w, "config not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(secrets)
+}
+
+func (d *fakeDoppler) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.downloads
+}
+
+func TestDopplerLookup(t *testing.T) {
+	doppler := &fakeDoppler{}
+	srv := httptest.NewServer(doppler)
+	defer srv.Close()
+	src, err := NewDopplerSource(DopplerOptions{Token: "dp.pt.personal", Project: "svc", Config: "prd", Server: srv.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+		err  string
+	}{
+		{"DB_PASSWORD", "s3cr3t", ""},
+		{"API_KEY", "key", ""},
+		{"doppler://svc/dev/DB_PASSWORD", "dev", ""},
+		{"MISSING", "", `secret "MISSING" not found`},
+		{"doppler://svc/stg/DB_PASSWORD", "", "download secrets of svc/stg: unexpected status 404"},
+		{"doppler://broken/prd/X", "", "decode secrets of broken/prd"},
+		{"doppler://svc/DB_PASSWORD", "", "invalid secret reference"},
+		{"svc/prd/DB_PASSWORD", "", "invalid secret reference"},
+		{"", "", "invalid secret reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := src.Lookup(context.Background(), tt.ref)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Lookup = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	// Токен сервиса читает свой конфиг без project и config
+	service, _ := NewDopplerSource(DopplerOptions{Token: "dp.st.prd", Server: srv.URL})
+	if v, err := service.Lookup(context.Background(), "DB_PASSWORD"); err != nil || v != "token config" {
+		t.Errorf("service token Lookup = %q, %v; want the token config", v, err)
+	}
+	bad, _ := NewDopplerSource(DopplerOptions{Token: "wrong", Project: "svc", Config: "prd", Server: srv.URL})
+	if _, err := bad.Lookup(context.Background(), "DB_PASSWORD"); err == nil || !strings.Contains(err.Error(), "unexpected status 401") {
+		t.Errorf("error = %v, want the status", err)
+	}
+	for _, opts := range []DopplerOptions{{}, {Token: "t", Project: "svc"}} {
+		if _, err := NewDopplerSource(opts); err == nil {
+			t.Errorf("NewDopplerSource(%+v) succeeded", opts)
+		}
+	}
+}
+
+func TestDopplerCache(t *testing.T) {
+	doppler := &fakeDoppler{}
+	srv := httptest.NewServer(doppler)
+	defer srv.Close()
+	var errs []string
+	src, _ := NewDopplerSource(DopplerOptions{Token: "dp.pt.personal", Project: "svc", Config: "prd", Server: srv.URL, OnError: func(ref string, err error) {
+		errs = append(errs, ref)
+	}})
+
+	// Все секреты конфига загружаются одним запросом
+	if err := src.PrefetchSecrets(context.Background(), []string{"DB_PASSWORD", "API_KEY"}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.ResolveSecret("API_KEY"); !ok || v != "key" {
+		t.Errorf("API_KEY = %q, %v; want key", v, ok)
+	}
+	if n := doppler.count(); n != 1 {
+		t.Errorf("%d downloads, want one", n)
+	}
+	src.Invalidate()
+	src.ResolveSecret("DB_PASSWORD")
+	if n := doppler.count(); n != 2 {
+		t.Errorf("%d downloads after Invalidate, want two", n)
+	}
+
+	if _, ok := src.ResolveSecret("MISSING"); ok || len(errs) != 1 || errs[0] != "MISSING" {
+		t.Errorf("missing secret: ok = %v, errors = %v", ok, errs)
+	}
+	if err := src.PrefetchSecrets(context.Background(), []string{"MISSING"}); err == nil {
+		t.Error("prefetch of a missing secret succeeded")
+	}
+
+	// Без кеша каждое чтение - отдельная выгрузка
+	uncached, _ := NewDopplerSource(DopplerOptions{Token: "dp.pt.personal", Project: "svc", Config: "prd", Server: srv.URL, CacheTTL: -1})
+	before := doppler.count()
+	uncached.ResolveSecret("DB_PASSWORD")
+	uncached.ResolveSecret("DB_PASSWORD")
+	if n := doppler.count() - before; n != 2 {
+		t.Errorf("%d downloads without caching, want two", n)
+	}
+}
@@ -0,0 +1,174 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseDotEnv parses a dotenv file into variables:
+//
+//	# comment
+//	SERVER_PORT=8080
+//	export SERVER_HOST=localhost   # "export" and trailing comments are allowed
+//	DATABASE_URL="postgres://u:p@db/app?sslmode=disable"
+//	TLS_CERT='-----BEGIN CERTIFICATE-----
+//	...'
+//
+// Values may be unquoted, 'single-quoted' (literal) or "double-quoted" (\n, \t, \", \\ and \$
+// escapes), quoted values may span lines, and adjacent parts are concatenated, so the output of
+// `ggconfig export-env` in both formats is read back as is. Variables are not expanded.
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	p := &dotenvParser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	vars := map[string]string{}
+	for !p.eof() {
+		p.skipBlank()
+		if p.eof() {
+			break
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		line := p.line
+		key := p.key()
+		if key == "export" && !p.eof() && p.peek() != '=' {
+			key = p.key()
+		}
+		if key == "" || p.eof() || p.peek() != '=' {
+			return nil, fmt.Errorf("dotenv: line %d: expected KEY=value", line)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		if !p.eof() && p.peek() != '#' && p.peek() != '\n' {
+			return nil, fmt.Errorf("dotenv: line %d: unexpected text after the value of %s", p.line, key)
+		}
+		p.skipLine()
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *dotenvParser) peek() byte { return p.src[p.pos] }
+
+// skipBlank пропускает пробелы и пустые строки
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+		if p.peek() == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipInline() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) key() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !(c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+	p.skipInline()
+	return key
+}
+
+// value читает значение до пробела, комментария или конца строки; части в кавычках и без
+// склеиваются, как в shell ('a'"b" - ab, так записывается одинарная кавычка: 'it'\'s)
+func (p *dotenvParser) value() (string, error) {
+	p.skipInline()
+	var b strings.Builder
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case ' ', '\t', '\n':
+			return b.String(), nil
+		case '\'':
+			line := p.line
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("dotenv: line %d: unterminated single-quoted value", line)
+			}
+			s := p.src[p.pos+1 : p.pos+1+end]
+			b.WriteString(s)
+			p.line += strings.Count(s, "\n")
+			p.pos += end + 2
+		case '"':
+			line := p.line
+			p.pos++
+			for {
+				if p.eof() {
+					return "", fmt.Errorf("dotenv: line %d: unterminated double-quoted value", line)
+				}
+				c := p.peek()
+				p.pos++
+				if c == '"' {
+					break
+				}
+				if c == '\n' {
+					p.line++
+				}
+				if c == '\\' && !p.eof() {
+					e := p.peek()
+					p.pos++
+					switch e {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					case 'r':
+						b.WriteByte('\r')
+					case '"', '\\', '$', '\'', '`':
+						b.WriteByte(e)
+					default:
+						b.WriteByte('\\')
+						b.WriteByte(e)
+					}
+					continue
+				}
+				b.WriteByte(c)
+			}
+		case '\\':
+			p.pos++
+			if !p.eof() && p.peek() != '\n' {
+				b.WriteByte(p.peek())
+				p.pos++
+			}
+		case '#':
+			// Комментарий начинается только после пробела: a#b - значение
+			if b.Len() == 0 {
+				return "", nil
+			}
+			b.WriteByte(c)
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string
+	}{
+		{"plain", "SERVER_PORT=8080\nSERVER_HOST=localhost\n", map[string]string{"SERVER_PORT": "8080", "SERVER_HOST": "localhost"}},
+		{"comments and blank lines", "# comment\n\nA=1 # trailing\n  # indented\nB=2", map[string]string{"A": "1", "B": "2"}},
+		{"export", "export A=1\nexport=2\n", map[string]string{"A": "1", "export": "2"}},
+		{"spaces around =", "A = 1\n", map[string]string{"A": "1"}},
+		{"empty", "A=\nB=''\nC=\"\"\nD= # comment\n", map[string]string{"A": "", "B": "", "C": "", "D": ""}},
+		{"hash inside value", "URL=http://x/#frag\n", map[string]string{"URL": "http://x/#frag"}},
+		{"single quoted is literal", `A='a\nb $X "q"'`, map[string]string{"A": `a\nb $X "q"`}},
+		{"double quoted escapes", `A="a\nb\t\"q\" \$X \\ \x"`, map[string]string{"A": "a\nb\t\"q\" $X \\ \\x"}},
+		{"multiline", "CERT='-----BEGIN-----\nabc\n-----END-----'\nNEXT=1\n", map[string]string{"CERT": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"}},
+		{"concatenated parts", `A='it'\''s'"  x"`, map[string]string{"A": "it's  x"}},
+		{"CRLF", "A=1\r\nB='x'\r\n", map[string]string{"A": "1", "B": "x"}},
+		{"vars are not expanded", "A=1\nB=${A}\n", map[string]string{"A": "1", "B": "${A}"}},
+		{"dotted key", "app.port=1\n", map[string]string{"app.port": "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotEnv([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("vars = %q, want %q", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDotEnvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"no =", "A=1\nB\n", "line 2: expected KEY=value"},
+		{"no key", "=1\n", "line 1: expected KEY=value"},
+		{"invalid key", "A-B=1\n", "line 1: expected KEY=value"},
+		{"unterminated single quote", "A=1\nB='abc\n", "line 2: unterminated single-quoted value"},
+		{"unterminated double quote", "A=\"abc\n\n", "line 1: unterminated double-quoted value"},
+		{"text after value", "A=1 2\n", "line 1: unexpected text after the value of A"},
+		{"text after multiline value", "A='x\ny' z\n", "line 2: unexpected text after the value of A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDotEnv([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
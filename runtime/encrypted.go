@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncryptedPrefix marks a YAML value encrypted with AES-256-GCM, a lightweight alternative to
+// SOPS for a handful of secrets: "enc:AES256GCM:<base64>", where the base64 payload is the
+// 12-byte nonce followed by the sealed value. ParseYAML decrypts such values in place with the
+// key from DecryptKeyEnv or DecryptKeyFileEnv; `ggconfig encrypt` produces them.
+const EncryptedPrefix = "enc:AES256GCM:"
+
+// ENV variables with the decryption key: the base64 encoded 32-byte key itself or the path
+// of a file that contains it (for mounted Kubernetes and Docker secrets).
+const (
+	DecryptKeyEnv     = "GGCONFIG_DECRYPT_KEY"
+	DecryptKeyFileEnv = "GGCONFIG_DECRYPT_KEY_FILE"
+)
+
+// ErrNoDecryptKey is returned when a document has encrypted values but neither
+// DecryptKeyEnv nor DecryptKeyFileEnv is set.
+var ErrNoDecryptKey = errors.New("decryption key is not set (" + DecryptKeyEnv + " or " + DecryptKeyFileEnv + ")")
+
+// DecryptKey loads the key from DecryptKeyEnv or, if it is empty, from the file named by
+// DecryptKeyFileEnv. It returns ErrNoDecryptKey when neither is set.
+func DecryptKey() ([]byte, error) {
+	encoded, source := os.Getenv(DecryptKeyEnv), DecryptKeyEnv
+	if encoded == "" {
+		path := os.Getenv(DecryptKeyFileEnv)
+		if path == "" {
+			return nil, ErrNoDecryptKey
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", DecryptKeyFileEnv, err)
+		}
+		encoded, source = string(data), path
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s: decryption key must be 32 bytes in base64", source)
+	}
+	return key, nil
+}
+
+// NewDecryptKey returns a random key in the form DecryptKeyEnv expects.
+func NewDecryptKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue seals plaintext with key into an "enc:AES256GCM:..." value.
+func EncryptValue(key []byte, plaintext string) (string, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue opens an "enc:AES256GCM:..." value sealed with key.
+func DecryptValue(key []byte, value string) (string, error) {
+	payload, ok := strings.CutPrefix(value, EncryptedPrefix)
+	if !ok {
+		return "", fmt.Errorf("value is not prefixed with %s", EncryptedPrefix)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		return "", fmt.Errorf("invalid base64: %w", err)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("decryption failed: wrong key or corrupted value")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptDocument заменяет зашифрованные скаляры документа расшифрованными строками до
+// декодирования: значения читаются как обычные, в том числе через runtime.Raw.
+// Ключ загружается только при первом зашифрованном значении
+func decryptDocument(doc *yaml.Node) error {
+	var key []byte
+	var walk func(n *yaml.Node) error
+	walk = func(n *yaml.Node) error {
+		if n.Kind == yaml.ScalarNode && strings.HasPrefix(n.Value, EncryptedPrefix) {
+			if key == nil {
+				k, err := DecryptKey()
+				if err != nil {
+					return fmt.Errorf("yaml: line %d: encrypted value: %w", n.Line, err)
+				}
+				key = k
+			}
+			plaintext, err := DecryptValue(key, n.Value)
+			if err != nil {
+				return fmt.Errorf("yaml: line %d: %w", n.Line, err)
+			}
+			n.Value, n.Tag, n.Style = plaintext, "!!str", yaml.DoubleQuotedStyle
+			return nil
+		}
+		for _, c := range n.Content {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(doc)
+}
@@ -0,0 +1,167 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testDecryptKey возвращает ключ и его base64 форму, как в GGCONFIG_DECRYPT_KEY
+func testDecryptKey(t *testing.T) ([]byte, string) {
+	t.Helper()
+	encoded, err := NewDecryptKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		t.Fatalf("NewDecryptKey = %q, %v; want 32 bytes in base64", encoded, err)
+	}
+	return key, encoded
+}
+
+func TestEncryptValueRoundTrip(t *testing.T) {
+	key, _ := testDecryptKey(t)
+	for _, plaintext := range []string{"", "s3cr3t", "многострочный\nсекрет"} {
+		value, err := EncryptValue(key, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(value, EncryptedPrefix) {
+			t.Fatalf("EncryptValue = %q, want the %s prefix", value, EncryptedPrefix)
+		}
+		got, err := DecryptValue(key, value)
+		if err != nil || got != plaintext {
+			t.Errorf("DecryptValue = %q, %v; want %q", got, err, plaintext)
+		}
+	}
+	// Случайный nonce: одно значение шифруется по-разному
+	a, _ := EncryptValue(key, "x")
+	b, _ := EncryptValue(key, "x")
+	if a == b {
+		t.Error("two encryptions of one value are equal")
+	}
+}
+
+func TestDecryptValueErrors(t *testing.T) {
+	key, _ := testDecryptKey(t)
+	other, _ := testDecryptKey(t)
+	value, err := EncryptValue(key, "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	sealed[len(sealed)-1] ^= 1
+	tampered := EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+
+	tests := []struct {
+		name  string
+		key   []byte
+		value string
+		err   string
+	}{
+		{"wrong key", other, value, "wrong key or corrupted value"},
+		{"corrupted value", key, tampered, "wrong key or corrupted value"},
+		{"bad base64", key, EncryptedPrefix + "not base64!", "invalid base64"},
+		{"too short", key, EncryptedPrefix + base64.StdEncoding.EncodeToString([]byte("short")), "too short"},
+		{"no prefix", key, "s3cr3t", "not prefixed"},
+		{"shorter AES key", key[:16], value, "wrong key or corrupted value"},
+		{"invalid key", key[:7], value, "invalid key size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecryptValue(tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecryptKey(t *testing.T) {
+	key, encoded := testDecryptKey(t)
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	keyFile := write("key", encoded+"\n") // Смонтированный секрет с переводом строки
+	badFile := write("bad", "short")
+
+	tests := []struct {
+		name    string
+		env     string
+		file    string
+		err     string
+		errorIs error
+	}{
+		{"env", encoded, "", "", nil},
+		{"env wins over the file", encoded, badFile, "", nil},
+		{"file", "", keyFile, "", nil},
+		{"not set", "", "", "", ErrNoDecryptKey},
+		{"missing file", "", filepath.Join(dir, "missing"), "read " + DecryptKeyFileEnv, nil},
+		{"bad file", "", badFile, badFile + ": decryption key must be 32 bytes", nil},
+		{"bad env", "c2hvcnQ=", "", DecryptKeyEnv + ": decryption key must be 32 bytes", nil},
+		{"not base64", "%%%", "", "decryption key must be 32 bytes in base64", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DecryptKeyEnv, tt.env)
+			t.Setenv(DecryptKeyFileEnv, tt.file)
+			got, err := DecryptKey()
+			switch {
+			case tt.errorIs != nil:
+				if !errors.Is(err, tt.errorIs) {
+					t.Fatalf("error = %v, want %v", err, tt.errorIs)
+				}
+			case tt.err != "":
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case string(got) != string(key):
+				t.Errorf("key = %x, want %x", got, key)
+			}
+		})
+	}
+}
+
+func TestParseYAMLDecrypts(t *testing.T) {
+	key, encoded := testDecryptKey(t)
+	value, err := EncryptValue(key, "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("db:\n  password: " + value + "\n  user: app\n")
+
+	t.Setenv(DecryptKeyEnv, encoded)
+	t.Setenv(DecryptKeyFileEnv, "")
+	y, err := ParseYAML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := y.GetString("db", "password"); !ok || v != "s3cr3t" {
+		t.Errorf("password = %q, %v; want the decrypted value", v, ok)
+	}
+
+	// Без ключа документ с зашифрованным значением не загружается; без таких значений ключ не нужен
+	t.Setenv(DecryptKeyEnv, "")
+	if _, err := ParseYAML(src); !errors.Is(err, ErrNoDecryptKey) || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error = %v, want ErrNoDecryptKey at line 2", err)
+	}
+	if _, err := ParseYAML([]byte("db:\n  user: app\n")); err != nil {
+		t.Errorf("plain document: %v", err)
+	}
+	other, _ := testDecryptKey(t)
+	t.Setenv(DecryptKeyEnv, base64.StdEncoding.EncodeToString(other))
+	if _, err := ParseYAML(src); err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Errorf("error = %v, want a wrong key error", err)
+	}
+}
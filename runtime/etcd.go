@@ -0,0 +1,277 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdOptions configures a source backed by etcd v3 keys under a prefix.
+type EtcdOptions struct {
+	// Server is the etcd client URL, e.g. "http://etcd:2379". The source uses the JSON
+	// gateway of the v3 API (/v3/kv/range, /v3/watch) served on the client port.
+	Server string
+	// Prefix selects the keys, e.g. "/my-service/config/". The rest of a key is the
+	// "section/key" path: /my-service/config/server/port sets server.port.
+	Prefix string
+	// Username and Password enable etcd authentication (optional).
+	Username string
+	Password string
+	// Client is the HTTP client (default: http.DefaultClient; requests are bounded by ctx
+	// and watch streams are reopened periodically, so a client timeout is not needed).
+	Client *http.Client
+}
+
+// EtcdSource loads the keys under an etcd prefix into a *YAML that the generated YAML
+// implementations read (New<Pkg><Interface>YAMLConfigParsed) and that GlobalConfig accepts
+// directly. Watch follows changes with an etcd watch and reloads the keys on every change,
+// notifying YAML().OnChange subscribers.
+type EtcdSource struct {
+	opts EtcdOptions
+	y    *YAML
+
+	mu       sync.Mutex
+	revision int64 // ревизия etcd последней загрузки: watch начинается со следующей
+	token    string
+}
+
+// NewEtcdSource fetches the keys once and returns the source.
+func NewEtcdSource(ctx context.Context, opts EtcdOptions) (*EtcdSource, error) {
+	if opts.Server == "" || opts.Prefix == "" {
+		return nil, errors.New("etcd: Server and Prefix are required")
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+
+	s := &EtcdSource{opts: opts, y: &YAML{}}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *EtcdSource) YAML() *YAML {
+	return s.y
+}
+
+// Revision returns the etcd revision of the last load.
+func (s *EtcdSource) Revision() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revision
+}
+
+// etcdHeader - заголовок ответов etcd; int64 в JSON шлюзе передаются строками
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// Reload fetches the keys under the prefix and replaces the configuration tree.
+func (s *EtcdSource) Reload(ctx context.Context) error {
+	var body struct {
+		Header etcdHeader `json:"header"`
+		Kvs    []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := s.call(ctx, "/v3/kv/range", s.rangeRequest(), &body); err != nil {
+		return err
+	}
+
+	props := make(map[string]string, len(body.Kvs))
+	for _, kv := range body.Kvs {
+		// /prefix/server/port -> server.port; вложенные пути сохраняют точки: tls/cert/file -> tls.cert.file
+		path := strings.Trim(strings.TrimPrefix(string(kv.Key), s.opts.Prefix), "/")
+		props[strings.ReplaceAll(path, "/", ".")] = string(kv.Value)
+	}
+	s.y.Replace(FromProperties(props))
+
+	s.mu.Lock()
+	s.revision = body.Header.Revision
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch follows changes of the keys under the prefix and reloads them on every change
+// until ctx is cancelled. Transient errors are retried with backoff; onError (optional)
+// receives them for logging.
+func (s *EtcdSource) Watch(ctx context.Context, onError func(error)) error {
+	return watchLoop(ctx, s.poll, s.Reload, onError)
+}
+
+// etcdWatchWindow - сколько держать поток watch открытым без событий: затем он
+// переоткрывается, чтобы зависшее соединение не оставило источник без обновлений
+const etcdWatchWindow = time.Minute
+
+// poll открывает поток watch с ревизии после последней загрузки и ждет первого события;
+// false без ошибки - событий не было за etcdWatchWindow
+func (s *EtcdSource) poll(ctx context.Context) (bool, error) {
+	watchCtx, cancel := context.WithTimeout(ctx, etcdWatchWindow)
+	defer cancel()
+
+	s.mu.Lock()
+	start := s.revision + 1
+	s.mu.Unlock()
+	create := s.rangeRequest()
+	create["start_revision"] = strconv.FormatInt(start, 10)
+
+	resp, err := s.post(watchCtx, "/v3/watch", map[string]any{"create_request": create})
+	if err != nil {
+		if watchCtx.Err() != nil && ctx.Err() == nil {
+			return false, nil
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("etcd: watch: unexpected status %s", resp.Status)
+	}
+
+	// Ответ - поток JSON объектов {"result": {...}}, по одному на сообщение watch
+	dec := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var msg struct {
+			Result struct {
+				Canceled        bool              `json:"canceled"`
+				CancelReason    string            `json:"cancel_reason"`
+				CompactRevision int64             `json:"compact_revision,string"`
+				Events          []json.RawMessage `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if watchCtx.Err() != nil && ctx.Err() == nil {
+				return false, nil
+			}
+			return false, fmt.Errorf("etcd: watch: %w", err)
+		}
+		switch {
+		case msg.Error != nil:
+			return false, fmt.Errorf("etcd: watch: %s", msg.Error.Message)
+		case msg.Result.CompactRevision != 0:
+			// Ревизия уже сжата: изменения пропущены, загружаем ключи заново
+			return true, nil
+		case msg.Result.Canceled:
+			return false, fmt.Errorf("etcd: watch canceled: %s", msg.Result.CancelReason)
+		case len(msg.Result.Events) > 0:
+			return true, nil
+		}
+	}
+}
+
+// rangeRequest задает диапазон ключей префикса (ключи передаются в base64)
+func (s *EtcdSource) rangeRequest() map[string]any {
+	return map[string]any{
+		"key":       base64.StdEncoding.EncodeToString([]byte(s.opts.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(etcdPrefixEnd([]byte(s.opts.Prefix))),
+	}
+}
+
+// etcdPrefixEnd возвращает конец диапазона ключей с префиксом, как clientv3.GetPrefixRangeEnd
+func etcdPrefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Префикс из 0xff: диапазон до конца пространства ключей
+	return []byte{0}
+}
+
+func (s *EtcdSource) call(ctx context.Context, path string, req, out any) error {
+	resp, err := s.post(ctx, path, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd: POST %s: unexpected status %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("etcd: decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+func (s *EtcdSource) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Server+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.Username != "" {
+		token, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.opts.Username != "" {
+		// Токен истек: следующий запрос получит новый
+		s.mu.Lock()
+		s.token = ""
+		s.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// authenticate возвращает токен etcd, получая его при первом запросе и после истечения
+func (s *EtcdSource) authenticate(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token != "" {
+		return token, nil
+	}
+
+	data, _ := json.Marshal(map[string]string{"name": s.opts.Username, "password": s.opts.Password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Server+"/v3/auth/authenticate", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("etcd: authenticate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd: authenticate: unexpected status %s", resp.Status)
+	}
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("etcd: decode authenticate response: %w", err)
+	}
+
+	s.mu.Lock()
+	s.token = body.Token
+	s.mu.Unlock()
+	return body.Token, nil
+}
@@ -0,0 +1,182 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEtcd - JSON шлюз etcd v3: range и watch по ключам, аутентификация по паролю
+type fakeEtcd struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	kvs      map[string]string
+	revision int64
+	watch    []string // Сообщения потока watch
+	auths    int
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if r.URL.Path == "/v3/auth/authenticate" {
+		if body["name"] != "root" || body["password"] != "pass" {
+			http.Error(w, "authentication failed", http.StatusUnauthorized)
+			return
+		}
+		e.auths++
+		json.NewEncoder(w).Encode(map[string]string{"token": "token-1"})
+		return
+	}
+	if r.Header.Get("Authorization") != "token-1" {
+		http.Error(w, "invalid auth token", http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		key, end := decodeEtcdKey(e.t, body["key"]), decodeEtcdKey(e.t, body["range_end"])
+		var kvs []map[string][]byte
+		for k, v := range e.kvs {
+			if k >= key && k < end {
+				kvs = append(kvs, map[string][]byte{"key": []byte(k), "value": []byte(v)})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"header": map[string]string{"revision": "7"},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		create, _ := body["create_request"].(map[string]any)
+		if create["start_revision"] != "8" || decodeEtcdKey(e.t, create["key"]) != "/svc/" {
+			http.Error(w, "bad watch request", http.StatusBadRequest)
+			return
+		}
+		for _, msg := range e.watch {
+			w.Write([]byte(msg + "\n"))
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func decodeEtcdKey(t *testing.T, v any) string {
+	s, _ := v.(string)
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Errorf("key %q is not base64: %v", s, err)
+	}
+	return string(b)
+}
+
+func TestEtcdSource(t *testing.T) {
+	etcd := &fakeEtcd{t: t, kvs: map[string]string{
+		"/svc/server/port":     "8080",
+		"/svc/server/tls/cert": "/etc/tls.crt",
+		"/svc0/other":          "x",
+		"/other/server/port":   "1",
+	}}
+	srv := httptest.NewServer(etcd)
+	defer srv.Close()
+
+	src, err := NewEtcdSource(context.Background(), EtcdOptions{Server: srv.URL + "/", Prefix: "/svc/", Username: "root", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+	if v, ok := src.YAML().GetString("server", "tls.cert"); !ok || v != "/etc/tls.crt" {
+		t.Errorf("server.tls.cert = %q, %v; want /etc/tls.crt", v, ok)
+	}
+	if rev := src.Revision(); rev != 7 {
+		t.Errorf("Revision = %d, want 7", rev)
+	}
+
+	// Watch начинается с ревизии после загрузки; событие означает изменение, сжатая ревизия - тоже
+	tests := []struct {
+		name    string
+		watch   []string
+		changed bool
+		err     string
+	}{
+		{"event", []string{`{"result":{"created":true}}`, `{"result":{"events":[{"kv":{}}]}}`}, true, ""},
+		{"compacted", []string{`{"result":{"compact_revision":"9"}}`}, true, ""},
+		{"canceled", []string{`{"result":{"canceled":true,"cancel_reason":"permission denied"}}`}, false, "watch canceled: permission denied"},
+		{"error", []string{`{"error":{"message":"etcdserver: no leader"}}`}, false, "etcdserver: no leader"},
+		{"closed stream", nil, false, "etcd: watch: EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etcd.mu.Lock()
+			etcd.watch = tt.watch
+			etcd.mu.Unlock()
+			changed, err := src.poll(context.Background())
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("poll error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || changed != tt.changed {
+				t.Errorf("poll = %v, %v; want %v", changed, err, tt.changed)
+			}
+		})
+	}
+
+	etcd.mu.Lock()
+	etcd.kvs["/svc/server/port"] = "9090"
+	etcd.mu.Unlock()
+	if err := src.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 9090 {
+		t.Errorf("server.port after Reload = %d, want 9090", v)
+	}
+	if etcd.auths != 1 {
+		t.Errorf("%d authentications, want the token reused", etcd.auths)
+	}
+}
+
+func TestEtcdSourceErrors(t *testing.T) {
+	etcd := &fakeEtcd{t: t, kvs: map[string]string{}}
+	srv := httptest.NewServer(etcd)
+	defer srv.Close()
+
+	if _, err := NewEtcdSource(context.Background(), EtcdOptions{Server: srv.URL}); err == nil || !strings.Contains(err.Error(), "Server and Prefix are required") {
+		t.Errorf("error = %v, want the required options", err)
+	}
+	if _, err := NewEtcdSource(context.Background(), EtcdOptions{Server: srv.URL, Prefix: "/svc/", Username: "root", Password: "wrong"}); err == nil || !strings.Contains(err.Error(), "authenticate: unexpected status 401") {
+		t.Errorf("error = %v, want an authentication error", err)
+	}
+	if _, err := NewEtcdSource(context.Background(), EtcdOptions{Server: srv.URL, Prefix: "/svc/"}); err == nil || !strings.Contains(err.Error(), "/v3/kv/range: unexpected status 401") {
+		t.Errorf("error = %v, want the range status", err)
+	}
+}
+
+func TestEtcdPrefixEnd(t *testing.T) {
+	for _, tt := range []struct{ prefix, want string }{
+		{"/svc/", "/svc0"},
+		{"a", "b"},
+		{"a\xff", "b"},
+		{"\xff\xff", "\x00"},
+	} {
+		if got := string(etcdPrefixEnd([]byte(tt.prefix))); got != tt.want {
+			t.Errorf("etcdPrefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package runtime
+
+// FlagEvaluator resolves feature flags for methods annotated with "ggconfig:flag".
+// The second result is false when the flag is unknown or the provider is offline,
+// so generated Flag sources fall through to the next source (ENV, YAML, default).
+type FlagEvaluator interface {
+	BoolFlag(key string) (bool, bool)
+	StringFlag(key string) (string, bool)
+}
+
+// LaunchDarklyClient is the subset of the LaunchDarkly Go SDK client (*ldclient.LDClient)
+// used by LaunchDarklyFlags. C is the evaluation context type (ldcontext.Context).
+type LaunchDarklyClient[C any] interface {
+	Initialized() bool
+	IsOffline() bool
+	BoolVariation(key string, context C, defaultVal bool) (bool, error)
+	StringVariation(key string, context C, defaultVal string) (string, error)
+}
+
+// LaunchDarklyFlags adapts a LaunchDarkly SDK client and evaluation context to a FlagEvaluator:
+//
+//	flags := runtime.LaunchDarklyFlags(ldClient, ldcontext.New("service-instance"))
+//	cfg := gconfig.NewInternalServerConfigAll(
+//		gconfig.NewInternalServerConfigFlagConfig(flags),
+//		gconfig.NewInternalServerConfigEnvConfig(),
+//	)
+//
+// Flags are reported as absent while the client is offline or not initialized,
+// and when an evaluation fails (unknown flag, wrong type).
+func LaunchDarklyFlags[C any](client LaunchDarklyClient[C], context C) FlagEvaluator {
+	return &ldFlags[C]{client: client, context: context}
+}
+
+type ldFlags[C any] struct {
+	client  LaunchDarklyClient[C]
+	context C
+}
+
+func (f *ldFlags[C]) online() bool {
+	return f.client != nil && f.client.Initialized() && !f.client.IsOffline()
+}
+
+func (f *ldFlags[C]) BoolFlag(key string) (bool, bool) {
+	if !f.online() {
+		return false, false
+	}
+	v, err := f.client.BoolVariation(key, f.context, false)
+	if err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+func (f *ldFlags[C]) StringFlag(key string) (string, bool) {
+	if !f.online() {
+		return "", false
+	}
+	v, err := f.client.StringVariation(key, f.context, "")
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
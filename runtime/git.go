@@ -0,0 +1,235 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGitInterval is how often GitSource.Watch fetches the ref when no interval is given.
+const DefaultGitInterval = time.Minute
+
+// GitOptions configures a source that reads a config file from a git repository.
+type GitOptions struct {
+	// Repo is the repository URL or path, e.g. "https://git.internal/platform/config.git"
+	// or "git@git.internal:platform/config.git".
+	Repo string
+	// Ref is a branch, a tag or a full commit hash (default HEAD, the default branch). A commit
+	// pins the config: every process started with it reads the same file.
+	Ref string
+	// Path of the file in the repository, e.g. "services/my-service.yaml". The extension selects
+	// the format: .json, .hcl and .cue as ParseJSON, ParseHCL and ParseCUE, anything else YAML.
+	Path string
+	// Dir is the local bare repository the ref is fetched into (default: a directory per Repo
+	// under the user cache directory, reused across restarts).
+	Dir string
+	// Git is the git binary (default "git" from PATH). Authentication is that of git itself:
+	// credential helpers, SSH keys, GIT_SSH_COMMAND.
+	Git string
+	// Env is added to the environment of git, e.g. "GIT_SSH_COMMAND=ssh -i /etc/deploy-key".
+	Env []string
+}
+
+// GitSource distributes configuration GitOps-style: the config file lives in a git repository,
+// changes go through review and are rolled out by moving a branch or a tag. The source fetches
+// the ref (shallow, without a working tree) with the git binary, reads the file at the fetched
+// commit and serves it as a *YAML that the generated YAML implementations read and GlobalConfig
+// accepts directly. Commit reports the commit the configuration comes from.
+type GitSource struct {
+	opts GitOptions
+	y    *YAML
+
+	mu     sync.Mutex
+	commit string
+}
+
+// NewGitSource fetches the ref and reads the file once, then returns the source.
+func NewGitSource(ctx context.Context, opts GitOptions) (*GitSource, error) {
+	if opts.Repo == "" {
+		return nil, errors.New("git source: Repo is required")
+	}
+	if opts.Path == "" {
+		return nil, errors.New("git source: Path is required")
+	}
+	if opts.Ref == "" {
+		opts.Ref = "HEAD"
+	}
+	if opts.Git == "" {
+		opts.Git = "git"
+	}
+	if opts.Dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		sum := sha256.Sum256([]byte(opts.Repo))
+		opts.Dir = filepath.Join(base, "ggconfig", "git", hex.EncodeToString(sum[:8]))
+	}
+	s := &GitSource{opts: opts, y: &YAML{}}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *GitSource) YAML() *YAML {
+	return s.y
+}
+
+// Commit returns the hash of the commit the current configuration was read from.
+func (s *GitSource) Commit() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit
+}
+
+// Reload fetches the ref and replaces the configuration tree if it points to another commit.
+// A ref pinned to a commit is fetched only once. A file that is missing at the commit or does
+// not parse is rejected and the current tree is kept.
+func (s *GitSource) Reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.commit != "" && isCommitHash(s.opts.Ref) {
+		return nil
+	}
+	if err := s.init(ctx); err != nil {
+		return err
+	}
+	commit, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if commit == s.commit {
+		return nil
+	}
+	data, err := s.git(ctx, "show", commit+":"+strings.TrimPrefix(path.Clean(s.opts.Path), "/"))
+	if err != nil {
+		return err
+	}
+	var y *YAML
+	switch strings.ToLower(path.Ext(s.opts.Path)) {
+	case ".json":
+		y, err = ParseJSON(data)
+	case ".hcl":
+		y, err = ParseHCL(data)
+	case ".cue":
+		y, err = ParseCUE(data)
+	default:
+		y, err = ParseYAML(data)
+	}
+	if err != nil {
+		return fmt.Errorf("git source: %s at %s: %w", s.opts.Path, shortCommit(commit), err)
+	}
+	s.y.Replace(y)
+	s.commit = commit
+	return nil
+}
+
+// Watch fetches the ref every interval (DefaultGitInterval if interval <= 0) and reloads the
+// file when the ref moves to another commit, until ctx is cancelled. Errors are retried with
+// backoff; onError (optional) receives them for logging.
+func (s *GitSource) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = DefaultGitInterval
+	}
+	wait := func(ctx context.Context) (bool, error) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+			return true, nil
+		}
+	}
+	return watchLoop(ctx, wait, s.Reload, onError)
+}
+
+// init создает локальный голый репозиторий, если его еще нет
+func (s *GitSource) init(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(s.opts.Dir, "HEAD")); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(s.opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("git source: %w", err)
+	}
+	_, err := s.git(ctx, "init", "--bare", "--quiet")
+	return err
+}
+
+// fetch забирает ref без истории и возвращает хэш коммита. Не каждый сервер отдает коммит по
+// хэшу (uploadpack.allowReachableSHA1InWant): тогда забираются ветки и коммит ищется среди них
+func (s *GitSource) fetch(ctx context.Context) (string, error) {
+	_, err := s.git(ctx, "fetch", "--quiet", "--no-tags", "--depth=1", s.opts.Repo, s.opts.Ref)
+	if err != nil && isCommitHash(s.opts.Ref) {
+		if _, fullErr := s.git(ctx, "fetch", "--quiet", "--no-tags", s.opts.Repo, "+refs/heads/*:refs/ggconfig/*"); fullErr == nil {
+			out, verifyErr := s.git(ctx, "rev-parse", "--verify", "--quiet", s.opts.Ref+"^{commit}")
+			if verifyErr == nil {
+				return strings.TrimSpace(string(out)), nil
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	out, err := s.git(ctx, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// git запускает git в локальном репозитории; запрос пароля в терминале отключен, чтобы
+// сервис без учетных данных получил ошибку, а не завис
+func (s *GitSource) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.opts.Git, append([]string{"--git-dir", s.opts.Dir}, args...)...)
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), s.opts.Env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git source: %s %s: %w: %s", args[0], redactRepo(s.opts.Repo), err, msg)
+		}
+		return nil, fmt.Errorf("git source: %s %s: %w", args[0], redactRepo(s.opts.Repo), err)
+	}
+	return out, nil
+}
+
+// isCommitHash - ref задан полным хэшем коммита (SHA-1 или SHA-256)
+func isCommitHash(ref string) bool {
+	if len(ref) != 40 && len(ref) != 64 {
+		return false
+	}
+	for _, c := range ref {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// redactRepo убирает учетные данные из URL репозитория; пути и адреса вида git@host:repo
+// выводятся как есть
+func redactRepo(repo string) string {
+	if strings.Contains(repo, "://") {
+		return redactURL(repo)
+	}
+	return repo
+}
@@ -0,0 +1,415 @@
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseHCL parses an HCL document into the section/key structure of a YAML one: a top-level
+// block is a section named by its last label or, without labels, by its type, and its
+// attributes are the keys:
+//
+//	service "server" {
+//	  port    = 8080
+//	  timeout = "30s"
+//	  tags    = ["a", "b"]
+//	  tls {
+//	    cert = "/etc/tls.crt"
+//	  }
+//	}
+//
+// A nested block is an object value (repeated blocks of one type are a list of objects).
+// Top-level attributes, which belong to no section, are ignored. Supported are the native
+// syntax literals: strings (with escapes, "${...}" is kept as is), heredocs (<<EOF, <<-EOF),
+// numbers, bools, null, lists and objects, and #, // and /* */ comments; expressions and
+// functions are not evaluated. Values encrypted with EncryptValue are decrypted.
+func ParseHCL(data []byte) (*YAML, error) {
+	p := &hclParser{src: string(data), line: 1}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	sections := map[string]bool{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		name, line := p.ident(), p.line
+		if name == "" {
+			return nil, p.errorf("expected an attribute or a block")
+		}
+		p.skipSpace()
+		if p.peek() == '=' {
+			p.pos++
+			if _, err := p.value(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		labels, err := p.labels()
+		if err != nil {
+			return nil, err
+		}
+		if len(labels) > 0 {
+			name = labels[len(labels)-1]
+		}
+		if sections[name] {
+			return nil, fmt.Errorf("hcl: line %d: section %q is defined twice", line, name)
+		}
+		sections[name] = true
+		body, err := p.body()
+		if err != nil {
+			return nil, err
+		}
+		root.Content = append(root.Content, hclKey(name, line), body)
+	}
+	return fromDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+}
+
+// hclParser - разбор нативного синтаксиса HCL в узлы yaml.v3 (значения получают явные
+// теги, как в ParseJSON, и читаются теми же геттерами YAML)
+type hclParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *hclParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("hcl: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace пропускает пробелы, переводы строк, запятые-разделители и комментарии
+func (p *hclParser) skipSpace() {
+	for !p.eof() {
+		switch c := p.src[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#' || strings.HasPrefix(p.src[p.pos:], "//"):
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				end = len(p.src) - p.pos - 4
+			}
+			p.line += strings.Count(p.src[p.pos:p.pos+end+2], "\n")
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func (p *hclParser) ident() string {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !(unicode.IsLetter(r) || r == '_' || (p.pos > start && (unicode.IsDigit(r) || r == '-'))) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
+
+// labels читает метки блока до открывающей скобки
+func (p *hclParser) labels() ([]string, error) {
+	var labels []string
+	for {
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == '{':
+			return labels, nil
+		case c == '"':
+			s, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			labels = append(labels, s)
+		default:
+			label := p.ident()
+			if label == "" {
+				return nil, p.errorf("expected '=' or a block")
+			}
+			labels = append(labels, label)
+		}
+	}
+}
+
+// body читает тело блока {...}: атрибуты и вложенные блоки
+func (p *hclParser) body() (*yaml.Node, error) {
+	line := p.line
+	p.pos++ // {
+	n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+	blocks := map[string]*yaml.Node{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, fmt.Errorf("hcl: line %d: unclosed block", line)
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return n, nil
+		}
+		key, keyLine := p.ident(), p.line
+		if key == "" {
+			return nil, p.errorf("expected an attribute or a block")
+		}
+		p.skipSpace()
+		if p.peek() == '=' {
+			p.pos++
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, hclKey(key, keyLine), v)
+			continue
+		}
+		if _, err := p.labels(); err != nil {
+			return nil, err
+		}
+		v, err := p.body()
+		if err != nil {
+			return nil, err
+		}
+		// Повторяющиеся блоки одного типа - список объектов
+		if prev, ok := blocks[key]; ok {
+			if prev.Kind != yaml.SequenceNode {
+				*prev = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: prev.Line, Content: []*yaml.Node{{
+					Kind: prev.Kind, Tag: prev.Tag, Line: prev.Line, Content: prev.Content,
+				}}}
+			}
+			prev.Content = append(prev.Content, v)
+			continue
+		}
+		blocks[key] = v
+		n.Content = append(n.Content, hclKey(key, keyLine), v)
+	}
+}
+
+func (p *hclParser) value() (*yaml.Node, error) {
+	p.skipSpace()
+	line := p.line
+	switch c := p.peek(); {
+	case c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: yaml.DoubleQuotedStyle, Line: line}, nil
+	case strings.HasPrefix(p.src[p.pos:], "<<"):
+		s, err := p.heredoc()
+		if err != nil {
+			return nil, err
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: yaml.LiteralStyle, Line: line}, nil
+	case c == '[':
+		p.pos++
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+		for {
+			p.skipSpace()
+			if p.eof() {
+				return nil, fmt.Errorf("hcl: line %d: unclosed list", line)
+			}
+			if p.peek() == ']' {
+				p.pos++
+				return n, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, v)
+		}
+	case c == '{':
+		p.pos++
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+		for {
+			p.skipSpace()
+			if p.eof() {
+				return nil, fmt.Errorf("hcl: line %d: unclosed object", line)
+			}
+			if p.peek() == '}' {
+				p.pos++
+				return n, nil
+			}
+			keyLine := p.line
+			var key string
+			if p.peek() == '"' {
+				s, err := p.quoted()
+				if err != nil {
+					return nil, err
+				}
+				key = s
+			} else if key = p.ident(); key == "" {
+				return nil, p.errorf("expected an object key")
+			}
+			p.skipSpace()
+			if c := p.peek(); c != '=' && c != ':' {
+				return nil, p.errorf("expected '=' after object key %q", key)
+			}
+			p.pos++
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, hclKey(key, keyLine), v)
+		}
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-xXabcdefABCDEF_", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		num := p.src[start:p.pos]
+		if _, err := strconv.ParseInt(num, 0, 64); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: num, Line: line}, nil
+		}
+		if _, err := strconv.ParseFloat(num, 64); err != nil {
+			return nil, p.errorf("invalid number %q", num)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: num, Line: line}, nil
+	}
+	word := p.ident()
+	switch word {
+	case "true", "false":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: word, Line: line}, nil
+	case "null":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null", Line: line}, nil
+	case "":
+		return nil, p.errorf("expected a value")
+	}
+	return nil, p.errorf("expressions are not supported: %s", word)
+}
+
+// quoted читает строку в двойных кавычках с escape-последовательностями HCL
+func (p *hclParser) quoted() (string, error) {
+	line := p.line
+	var b strings.Builder
+	p.pos++ // "
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", fmt.Errorf("hcl: line %d: unterminated string", line)
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(e)
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if p.pos+size >= len(p.src) {
+					return "", p.errorf("invalid escape sequence")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid escape sequence \\%c%s", e, p.src[p.pos+1:p.pos+1+size])
+				}
+				b.WriteRune(rune(r))
+				p.pos += size
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", e)
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("hcl: line %d: unterminated string", line)
+}
+
+// heredoc читает <<EOF ... EOF; в форме <<-EOF общий отступ строк убирается
+func (p *hclParser) heredoc() (string, error) {
+	line := p.line
+	p.pos += 2
+	indent := p.peek() == '-'
+	if indent {
+		p.pos++
+	}
+	marker := p.ident()
+	if marker == "" {
+		return "", p.errorf("expected a heredoc marker")
+	}
+	nl := strings.IndexByte(p.src[p.pos:], '\n')
+	if nl < 0 {
+		return "", fmt.Errorf("hcl: line %d: unterminated heredoc", line)
+	}
+	p.pos += nl + 1
+	p.line++
+	var lines []string
+	for !p.eof() {
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		text := p.src[p.pos : p.pos+end]
+		p.pos += end
+		if strings.TrimSpace(text) == marker {
+			if indent {
+				lines = trimCommonIndent(lines)
+			}
+			return strings.Join(lines, "\n") + "\n", nil
+		}
+		lines = append(lines, text)
+		if !p.eof() {
+			p.pos++
+			p.line++
+		}
+	}
+	return "", fmt.Errorf("hcl: line %d: unterminated heredoc %s", line, marker)
+}
+
+func trimCommonIndent(lines []string) []string {
+	common := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= common && common > 0 {
+			l = l[common:]
+		}
+		out[i] = l
+	}
+	return out
+}
+
+func hclKey(name string, line int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name, Line: line}
+}
@@ -0,0 +1,162 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseHCL(t *testing.T, src string) *YAML {
+	t.Helper()
+	y, err := ParseHCL([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseHCL: %v", err)
+	}
+	return y
+}
+
+func TestParseHCLDocExample(t *testing.T) {
+	y := mustParseHCL(t, `
+# Атрибуты верхнего уровня не относятся к секции
+region = "eu"
+
+service "server" {
+  port    = 8080
+  timeout = "30s"
+  tags    = ["a", "b"] // комментарий
+  tls {
+    cert = "/etc/tls.crt"
+  }
+}
+
+/* блок без меток - секция по типу */
+database {
+  enabled = true
+}
+`)
+	if v, ok := y.GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("port = %d, %v; want 8080", v, ok)
+	}
+	if v, ok := y.GetDuration("server", "timeout"); !ok || v != 30*time.Second {
+		t.Errorf("timeout = %v, %v; want 30s", v, ok)
+	}
+	if v, ok := y.GetStrings(",", "server", "tags"); !ok || strings.Join(v, ",") != "a,b" {
+		t.Errorf("tags = %v, %v; want [a b]", v, ok)
+	}
+	raw, ok := y.GetRaw("server", "tls")
+	if !ok {
+		t.Fatal("nested block tls is absent")
+	}
+	var tls struct{ Cert string }
+	if err := raw.Decode(&tls); err != nil || tls.Cert != "/etc/tls.crt" {
+		t.Errorf("tls = %+v, %v; want cert /etc/tls.crt", tls, err)
+	}
+	if v, ok := y.GetBool("database", "enabled"); !ok || !v {
+		t.Errorf("database.enabled = %v, %v; want true", v, ok)
+	}
+	if _, ok := y.GetString("region", "region"); ok {
+		t.Error("top-level attribute must be ignored")
+	}
+}
+
+func TestParseHCLValues(t *testing.T) {
+	y := mustParseHCL(t, `
+s {
+  str     = "a\tb\"c"
+  interp  = "${var.name}"
+  hex     = 0x1F
+  neg     = -7
+  float   = 2.5
+  off     = false
+  nothing = null
+  obj     = { a = 1, "b c": "d" }
+  text = <<EOF
+line1
+  line2
+EOF
+  indented = <<-EOT
+    one
+      two
+    EOT
+}
+`)
+	texts := map[string]string{
+		"str":      "a\tb\"c",
+		"interp":   "${var.name}",
+		"text":     "line1\n  line2\n",
+		"indented": "one\n  two\n",
+	}
+	for key, want := range texts {
+		if v, ok := y.GetString("s", key); !ok || v != want {
+			t.Errorf("%s = %q, %v; want %q", key, v, ok, want)
+		}
+	}
+	ints := map[string]int{"hex": 31, "neg": -7}
+	for key, want := range ints {
+		if v, ok := y.GetInt("s", key); !ok || v != want {
+			t.Errorf("%s = %d, %v; want %d", key, v, ok, want)
+		}
+	}
+	var f float64
+	if raw, ok := y.GetRaw("s", "float"); !ok || raw.Decode(&f) != nil || f != 2.5 {
+		t.Errorf("float = %v, %v; want 2.5", f, ok)
+	}
+	if v, ok := y.GetBool("s", "off"); !ok || v {
+		t.Errorf("off = %v, %v; want false", v, ok)
+	}
+	if _, ok := y.GetString("s", "nothing"); ok {
+		t.Error("null must be absent")
+	}
+	raw, ok := y.GetRaw("s", "obj")
+	if !ok {
+		t.Fatal("obj is absent")
+	}
+	var obj map[string]any
+	if err := raw.Decode(&obj); err != nil || obj["a"] != 1 || obj["b c"] != "d" {
+		t.Errorf("obj = %v, %v; want a=1, b c=d", obj, err)
+	}
+}
+
+func TestParseHCLRepeatedBlocks(t *testing.T) {
+	y := mustParseHCL(t, `
+server {
+  listener { port = 80 }
+  listener { port = 443 }
+}
+`)
+	raw, ok := y.GetRaw("server", "listener")
+	if !ok {
+		t.Fatal("listener is absent")
+	}
+	var listeners []struct{ Port int }
+	if err := raw.Decode(&listeners); err != nil || len(listeners) != 2 || listeners[1].Port != 443 {
+		t.Errorf("listeners = %+v, %v; want ports 80 and 443", listeners, err)
+	}
+}
+
+func TestParseHCLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"duplicate section", "a {}\na {}\n", `line 2: section "a" is defined twice`},
+		{"unclosed block", "a {\n  x = 1\n", "line 1: unclosed block"},
+		{"unclosed list", "a {\n  x = [1, 2\n", "unclosed list"},
+		{"unclosed object", "a {\n  x = { y = 1\n", "unclosed object"},
+		{"unterminated string", "a {\n  x = \"abc\n}\n", "line 2: unterminated string"},
+		{"unterminated heredoc", "a {\n  x = <<EOF\nabc\n", "unterminated heredoc EOF"},
+		{"expression", "a {\n  x = var.name\n}\n", "expressions are not supported"},
+		{"invalid number", "a {\n  x = 1.2.3\n}\n", `invalid number "1.2.3"`},
+		{"missing value", "a {\n  x = \n}\n", "expected a value"},
+		{"object key", "a {\n  x = { = 1 }\n}\n", "expected an object key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHCL([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDocument - сервис конфигурации: документ с ETag и Last-Modified, условные запросы
+type fakeDocument struct {
+	mu           sync.Mutex
+	body         string
+	contentType  string
+	etag         string
+	lastModified string
+	requests     []http.Header // Заголовки полученных запросов
+}
+
+func (d *fakeDocument) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, r.Header.Clone())
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if (d.etag != "" && r.Header.Get("If-None-Match") == d.etag) || (d.lastModified != "" && r.Header.Get("If-Modified-Since") == d.lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if d.etag != "" {
+		w.Header().Set("ETag", d.etag)
+	}
+	if d.lastModified != "" {
+		w.Header().Set("Last-Modified", d.lastModified)
+	}
+	if d.contentType != "" {
+		w.Header().Set("Content-Type", d.contentType)
+	}
+	w.Write([]byte(d.body))
+}
+
+func (d *fakeDocument) set(body, etag, lastModified string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.body, d.etag, d.lastModified = body, etag, lastModified
+}
+
+func (d *fakeDocument) last() http.Header {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.requests[len(d.requests)-1]
+}
+
+func TestHTTPSource(t *testing.T) {
+	doc := &fakeDocument{}
+	doc.set("server:\n  port: 8080\n", `"v1"`, "")
+	srv := httptest.NewServer(doc)
+	defer srv.Close()
+
+	ctx := context.Background()
+	src, err := NewHTTPSource(ctx, HTTPOptions{URL: srv.URL + "/svc.yaml", Header: http.Header{"Authorization": {"Bearer token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+	if accept := doc.last().Get("Accept"); !strings.HasPrefix(accept, "application/yaml") {
+		t.Errorf("Accept = %q, want YAML first", accept)
+	}
+	changes := 0
+	src.YAML().OnChange(func() { changes++ })
+
+	// ETag совпал - 304, подписчики не уведомляются
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h := doc.last(); h.Get("If-None-Match") != `"v1"` || changes != 0 {
+		t.Errorf("If-None-Match = %q, %d changes; want \"v1\" and no change", h.Get("If-None-Match"), changes)
+	}
+
+	doc.set("server:\n  port: 9090\n", `"v2"`, "")
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 9090 || changes != 1 {
+		t.Errorf("server.port = %d after %d changes, want 9090 after one", v, changes)
+	}
+
+	// Без валидаторов сервер отдает документ целиком; тот же документ не тревожит подписчиков
+	doc.set("server:\n  port: 9090\n", "", "")
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if changes != 1 {
+		t.Errorf("%d changes after an identical document, want one", changes)
+	}
+
+	// Невалидный документ отклоняется: дерево сохраняется, валидаторы сбрасываются
+	doc.set("server: [\n", "", "Mon, 01 Jan 2024 09:00:00 GMT")
+	if err := src.Reload(ctx); err == nil || !strings.Contains(err.Error(), srv.URL+"/svc.yaml") {
+		t.Errorf("error = %v, want the document URL", err)
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 9090 || changes != 1 {
+		t.Errorf("server.port = %d after %d changes, want the tree kept", v, changes)
+	}
+	doc.set("server:\n  port: 7070\n", "", "Mon, 01 Jan 2024 09:00:00 GMT")
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h := doc.last(); h.Get("If-Modified-Since") != "" {
+		t.Errorf("If-Modified-Since = %q after a rejected document, want none", h.Get("If-Modified-Since"))
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 7070 {
+		t.Errorf("server.port = %d, want 7070", v)
+	}
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if h := doc.last(); h.Get("If-Modified-Since") != "Mon, 01 Jan 2024 09:00:00 GMT" {
+		t.Errorf("If-Modified-Since = %q, want the Last-Modified value", h.Get("If-Modified-Since"))
+	}
+}
+
+func TestHTTPSourceFormat(t *testing.T) {
+	// YAML-парсер принимает и JSON, поэтому формат проверяется на документе, который допустим только в YAML
+	tests := []struct {
+		name        string
+		path        string
+		format      string
+		contentType string
+		body        string
+		err         bool
+	}{
+		{"yaml by default", "/svc", "", "", "server: {port: 8080}", false},
+		{"json content type", "/svc", "", "application/json; charset=utf-8", "server: {port: 8080}", true},
+		{"json suffix content type", "/svc", "", "application/vnd.config+json", "server: {port: 8080}", true},
+		{"json extension", "/svc.json", "", "", "server: {port: 8080}", true},
+		{"json document", "/svc.json", "", "", `{"server": {"port": 8080}}`, false},
+		{"format wins over content type", "/svc", "yaml", "application/json", "server: {port: 8080}", false},
+		{"format json", "/svc.yaml", "json", "", "server: {port: 8080}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &fakeDocument{contentType: tt.contentType}
+			doc.set(tt.body, "", "")
+			srv := httptest.NewServer(doc)
+			defer srv.Close()
+			src, err := NewHTTPSource(context.Background(), HTTPOptions{URL: srv.URL + tt.path, Format: tt.format, Header: http.Header{"Authorization": {"Bearer token"}}})
+			if tt.err {
+				if err == nil {
+					t.Fatal("the document parsed in the wrong format")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+				t.Errorf("server.port = %d, %v; want 8080", v, ok)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceErrors(t *testing.T) {
+	doc := &fakeDocument{}
+	srv := httptest.NewServer(doc)
+	defer srv.Close()
+
+	if _, err := NewHTTPSource(context.Background(), HTTPOptions{}); err == nil || !strings.Contains(err.Error(), "URL is required") {
+		t.Errorf("error = %v, want the required URL", err)
+	}
+	if _, err := NewHTTPSource(context.Background(), HTTPOptions{URL: srv.URL, Format: "toml"}); err == nil || !strings.Contains(err.Error(), `unknown format "toml"`) {
+		t.Errorf("error = %v, want the unknown format", err)
+	}
+	// Учетные данные и параметры запроса не попадают в ошибку
+	u := strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/svc.yaml?token=secret"
+	_, err := NewHTTPSource(context.Background(), HTTPOptions{URL: u})
+	if err == nil || !strings.Contains(err.Error(), "GET "+srv.URL+"/svc.yaml: unexpected status 401") {
+		t.Fatalf("error = %v, want the redacted URL and status", err)
+	}
+	if strings.Contains(err.Error(), "pass") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %q leaks credentials", err)
+	}
+}
@@ -0,0 +1,101 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+	"sync"
+)
+
+// KeyringOptions configures a secret resolver backed by the OS credential store.
+type KeyringOptions struct {
+	// Service is the keyring service name used for references without a service part.
+	Service string
+	// Get reads a secret for service and user. It has the signature of keyring.Get from
+	// github.com/zalando/go-keyring, which can be passed directly. By default the OS tools
+	// are used: "security" (macOS keychain) and "secret-tool" (libsecret) elsewhere,
+	// with the same item attributes as go-keyring. Windows requires Get.
+	Get func(service, user string) (string, error)
+	// OnError (optional) receives lookup errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+}
+
+// KeyringSource resolves ggconfig:secret methods from the OS credential store, so developers
+// can keep API keys in the keychain instead of .env files. References are "<user>" (the
+// configured Service is used) or "<service>/<user>"; without a directive value the user is
+// the ENV key of the method. Found secrets are cached for the lifetime of the source.
+type KeyringSource struct {
+	opts KeyringOptions
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewKeyringSource returns a resolver for the OS credential store.
+func NewKeyringSource(opts KeyringOptions) (*KeyringSource, error) {
+	if opts.Get == nil {
+		if goruntime.GOOS == "windows" {
+			return nil, errors.New("keyring: Get is required on windows (e.g. keyring.Get from github.com/zalando/go-keyring)")
+		}
+		opts.Get = osKeyringGet
+	}
+	return &KeyringSource{opts: opts, cache: map[string]string{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *KeyringSource) ResolveSecret(ref string) (string, bool) {
+	s.mu.Lock()
+	v, ok := s.cache[ref]
+	s.mu.Unlock()
+	if ok {
+		return v, true
+	}
+
+	service, user := s.opts.Service, ref
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		service, user = ref[:i], ref[i+1:]
+	}
+	if service == "" {
+		s.reportError(ref, errors.New("keyring: no service in reference and KeyringOptions.Service is empty"))
+		return "", false
+	}
+	v, err := s.opts.Get(service, user)
+	if err != nil {
+		s.reportError(ref, fmt.Errorf("keyring: %s/%s: %w", service, user, err))
+		return "", false
+	}
+
+	s.mu.Lock()
+	s.cache[ref] = v
+	s.mu.Unlock()
+	return v, true
+}
+
+func (s *KeyringSource) reportError(ref string, err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(ref, err)
+	}
+}
+
+// osKeyringGet читает секрет системными утилитами, совместимо с записями go-keyring
+func osKeyringGet(service, user string) (string, error) {
+	var cmd *exec.Cmd
+	if goruntime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", user, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "username", user)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", cmd.Args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,107 @@
+package runtime
+
+import "strings"
+
+// KeyFunc customizes the keys of a generated config for naming conventions the generator
+// does not derive (double underscores, service IDs embedded in keys). It receives the method
+// name ("MaxConns") and returns the ENV variable and the YAML key to read instead of the
+// derived ones (DB_MAX_CONNS, db.maxconns). The YAML key is "section.key" or a key of the
+// package section. An empty result keeps the derived key (and its aliases).
+// Generated New...EnvConfigWithKeys and New...YAMLConfigWithKeys accept a KeyFunc:
+//
+//	keys := func(method string) (string, string) {
+//		return "SVC42__DB__" + strings.ToUpper(method), "svc42.db_" + strings.ToLower(method)
+//	}
+type KeyFunc func(method string) (envKey, yamlKey string)
+
+// EnvKeys returns a key mapper for generated ENV configs (EnvConfigWithMap) that applies keys.
+// derived maps every ENV key the generated code reads (derived keys and aliases) to its method;
+// for a method with a custom ENV key all of them read the custom variable. Other keys pass through.
+func EnvKeys(keys KeyFunc, derived map[string]string) func(key string) string {
+	custom := make(map[string]string, len(derived))
+	for key, method := range derived {
+		if env, _ := keys(method); env != "" {
+			custom[key] = env
+		}
+	}
+	return func(key string) string {
+		if env, ok := custom[key]; ok {
+			return env
+		}
+		return key
+	}
+}
+
+// YAMLField describes where generated YAML code reads a method: Sections are the section
+// aliases followed by the package section, Keys are the key aliases followed by the derived key.
+type YAMLField struct {
+	Method   string
+	Sections []string
+	Keys     []string
+}
+
+// RemapYAML returns a view of y for generated YAML configs (YAMLConfigParsed) that applies keys:
+// for a method with a custom YAML key the derived key holds the value of the custom key, and
+// the aliases of the method are removed, so only the custom key is read. The view follows y:
+// when y is replaced (a reloading source), the view is recomputed.
+func RemapYAML(y *YAML, keys KeyFunc, fields ...YAMLField) *YAML {
+	out := &YAML{root: remapRoot(y, keys, fields)}
+	y.OnChange(func() { out.Replace(&YAML{root: remapRoot(y, keys, fields)}) })
+	y.mu.RLock()
+	out.secrets = y.secrets
+	y.mu.RUnlock()
+	return out
+}
+
+func remapRoot(y *YAML, keys KeyFunc, fields []YAMLField) map[string]any {
+	y.mu.RLock()
+	src := y.root
+	y.mu.RUnlock()
+
+	// Карты исходного дерева не изменяются: измененные секции копируются
+	root := make(map[string]any, len(src))
+	for k, v := range src {
+		root[k] = v
+	}
+	copied := map[string]map[string]any{}
+	sectionCopy := func(name string) map[string]any {
+		if sec, ok := copied[name]; ok {
+			return sec
+		}
+		old, _ := root[name].(map[string]any)
+		sec := make(map[string]any, len(old)+1)
+		for k, v := range old {
+			sec[k] = v
+		}
+		root[name], copied[name] = sec, sec
+		return sec
+	}
+	for _, f := range fields {
+		_, custom := keys(f.Method)
+		if custom == "" || len(f.Sections) == 0 || len(f.Keys) == 0 {
+			continue
+		}
+		main := f.Sections[len(f.Sections)-1]
+		section, key, ok := strings.Cut(custom, ".")
+		if !ok {
+			section, key = main, custom
+		}
+		var value any
+		if sec, ok := src[section].(map[string]any); ok {
+			value = sec[key]
+		}
+		for _, s := range f.Sections {
+			if _, ok := root[s].(map[string]any); !ok {
+				continue
+			}
+			sec := sectionCopy(s)
+			for _, k := range f.Keys {
+				delete(sec, k)
+			}
+		}
+		if value != nil {
+			sectionCopy(main)[f.Keys[len(f.Keys)-1]] = value
+		}
+	}
+	return root
+}
@@ -0,0 +1,155 @@
+package runtime
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMountInterval is how often MountSource.Watch checks the directory when no interval is given.
+// The kubelet itself refreshes mounted ConfigMaps and Secrets only every minute or so.
+const DefaultMountInterval = 10 * time.Second
+
+// MountSource reads configuration from a mounted directory with one file per key, the way
+// Kubernetes mounts ConfigMap and Secret volumes:
+//
+//	/etc/config/server_port         -> server.port
+//	/etc/config/server.readtimeout  -> server.readtimeout
+//	/etc/secrets/database_password  -> database.password
+//
+// A file name is "<section>.<key>" or "<section>_<key>"; keys are lower-case method names and
+// have no underscores, so the name is split at its last underscore (my_pkg_port -> my_pkg.port).
+// The content is the value, without one trailing newline. Hidden entries (the ..data symlink and
+// timestamped directories of the kubelet) and directories are skipped. The tree is served as a
+// *YAML that the generated YAML implementations read and GlobalConfig accepts directly.
+type MountSource struct {
+	dir string
+	y   *YAML
+
+	mu          sync.Mutex
+	fingerprint [sha256.Size]byte
+}
+
+// NewMountSource reads the directory once and returns the source.
+func NewMountSource(dir string) (*MountSource, error) {
+	if dir == "" {
+		return nil, errors.New("mount: directory is required")
+	}
+	s := &MountSource{dir: dir, y: &YAML{}}
+	if err := s.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *MountSource) YAML() *YAML {
+	return s.y
+}
+
+// Reload re-reads the directory and replaces the configuration tree if any file changed.
+func (s *MountSource) Reload(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var props map[string]string
+	var fingerprint [sha256.Size]byte
+	for attempt := 0; ; attempt++ {
+		// Kubelet подменяет ссылку ..data разом для всех файлов тома: если она сменилась во
+		// время чтения, часть файлов могла прийти из прежней версии - читаем заново
+		before, _ := os.Readlink(filepath.Join(s.dir, "..data"))
+		var err error
+		props, fingerprint, err = readMountDir(s.dir)
+		if err != nil {
+			return err
+		}
+		after, _ := os.Readlink(filepath.Join(s.dir, "..data"))
+		if before == after || attempt == 2 {
+			break
+		}
+	}
+	if fingerprint == s.fingerprint {
+		return nil
+	}
+	s.y.Replace(FromProperties(props))
+	s.fingerprint = fingerprint
+	return nil
+}
+
+// Watch re-reads the directory every interval (DefaultMountInterval if interval <= 0) and
+// replaces the tree when the files change, notifying YAML().OnChange subscribers, until ctx is
+// cancelled. The kubelet swaps all files of a volume at once, so a reload never mixes old and
+// new values. Errors (e.g. an unmounted volume) are retried with backoff; onError (optional)
+// receives them for logging.
+func (s *MountSource) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = DefaultMountInterval
+	}
+	wait := func(ctx context.Context) (bool, error) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+			return true, nil
+		}
+	}
+	return watchLoop(ctx, wait, s.Reload, onError)
+}
+
+// readMountDir читает файлы директории в плоскую карту section.key и считает отпечаток
+// содержимого: перезагрузка без изменений не тревожит подписчиков OnChange
+func readMountDir(dir string) (map[string]string, [sha256.Size]byte, error) {
+	var fingerprint [sha256.Size]byte
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fingerprint, fmt.Errorf("mount: %w", err)
+	}
+	h := sha256.New()
+	props := map[string]string{}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// Ключи тома - символические ссылки на ..data/<key>: Stat следует по ссылке
+		st, err := os.Stat(path)
+		if err != nil {
+			return nil, fingerprint, fmt.Errorf("mount: %w", err)
+		}
+		if !st.Mode().IsRegular() {
+			continue
+		}
+		key, ok := mountKey(name)
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fingerprint, fmt.Errorf("mount: %w", err)
+		}
+		value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		props[key] = value
+		fmt.Fprintf(h, "%s\x00%d\x00%s\x00", name, len(value), value)
+	}
+	copy(fingerprint[:], h.Sum(nil))
+	return props, fingerprint, nil
+}
+
+// mountKey переводит имя файла в ключ section.key: server.port как есть, server_port - по
+// последнему подчеркиванию
+func mountKey(name string) (string, bool) {
+	if section, key, ok := strings.Cut(name, "."); ok {
+		return section + "." + strings.ToLower(key), section != "" && key != ""
+	}
+	i := strings.LastIndex(name, "_")
+	if i <= 0 || i == len(name)-1 {
+		return "", false
+	}
+	return name[:i] + "." + strings.ToLower(name[i+1:]), true
+}
@@ -0,0 +1,214 @@
+package runtime
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NacosOptions configures a source backed by a Nacos configuration service.
+type NacosOptions struct {
+	// Server is the Nacos server address, e.g. "http://nacos:8848".
+	Server string
+	// DataID is the Nacos data id. Its extension selects the format:
+	// .properties maps "section.key" entries onto sections, anything else
+	// (.yaml, .yml, .json or no extension) is parsed as a YAML document.
+	DataID string
+	// Group is the Nacos group (default "DEFAULT_GROUP").
+	Group string
+	// Namespace is the Nacos namespace (tenant) id (optional).
+	Namespace string
+	// Username and Password enable Nacos authentication (optional).
+	Username string
+	Password string
+	// Client is the HTTP client (default: a client with a timeout suitable for long polling).
+	Client *http.Client
+}
+
+// NacosSource loads a Nacos config (dataId + group) into a *YAML that the generated YAML
+// implementations read and GlobalConfig accepts directly. Watch registers a listener
+// (long polling) and reloads the config on every change, notifying YAML().OnChange subscribers.
+type NacosSource struct {
+	opts NacosOptions
+	y    *YAML
+
+	mu          sync.Mutex
+	md5         string // MD5 текущего содержимого - Nacos сравнивает его при прослушивании
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewNacosSource fetches the config once and returns the source.
+func NewNacosSource(ctx context.Context, opts NacosOptions) (*NacosSource, error) {
+	if opts.Server == "" || opts.DataID == "" {
+		return nil, errors.New("nacos: Server and DataID are required")
+	}
+	if opts.Group == "" {
+		opts.Group = "DEFAULT_GROUP"
+	}
+	if opts.Client == nil {
+		// Сервер удерживает запрос прослушивания до 30 секунд
+		opts.Client = &http.Client{Timeout: 60 * time.Second}
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+
+	s := &NacosSource{opts: opts, y: &YAML{}}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *NacosSource) YAML() *YAML {
+	return s.y
+}
+
+// Reload fetches the config and replaces the configuration tree.
+func (s *NacosSource) Reload(ctx context.Context) error {
+	q := url.Values{"dataId": {s.opts.DataID}, "group": {s.opts.Group}}
+	if s.opts.Namespace != "" {
+		q.Set("tenant", s.opts.Namespace)
+	}
+	req, err := s.request(ctx, http.MethodGet, "/nacos/v1/cs/configs", q, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("nacos: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nacos: get config %s/%s: unexpected status %s", s.opts.Group, s.opts.DataID, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("nacos: read config: %w", err)
+	}
+
+	var y *YAML
+	if strings.HasSuffix(s.opts.DataID, ".properties") {
+		y = FromProperties(ParseProperties(string(data)))
+	} else {
+		y, err = ParseYAML(data)
+		if err != nil {
+			return fmt.Errorf("nacos: config %s/%s: %w", s.opts.Group, s.opts.DataID, err)
+		}
+	}
+	s.y.Replace(y)
+
+	sum := md5.Sum(data)
+	s.mu.Lock()
+	s.md5 = hex.EncodeToString(sum[:])
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch listens for config changes (Nacos long polling) and reloads on every change
+// until ctx is cancelled. Transient errors are retried with backoff; onError (optional)
+// receives them for logging.
+func (s *NacosSource) Watch(ctx context.Context, onError func(error)) error {
+	return watchLoop(ctx, s.poll, s.Reload, onError)
+}
+
+// poll выполняет один запрос прослушивания; непустой ответ означает изменение конфига
+func (s *NacosSource) poll(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	sum := s.md5
+	s.mu.Unlock()
+
+	// Формат Listening-Configs: dataId^2group^2md5[^2tenant]^1
+	listening := s.opts.DataID + "\x02" + s.opts.Group + "\x02" + sum
+	if s.opts.Namespace != "" {
+		listening += "\x02" + s.opts.Namespace
+	}
+	listening += "\x01"
+	form := url.Values{"Listening-Configs": {listening}}
+
+	req, err := s.request(ctx, http.MethodPost, "/nacos/v1/cs/configs/listener", nil, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Long-Pulling-Timeout", "30000")
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("nacos: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("nacos: listener: unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("nacos: read listener response: %w", err)
+	}
+	return strings.TrimSpace(string(body)) != "", nil
+}
+
+func (s *NacosSource) request(ctx context.Context, method, path string, q url.Values, body io.Reader) (*http.Request, error) {
+	if q == nil {
+		q = url.Values{}
+	}
+	if s.opts.Username != "" {
+		token, err := s.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("accessToken", token)
+	}
+	u := s.opts.Server + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return http.NewRequestWithContext(ctx, method, u, body)
+}
+
+// token возвращает токен доступа Nacos, обновляя его по истечении срока
+func (s *NacosSource) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token, expiry := s.accessToken, s.tokenExpiry
+	s.mu.Unlock()
+	if token != "" && time.Now().Before(expiry) {
+		return token, nil
+	}
+
+	form := url.Values{"username": {s.opts.Username}, "password": {s.opts.Password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Server+"/nacos/v1/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("nacos: login: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("nacos: login: unexpected status %s", resp.Status)
+	}
+	var body struct {
+		AccessToken string `json:"accessToken"`
+		TokenTTL    int64  `json:"tokenTtl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("nacos: decode login response: %w", err)
+	}
+
+	s.mu.Lock()
+	s.accessToken = body.AccessToken
+	// Обновляем токен заранее, за минуту до истечения
+	s.tokenExpiry = time.Now().Add(time.Duration(body.TokenTTL)*time.Second - time.Minute)
+	s.mu.Unlock()
+	return body.AccessToken, nil
+}
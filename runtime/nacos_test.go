@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeNacos - сервер Nacos: конфиг по dataId/group/tenant, прослушивание по MD5 и вход по паролю
+type fakeNacos struct {
+	mu      sync.Mutex
+	configs map[string]string // group/dataId -> содержимое
+	logins  int
+}
+
+func (n *fakeNacos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if r.URL.Path == "/nacos/v1/auth/login" {
+		if r.Method != http.MethodPost || r.PostFormValue("username") != "nacos" || r.PostFormValue("password") != "pass" {
+			http.Error(w, "unknown user", http.StatusForbidden)
+			return
+		}
+		n.logins++
+		json.NewEncoder(w).Encode(map[string]any{"accessToken": "token-1", "tokenTtl": 18000})
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("accessToken") != "token-1" {
+		http.Error(w, "invalid token", http.StatusForbidden)
+		return
+	}
+	switch r.URL.Path {
+	case "/nacos/v1/cs/configs":
+		if q.Get("tenant") != "dev" {
+			http.Error(w, "bad tenant", http.StatusBadRequest)
+			return
+		}
+		data, ok := n.configs[q.Get("group")+"/"+q.Get("dataId")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data))
+	case "/nacos/v1/cs/configs/listener":
+		if r.Method != http.MethodPost || r.Header.Get("Long-Pulling-Timeout") != "30000" {
+			http.Error(w, "bad listener request", http.StatusBadRequest)
+			return
+		}
+		// Изменившиеся конфиги возвращаются в ответе, совпавшие - пустой ответ
+		fields := strings.Split(strings.TrimSuffix(r.PostFormValue("Listening-Configs"), "\x01"), "\x02")
+		if len(fields) != 4 || fields[3] != "dev" {
+			http.Error(w, "bad Listening-Configs", http.StatusBadRequest)
+			return
+		}
+		sum := md5.Sum([]byte(n.configs[fields[1]+"/"+fields[0]]))
+		if fields[2] != hex.EncodeToString(sum[:]) {
+			w.Write([]byte(fields[0] + "%02" + fields[1] + "%02dev%01\n"))
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (n *fakeNacos) set(key, data string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.configs[key] = data
+}
+
+func TestNacosSource(t *testing.T) {
+	nacos := &fakeNacos{configs: map[string]string{"DEFAULT_GROUP/svc.yaml": "server:\n  port: 8080\n"}}
+	srv := httptest.NewServer(nacos)
+	defer srv.Close()
+
+	ctx := context.Background()
+	src, err := NewNacosSource(ctx, NacosOptions{Server: srv.URL + "/", DataID: "svc.yaml", Namespace: "dev", Username: "nacos", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+
+	// MD5 загруженного содержимого совпадает - изменений нет
+	if changed, err := src.poll(ctx); err != nil || changed {
+		t.Errorf("poll = %v, %v; want no change", changed, err)
+	}
+	nacos.set("DEFAULT_GROUP/svc.yaml", "server:\n  port: 9090\n")
+	if changed, err := src.poll(ctx); err != nil || !changed {
+		t.Errorf("poll after a publish = %v, %v; want a change", changed, err)
+	}
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := src.YAML().GetInt("server", "port"); v != 9090 {
+		t.Errorf("server.port after Reload = %d, want 9090", v)
+	}
+	if changed, err := src.poll(ctx); err != nil || changed {
+		t.Errorf("poll after Reload = %v, %v; want no change", changed, err)
+	}
+	if nacos.logins != 1 {
+		t.Errorf("%d logins, want the token reused", nacos.logins)
+	}
+}
+
+func TestNacosProperties(t *testing.T) {
+	nacos := &fakeNacos{configs: map[string]string{"APP/svc.properties": "server.port=8080\nserver.host=db\n"}}
+	srv := httptest.NewServer(nacos)
+	defer srv.Close()
+
+	src, err := NewNacosSource(context.Background(), NacosOptions{Server: srv.URL, DataID: "svc.properties", Group: "APP", Namespace: "dev", Username: "nacos", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := src.YAML().GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+	if v, ok := src.YAML().GetString("server", "host"); !ok || v != "db" {
+		t.Errorf("server.host = %q, %v; want db", v, ok)
+	}
+}
+
+func TestNacosSourceErrors(t *testing.T) {
+	nacos := &fakeNacos{configs: map[string]string{"DEFAULT_GROUP/bad.yaml": "server: [\n"}}
+	srv := httptest.NewServer(nacos)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts NacosOptions
+		err  string
+	}{
+		{"required", NacosOptions{Server: srv.URL}, "Server and DataID are required"},
+		{"login", NacosOptions{Server: srv.URL, DataID: "svc.yaml", Username: "nacos", Password: "wrong"}, "login: unexpected status 403"},
+		{"no token", NacosOptions{Server: srv.URL, DataID: "svc.yaml"}, "get config DEFAULT_GROUP/svc.yaml: unexpected status 403"},
+		{"missing config", NacosOptions{Server: srv.URL, DataID: "svc.yaml", Namespace: "dev", Username: "nacos", Password: "pass"}, "unexpected status 404"},
+		{"invalid document", NacosOptions{Server: srv.URL, DataID: "bad.yaml", Namespace: "dev", Username: "nacos", Password: "pass"}, "nacos: config DEFAULT_GROUP/bad.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNacosSource(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
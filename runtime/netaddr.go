@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseIP parses an IPv4 or IPv6 address ("10.0.0.1", "::1") of a net.IP method with net.ParseIP.
+func ParseIP(value string) (net.IP, error) {
+	ip := net.ParseIP(strings.TrimSpace(value))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", value)
+	}
+	return ip, nil
+}
+
+// ParseCIDR parses a network in CIDR notation ("10.0.0.0/8", "fd00::/8") of a *net.IPNet
+// method with net.ParseCIDR. Host bits are dropped: "10.1.2.3/8" is the network 10.0.0.0/8.
+func ParseCIDR(value string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// GetIP retrieves a net.IP parsed with ParseIP from a string value. Values that are not
+// an IP address are skipped as if the key was absent.
+func (y *YAML) GetIP(section string, keys ...string) (net.IP, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return nil, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if s, ok := sec[k].(string); ok {
+			if ip, err := ParseIP(s); err == nil {
+				return ip, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// GetCIDR retrieves a *net.IPNet parsed with ParseCIDR from a string value. Values that
+// are not a network in CIDR notation (including a plain address) are skipped as if the key was absent.
+func (y *YAML) GetCIDR(section string, keys ...string) (*net.IPNet, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return nil, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if s, ok := sec[k].(string); ok {
+			if network, err := ParseCIDR(s); err == nil {
+				return network, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseOneOf returns value if it is one of allowed (a method annotated with
+// ggconfig:oneof=debug,info,warn,error) and an error listing the allowed values otherwise.
+// Values are compared as is, case-sensitively.
+func ParseOneOf(value string, allowed ...string) (string, error) {
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// GetOneOf retrieves a string that is one of allowed. Other values are skipped as if the
+// key was absent, so the lookup falls through to the next key, section or source.
+func (y *YAML) GetOneOf(allowed []string, section string, keys ...string) (string, bool) {
+	for _, k := range keys {
+		if v, ok := y.GetString(section, k); ok {
+			if _, err := ParseOneOf(v, allowed...); err == nil {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ReportNotOneOf reports the first value of sections/keys that is not one of allowed to the
+// parse error handler (see NotOneOfValue).
+func (y *YAML) ReportNotOneOf(allowed []string, sections []string, keys ...string) {
+	if err := y.NotOneOfValue(allowed, sections, keys...); err != nil {
+		HandleParseError(err)
+	}
+}
+
+// NotOneOfValue returns the first value of sections/keys that is not one of allowed as a
+// *ParseError, nil if there is none. It is called by strict generated code after GetOneOf found nothing.
+func (y *YAML) NotOneOfValue(allowed []string, sections []string, keys ...string) *ParseError {
+	for _, section := range sections {
+		for _, k := range keys {
+			if v, ok := y.GetString(section, k); ok {
+				_, err := ParseOneOf(v, allowed...)
+				return &ParseError{Source: "yaml", Key: section + "." + k, Value: v, Type: "string", Err: err}
+			}
+		}
+	}
+	return y.InvalidValue("string", sections, keys...)
+}
+
+// NotOneOfValueUnset is NotOneOfValue for a method annotated with ggconfig:unset: keys holding
+// the sentinel unset are skipped as absent.
+func (y *YAML) NotOneOfValueUnset(allowed []string, unset string, sections []string, keys ...string) *ParseError {
+	for _, section := range sections {
+		for _, k := range keys {
+			if v, ok := y.GetString(section, k); ok && v != unset {
+				_, err := ParseOneOf(v, allowed...)
+				return &ParseError{Source: "yaml", Key: section + "." + k, Value: v, Type: "string", Err: err}
+			}
+		}
+	}
+	return y.InvalidValueUnset("string", unset, sections, keys...)
+}
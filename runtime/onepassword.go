@@ -0,0 +1,244 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OnePasswordOptions configures a secret resolver backed by a 1Password Connect server.
+type OnePasswordOptions struct {
+	// Server is the Connect server address, e.g. "http://op-connect:8080".
+	Server string
+	// Token is the Connect access token.
+	Token string
+	// CacheTTL is how long resolved secrets are cached (default 5 minutes, negative disables caching).
+	CacheTTL time.Duration
+	// OnError (optional) receives resolution errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+	// Client is the HTTP client (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// OnePasswordSource resolves 1Password secret references of the form
+// "op://<vault>/<item>/[<section>/]<field>" through the 1Password Connect API.
+// Vaults and items are matched by name first and then by id; fields by label, then by id.
+type OnePasswordSource struct {
+	opts OnePasswordOptions
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// NewOnePasswordSource returns a resolver for the given Connect server. Secrets are fetched lazily.
+func NewOnePasswordSource(opts OnePasswordOptions) (*OnePasswordSource, error) {
+	if opts.Server == "" || opts.Token == "" {
+		return nil, errors.New("1password: Server and Token are required")
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+	return &OnePasswordSource{opts: opts, cache: map[string]cachedSecret{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *OnePasswordSource) ResolveSecret(ref string) (string, bool) {
+	s.mu.Lock()
+	c, ok := s.cache[ref]
+	s.mu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.value, true
+	}
+
+	v, err := s.Lookup(context.Background(), ref)
+	if err != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(ref, err)
+		}
+		return "", false
+	}
+	if s.opts.CacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[ref] = cachedSecret{value: v, expires: time.Now().Add(s.opts.CacheTTL)}
+		s.mu.Unlock()
+	}
+	return v, true
+}
+
+// Lookup fetches a secret by reference, bypassing the cache.
+func (s *OnePasswordSource) Lookup(ctx context.Context, ref string) (string, error) {
+	vault, item, section, field, err := parseOnePasswordRef(ref)
+	if err != nil {
+		return "", err
+	}
+	vaultID, err := s.findID(ctx, "/v1/vaults", "name", vault)
+	if err != nil {
+		return "", err
+	}
+	fields, err := s.itemFields(ctx, vaultID, item)
+	if err != nil {
+		return "", err
+	}
+	return fields.find(ref, section, field)
+}
+
+// PrefetchSecrets implements SecretPrefetcher: each vault and item referenced by refs is
+// fetched once, and all requested fields are cached for CacheTTL. Without caching it does nothing.
+func (s *OnePasswordSource) PrefetchSecrets(ctx context.Context, refs []string) error {
+	if s.opts.CacheTTL <= 0 {
+		return nil
+	}
+	type itemKey struct{ vault, item string }
+	byItem := map[itemKey][]string{}
+	var order []itemKey
+	for _, ref := range refs {
+		vault, item, _, _, err := parseOnePasswordRef(ref)
+		if err != nil {
+			return err
+		}
+		k := itemKey{vault, item}
+		if _, ok := byItem[k]; !ok {
+			order = append(order, k)
+		}
+		byItem[k] = append(byItem[k], ref)
+	}
+
+	vaultIDs := map[string]string{}
+	for _, k := range order {
+		vaultID, ok := vaultIDs[k.vault]
+		if !ok {
+			id, err := s.findID(ctx, "/v1/vaults", "name", k.vault)
+			if err != nil {
+				return err
+			}
+			vaultID, vaultIDs[k.vault] = id, id
+		}
+		fields, err := s.itemFields(ctx, vaultID, k.item)
+		if err != nil {
+			return err
+		}
+		expires := time.Now().Add(s.opts.CacheTTL)
+		for _, ref := range byItem[k] {
+			_, _, section, field, _ := parseOnePasswordRef(ref)
+			v, err := fields.find(ref, section, field)
+			if err != nil {
+				return err
+			}
+			s.mu.Lock()
+			s.cache[ref] = cachedSecret{value: v, expires: expires}
+			s.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+type onePasswordFields []struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Section *struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+	} `json:"section"`
+}
+
+// itemFields загружает поля элемента item (по имени или id) из хранилища vaultID
+func (s *OnePasswordSource) itemFields(ctx context.Context, vaultID, item string) (onePasswordFields, error) {
+	itemID, err := s.findID(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items", "title", item)
+	if err != nil {
+		return nil, err
+	}
+	var full struct {
+		Fields onePasswordFields `json:"fields"`
+	}
+	if err := s.get(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items/"+url.PathEscape(itemID), &full); err != nil {
+		return nil, err
+	}
+	return full.Fields, nil
+}
+
+func (fields onePasswordFields) find(ref, section, field string) (string, error) {
+	// Сначала совпадение по label, затем по id - как в op read
+	for _, byLabel := range []bool{true, false} {
+		for _, f := range fields {
+			name := f.ID
+			if byLabel {
+				name = f.Label
+			}
+			if name != field {
+				continue
+			}
+			if section != "" && (f.Section == nil || (f.Section.Label != section && f.Section.ID != section)) {
+				continue
+			}
+			return f.Value, nil
+		}
+	}
+	return "", fmt.Errorf("1password: %s: field %q not found", ref, field)
+}
+
+// findID возвращает id объекта по имени (filter=<attr> eq "<name>"), иначе считает name идентификатором
+func (s *OnePasswordSource) findID(ctx context.Context, path, attr, name string) (string, error) {
+	var list []struct {
+		ID string `json:"id"`
+	}
+	q := url.Values{"filter": {attr + ` eq "` + name + `"`}}
+	if err := s.get(ctx, path+"?"+strings.ReplaceAll(q.Encode(), "+", "%20"), &list); err != nil {
+		return "", err
+	}
+	if len(list) > 0 {
+		return list[0].ID, nil
+	}
+	return name, nil
+}
+
+func (s *OnePasswordSource) get(ctx context.Context, pathWithQuery string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.Server+pathWithQuery, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.opts.Token)
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("1password: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("1password: GET %s: unexpected status %s", pathWithQuery, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("1password: decode %s: %w", pathWithQuery, err)
+	}
+	return nil
+}
+
+// parseOnePasswordRef разбирает ссылку op://vault/item/[section/]field
+func parseOnePasswordRef(ref string) (vault, item, section, field string, err error) {
+	rest, ok := strings.CutPrefix(ref, "op://")
+	if !ok {
+		return "", "", "", "", fmt.Errorf("1password: invalid secret reference %q (want op://vault/item/[section/]field)", ref)
+	}
+	parts := strings.Split(rest, "/")
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], "", parts[2], nil
+	case 4:
+		return parts[0], parts[1], parts[2], parts[3], nil
+	}
+	return "", "", "", "", fmt.Errorf("1password: invalid secret reference %q (want op://vault/item/[section/]field)", ref)
+}
@@ -0,0 +1,143 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConnect - сервер 1Password Connect: хранилище Prod (id vault-1) с элементом db (id item-1)
+type fakeConnect struct {
+	mu       sync.Mutex
+	requests map[string]int // Число запросов по пути
+}
+
+func (c *fakeConnect) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.requests[r.URL.Path]++
+	c.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	// Фильтр по имени: пустой список означает, что имя - это id
+	filter := r.URL.Query().Get("filter")
+	switch r.URL.Path {
+	case "/v1/vaults":
+		if filter == `name eq "Prod"` {
+			json.NewEncoder(w).Encode([]map[string]string{{"id": "vault-1"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]string{})
+	case "/v1/vaults/vault-1/items":
+		if filter == `title eq "db"` {
+			json.NewEncoder(w).Encode([]map[string]string{{"id": "item-1"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]string{})
+	case "/v1/vaults/vault-1/items/item-1":
+		json.NewEncoder(w).Encode(map[string]any{"fields": []map[string]any{
+			{"id": "password", "label": "password", "value": "s3cr3t"},
+			{"id": "f1", "label": "user", "value": "app"},
+			{"id": "user", "label": "login", "value": "by id"},
+			{"id": "f2", "label": "password", "value": "replica", "section": map[string]string{"id": "s1", "label": "replica"}},
+		}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c *fakeConnect) count(path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests[path]
+}
+
+func TestOnePasswordLookup(t *testing.T) {
+	connect := &fakeConnect{requests: map[string]int{}}
+	srv := httptest.NewServer(connect)
+	defer srv.Close()
+	src, err := NewOnePasswordSource(OnePasswordOptions{Server: srv.URL + "/", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+		err  string
+	}{
+		{"op://Prod/db/password", "s3cr3t", ""},
+		{"op://vault-1/item-1/password", "s3cr3t", ""},
+		// Label важнее id, секция сужает поиск по label или id
+		{"op://Prod/db/user", "app", ""},
+		{"op://Prod/db/f1", "app", ""},
+		{"op://Prod/db/replica/password", "replica", ""},
+		{"op://Prod/db/s1/password", "replica", ""},
+		{"op://Prod/db/missing", "", `field "missing" not found`},
+		{"op://Prod/db/other/password", "", `field "password" not found`},
+		{"op://Dev/db/password", "", "GET /v1/vaults/Dev/items"},
+		{"op://Prod/db", "", "invalid secret reference"},
+		{"vault://Prod/db/password", "", "invalid secret reference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := src.Lookup(context.Background(), tt.ref)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Lookup = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	bad, _ := NewOnePasswordSource(OnePasswordOptions{Server: srv.URL, Token: "wrong"})
+	if _, err := bad.Lookup(context.Background(), "op://Prod/db/password"); err == nil || !strings.Contains(err.Error(), "unexpected status 401") {
+		t.Errorf("error = %v, want the status", err)
+	}
+	if _, err := NewOnePasswordSource(OnePasswordOptions{Server: srv.URL}); err == nil {
+		t.Error("a source without a token was created")
+	}
+}
+
+func TestOnePasswordCache(t *testing.T) {
+	connect := &fakeConnect{requests: map[string]int{}}
+	srv := httptest.NewServer(connect)
+	defer srv.Close()
+	var errs []string
+	src, _ := NewOnePasswordSource(OnePasswordOptions{Server: srv.URL, Token: "token", OnError: func(ref string, err error) {
+		errs = append(errs, ref)
+	}})
+
+	// Prefetch загружает элемент один раз для всех его полей, дальше ResolveSecret берет из кеша
+	refs := []string{"op://Prod/db/password", "op://Prod/db/user", "op://Prod/db/replica/password"}
+	if err := src.PrefetchSecrets(context.Background(), refs); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range refs {
+		if _, ok := src.ResolveSecret(ref); !ok {
+			t.Errorf("%s is not resolved", ref)
+		}
+	}
+	if n := connect.count("/v1/vaults/vault-1/items/item-1"); n != 1 {
+		t.Errorf("item fetched %d times, want once", n)
+	}
+	if n := connect.count("/v1/vaults"); n != 1 {
+		t.Errorf("vaults listed %d times, want once", n)
+	}
+
+	if _, ok := src.ResolveSecret("op://Prod/db/missing"); ok || len(errs) != 1 || errs[0] != "op://Prod/db/missing" {
+		t.Errorf("missing field: ok = %v, errors = %v", ok, errs)
+	}
+	if err := src.PrefetchSecrets(context.Background(), []string{"op://Prod/db/missing"}); err == nil {
+		t.Error("prefetch of a missing field succeeded")
+	}
+}
@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvOverrideChain returns a key mapper for generated ENV configs (EnvConfigWithMap,
+// registry NewEnvConfig) that resolves every key through a hierarchy of prefixes,
+// most specific first. With prefix "APP" and levels "eu1", "c7" the key DB_HOST is
+// looked up as APP_EU1_C7_DB_HOST, APP_EU1_DB_HOST and APP_DB_HOST; the first variable
+// that is set (non-empty) wins. An empty prefix makes the last link the bare key.
+// Empty levels are skipped, so a level can come straight from an unset variable:
+//
+//	mapKey := runtime.EnvOverrideChain("APP", os.Getenv("REGION"), os.Getenv("CLUSTER"), os.Getenv("INSTANCE"))
+func EnvOverrideChain(prefix string, levels ...string) func(key string) string {
+	base := envKeyPart(prefix)
+	prefixes := []string{base}
+	cur := base
+	for _, l := range levels {
+		if l = envKeyPart(l); l == "" {
+			continue
+		}
+		if cur == "" {
+			cur = l
+		} else {
+			cur += "_" + l
+		}
+		prefixes = append(prefixes, cur)
+	}
+	return func(key string) string {
+		withPrefix := func(p string) string {
+			if p == "" {
+				return key
+			}
+			return p + "_" + key
+		}
+		for i := len(prefixes) - 1; i > 0; i-- {
+			if name := withPrefix(prefixes[i]); os.Getenv(name) != "" {
+				return name
+			}
+		}
+		return withPrefix(prefixes[0])
+	}
+}
+
+// envKeyPart приводит уровень иерархии к виду части имени переменной: eu-west.1 -> EU_WEST_1
+func envKeyPart(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(strings.TrimSpace(s)))
+}
+
+// MergeYAML overlays configuration trees, most specific first: for every section.key
+// the first layer that has a value wins; nested mappings are merged the same way and
+// lists are replaced whole. Null values in a layer are ignored. The result follows its
+// layers: when a layer is replaced (a reloading source), the merge is recomputed.
+func MergeYAML(layers ...*YAML) *YAML {
+	out := &YAML{root: mergeLayers(layers)}
+	for _, l := range layers {
+		l.OnChange(func() { out.Replace(&YAML{root: mergeLayers(layers)}) })
+	}
+	return out
+}
+
+func mergeLayers(layers []*YAML) map[string]any {
+	root := map[string]any{}
+	// От наименее специфичного слоя к наиболее специфичному
+	for i := len(layers) - 1; i >= 0; i-- {
+		layers[i].mu.RLock()
+		layer := layers[i].root
+		layers[i].mu.RUnlock()
+		root = mergeValue(root, layer).(map[string]any)
+	}
+	return root
+}
+
+// mergeValue накладывает over на base. Карты слоев не изменяются: при слиянии создается копия
+func mergeValue(base, over any) any {
+	overMap, ok := over.(map[string]any)
+	if !ok {
+		return over
+	}
+	baseMap, ok := base.(map[string]any)
+	if !ok {
+		baseMap = nil
+	}
+	out := make(map[string]any, len(baseMap)+len(overMap))
+	for k, v := range baseMap {
+		out[k] = v
+	}
+	for k, v := range overMap {
+		if v == nil {
+			continue
+		}
+		out[k] = mergeValue(out[k], v)
+	}
+	return out
+}
+
+// LoadOverlays loads path and its overlay files for levels, most specific first, and
+// merges them with MergeYAML. With path "config.yaml" and levels "eu1", "c7" the files are
+// config.eu1.c7.yaml, config.eu1.yaml and config.yaml. Overlay files are optional,
+// the base file is required; empty levels are skipped.
+func LoadOverlays(path string, levels ...string) (*YAML, error) {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	paths := []string{path}
+	for _, l := range levels {
+		if l = strings.TrimSpace(l); l == "" {
+			continue
+		}
+		stem += "." + l
+		paths = append([]string{stem + ext}, paths...)
+	}
+
+	var layers []*YAML
+	for i, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && i < len(paths)-1 {
+				continue
+			}
+			return nil, err
+		}
+		y, err := ParseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		layers = append(layers, y)
+	}
+	return MergeYAML(layers...), nil
+}
+
+// YAML returns y itself, so a configuration tree (for example the result of MergeYAML)
+// can be passed wherever a document source is accepted, such as NewGlobalConfig.
+func (y *YAML) YAML() *YAML {
+	return y
+}
@@ -0,0 +1,171 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// prefetchServer отвечает handler и считает запросы
+func prefetchServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// checkPrefetch предзагружает refs, затем проверяет, что значения читаются из кеша без запросов
+func checkPrefetch(t *testing.T, r SecretResolver, requests *atomic.Int32, wantRequests int32, want map[string]string) {
+	t.Helper()
+	refs := make([]string, 0, len(want))
+	for ref := range want {
+		refs = append(refs, ref)
+	}
+	if err := PrefetchSecrets(context.Background(), r, refs...); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != wantRequests {
+		t.Errorf("Prefetch made %d requests, want %d", n, wantRequests)
+	}
+	for ref, v := range want {
+		if got, ok := r.ResolveSecret(ref); !ok || got != v {
+			t.Errorf("ResolveSecret(%q) = %q, %v; want %q", ref, got, ok, v)
+		}
+	}
+	if n := requests.Load(); n != wantRequests {
+		t.Errorf("%d requests after Prefetch, want none", n-wantRequests)
+	}
+	if err := PrefetchSecrets(context.Background(), r, "missing"); err == nil {
+		t.Error("Prefetch of a missing secret succeeded")
+	}
+}
+
+func TestSSMPrefetch(t *testing.T) {
+	params := map[string]string{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"} {
+		params["/svc/"+name] = "v-" + name
+	}
+	srv, requests := prefetchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AmazonSSM.GetParameters" || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var in struct {
+			Names          []string
+			WithDecryption bool
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || !in.WithDecryption || len(in.Names) > ssmBatchSize {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		out := map[string]any{"Parameters": []map[string]string{}, "InvalidParameters": []string{}}
+		for _, name := range in.Names {
+			if v, ok := params[name]; ok {
+				out["Parameters"] = append(out["Parameters"].([]map[string]string), map[string]string{"Name": name, "Value": v})
+			} else {
+				out["InvalidParameters"] = append(out["InvalidParameters"].([]string), name)
+			}
+		}
+		json.NewEncoder(w).Encode(out)
+	})
+	src, err := NewSSMSource(SSMOptions{Region: "eu-west-1", AccessKeyID: "id", SecretAccessKey: "secret", Endpoint: srv.URL, Path: "/svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"/svc/a": "v-a"}
+	for _, name := range []string{"b", "c", "d", "e", "f", "g", "h", "i", "j", "k"} {
+		want[name] = "v-" + name // относительно Path
+	}
+	// 11 параметров - два запроса GetParameters
+	checkPrefetch(t, src, requests, 2, want)
+}
+
+func TestConsulPrefetch(t *testing.T) {
+	kv := map[string]string{
+		"svc/prod/SERVER_DB_PASSWORD":  "db",
+		"svc/prod/SERVER_API_TOKEN":    "api",
+		"svc/prod/tls/key":             "tls",
+		"svc/staging/SERVER_API_TOKEN": "other",
+	}
+	srv, requests := prefetchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		var pairs []consulPair
+		for k, v := range kv {
+			if k == key || (r.URL.Query().Get("recurse") == "true" && strings.HasPrefix(k, key)) {
+				pairs = append(pairs, consulPair{Key: k, Value: []byte(v)})
+			}
+		}
+		if len(pairs) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(pairs)
+	})
+	src, err := NewConsulSource(ConsulOptions{Address: srv.URL, Token: "token", Prefix: "svc/prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Общий префикс svc/prod читается одним запросом
+	checkPrefetch(t, src, requests, 1, map[string]string{"SERVER_DB_PASSWORD": "db", "SERVER_API_TOKEN": "api", "tls/key": "tls"})
+	if v, err := src.Lookup(context.Background(), "SERVER_API_TOKEN"); err != nil || v != "api" {
+		t.Errorf("Lookup = %q, %v; want api", v, err)
+	}
+}
+
+func TestConsulCommonPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"a/b/c"}, "a/b/c"},
+		{[]string{"a/b/c", "a/b/d"}, "a/b"},
+		{[]string{"a", "a/b"}, "a"},
+		{[]string{"ab/c", "a/c"}, ""},
+	} {
+		if got := consulCommonPrefix(tt.keys); got != tt.want {
+			t.Errorf("consulCommonPrefix(%q) = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVaultPrefetch(t *testing.T) {
+	secrets := map[string]map[string]any{
+		"/v1/kv/data/svc/prod": {"SERVER_DB_PASSWORD": "db", "SERVER_API_TOKEN": "api", "port": 5432},
+		"/v1/kv/data/svc/tls":  {"key": "tls"},
+	}
+	srv, requests := prefetchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+	})
+	src, err := NewVaultSource(VaultOptions{Address: srv.URL, Token: "token", Mount: "kv", Path: "svc/prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Один запрос на каждый путь секрета, а не на каждое поле
+	checkPrefetch(t, src, requests, 2, map[string]string{
+		"SERVER_DB_PASSWORD": "db",
+		"SERVER_API_TOKEN":   "api",
+		"svc/prod#port":      "5432",
+		"svc/tls#key":        "tls",
+	})
+}
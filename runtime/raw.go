@@ -0,0 +1,134 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Raw is an untouched configuration subtree for a single key.
+// Methods declared as returning runtime.Raw (or yaml.Node) receive it instead of a decoded value,
+// so packages with exotic schemas can decode it themselves while still using sections,
+// aliases and the registry.
+type Raw struct {
+	// Node is the original YAML node of the value, including tags, styles and positions.
+	Node *yaml.Node
+}
+
+// IsZero reports whether the value is empty (no node).
+func (r Raw) IsZero() bool {
+	return r.Node == nil
+}
+
+// Decode decodes the subtree into out using yaml.v3 semantics (yaml struct tags).
+func (r Raw) Decode(out any) error {
+	if r.Node == nil {
+		return errors.New("runtime: decode of empty raw value")
+	}
+	return r.Node.Decode(out)
+}
+
+// ParseRaw parses a YAML (or JSON, which is valid YAML) document into a Raw value.
+// It is used by generated ENV sources for raw methods.
+func ParseRaw(data []byte) (Raw, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Raw{}, fmt.Errorf("yaml unmarshal: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return Raw{}, errors.New("runtime: empty raw value")
+	}
+	return Raw{Node: doc.Content[0]}, nil
+}
+
+// GetRaw retrieves the untouched subtree for a given section and keys.
+// When the YAML was parsed from a document the original node is returned,
+// otherwise the decoded value is re-encoded into a node.
+func (y *YAML) GetRaw(section string, keys ...string) (Raw, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return Raw{}, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		v, ok := sec[k]
+		if !ok {
+			continue
+		}
+		if n := y.node(section, k); n != nil {
+			return Raw{Node: n}, true
+		}
+		var n yaml.Node
+		if err := n.Encode(v); err != nil {
+			continue
+		}
+		return Raw{Node: &n}, true
+	}
+	return Raw{}, false
+}
+
+// node находит узел значения section.key в исходном документе (с разрешением алиасов)
+func (y *YAML) node(section, key string) *yaml.Node {
+	y.mu.RLock()
+	doc := y.doc
+	y.mu.RUnlock()
+	if doc == nil || len(doc.Content) == 0 {
+		return nil
+	}
+	sec := mappingValue(doc.Content[0], section)
+	if sec == nil {
+		return nil
+	}
+	return mappingValue(sec, key)
+}
+
+// mappingValue находит значение key в отображении m с теми же правилами, что и декодирование
+// yaml.v3: ключи, записанные в m, важнее ключей слияния (<<), а из последовательности слияния
+// (<<: [*a, *b]) берется первое отображение с ключом
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	m = resolveAlias(m)
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k := m.Content[i]
+		if isMergeKey(k) {
+			merges = append(merges, m.Content[i+1])
+			continue
+		}
+		if k.Value == key {
+			return resolveAlias(m.Content[i+1])
+		}
+	}
+	for _, merge := range merges {
+		merge = resolveAlias(merge)
+		if merge == nil {
+			continue
+		}
+		sources := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			sources = merge.Content
+		}
+		for _, src := range sources {
+			if v := mappingValue(src, key); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func isMergeKey(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!merge"
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// StartupReport describes the configuration state of a service: which source resolved
+// every key, which keys fall back to defaults and which malformed values were skipped.
+// It marshals to JSON and String returns it as one JSON line for the boot log:
+//
+//	log.Printf("config: %s", cfg.Report())
+//
+// Generated New...All configs and the registry GlobalConfig provide Report methods.
+type StartupReport struct {
+	Keys     []ReportKey `json:"keys"`
+	Sources  []string    `json:"sources"`            // Sources that resolved at least one key, in order of first use
+	Defaults []string    `json:"defaults,omitempty"` // Keys no source has: the caller's default applies
+	Warnings []string    `json:"warnings,omitempty"` // Malformed values skipped while resolving
+}
+
+// ReportKey is a single resolved key of a StartupReport.
+type ReportKey struct {
+	Key    string `json:"key"`              // "section.key"
+	Source string `json:"source,omitempty"` // Empty when the default applies
+	Value  any    `json:"value,omitempty"`  // Secrets are redacted
+}
+
+// RedactedValue replaces values of secret keys in reports.
+const RedactedValue = "<redacted>"
+
+// Add records how key was resolved: source is the name of the source that returned
+// the value ("" when none did and the default applies).
+func (r *StartupReport) Add(key, source string, value any, secret bool) {
+	if source == "" {
+		r.Keys = append(r.Keys, ReportKey{Key: key})
+		r.Defaults = append(r.Defaults, key)
+		return
+	}
+	if secret {
+		value = RedactedValue
+	} else {
+		value = plainValue(value)
+	}
+	r.Keys = append(r.Keys, ReportKey{Key: key, Source: source, Value: value})
+	for _, s := range r.Sources {
+		if s == source {
+			return
+		}
+	}
+	r.Sources = append(r.Sources, source)
+}
+
+// Observe runs fn (which resolves the reported keys) and records as warnings the malformed
+// values skipped meanwhile by code generated without --strict (see SetParseErrorObserver); the
+// current observer is still notified. Generated Report methods read strict keys through their
+// error-returning form and add those warnings themselves, so the parse error handler is not
+// involved. Observe calls are serialized.
+func (r *StartupReport) Observe(fn func()) {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+
+	var mu sync.Mutex
+	var warnings []string
+	var prevObserver func(*ParseError)
+	prevObserver = SetParseErrorObserver(func(err *ParseError) {
+		mu.Lock()
+		warnings = append(warnings, err.Error())
+		mu.Unlock()
+		if prevObserver != nil {
+			prevObserver(err)
+		}
+	})
+	defer func() { SetParseErrorObserver(prevObserver) }()
+
+	fn()
+	mu.Lock()
+	defer mu.Unlock()
+	r.Warnings = append(r.Warnings, warnings...)
+}
+
+// Merge appends the keys, sources, defaults and warnings of other (another package) to r.
+func (r *StartupReport) Merge(other StartupReport) {
+	r.Keys = append(r.Keys, other.Keys...)
+	for _, s := range other.Sources {
+		found := false
+		for _, have := range r.Sources {
+			if have == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Sources = append(r.Sources, s)
+		}
+	}
+	r.Defaults = append(r.Defaults, other.Defaults...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+}
+
+// String returns the report as a single line of JSON.
+func (r StartupReport) String() string {
+	if r.Keys == nil {
+		r.Keys = []ReportKey{}
+	}
+	if r.Sources == nil {
+		r.Sources = []string{}
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r); err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SourceName names a configuration source in reports. Sources can name themselves with
+// a SourceName() string method; generated sources are named by kind (env, dotenv, credentials, yaml, json, hcl, cue,
+// mount, http, git, sql, flag, secret, mock, override, all), anything else by its Go type.
+func SourceName(s any) string {
+	if n, ok := s.(interface{ SourceName() string }); ok {
+		return n.SourceName()
+	}
+	name := fmt.Sprintf("%T", s)
+	kinds := []struct{ suffix, kind string }{
+		{"DotEnvConfig", "dotenv"},
+		{"EnvConfig", "env"},
+		{"YAMLConfig", "yaml"},
+		{"JSONConfig", "json"},
+		{"HCLConfig", "hcl"},
+		{"CUEConfig", "cue"},
+		{"CredentialsConfig", "credentials"},
+		{"MountConfig", "mount"},
+		{"HTTPConfig", "http"},
+		{"SQLConfig", "sql"},
+		{"GitConfig", "git"},
+		{"FlagConfig", "flag"},
+		{"SecretConfig", "secret"},
+		{"MockConfig", "mock"},
+		{"OverrideConfig", "override"},
+		{"AllConfig", "all"},
+	}
+	for _, k := range kinds {
+		if strings.HasSuffix(name, k.suffix) {
+			return k.kind
+		}
+	}
+	return strings.TrimPrefix(name, "*")
+}
@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+)
+
+// SecretResolver resolves secret references of methods annotated with "ggconfig:secret".
+// The reference is the directive value (e.g. "op://Prod/db/password") or, without a value,
+// the ENV key of the method. The second result is false when the secret cannot be resolved,
+// so generated Secret sources fall through to the next source (ENV, YAML, default).
+type SecretResolver interface {
+	ResolveSecret(ref string) (string, bool)
+}
+
+// SecretPrefetcher is implemented by resolvers that can load many secrets in bulk
+// (OnePasswordSource fetches every item once for all of its fields, DopplerSource every config,
+// VaultSource every secret path, SSMSource ten parameters per request, ConsulSource the common
+// key prefix). Prefetched secrets are cached, so the first ResolveSecret calls need no round trips.
+type SecretPrefetcher interface {
+	PrefetchSecrets(ctx context.Context, refs []string) error
+}
+
+// PrefetchSecrets loads refs in bulk when r is a SecretPrefetcher and does nothing
+// otherwise. Generated Secret sources call it from Prefetch with the references of
+// all their methods, so a service can warm the cache at startup:
+//
+//	if err := gconfig.NewInternalDatabaseConfigSecretConfig(op).Prefetch(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+func PrefetchSecrets(ctx context.Context, r SecretResolver, refs ...string) error {
+	p, ok := r.(SecretPrefetcher)
+	if !ok || len(refs) == 0 {
+		return nil
+	}
+	return p.PrefetchSecrets(ctx, refs)
+}
+
+const (
+	secretPlaceholderPrefix = "${secret:"
+	secretPlaceholderSuffix = "}"
+)
+
+// SecretPlaceholder returns the config value that references a secret instead of holding it:
+// "${secret:op://Prod/db/password}". Example configs use it for ggconfig:secret methods,
+// and YAML resolves such values through the resolver set with SetSecretResolver.
+func SecretPlaceholder(ref string) string {
+	return secretPlaceholderPrefix + ref + secretPlaceholderSuffix
+}
+
+// ParseSecretPlaceholder returns the reference of a "${secret:<ref>}" value.
+func ParseSecretPlaceholder(s string) (string, bool) {
+	if !strings.HasPrefix(s, secretPlaceholderPrefix) || !strings.HasSuffix(s, secretPlaceholderSuffix) {
+		return "", false
+	}
+	return s[len(secretPlaceholderPrefix) : len(s)-len(secretPlaceholderSuffix)], true
+}
@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Snapshot is a resolved view of all keys of a configuration: "section.key" -> value.
+// Keys that were not resolved by any source are absent from the snapshot.
+// Generated Snapshot<Pkg><Interface> functions capture snapshots from any implementation.
+type Snapshot map[string]any
+
+// Set stores a resolved value. Raw values and YAML nodes are stored decoded,
+// so snapshots compare by content rather than by position in a document;
+// durations, times, URLs and IP addresses are stored as strings ("1m30s", RFC3339).
+func (s Snapshot) Set(key string, v any) {
+	s[key] = plainValue(v)
+}
+
+// plainValue декодирует Raw и YAML узлы в обычные значения (карты, списки, скаляры),
+// длительности, время, URL и IP адреса записывает строкой ("30s", RFC3339)
+func plainValue(v any) any {
+	switch t := v.(type) {
+	case Raw:
+		return decodeNode(t.Node)
+	case yaml.Node:
+		return decodeNode(&t)
+	case *yaml.Node:
+		return decodeNode(t)
+	case time.Duration:
+		return t.String()
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case *url.URL:
+		if t != nil {
+			return t.String()
+		}
+	case net.IP:
+		if t != nil {
+			return t.String()
+		}
+	case *net.IPNet:
+		if t != nil {
+			return t.String()
+		}
+	case encoding.TextMarshaler:
+		// Типы ggconfig:text (перечисления, slog.Level) - в текстовом виде
+		if text, err := t.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return v
+}
+
+func decodeNode(n *yaml.Node) any {
+	if n == nil {
+		return nil
+	}
+	var out any
+	if err := n.Decode(&out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// ChangeKind describes how a key differs between two snapshots.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a single difference between two snapshots.
+type Change struct {
+	Key  string
+	Kind ChangeKind
+	Old  any // value in the first snapshot (nil for ChangeAdded)
+	New  any // value in the second snapshot (nil for ChangeRemoved)
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %v", c.Key, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %v", c.Key, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Key, c.Old, c.New)
+	}
+}
+
+// Diff returns the changes needed to turn snapshot a into snapshot b, sorted by key.
+func Diff(a, b Snapshot) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, k := range keys {
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Key: k, Kind: ChangeAdded, New: bv})
+		case !inB:
+			changes = append(changes, Change{Key: k, Kind: ChangeRemoved, Old: av})
+		case !reflect.DeepEqual(av, bv):
+			changes = append(changes, Change{Key: k, Kind: ChangeModified, Old: av, New: bv})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotSet(t *testing.T) {
+	y, err := ParseYAML([]byte("svc:\n  limits: {cpu: 2, tags: [a, b]}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, ok := y.GetRaw("svc", "limits")
+	if !ok {
+		t.Fatal("limits is absent")
+	}
+	u, _ := url.Parse("https://example.com/api")
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/8")
+	s := Snapshot{}
+	s.Set("raw", raw)
+	s.Set("duration", 90*time.Second)
+	s.Set("time", time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC))
+	s.Set("url", u)
+	s.Set("nil url", (*url.URL)(nil))
+	s.Set("ip", net.ParseIP("127.0.0.1"))
+	s.Set("cidr", cidr)
+	s.Set("int", 8080)
+
+	want := map[string]string{
+		"duration": "1m30s",
+		"time":     "2024-01-01T09:00:00Z",
+		"url":      "https://example.com/api",
+		"ip":       "127.0.0.1",
+		"cidr":     "10.0.0.0/8",
+	}
+	for k, v := range want {
+		if s[k] != v {
+			t.Errorf("%s = %#v, want %q", k, s[k], v)
+		}
+	}
+	if s["int"] != 8080 || s["nil url"] != (*url.URL)(nil) {
+		t.Errorf("int = %#v, nil url = %#v; want them unchanged", s["int"], s["nil url"])
+	}
+	// Raw хранится декодированным: снимки сравниваются по содержимому, а не по строкам документа
+	other, _ := ParseYAML([]byte("# comment\nsvc:\n  limits:\n    cpu: 2\n    tags: [a, b]\n"))
+	raw2, _ := other.GetRaw("svc", "limits")
+	s2 := Snapshot{}
+	s2.Set("raw", raw2)
+	if changes := Diff(Snapshot{"raw": s["raw"]}, s2); len(changes) != 0 {
+		t.Errorf("equal raw values differ: %v", changes)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Snapshot
+		want []string
+	}{
+		{"equal", Snapshot{"a.x": 1, "a.y": []any{"p"}}, Snapshot{"a.x": 1, "a.y": []any{"p"}}, nil},
+		{"empty", nil, Snapshot{}, nil},
+		{"added", Snapshot{}, Snapshot{"a.x": 1}, []string{"+ a.x: 1"}},
+		{"removed", Snapshot{"a.x": "v"}, nil, []string{"- a.x: v"}},
+		{"modified", Snapshot{"a.x": 1}, Snapshot{"a.x": 2}, []string{"~ a.x: 1 -> 2"}},
+		{"modified type", Snapshot{"a.x": 1}, Snapshot{"a.x": int64(1)}, []string{"~ a.x: 1 -> 1"}},
+		{"deep", Snapshot{"a.m": map[string]any{"k": []any{1}}}, Snapshot{"a.m": map[string]any{"k": []any{2}}}, []string{"~ a.m: map[k:[1]] -> map[k:[2]]"}},
+		// Изменения отсортированы по ключу, независимо от вида
+		{"sorted", Snapshot{"b": 1, "c": 1, "d": 1}, Snapshot{"a": 1, "c": 2, "d": 1}, []string{"+ a: 1", "- b: 1", "~ c: 1 -> 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(tt.a, tt.b)
+			got := make([]string, len(changes))
+			for i, c := range changes {
+				got[i] = c.String()
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	c := Diff(Snapshot{"k": "old"}, Snapshot{"k": "new"})[0]
+	if c.Key != "k" || c.Kind != ChangeModified || c.Old != "old" || c.New != "new" {
+		t.Errorf("change = %+v", c)
+	}
+	if c := Diff(nil, Snapshot{"k": 1})[0]; c.Kind != ChangeAdded || c.Old != nil || c.New != 1 {
+		t.Errorf("added change = %+v", c)
+	}
+}
@@ -0,0 +1,65 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"time"
+)
+
+// watchLoop - общий цикл long polling для удаленных источников: poll ждет изменения,
+// reload загружает новую версию. Ошибки повторяются с экспоненциальной задержкой.
+func watchLoop(ctx context.Context, poll func(context.Context) (bool, error), reload func(context.Context) error, onError func(error)) error {
+	backoff := time.Second
+	for {
+		changed, err := poll(ctx)
+		if err == nil && changed {
+			err = reload(ctx)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+		if onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// ParseProperties parses a Java .properties document ("key=value" or "key: value" lines,
+// "#" and "!" comments, trailing "\" line continuations) into a flat map.
+func ParseProperties(data string) map[string]string {
+	out := map[string]string{}
+	sc := bufio.NewScanner(strings.NewReader(data))
+	var pending string
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if pending == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		if strings.HasSuffix(line, `\`) {
+			pending += strings.TrimSuffix(line, `\`)
+			continue
+		}
+		line = pending + line
+		pending = ""
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			out[line] = ""
+			continue
+		}
+		out[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpringCloudOptions configures a source backed by a Spring Cloud Config Server.
+type SpringCloudOptions struct {
+	// Server is the config server address, e.g. "http://config-server:8888".
+	Server string
+	// Application is the application name ({application} in the server API).
+	Application string
+	// Profile is the comma-separated list of profiles (default "default").
+	Profile string
+	// Label is the git label/branch (optional, server default when empty).
+	Label string
+	// Username and Password enable HTTP basic authentication (optional).
+	Username string
+	Password string
+	// Client is the HTTP client (default: a client with a 30s timeout).
+	Client *http.Client
+}
+
+// SpringCloudSource fetches /{application}/{profile}/{label} from a Spring Cloud Config Server
+// and flattens its property sources into sections: "server.read-timeout" becomes section
+// "server", key "readtimeout" (relaxed binding onto lowercased method names). Lists, which the
+// server serves as indexed properties (server.tags[0], server.tags[1]), become sequences.
+// Property sources earlier in the response take precedence, as in Spring; a list is taken
+// whole from the first property source that defines it.
+type SpringCloudSource struct {
+	opts SpringCloudOptions
+	y    *YAML
+
+	mu      sync.Mutex
+	version string
+}
+
+// NewSpringCloudSource fetches the environment once and returns the source.
+func NewSpringCloudSource(ctx context.Context, opts SpringCloudOptions) (*SpringCloudSource, error) {
+	if opts.Server == "" || opts.Application == "" {
+		return nil, errors.New("spring cloud config: Server and Application are required")
+	}
+	if opts.Profile == "" {
+		opts.Profile = "default"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+
+	s := &SpringCloudSource{opts: opts, y: &YAML{}}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// YAML returns the configuration tree served by this source.
+func (s *SpringCloudSource) YAML() *YAML {
+	return s.y
+}
+
+// Reload fetches the environment and replaces the configuration tree
+// when the server reports a new version.
+func (s *SpringCloudSource) Reload(ctx context.Context) error {
+	path := "/" + url.PathEscape(s.opts.Application) + "/" + url.PathEscape(s.opts.Profile)
+	if s.opts.Label != "" {
+		path += "/" + url.PathEscape(s.opts.Label)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.Server+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("spring cloud config: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("spring cloud config: GET %s: unexpected status %s", path, resp.Status)
+	}
+
+	var env struct {
+		Version         string `json:"version"`
+		PropertySources []struct {
+			Name   string         `json:"name"`
+			Source map[string]any `json:"source"`
+		} `json:"propertySources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("spring cloud config: decode environment: %w", err)
+	}
+
+	s.mu.Lock()
+	unchanged := env.Version != "" && env.Version == s.version
+	s.version = env.Version
+	s.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	// Первый источник имеет наивысший приоритет - применяем с конца
+	props := map[string]string{}
+	lists := map[string]map[int]string{}
+	for i := len(env.PropertySources) - 1; i >= 0; i-- {
+		sourceLists := map[string]map[int]string{}
+		for k, v := range env.PropertySources[i].Source {
+			if name, index, ok := indexedPropertyKey(k); ok {
+				key := relaxedPropertyKey(name)
+				if sourceLists[key] == nil {
+					sourceLists[key] = map[int]string{}
+				}
+				sourceLists[key][index] = propertyString(v)
+				continue
+			}
+			key := relaxedPropertyKey(k)
+			props[key] = propertyString(v)
+			delete(lists, key)
+		}
+		// Список источника заменяет список и значение источников с меньшим приоритетом целиком
+		for key, items := range sourceLists {
+			lists[key] = items
+			delete(props, key)
+		}
+	}
+	y := FromProperties(props)
+	for k, items := range lists {
+		section, key, ok := strings.Cut(k, ".")
+		if !ok || section == "" || key == "" {
+			continue
+		}
+		sec, _ := y.root[section].(map[string]any)
+		if sec == nil {
+			sec = map[string]any{}
+			y.root[section] = sec
+		}
+		sec[key] = propertyList(items)
+	}
+	s.y.Replace(y)
+	return nil
+}
+
+// Watch polls the server every interval (default one minute) and reloads on new versions
+// until ctx is cancelled. onError (optional) receives transient errors for logging.
+func (s *SpringCloudSource) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	wait := func(ctx context.Context) (bool, error) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+			return true, nil
+		}
+	}
+	return watchLoop(ctx, wait, s.Reload, onError)
+}
+
+// relaxedPropertyKey нормализует ключ внутри секции как relaxed binding в Spring:
+// server.read-timeout, server.read_timeout и server.readTimeout -> server.readtimeout
+func relaxedPropertyKey(k string) string {
+	section, key, ok := strings.Cut(k, ".")
+	if !ok {
+		return k
+	}
+	key = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+	return section + "." + key
+}
+
+// indexedPropertyKey разбирает элемент списка server.tags[1] на имя списка и индекс; элементы
+// со вложенными ключами (server.listeners[0].port) остаются обычными свойствами
+func indexedPropertyKey(k string) (string, int, bool) {
+	if !strings.HasSuffix(k, "]") {
+		return "", 0, false
+	}
+	i := strings.LastIndex(k, "[")
+	if i <= 0 {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(k[i+1 : len(k)-1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return k[:i], index, true
+}
+
+// propertyList собирает элементы списка по возрастанию индексов
+func propertyList(items map[int]string) []any {
+	indexes := make([]int, 0, len(items))
+	for i := range items {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	list := make([]any, 0, len(indexes))
+	for _, i := range indexes {
+		list = append(list, items[i])
+	}
+	return list
+}
+
+func propertyString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
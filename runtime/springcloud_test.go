@@ -0,0 +1,157 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConfigServer - Spring Cloud Config Server: окружение с версией и basic auth
+type fakeConfigServer struct {
+	mu      sync.Mutex
+	path    string // Ожидаемый путь /{application}/{profile}[/{label}]
+	version string
+	sources []map[string]any // propertySources в порядке приоритета
+}
+
+func (c *fakeConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.URL.EscapedPath() != c.path || r.Header.Get("Accept") != "application/json" {
+		http.NotFound(w, r)
+		return
+	}
+	sources := make([]map[string]any, len(c.sources))
+	for i, src := range c.sources {
+		sources[i] = map[string]any{"name": fmt.Sprint("source-", i), "source": src}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"name": "svc", "version": c.version, "propertySources": sources})
+}
+
+func (c *fakeConfigServer) set(version string, sources ...map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version, c.sources = version, sources
+}
+
+func TestSpringCloudSource(t *testing.T) {
+	server := &fakeConfigServer{path: "/svc/prod%2Ceu/release%2F1"}
+	server.set("v1",
+		map[string]any{"server.read-timeout": "5s", "server.tags[0]": "a", "server.tags[1]": "b", "server.port": 8080.0},
+		map[string]any{"server.port": 1.0, "server.readTimeout": "1s", "server.tags[0]": "x", "server.tags[1]": "y", "server.tags[2]": "z", "server.host": "db", "server.debug": true},
+	)
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	ctx := context.Background()
+	src, err := NewSpringCloudSource(ctx, SpringCloudOptions{Server: srv.URL + "/", Application: "svc", Profile: "prod,eu", Label: "release/1", Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := src.YAML()
+	// Первый источник приоритетнее; ключи сводятся к relaxed форме, список берется целиком из одного источника
+	if v, ok := y.GetInt("server", "port"); !ok || v != 8080 {
+		t.Errorf("server.port = %d, %v; want 8080", v, ok)
+	}
+	if v, ok := y.GetString("server", "readtimeout"); !ok || v != "5s" {
+		t.Errorf("server.readtimeout = %q, %v; want 5s", v, ok)
+	}
+	if v, ok := y.GetString("server", "host"); !ok || v != "db" {
+		t.Errorf("server.host = %q, %v; want db", v, ok)
+	}
+	if v, ok := y.GetBool("server", "debug"); !ok || !v {
+		t.Errorf("server.debug = %v, %v; want true", v, ok)
+	}
+	if v, ok := y.GetSlice("server", "tags"); !ok || fmt.Sprint(v) != "[a b]" {
+		t.Errorf("server.tags = %v, %v; want [a b]", v, ok)
+	}
+
+	// Та же версия - дерево не заменяется
+	server.set("v1", map[string]any{"server.port": 9090.0})
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := y.GetInt("server", "port"); v != 8080 {
+		t.Errorf("server.port with an unchanged version = %d, want 8080", v)
+	}
+	server.set("v2", map[string]any{"server.port": 9090.0})
+	if err := src.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := y.GetInt("server", "port"); v != 9090 {
+		t.Errorf("server.port after a new version = %d, want 9090", v)
+	}
+	if _, ok := y.GetString("server", "host"); ok {
+		t.Error("server.host removed by the new version is still set")
+	}
+}
+
+func TestSpringCloudSourceErrors(t *testing.T) {
+	server := &fakeConfigServer{path: "/svc/default"}
+	server.set("v1")
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	defer broken.Close()
+
+	tests := []struct {
+		name string
+		opts SpringCloudOptions
+		err  string
+	}{
+		{"required", SpringCloudOptions{Server: srv.URL}, "Server and Application are required"},
+		{"auth", SpringCloudOptions{Server: srv.URL, Application: "svc"}, "GET /svc/default: unexpected status 401"},
+		{"unknown application", SpringCloudOptions{Server: srv.URL, Application: "other", Username: "user", Password: "pass"}, "GET /other/default: unexpected status 404"},
+		{"not json", SpringCloudOptions{Server: broken.URL, Application: "svc"}, "decode environment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSpringCloudSource(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRelaxedPropertyKey(t *testing.T) {
+	for _, tt := range []struct{ key, want string }{
+		{"server.read-timeout", "server.readtimeout"},
+		{"server.read_timeout", "server.readtimeout"},
+		{"server.readTimeout", "server.readtimeout"},
+		{"server.tls.cert-file", "server.tls.certfile"},
+		{"port", "port"},
+	} {
+		if got := relaxedPropertyKey(tt.key); got != tt.want {
+			t.Errorf("relaxedPropertyKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		key   string
+		name  string
+		index int
+		ok    bool
+	}{
+		{"server.tags[1]", "server.tags", 1, true},
+		{"server.tags[10]", "server.tags", 10, true},
+		{"server.listeners[0].port", "", 0, false},
+		{"server.tags[-1]", "", 0, false},
+		{"[0]", "", 0, false},
+	} {
+		name, index, ok := indexedPropertyKey(tt.key)
+		if name != tt.name || index != tt.index || ok != tt.ok {
+			t.Errorf("indexedPropertyKey(%q) = %q, %d, %v; want %q, %d, %v", tt.key, name, index, ok, tt.name, tt.index, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSQLQuery reads one value of a config table; placeholders are the section and the key.
+// The key column is called name: key is a reserved word in MySQL and several other databases.
+const DefaultSQLQuery = "SELECT value FROM config WHERE section = ? AND name = ?"
+
+// SQLPlaceholder is the bind parameter style of a database driver.
+type SQLPlaceholder int
+
+const (
+	// SQLQuestion is ? (MySQL, SQLite).
+	SQLQuestion SQLPlaceholder = iota
+	// SQLDollar is $1, $2, ... (PostgreSQL).
+	SQLDollar
+	// SQLColon is :1, :2, ... (Oracle).
+	SQLColon
+	// SQLAtP is @p1, @p2, ... (SQL Server).
+	SQLAtP
+)
+
+// Rebind rewrites the ? placeholders of query in the style p; question marks inside quoted
+// strings and identifiers are kept.
+func (p SQLPlaceholder) Rebind(query string) string {
+	if p == SQLQuestion {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			// Внутри строки или идентификатора в кавычках; удвоенная кавычка закрывает и снова открывает
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			switch p {
+			case SQLDollar:
+				fmt.Fprintf(&b, "$%d", n)
+			case SQLColon:
+				fmt.Fprintf(&b, ":%d", n)
+			case SQLAtP:
+				fmt.Fprintf(&b, "@p%d", n)
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// SQLOptions configures a source that reads key/value rows from a config table.
+type SQLOptions struct {
+	// DB is the database, opened with any database/sql driver.
+	DB *sql.DB
+	// Query selects one value column for the section and the key, the last two arguments
+	// (default DefaultSQLQuery). It is written with ? placeholders, which are rewritten in the
+	// Placeholder style.
+	Query string
+	// Placeholder is the bind parameter style of the driver (default SQLQuestion); PostgreSQL
+	// needs SQLDollar.
+	Placeholder SQLPlaceholder
+	// Args come before the section and the key, e.g. a tenant ID for
+	// "SELECT value FROM config WHERE tenant = ? AND section = ? AND name = ?".
+	Args []any
+	// CacheTTL keeps looked up values, absent keys included, for this duration; 0 queries the
+	// database on every lookup.
+	CacheTTL time.Duration
+	// Timeout limits one query (default 5s).
+	Timeout time.Duration
+	// OnError (optional) receives query errors, which are otherwise reported as absent values.
+	OnError func(section, key string, err error)
+}
+
+// SQLSource reads configuration values from a database table, for apps that keep tenant or
+// runtime config next to their data. The generated New<Package><Interface>SQLConfig reads the
+// methods through it:
+//
+//	src, err := runtime.NewSQLSource(ctx, runtime.SQLOptions{DB: db, Placeholder: runtime.SQLDollar, CacheTTL: time.Minute})
+//	cfg := gconfig.NewInternalServerConfigSQLConfig(ctx, src)
+//
+// A row holds the value of one key in the ENV format: scalars as is, lists comma separated,
+// slices and structs JSON; a missing row or a NULL value is an absent key. The query is
+// prepared once. An SQLSource is safe for concurrent use and may be shared by the configs of
+// several packages.
+type SQLSource struct {
+	opts SQLOptions
+	stmt *sql.Stmt
+
+	mu    sync.Mutex
+	cache map[[2]string]sqlEntry
+}
+
+type sqlEntry struct {
+	value   string
+	ok      bool
+	expires time.Time
+}
+
+// NewSQLSource prepares the query and returns the source. ctx applies to the preparation only;
+// lookups take their own context (LookupContext).
+func NewSQLSource(ctx context.Context, opts SQLOptions) (*SQLSource, error) {
+	if opts.DB == nil {
+		return nil, errors.New("sql source: DB is required")
+	}
+	if opts.Query == "" {
+		opts.Query = DefaultSQLQuery
+	}
+	opts.Query = opts.Placeholder.Rebind(opts.Query)
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	stmt, err := opts.DB.PrepareContext(ctx, opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("sql source: prepare %q: %w", opts.Query, err)
+	}
+	return &SQLSource{opts: opts, stmt: stmt, cache: map[[2]string]sqlEntry{}}, nil
+}
+
+// Lookup returns the value of section.key and whether the row exists; see LookupContext.
+func (s *SQLSource) Lookup(section, key string) (string, bool) {
+	return s.LookupContext(context.Background(), section, key)
+}
+
+// LookupContext is Lookup that runs the query under ctx, limited by Timeout: a canceled ctx,
+// e.g. on shutdown, aborts the query and the key is reported absent.
+func (s *SQLSource) LookupContext(ctx context.Context, section, key string) (string, bool) {
+	k := [2]string{section, key}
+	if s.opts.CacheTTL > 0 {
+		s.mu.Lock()
+		e, ok := s.cache[k]
+		s.mu.Unlock()
+		if ok && time.Now().Before(e.expires) {
+			return e.value, e.ok
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	var value sql.NullString
+	err := s.stmt.QueryRowContext(ctx, append(append([]any{}, s.opts.Args...), section, key)...).Scan(&value)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		// Ошибка запроса не кэшируется: следующее чтение спросит базу снова
+		if s.opts.OnError != nil {
+			s.opts.OnError(section, key, fmt.Errorf("sql source: %s.%s: %w", section, key, err))
+		}
+		return "", false
+	}
+
+	if s.opts.CacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[k] = sqlEntry{value: value.String, ok: value.Valid, expires: time.Now().Add(s.opts.CacheTTL)}
+		s.mu.Unlock()
+	}
+	return value.String, value.Valid
+}
+
+// Invalidate drops the cached values, e.g. after the application changed the table.
+func (s *SQLSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[[2]string]sqlEntry{}
+}
+
+// Close releases the prepared statement; DB stays open.
+func (s *SQLSource) Close() error {
+	return s.stmt.Close()
+}
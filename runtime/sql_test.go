@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+)
+
+func TestSQLPlaceholderRebind(t *testing.T) {
+	query := "SELECT value FROM config WHERE note <> 'why?' AND \"a?\" = ? AND section = ? AND name = ?"
+	for p, want := range map[SQLPlaceholder]string{
+		SQLQuestion: query,
+		SQLDollar:   "SELECT value FROM config WHERE note <> 'why?' AND \"a?\" = $1 AND section = $2 AND name = $3",
+		SQLColon:    "SELECT value FROM config WHERE note <> 'why?' AND \"a?\" = :1 AND section = :2 AND name = :3",
+		SQLAtP:      "SELECT value FROM config WHERE note <> 'why?' AND \"a?\" = @p1 AND section = @p2 AND name = @p3",
+	} {
+		if got := p.Rebind(query); got != want {
+			t.Errorf("Rebind(%d) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+// sqlTestDriver отвечает на любой запрос значением "9090" и запоминает подготовленный запрос
+type sqlTestDriver struct{ query string }
+
+func (d *sqlTestDriver) Open(string) (driver.Conn, error) { return sqlTestConn{d}, nil }
+
+type sqlTestConn struct{ d *sqlTestDriver }
+
+func (c sqlTestConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.query = query
+	return sqlTestStmt{}, nil
+}
+func (sqlTestConn) Close() error              { return nil }
+func (sqlTestConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type sqlTestStmt struct{}
+
+func (sqlTestStmt) Close() error                               { return nil }
+func (sqlTestStmt) NumInput() int                              { return -1 }
+func (sqlTestStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (sqlTestStmt) Query([]driver.Value) (driver.Rows, error)  { return &sqlTestRows{}, nil }
+
+type sqlTestRows struct{ done bool }
+
+func (*sqlTestRows) Columns() []string { return []string{"value"} }
+func (*sqlTestRows) Close() error      { return nil }
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = "9090"
+	return nil
+}
+
+func TestSQLSourceContext(t *testing.T) {
+	d := &sqlTestDriver{}
+	sql.Register("ggconfig-sql-test", d)
+	db, err := sql.Open("ggconfig-sql-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var queryErr error
+	src, err := NewSQLSource(context.Background(), SQLOptions{DB: db, Placeholder: SQLDollar, OnError: func(_, _ string, err error) { queryErr = err }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if want := "SELECT value FROM config WHERE section = $1 AND name = $2"; d.query != want {
+		t.Errorf("prepared %q, want %q", d.query, want)
+	}
+	if v, ok := src.LookupContext(context.Background(), "server", "port"); !ok || v != "9090" {
+		t.Errorf("LookupContext = %q, %v; want 9090", v, ok)
+	}
+
+	// Отмененный контекст вызывающего прерывает запрос: ключ отсутствует, ошибка уходит в OnError
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v, ok := src.LookupContext(ctx, "server", "port"); ok {
+		t.Errorf("LookupContext with a canceled context = %q, want absent", v)
+	}
+	if queryErr == nil {
+		t.Error("canceled query was not reported to OnError")
+	}
+}
@@ -0,0 +1,215 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ssmBatchSize - наибольшее число имен в одном запросе GetParameters
+const ssmBatchSize = 10
+
+// SSMOptions configures a secret resolver backed by AWS Systems Manager Parameter Store.
+type SSMOptions struct {
+	// Region is the AWS region (default: AWS_REGION / AWS_DEFAULT_REGION).
+	Region string
+	// Path is prepended to references that do not start with "/", e.g. "/my-service/prod/"
+	// for SERVER_DB_PASSWORD (optional).
+	Path string
+	// AccessKeyID, SecretAccessKey and SessionToken are the AWS credentials used to sign
+	// requests (default: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN).
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides the SSM endpoint (default "https://ssm.<region>.amazonaws.com").
+	Endpoint string
+	// CacheTTL is how long resolved parameters are cached (default 5 minutes, negative disables caching).
+	CacheTTL time.Duration
+	// OnError (optional) receives resolution errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+	// Client is the HTTP client (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// SSMSource resolves ggconfig:secret methods from SSM Parameter Store. A reference is the
+// parameter name ("/my-service/prod/db-password") or a name relative to SSMOptions.Path;
+// SecureString parameters are decrypted. PrefetchSecrets reads up to ten parameters per
+// GetParameters request instead of one request per key.
+type SSMSource struct {
+	opts SSMOptions
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewSSMSource returns a resolver for the given region and credentials. Parameters are fetched lazily.
+func NewSSMSource(opts SSMOptions) (*SSMSource, error) {
+	if opts.Region == "" {
+		opts.Region = os.Getenv("AWS_REGION")
+	}
+	if opts.Region == "" {
+		opts.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if opts.AccessKeyID == "" {
+		opts.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		opts.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		opts.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if opts.Region == "" || opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("ssm: region and credentials are required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://ssm." + opts.Region + ".amazonaws.com"
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	opts.Endpoint = strings.TrimRight(opts.Endpoint, "/")
+	return &SSMSource{opts: opts, cache: map[string]cachedSecret{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *SSMSource) ResolveSecret(ref string) (string, bool) {
+	s.mu.Lock()
+	c, ok := s.cache[ref]
+	s.mu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.value, true
+	}
+
+	v, err := s.Lookup(context.Background(), ref)
+	if err != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(ref, err)
+		}
+		return "", false
+	}
+	s.store(map[string]string{ref: v})
+	return v, true
+}
+
+// Lookup fetches a parameter by reference, bypassing the cache.
+func (s *SSMSource) Lookup(ctx context.Context, ref string) (string, error) {
+	values, err := s.getParameters(ctx, []string{ref})
+	if err != nil {
+		return "", err
+	}
+	return values[ref], nil
+}
+
+// PrefetchSecrets implements SecretPrefetcher: refs are read with one GetParameters request
+// per ten parameters and cached for CacheTTL; a missing parameter is an error. Without
+// caching it does nothing.
+func (s *SSMSource) PrefetchSecrets(ctx context.Context, refs []string) error {
+	if s.opts.CacheTTL <= 0 {
+		return nil
+	}
+	for len(refs) > 0 {
+		n := min(len(refs), ssmBatchSize)
+		values, err := s.getParameters(ctx, refs[:n])
+		if err != nil {
+			return err
+		}
+		s.store(values)
+		refs = refs[n:]
+	}
+	return nil
+}
+
+// Invalidate drops the cached parameters, e.g. after a rotation.
+func (s *SSMSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[string]cachedSecret{}
+}
+
+func (s *SSMSource) store(values map[string]string) {
+	if s.opts.CacheTTL <= 0 {
+		return
+	}
+	expires := time.Now().Add(s.opts.CacheTTL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ref, v := range values {
+		s.cache[ref] = cachedSecret{value: v, expires: expires}
+	}
+}
+
+// name возвращает имя параметра ссылки: относительные имена читаются под Path
+func (s *SSMSource) name(ref string) string {
+	if strings.HasPrefix(ref, "/") || s.opts.Path == "" {
+		return ref
+	}
+	return strings.TrimRight(s.opts.Path, "/") + "/" + ref
+}
+
+// getParameters читает параметры refs (не больше ssmBatchSize) одним запросом
+// GetParameters; значения возвращаются по ссылкам, параметр, которого нет, - ошибка
+func (s *SSMSource) getParameters(ctx context.Context, refs []string) (map[string]string, error) {
+	byName := make(map[string][]string, len(refs))
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref == "" {
+			return nil, errors.New("ssm: empty secret reference")
+		}
+		name := s.name(ref)
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], ref)
+	}
+	body, err := json.Marshal(map[string]any{"Names": names, "WithDecryption": true})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM.GetParameters")
+	signAWSV4(req, body, s.opts.Region, "ssm", s.opts.AccessKeyID, s.opts.SecretAccessKey, s.opts.SessionToken, time.Now())
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ssm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ssm: GetParameters: %s", appConfigError(resp))
+	}
+	var out struct {
+		Parameters []struct {
+			Name  string `json:"Name"`
+			Value string `json:"Value"`
+		} `json:"Parameters"`
+		InvalidParameters []string `json:"InvalidParameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("ssm: decode GetParameters: %w", err)
+	}
+	if len(out.InvalidParameters) > 0 {
+		return nil, fmt.Errorf("ssm: %s: parameter not found", strings.Join(out.InvalidParameters, ", "))
+	}
+	values := make(map[string]string, len(refs))
+	for _, p := range out.Parameters {
+		for _, ref := range byName[p.Name] {
+			values[ref] = p.Value
+		}
+	}
+	for _, ref := range refs {
+		if _, ok := values[ref]; !ok {
+			return nil, fmt.Errorf("ssm: %s: parameter %q not found", ref, s.name(ref))
+		}
+	}
+	return values, nil
+}
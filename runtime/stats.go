@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// ResolutionStats counts, per key, which source position of a generated composite config
+// (New...All) satisfied each lookup. A key that starts resolving from a lower-priority source
+// after a deploy (YAML instead of ENV, or the default) shows up in the counters and in the
+// OnShift callback. Attach it with the generated WithStats method:
+//
+//	stats := runtime.NewResolutionStats()
+//	cfg := gconfig.NewInternalServerConfigAll(env, yaml).WithStats(stats)
+//	expvar.Publish("config_resolution", stats)
+//
+// It is safe for concurrent use; String returns the counters as JSON (an expvar.Var).
+type ResolutionStats struct {
+	mu      sync.Mutex
+	keys    map[string]*keyCounters
+	onShift func(key string, from, to Resolution)
+}
+
+// Resolution is the source that satisfied a lookup: its position in the composite (0 is the
+// highest priority) and name (see SourceName). Position -1 means no source had the key and
+// the default applied.
+type Resolution struct {
+	Position int    `json:"position"`
+	Source   string `json:"source,omitempty"`
+}
+
+// KeyStats are the counters of one key.
+type KeyStats struct {
+	Key     string        `json:"key"`
+	Sources []SourceCount `json:"sources"` // By position, defaults (position -1) last
+	Last    Resolution    `json:"last"`    // Source of the latest lookup
+}
+
+// SourceCount is the number of lookups of a key satisfied by one source.
+type SourceCount struct {
+	Position int    `json:"position"`
+	Source   string `json:"source,omitempty"`
+	Count    uint64 `json:"count"`
+}
+
+type keyCounters struct {
+	counts map[Resolution]uint64
+	last   Resolution
+}
+
+// NewResolutionStats returns empty counters.
+func NewResolutionStats() *ResolutionStats {
+	return &ResolutionStats{keys: map[string]*keyCounters{}}
+}
+
+// OnShift registers fn to be called when a key resolves from a different source than on
+// its previous lookup. fn runs synchronously on the lookup path and must be fast.
+func (s *ResolutionStats) OnShift(fn func(key string, from, to Resolution)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShift = fn
+}
+
+// Record counts a lookup of key satisfied by source at position (-1 and "" for the default).
+// It is called by generated composite configs.
+func (s *ResolutionStats) Record(key string, position int, source string) {
+	r := Resolution{Position: position, Source: source}
+	s.mu.Lock()
+	k, ok := s.keys[key]
+	if !ok {
+		k = &keyCounters{counts: map[Resolution]uint64{}}
+		s.keys[key] = k
+	}
+	k.counts[r]++
+	from, shifted := k.last, ok && k.last != r
+	k.last = r
+	onShift := s.onShift
+	s.mu.Unlock()
+
+	if shifted && onShift != nil {
+		onShift(key, from, r)
+	}
+}
+
+// Keys returns the counters of all recorded keys in key order.
+func (s *ResolutionStats) Keys() []KeyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]KeyStats, 0, len(s.keys))
+	for key, k := range s.keys {
+		ks := KeyStats{Key: key, Sources: make([]SourceCount, 0, len(k.counts)), Last: k.last}
+		for r, n := range k.counts {
+			ks.Sources = append(ks.Sources, SourceCount{Position: r.Position, Source: r.Source, Count: n})
+		}
+		sort.Slice(ks.Sources, func(i, j int) bool {
+			a, b := ks.Sources[i].Position, ks.Sources[j].Position
+			if a != b {
+				// Значения по умолчанию (-1) - в конце
+				return a >= 0 && (b < 0 || a < b)
+			}
+			return ks.Sources[i].Source < ks.Sources[j].Source
+		})
+		out = append(out, ks)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// String returns the counters as JSON.
+func (s *ResolutionStats) String() string {
+	b, err := json.Marshal(s.Keys())
+	if err != nil {
+		return "[]"
+	}
+	return string(b)
+}
@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParseError reports a configuration value that is present but cannot be parsed
+// into the method type (a non-numeric DB_PORT, a list where a string is expected).
+// Code generated with --strict reports such values instead of silently falling back to defaults.
+type ParseError struct {
+	Source string // "env" or "yaml"
+	Key    string // ENV variable or "section.key"
+	Value  string
+	Type   string // method type
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("ggconfig: invalid %s value %q in %s %s: %v", e.Type, e.Value, e.Source, e.Key, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+var (
+	parseErrorMu       sync.RWMutex
+	parseErrorHandler  = func(err *ParseError) { panic(err) }
+	parseErrorObserver func(*ParseError)
+	validateMu         sync.Mutex
+)
+
+// SetParseErrorHandler replaces the handler for malformed values reported by strict
+// generated code and returns the previous one. The default handler panics with the
+// *ParseError; a handler that returns lets the method fall through to the next source.
+func SetParseErrorHandler(fn func(*ParseError)) func(*ParseError) {
+	parseErrorMu.Lock()
+	defer parseErrorMu.Unlock()
+	prev := parseErrorHandler
+	parseErrorHandler = fn
+	return prev
+}
+
+// ReportParseError passes a malformed value to the current handler.
+func ReportParseError(source, key, value, typ string, err error) {
+	HandleParseError(&ParseError{Source: source, Key: key, Value: value, Type: typ, Err: err})
+}
+
+// HandleParseError passes err to the current handler. It is called by the (T, bool) methods
+// of code generated with --strict; (T, error) methods return the error to the caller instead.
+func HandleParseError(err *ParseError) {
+	parseErrorMu.RLock()
+	handler := parseErrorHandler
+	parseErrorMu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// SetParseErrorObserver registers fn to be notified of malformed ENV values in code
+// generated without --strict (including values of alias variables), where the method
+// skips the value and falls through to the next alias, source or default. Logging them
+// makes a typo like DB_PORT=80a visible without failing startup. It returns the previous
+// observer; nil disables notifications (the default).
+func SetParseErrorObserver(fn func(*ParseError)) func(*ParseError) {
+	parseErrorMu.Lock()
+	defer parseErrorMu.Unlock()
+	prev := parseErrorObserver
+	parseErrorObserver = fn
+	return prev
+}
+
+// ObserveParseError passes a skipped malformed value to the observer, if any.
+// It is called by code generated without --strict.
+func ObserveParseError(source, key, value, typ string, err error) {
+	parseErrorMu.RLock()
+	observer := parseErrorObserver
+	parseErrorMu.RUnlock()
+	if observer != nil {
+		observer(&ParseError{Source: source, Key: key, Value: value, Type: typ, Err: err})
+	}
+}
+
+// Validate runs fn (typically a generated Snapshot function, which reads every key)
+// and returns the malformed values it encountered as one error instead of panicking:
+//
+//	if err := runtime.Validate(func() { gconfig.SnapshotInternalServerConfig(cfg) }); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The handler is replaced for the duration of fn, so values reported concurrently
+// from other goroutines are collected as well. Validate calls are serialized.
+//
+// Deprecated: Validate replaces the process-wide parse error handler, so errors of unrelated
+// goroutines land in whichever call is running. Use the Validate method of the generated
+// composite (cfg.Validate()), which keeps the errors with the config it checks.
+func Validate(fn func()) error {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+
+	var mu sync.Mutex
+	var errs []error
+	prev := SetParseErrorHandler(func(err *ParseError) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	defer SetParseErrorHandler(prev)
+
+	fn()
+	mu.Lock()
+	defer mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// ReportInvalid reports the first non-null value found for keys in sections as malformed
+// to the parse error handler (see InvalidValue).
+func (y *YAML) ReportInvalid(typ string, sections []string, keys ...string) {
+	if err := y.InvalidValue(typ, sections, keys...); err != nil {
+		HandleParseError(err)
+	}
+}
+
+// InvalidValue returns the first non-null value found for keys in sections as a *ParseError,
+// nil if there is none. Strict generated YAML implementations call it when no value could be
+// parsed as typ.
+func (y *YAML) InvalidValue(typ string, sections []string, keys ...string) *ParseError {
+	return y.invalidValue(typ, nil, sections, keys)
+}
+
+// InvalidValueUnset is InvalidValue for a method annotated with ggconfig:unset: keys holding
+// the sentinel unset are skipped as absent (see isUnsetValue).
+func (y *YAML) InvalidValueUnset(typ, unset string, sections []string, keys ...string) *ParseError {
+	return y.invalidValue(typ, func(v any) bool { return isUnsetValue(typ, unset, v) }, sections, keys)
+}
+
+// isUnsetValue сообщает, что значение YAML v - заглушка unset метода типа typ: bool
+// сравнивается после strconv.ParseBool, остальные типы - как текст
+func isUnsetValue(typ, unset string, v any) bool {
+	s := fmt.Sprint(v)
+	if typ != "string" {
+		s = strings.TrimSpace(s)
+	}
+	if typ == "bool" {
+		b, err := strconv.ParseBool(s)
+		u, uerr := strconv.ParseBool(unset)
+		return err == nil && uerr == nil && b == u
+	}
+	return s == unset
+}
+
+func (y *YAML) invalidValue(typ string, unset func(any) bool, sections, keys []string) *ParseError {
+	for _, section := range sections {
+		sec, ok := y.section(section)
+		if !ok {
+			continue
+		}
+		for _, k := range keys {
+			v, ok := sec[k]
+			if !ok || v == nil || k == "" || (unset != nil && unset(v)) {
+				continue
+			}
+			if list, ok := v.([]any); ok && len(list) == 0 {
+				// Пустой список - корректное отсутствие элементов
+				return nil
+			}
+			if str, ok := v.(string); ok {
+				if ref, ok := ParseSecretPlaceholder(str); ok {
+					return &ParseError{Source: "yaml", Key: section + "." + k, Value: str, Type: typ, Err: fmt.Errorf("secret %q is not resolved", ref)}
+				}
+			}
+			return &ParseError{Source: "yaml", Key: section + "." + k, Value: fmt.Sprint(v), Type: typ, Err: fmt.Errorf("%T value is not a valid %s", v, typ)}
+		}
+	}
+	return nil
+}
+
+// ErrNotSet is returned by generated methods declared with an error second return
+// ((T, error) instead of (T, bool)) when no source has the key; the error names the key.
+var ErrNotSet = errors.New("not set")
+
+// LookupValue is the generated body of a method declared as Name(defaultValue T) (T, error):
+// lookup is the error-returning form of the method (lookupErr<Name>). An absent key returns
+// defaultValue and an error wrapping ErrNotSet; a malformed value returns defaultValue and the
+// *ParseError. The parse error handler is not involved.
+func LookupValue[T any](key string, defaultValue T, lookup func(T) (T, bool, error)) (T, error) {
+	v, ok, err := lookup(defaultValue)
+	if err != nil {
+		return defaultValue, err
+	}
+	if ok {
+		return v, nil
+	}
+	return defaultValue, fmt.Errorf("ggconfig: %s: %w", key, ErrNotSet)
+}
@@ -0,0 +1,34 @@
+package runtime
+
+// GetStruct decodes the subtree of a key into T with yaml.v3 semantics (yaml struct tags,
+// nested structs, slices and maps). It is the typed counterpart of GetRaw for methods
+// returning a struct; null values and subtrees T cannot be decoded from are skipped as if
+// the key was absent.
+func GetStruct[T any](y *YAML, section string, keys ...string) (T, bool) {
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		raw, ok := y.GetRaw(section, k)
+		if !ok || raw.IsZero() || raw.Node.Tag == "!!null" {
+			continue
+		}
+		var v T
+		if err := raw.Decode(&v); err == nil {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// GetStructs decodes the subtree of a key into []T like GetStruct for methods returning a
+// slice of structs. An empty sequence is treated as absent.
+func GetStructs[T any](y *YAML, section string, keys ...string) ([]T, bool) {
+	for _, k := range keys {
+		if v, ok := GetStruct[[]T](y, section, k); ok && len(v) > 0 {
+			return v, true
+		}
+	}
+	return nil, false
+}
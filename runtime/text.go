@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"encoding"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseText parses value into a method type that implements encoding.TextUnmarshaler
+// (enumerations, slog.Level, netip.Addr). The first argument only carries the type: generated
+// code passes the default value.
+func ParseText[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](_ T, value string) (T, error) {
+	var v T
+	if err := PT(&v).UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// GetText retrieves a value of a type that implements encoding.TextUnmarshaler. Scalars are
+// passed to UnmarshalText as text (numbers and booleans as written, unquoted YAML timestamps
+// as RFC3339); values UnmarshalText rejects are skipped as if the key was absent.
+func GetText[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](y *YAML, defaultValue T, section string, keys ...string) (T, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return defaultValue, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		var text string
+		switch t := sec[k].(type) {
+		case nil:
+			continue
+		case string:
+			text = t
+		case bool:
+			text = strconv.FormatBool(t)
+		case int:
+			text = strconv.Itoa(t)
+		case int64:
+			text = strconv.FormatInt(t, 10)
+		case uint64:
+			text = strconv.FormatUint(t, 10)
+		case float64:
+			text = strconv.FormatFloat(t, 'g', -1, 64)
+		case time.Time:
+			text = t.Format(time.RFC3339Nano)
+		default:
+			continue
+		}
+		if v, err := ParseText[T, PT](defaultValue, text); err == nil {
+			return v, true
+		}
+	}
+	return defaultValue, false
+}
@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp formats of int64 methods annotated with ggconfig:format.
+const (
+	FormatUnix   = "unix"   // seconds since the Unix epoch
+	FormatUnixMs = "unixms" // milliseconds since the Unix epoch
+)
+
+// ParseUnixTime parses a timestamp of an int64 method annotated with ggconfig:format=unix
+// or ggconfig:format=unixms: an integer is taken as is (seconds or milliseconds since the
+// epoch), any other value must be an RFC3339 time ("2024-05-01T09:00:00Z") and is converted.
+func ParseUnixTime(value, format string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither a unix timestamp nor an RFC3339 time", value)
+	}
+	return unixTime(t, format), nil
+}
+
+func unixTime(t time.Time, format string) int64 {
+	if format == FormatUnixMs {
+		return t.UnixMilli()
+	}
+	return t.Unix()
+}
+
+// GetUnixTime retrieves a timestamp in format (FormatUnix or FormatUnixMs): integers are
+// taken as is, RFC3339 strings and unquoted YAML timestamps are converted. Other values
+// are skipped as if the key was absent.
+func (y *YAML) GetUnixTime(format, section string, keys ...string) (int64, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return 0, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		switch t := sec[k].(type) {
+		case int:
+			return int64(t), true
+		case int64:
+			return t, true
+		case uint64:
+			if t <= uint64(math.MaxInt64) {
+				return int64(t), true
+			}
+		case float64:
+			if math.Trunc(t) == t && t < math.MaxInt64 && t > math.MinInt64 {
+				return int64(t), true
+			}
+		case time.Time:
+			// yaml.v3 разбирает значения без кавычек вида 2024-05-01T09:00:00Z в time.Time
+			return unixTime(t, format), true
+		case string:
+			if n, err := ParseUnixTime(t, format); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// GetTime retrieves a time.Time: strings are parsed with layout (time.RFC3339 unless the
+// method has ggconfig:layout), unquoted YAML timestamps are taken as is. Other values are
+// skipped as if the key was absent.
+func (y *YAML) GetTime(layout, section string, keys ...string) (time.Time, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		switch t := sec[k].(type) {
+		case time.Time:
+			// yaml.v3 разбирает значения без кавычек вида 2024-05-01T09:00:00Z и 2024-05-01 в time.Time
+			return t, true
+		case string:
+			if v, err := time.Parse(layout, strings.TrimSpace(t)); err == nil {
+				return v, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
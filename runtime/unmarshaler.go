@@ -0,0 +1,37 @@
+package runtime
+
+import (
+	"encoding"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseUnmarshaler parses an ENV value into a method type that implements yaml.Unmarshaler
+// (ByteSize, LogLevel): with UnmarshalText if the type has it too, otherwise the value is
+// decoded as a YAML document, so UnmarshalYAML receives "10MB" as a scalar and '{"a": 1}' as
+// a mapping. The first argument only carries the type: generated code passes the default value.
+func ParseUnmarshaler[T any](_ T, value string) (T, error) {
+	var v T
+	var err error
+	if tu, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		err = tu.UnmarshalText([]byte(strings.TrimSpace(value)))
+	} else {
+		err = yaml.Unmarshal([]byte(value), &v)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// GetUnmarshaler retrieves a value of a type that implements yaml.Unmarshaler: the node of the
+// key is passed to UnmarshalYAML as is. Null values and values UnmarshalYAML rejects are
+// skipped as if the key was absent.
+func GetUnmarshaler[T any](y *YAML, defaultValue T, section string, keys ...string) (T, bool) {
+	if v, ok := GetStruct[T](y, section, keys...); ok {
+		return v, true
+	}
+	return defaultValue, false
+}
@@ -0,0 +1,195 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultOptions configures a secret resolver backed by a HashiCorp Vault KV version 2 engine.
+type VaultOptions struct {
+	// Address is the Vault server address (default: VAULT_ADDR).
+	Address string
+	// Token is the Vault token (default: VAULT_TOKEN).
+	Token string
+	// Namespace is the Vault Enterprise namespace (default: VAULT_NAMESPACE).
+	Namespace string
+	// Mount is the mount path of the KV engine (default "secret").
+	Mount string
+	// Path is the secret that references without a path read, e.g. "my-service/prod" for
+	// SERVER_DB_PASSWORD (optional).
+	Path string
+	// CacheTTL is how long read secrets are cached (default 5 minutes, negative disables caching).
+	CacheTTL time.Duration
+	// OnError (optional) receives resolution errors, which are otherwise reported as absent values.
+	OnError func(ref string, err error)
+	// Client is the HTTP client (default: a client with a 10s timeout).
+	Client *http.Client
+}
+
+// VaultSource resolves ggconfig:secret methods from Vault KV v2. A reference is
+// "<path>#<field>", the field of the secret at the path under VaultOptions.Mount, or a field
+// of the VaultOptions.Path secret. A secret is read once for all of its fields, so
+// PrefetchSecrets costs one request per secret path instead of one per key.
+type VaultSource struct {
+	opts VaultOptions
+
+	mu      sync.Mutex
+	secrets map[string]vaultSecret
+}
+
+type vaultSecret struct {
+	data    map[string]any
+	expires time.Time
+}
+
+// NewVaultSource returns a resolver for the given server. Secrets are fetched lazily.
+func NewVaultSource(opts VaultOptions) (*VaultSource, error) {
+	if opts.Address == "" {
+		opts.Address = os.Getenv("VAULT_ADDR")
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if opts.Address == "" || opts.Token == "" {
+		return nil, errors.New("vault: Address and Token are required")
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	opts.Mount = strings.Trim(opts.Mount, "/")
+	opts.Path = strings.Trim(opts.Path, "/")
+	return &VaultSource{opts: opts, secrets: map[string]vaultSecret{}}, nil
+}
+
+// ResolveSecret implements SecretResolver.
+func (s *VaultSource) ResolveSecret(ref string) (string, bool) {
+	v, err := s.Lookup(context.Background(), ref)
+	if err != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(ref, err)
+		}
+		return "", false
+	}
+	return v, true
+}
+
+// Lookup returns a secret field by reference, reading its secret unless it is cached.
+func (s *VaultSource) Lookup(ctx context.Context, ref string) (string, error) {
+	path, field, err := s.parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	data, err := s.secret(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	v, ok := data[field]
+	if !ok || v == nil {
+		return "", fmt.Errorf("vault: %s: field %q not found", ref, field)
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	// Не строковые поля (числа, объекты) возвращаются в JSON, как их показывает vault kv get -format=json
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("vault: %s: %w", ref, err)
+	}
+	return string(b), nil
+}
+
+// PrefetchSecrets implements SecretPrefetcher: each secret path referenced by refs is read
+// once and cached for CacheTTL; a missing secret or field is an error. Without caching it
+// does nothing.
+func (s *VaultSource) PrefetchSecrets(ctx context.Context, refs []string) error {
+	if s.opts.CacheTTL <= 0 {
+		return nil
+	}
+	for _, ref := range refs {
+		if _, err := s.Lookup(ctx, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate drops the cached secrets, e.g. after a rotation.
+func (s *VaultSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets = map[string]vaultSecret{}
+}
+
+// secret возвращает поля секрета path из кеша или читает их запросом к
+// /v1/<mount>/data/<path>. Блокировка удерживается на время запроса, как в DopplerSource
+func (s *VaultSource) secret(ctx context.Context, path string) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.secrets[path]; ok && time.Now().Before(c.expires) {
+		return c.data, nil
+	}
+
+	u := s.opts.Address + "/v1/" + (&url.URL{Path: s.opts.Mount + "/data/" + path}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.opts.Token)
+	if s.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.opts.Namespace)
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("vault: secret %s/%s not found", s.opts.Mount, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: read %s/%s: unexpected status %s", s.opts.Mount, path, resp.Status)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("vault: decode %s/%s: %w", s.opts.Mount, path, err)
+	}
+	if s.opts.CacheTTL > 0 {
+		s.secrets[path] = vaultSecret{data: body.Data.Data, expires: time.Now().Add(s.opts.CacheTTL)}
+	}
+	return body.Data.Data, nil
+}
+
+// parseRef разбирает ссылку path#field или field секрета Path
+func (s *VaultSource) parseRef(ref string) (path, field string, err error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok {
+		path, field = s.opts.Path, ref
+	}
+	path = strings.Trim(path, "/")
+	if path == "" || field == "" {
+		return "", "", fmt.Errorf("vault: invalid secret reference %q (want path#field, or field with VaultOptions.Path)", ref)
+	}
+	return path, field, nil
+}
@@ -3,36 +3,111 @@ package runtime
 import (
 	"fmt"
 	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // YAML is a parsed YAML configuration stored as a generic map.
 // Expected top-level structure: map[section]map[key]value.
+// It is safe for concurrent use; reloading sources swap its contents with Replace.
 type YAML struct {
-	root map[string]any
+	mu       sync.RWMutex
+	root     map[string]any
+	doc      *yaml.Node // исходное дерево документа (nil, если YAML собран не из документа)
+	onChange []func()
+	secrets  SecretResolver
 }
 
-func (y *YAML) ensure() {
-	if y.root == nil {
-		y.root = map[string]any{}
+// section возвращает карту секции. Содержимое секций не изменяется после загрузки
+// (Replace подменяет дерево целиком), поэтому карту можно читать без блокировки.
+func (y *YAML) section(name string) (map[string]any, bool) {
+	y.mu.RLock()
+	defer y.mu.RUnlock()
+	sec, ok := y.root[name].(map[string]any)
+	return sec, ok
+}
+
+// Replace atomically swaps the contents of y with the contents of other
+// and notifies OnChange subscribers. Used by sources that reload configuration.
+func (y *YAML) Replace(other *YAML) {
+	other.mu.RLock()
+	root, doc := other.root, other.doc
+	other.mu.RUnlock()
+
+	y.mu.Lock()
+	y.root, y.doc = root, doc
+	subscribers := append([]func(){}, y.onChange...)
+	y.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn()
 	}
 }
 
+// OnChange registers fn to be called after every Replace.
+func (y *YAML) OnChange(fn func()) {
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	y.onChange = append(y.onChange, fn)
+}
+
+// FromProperties builds a YAML tree from flat "section.key" properties,
+// as served by config centers (Apollo, Nacos, Spring Cloud Config).
+// The section is the part before the first dot; keys without a dot are ignored.
+// Values stay strings: numeric getters accept numeric strings.
+func FromProperties(props map[string]string) *YAML {
+	root := map[string]any{}
+	for k, v := range props {
+		section, key, ok := strings.Cut(k, ".")
+		if !ok || section == "" || key == "" {
+			continue
+		}
+		sec, _ := root[section].(map[string]any)
+		if sec == nil {
+			sec = map[string]any{}
+			root[section] = sec
+		}
+		sec[key] = v
+	}
+	return &YAML{root: root}
+}
+
+// ParseYAML parses a YAML document. Anchors (&name), aliases (*name) and merge keys (<<)
+// are resolved into the effective map: a key merged from another mapping is read like one
+// written in place, and keys written in place override merged ones (ParseYAMLNoAnchors
+// rejects such documents instead). Values encrypted with EncryptValue are decrypted.
 func ParseYAML(data []byte) (*YAML, error) {
-	var root map[string]any
-	if err := yaml.Unmarshal(data, &root); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("yaml unmarshal: %w", err)
 	}
+	return fromDocument(&doc)
+}
+
+func fromDocument(doc *yaml.Node) (*YAML, error) {
+	// Значения enc:AES256GCM:... расшифровываются до декодирования
+	if err := decryptDocument(doc); err != nil {
+		return nil, err
+	}
+	var root map[string]any
+	if doc.Kind != 0 {
+		if err := doc.Decode(&root); err != nil {
+			return nil, fmt.Errorf("yaml unmarshal: %w", err)
+		}
+	}
 	if root == nil {
 		root = map[string]any{}
 	}
-	return &YAML{root: root}, nil
+	return &YAML{root: root, doc: doc}, nil
 }
 
 func (y *YAML) GetString(section string, keys ...string) (string, bool) {
-	y.ensure()
-	sec, ok := y.root[section].(map[string]any)
+	sec, ok := y.section(section)
 	if !ok {
 		return "", false
 	}
@@ -42,6 +117,13 @@ func (y *YAML) GetString(section string, keys ...string) (string, bool) {
 		}
 		if v, ok := sec[k]; ok {
 			if s, ok := v.(string); ok {
+				if ref, ok := ParseSecretPlaceholder(s); ok {
+					// Ссылка на секрет никогда не возвращается как значение:
+					// без резолвера или при ошибке ключ считается отсутствующим
+					if s, ok = y.resolveSecret(ref); !ok {
+						continue
+					}
+				}
 				return s, true
 			}
 		}
@@ -49,12 +131,42 @@ func (y *YAML) GetString(section string, keys ...string) (string, bool) {
 	return "", false
 }
 
+// SetSecretResolver enables resolution of "${secret:<ref>}" string values (see SecretPlaceholder).
+// Without a resolver such values are reported as absent, so a placeholder never leaks into
+// the configuration as a literal value. The resolver is kept across Replace.
+func (y *YAML) SetSecretResolver(r SecretResolver) {
+	y.mu.Lock()
+	y.secrets = r
+	y.mu.Unlock()
+}
+
+func (y *YAML) resolveSecret(ref string) (string, bool) {
+	y.mu.RLock()
+	r := y.secrets
+	y.mu.RUnlock()
+	if r == nil {
+		return "", false
+	}
+	return r.ResolveSecret(ref)
+}
+
 func (y *YAML) GetInt(section string, keys ...string) (int, bool) {
-	y.ensure()
-	sec, ok := y.root[section].(map[string]any)
+	v, ok := y.GetIntN(0, section, keys...)
+	return int(v), ok
+}
+
+// GetIntN retrieves a signed integer that fits into bitSize bits (8, 16, 32, 64; 0 means int).
+// Values out of range or with a fractional part are skipped as if the key was absent.
+func (y *YAML) GetIntN(bitSize int, section string, keys ...string) (int64, bool) {
+	sec, ok := y.section(section)
 	if !ok {
 		return 0, false
 	}
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	minVal := int64(-1) << (bitSize - 1)
+	maxVal := int64(1)<<(bitSize-1) - 1
 	for _, k := range keys {
 		if k == "" {
 			continue
@@ -63,25 +175,151 @@ func (y *YAML) GetInt(section string, keys ...string) (int, bool) {
 		if !ok {
 			continue
 		}
+		var n int64
 		switch t := v.(type) {
+		case string:
+			// Значения из properties-источников приходят строками
+			parsed, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+			if err != nil {
+				continue
+			}
+			n = parsed
 		case int:
-			return t, true
+			n = int64(t)
 		case int64:
-			if t > int64(math.MaxInt) || t < int64(math.MinInt) {
-				return 0, false
+			n = t
+		case uint64:
+			if t > uint64(math.MaxInt64) {
+				continue
 			}
-			return int(t), true
+			n = int64(t)
 		case float64:
 			// YAML иногда может распарсить числа как float64 в зависимости от структуры.
-			if math.Trunc(t) != t {
-				return 0, false
+			// Целое, которое не поместилось в int64, yaml.v3 тоже отдает как float64: -2^63-1
+			// округляется до -2^63, поэтому нижняя граница, как и верхняя, исключается
+			if math.Trunc(t) != t || t >= math.MaxInt64 || t <= math.MinInt64 {
+				continue
 			}
-			if t > float64(math.MaxInt) || t < float64(math.MinInt) {
-				return 0, false
+			n = int64(t)
+		default:
+			// no conversion
+			continue
+		}
+		if n < minVal || n > maxVal {
+			continue
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// GetUintN retrieves an unsigned integer that fits into bitSize bits (8, 16, 32, 64; 0 means uint).
+// Negative values, values out of range or with a fractional part are skipped as if the key was absent.
+func (y *YAML) GetUintN(bitSize int, section string, keys ...string) (uint64, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return 0, false
+	}
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	maxVal := uint64(math.MaxUint64) >> (64 - bitSize)
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		v, ok := sec[k]
+		if !ok {
+			continue
+		}
+		var n uint64
+		switch t := v.(type) {
+		case string:
+			parsed, err := strconv.ParseUint(strings.TrimSpace(t), 10, 64)
+			if err != nil {
+				continue
+			}
+			n = parsed
+		case int:
+			if t < 0 {
+				continue
+			}
+			n = uint64(t)
+		case int64:
+			if t < 0 {
+				continue
+			}
+			n = uint64(t)
+		case uint64:
+			n = t
+		case float64:
+			if math.Trunc(t) != t || t < 0 || t >= math.MaxUint64 {
+				continue
 			}
-			return int(t), true
+			n = uint64(t)
 		default:
 			// no conversion
+			continue
+		}
+		if n > maxVal {
+			continue
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// GetBool retrieves a boolean value. Strings accepted by strconv.ParseBool
+// ("true", "false", "1", "0", ...) are converted, as properties sources serve strings.
+func (y *YAML) GetBool(section string, keys ...string) (bool, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return false, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		switch t := sec[k].(type) {
+		case bool:
+			return t, true
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(t)); err == nil {
+				return b, true
+			}
+		}
+	}
+	return false, false
+}
+
+// GetDuration retrieves a time.Duration: strings are parsed with time.ParseDuration ("30s",
+// "1m30s"), numbers are taken as seconds (30, 0.5). Other values are skipped as if the key was absent.
+func (y *YAML) GetDuration(section string, keys ...string) (time.Duration, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return 0, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		switch t := sec[k].(type) {
+		case string:
+			if d, err := time.ParseDuration(strings.TrimSpace(t)); err == nil {
+				return d, true
+			}
+		case int:
+			return time.Duration(t) * time.Second, true
+		case int64:
+			return time.Duration(t) * time.Second, true
+		case uint64:
+			if t <= uint64(math.MaxInt64/int64(time.Second)) {
+				return time.Duration(t) * time.Second, true
+			}
+		case float64:
+			if d := t * float64(time.Second); d < math.MaxInt64 && d > math.MinInt64 {
+				return time.Duration(d), true
+			}
 		}
 	}
 	return 0, false
@@ -91,8 +329,7 @@ func (y *YAML) GetInt(section string, keys ...string) (int, bool) {
 // It returns the slice as []any and a boolean indicating success.
 // This is a generic method that can be used for any slice type.
 func (y *YAML) GetSlice(section string, keys ...string) ([]any, bool) {
-	y.ensure()
-	sec, ok := y.root[section].(map[string]any)
+	sec, ok := y.section(section)
 	if !ok {
 		return nil, false
 	}
@@ -109,4 +346,60 @@ func (y *YAML) GetSlice(section string, keys ...string) ([]any, bool) {
 	return nil, false
 }
 
+// GetStrings retrieves a []string: sequence elements are converted to strings (numbers
+// and booleans with their YAML spelling, nested mappings and sequences are skipped), a
+// string value (for example from a properties source) is split on sep with empty items dropped.
+// An empty result is treated as absent.
+func (y *YAML) GetStrings(sep, section string, keys ...string) ([]string, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return nil, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		var result []string
+		switch t := sec[k].(type) {
+		case []any:
+			for _, item := range t {
+				switch v := item.(type) {
+				case string:
+					result = append(result, v)
+				case int, int64, uint64, float64, bool:
+					result = append(result, fmt.Sprint(v))
+				}
+			}
+		case string:
+			for _, s := range strings.Split(t, sep) {
+				if s = strings.TrimSpace(s); s != "" {
+					result = append(result, s)
+				}
+			}
+		}
+		if len(result) > 0 {
+			return result, true
+		}
+	}
+	return nil, false
+}
 
+// GetURL retrieves a *url.URL parsed with url.Parse from a string value. Empty strings and
+// values url.Parse rejects are skipped as if the key was absent.
+func (y *YAML) GetURL(section string, keys ...string) (*url.URL, bool) {
+	sec, ok := y.section(section)
+	if !ok {
+		return nil, false
+	}
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if s, ok := sec[k].(string); ok && strings.TrimSpace(s) != "" {
+			if u, err := url.Parse(strings.TrimSpace(s)); err == nil {
+				return u, true
+			}
+		}
+	}
+	return nil, false
+}
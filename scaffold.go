@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
+
+// scaffoldPreset - заготовка интерфейса Config для типовой подсистемы
+type scaffoldPreset struct {
+	Package string // Имя пакета и YAML секции по умолчанию
+	Doc     string
+	Methods []scaffoldMethod
+}
+
+type scaffoldMethod struct {
+	Name    string
+	Type    string
+	Doc     string
+	Example string // Значение в примере конфига (как в YAML)
+}
+
+var scaffoldPresets = map[string]scaffoldPreset{
+	"db": {
+		Package: "database",
+		Doc:     "Config describes the SQL database connection.",
+		Methods: []scaffoldMethod{
+			{"Host", "string", "Host is the database server host", `"localhost"`},
+			{"Port", "int", "Port is the database server port", "5432"},
+			{"Name", "string", "Name is the database name", `"app"`},
+			{"User", "string", "User is the database user", `"app"`},
+			{"Password", "string", "Password is the database password", `""`},
+			{"SSLMode", "string", "SSLMode is the PostgreSQL sslmode (disable, require, verify-full)", `"disable"`},
+			{"MaxOpenConns", "int", "MaxOpenConns limits open connections in the pool", "25"},
+			{"MaxIdleConns", "int", "MaxIdleConns limits idle connections in the pool", "5"},
+			{"ConnMaxLifetime", "time.Duration", "ConnMaxLifetime is the maximum connection lifetime", `"5m"`},
+		},
+	},
+	"http-server": {
+		Package: "server",
+		Doc:     "Config describes the HTTP server.",
+		Methods: []scaffoldMethod{
+			{"Host", "string", "Host is the listen address", `"0.0.0.0"`},
+			{"Port", "int", "Port is the listen port", "8080"},
+			{"ReadTimeout", "time.Duration", "ReadTimeout is the request read timeout", `"15s"`},
+			{"WriteTimeout", "time.Duration", "WriteTimeout is the response write timeout", `"15s"`},
+			{"IdleTimeout", "time.Duration", "IdleTimeout is the keep-alive idle timeout", `"60s"`},
+			{"ShutdownTimeout", "time.Duration", "ShutdownTimeout is the graceful shutdown timeout", `"10s"`},
+			{"TLSCertFile", "string", "TLSCertFile is the TLS certificate path (empty: plain HTTP)", `""`},
+			{"TLSKeyFile", "string", "TLSKeyFile is the TLS private key path", `""`},
+		},
+	},
+	"redis": {
+		Package: "redis",
+		Doc:     "Config describes the Redis client.",
+		Methods: []scaffoldMethod{
+			{"Addr", "string", "Addr is the Redis server address (host:port)", `"localhost:6379"`},
+			{"Username", "string", "Username is the ACL user (Redis 6+)", `""`},
+			{"Password", "string", "Password is the Redis password", `""`},
+			{"DB", "int", "DB is the database number", "0"},
+			{"PoolSize", "int", "PoolSize is the maximum number of connections", "10"},
+			{"DialTimeout", "time.Duration", "DialTimeout is the connect timeout", `"5s"`},
+			{"TLS", "bool", "TLS enables TLS connections", "false"},
+		},
+	},
+	"kafka": {
+		Package: "kafka",
+		Doc:     "Config describes the Kafka producer and consumer.",
+		Methods: []scaffoldMethod{
+			{"Brokers", "[]string", "Brokers is the list of bootstrap brokers", `["localhost:9092"]`},
+			{"ClientID", "string", "ClientID identifies the client in broker logs", `"app"`},
+			{"GroupID", "string", "GroupID is the consumer group", `"app"`},
+			{"Topic", "string", "Topic is the default topic", `"events"`},
+			{"SASLMechanism", "string", "SASLMechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512 (empty: no SASL)", `""`},
+			{"Username", "string", "Username is the SASL user", `""`},
+			{"Password", "string", "Password is the SASL password", `""`},
+			{"TLS", "bool", "TLS enables TLS connections", "false"},
+		},
+	},
+	"s3": {
+		Package: "s3",
+		Doc:     "Config describes the S3-compatible object storage.",
+		Methods: []scaffoldMethod{
+			{"Endpoint", "string", "Endpoint overrides the S3 endpoint (empty: AWS)", `""`},
+			{"Region", "string", "Region is the bucket region", `"us-east-1"`},
+			{"Bucket", "string", "Bucket is the bucket name", `"app"`},
+			{"AccessKeyID", "string", "AccessKeyID is the access key (empty: default AWS credentials)", `""`},
+			{"SecretAccessKey", "string", "SecretAccessKey is the secret key", `""`},
+			{"UsePathStyle", "bool", "UsePathStyle enables path-style addressing (MinIO, Ceph)", "false"},
+		},
+	},
+}
+
+// runScaffold реализует команду scaffold: по заготовкам создает пакеты с интерфейсом Config,
+// директивой go:generate и примером конфига со значениями по умолчанию.
+func runScaffold(args []string) error {
+	fs := flag.NewFlagSet("scaffold", flag.ExitOnError)
+	outDir := fs.String("out", "internal", "directory for generated packages")
+	pkgName := fs.String("pkg", "", "package name (default: preset package, only with a single preset)")
+	generateArgs := fs.String("generate-args", "", "extra ggconfig flags for the go:generate directive, e.g. \"--output=../gconfig --registry\"")
+	stdout := fs.Bool("stdout", false, "print generated files instead of writing them")
+	force := fs.Bool("force", false, "overwrite existing files")
+	list := fs.Bool("list", false, "list available presets")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig scaffold [--out=internal] [options] preset...")
+		fmt.Fprintln(fs.Output(), "\nPresets: "+strings.Join(scaffoldPresetNames(), ", "))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *list {
+		for _, name := range scaffoldPresetNames() {
+			p := scaffoldPresets[name]
+			fmt.Printf("%-12s %s (package %s)\n", name, strings.TrimSuffix(p.Doc, "."), p.Package)
+		}
+		return nil
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("preset is required")
+	}
+	if *pkgName != "" && fs.NArg() > 1 {
+		return fmt.Errorf("--pkg can be used with a single preset only")
+	}
+
+	for _, name := range fs.Args() {
+		preset, ok := scaffoldPresets[name]
+		if !ok {
+			return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(scaffoldPresetNames(), ", "))
+		}
+		pkg := preset.Package
+		if *pkgName != "" {
+			pkg = *pkgName
+		}
+		dir := filepath.Join(*outDir, pkg)
+		files := []struct {
+			path string
+			data []byte
+		}{
+			{filepath.Join(dir, "config.go"), renderScaffoldInterface(name, pkg, preset, *generateArgs)},
+			{filepath.Join(dir, pkg+"_example.yaml"), renderScaffoldExample(pkg, preset)},
+		}
+		if *stdout {
+			for _, f := range files {
+				fmt.Printf("// ===== %s =====\n%s\n", f.path, f.data)
+			}
+			continue
+		}
+		for _, f := range files {
+			if _, err := os.Stat(f.path); err == nil && !*force {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", f.path)
+			}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+		for _, f := range files {
+			if err := os.WriteFile(f.path, f.data, 0644); err != nil {
+				return fmt.Errorf("write %s: %w", f.path, err)
+			}
+		}
+		fmt.Printf("✅ %s: %s preset, %d methods\n", dir, name, len(preset.Methods))
+	}
+	return nil
+}
+
+func scaffoldPresetNames() []string {
+	names := make([]string, 0, len(scaffoldPresets))
+	for name := range scaffoldPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func renderScaffoldInterface(name, pkg string, preset scaffoldPreset, generateArgs string) []byte {
+	directive := "//go:generate ggconfig --interface=Config"
+	if generateArgs != "" {
+		directive += " " + generateArgs
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	for _, m := range preset.Methods {
+		if strings.HasPrefix(m.Type, "time.") {
+			b.WriteString("import \"time\"\n\n")
+			break
+		}
+	}
+	fmt.Fprintf(&b, "// %s\n// Generated by ggconfig scaffold %s: edit freely.\n//\n", preset.Doc, name)
+	b.WriteString(directive + "\n")
+	b.WriteString("type Config interface {\n")
+	for _, m := range preset.Methods {
+		fmt.Fprintf(&b, "\t// %s\n", m.Doc)
+		fmt.Fprintf(&b, "\t%s(defaultValue %s) (%s, bool)\n", m.Name, m.Type, m.Type)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// renderScaffoldExample формирует пример конфига в формате сгенерированных примеров
+// (секция - имя пакета, ключ - имя метода в нижнем регистре), но со значениями заготовки
+func renderScaffoldExample(pkg string, preset scaffoldPreset) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n", preset.Doc)
+	fmt.Fprintf(&b, "# ENV overrides: %s_<KEY>, e.g. %s\n", strings.ToUpper(pkg), generator.EnvKey(pkg, preset.Methods[0].Name))
+	fmt.Fprintf(&b, "%s:\n", pkg)
+	for _, m := range preset.Methods {
+		fmt.Fprintf(&b, "  # %s\n", m.Doc)
+		fmt.Fprintf(&b, "  %s: %s\n", strings.ToLower(m.Name), m.Example)
+	}
+	return b.Bytes()
+}
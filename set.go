@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+	"github.com/apopov-app/ggconfig/runtime"
+)
+
+// runSet реализует команду set: значение section.key проверяется по схеме интерфейсов
+// (директивы //go:generate ggconfig) и записывается в YAML с сохранением комментариев.
+func runSet(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ExitOnError)
+	file := fs.String("file", "config.yaml", "YAML config file to edit")
+	var pkgs generator.AliasFlags
+	fs.Var(&pkgs, "pkg", "package dir with //go:generate ggconfig directives (repeatable, default: all packages under the current directory)")
+	noValidate := fs.Bool("no-validate", false, "write the value as is without checking it against the interfaces")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig set [--file=config.yaml] [--pkg=dir] section.key value")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected section.key and value")
+	}
+	section, key, ok := strings.Cut(fs.Arg(0), ".")
+	if !ok || section == "" || key == "" {
+		return fmt.Errorf("invalid key %q (want section.key)", fs.Arg(0))
+	}
+	raw := fs.Arg(1)
+
+	var value any = raw
+	if !*noValidate {
+		var directives []generator.Directive
+		if len(pkgs) == 0 {
+			all, err := generator.WalkDirectives(".")
+			if err != nil {
+				return err
+			}
+			directives = all
+		}
+		for _, dir := range pkgs {
+			found, err := generator.FindDirectives(dir)
+			if err != nil {
+				return err
+			}
+			directives = append(directives, found...)
+		}
+		m, err := findSchemaMethod(directives, section, key)
+		if err != nil {
+			return err
+		}
+		if value, err = generator.ParseValue(m, raw); err != nil {
+			return fmt.Errorf("%s: %w", fs.Arg(0), err)
+		}
+	}
+
+	doc, err := runtime.OpenDocument(*file)
+	if err != nil {
+		return err
+	}
+	if err := doc.Set(section, key, value); err != nil {
+		return err
+	}
+	if err := doc.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s: %s.%s = %s\n", *file, section, key, raw)
+	return nil
+}
+
+// findSchemaMethod ищет метод, который читает section.key: секция - имя пакета или алиас
+// yaml.section, ключ - имя метода в нижнем регистре или алиас yaml.key.<Method>
+func findSchemaMethod(directives []generator.Directive, section, key string) (generator.Method, error) {
+	var known []string
+	sectionFound := false
+	for _, d := range directives {
+		abs, err := filepath.Abs(d.Dir)
+		if err != nil {
+			return generator.Method{}, err
+		}
+		packageName := filepath.Base(abs)
+		sections := append([]string{packageName}, d.Aliases.YAMLSection...)
+		if !containsString(sections, section) {
+			continue
+		}
+		sectionFound = true
+		info, err := generator.ParseInterface(d.Dir, d.SourceFile, packageName, packageName, d.Interface)
+		if err != nil {
+			return generator.Method{}, err
+		}
+		d.Aliases = generator.MethodAliases(info, d.Aliases)
+		for _, m := range info.Methods {
+			keys := append([]string{strings.ToLower(m.Name)}, d.Aliases.YAMLKey[m.Name]...)
+			if containsString(keys, key) {
+				return m, nil
+			}
+			known = append(known, keys[0])
+		}
+	}
+	if !sectionFound {
+		return generator.Method{}, fmt.Errorf("unknown section %q: no interface reads it (use --pkg or --no-validate)", section)
+	}
+	sort.Strings(known)
+	return generator.Method{}, fmt.Errorf("unknown key %s.%s (known keys: %s)", section, key, strings.Join(known, ", "))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apopov-app/ggconfig/pkg/generator"
+)
+
+// workspaceDirective - директива //go:generate ggconfig для запуска в режиме ./...
+type workspaceDirective struct {
+	File    string // Файл с директивой
+	Line    int
+	Package string   // Имя пакета файла ($GOPACKAGE)
+	Args    []string // Аргументы ggconfig без самой команды
+}
+
+// workspaceResult - вывод и ошибка запуска одной директивы
+type workspaceResult struct {
+	output []byte
+	err    error
+}
+
+// isWorkspacePattern сообщает, что аргумент - шаблон пакетов вида ./... или ./internal/...
+func isWorkspacePattern(arg string) bool {
+	return arg == "..." || strings.HasSuffix(arg, "/...")
+}
+
+// hasWorkspacePattern сообщает, что среди аргументов командной строки есть шаблон пакетов:
+// ggconfig ./... и ggconfig --check ./... запускают режим рабочего пространства
+func hasWorkspacePattern(args []string) bool {
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") && isWorkspacePattern(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWorkspace реализует режим ggconfig ./...: находит под шаблонами все директивы
+// //go:generate ggconfig и выполняет их одним запуском, параллельно, с тем же окружением,
+// что дает go generate (рабочая директория пакета, $GOFILE, $GOLINE, $GOPACKAGE). Директивы
+// выполняет этот же бинарник, поэтому все пакеты генерируются одной версией ggconfig.
+// Файлы записываются атомарно, так что общие выходные директории (--registry) безопасны
+func runWorkspace(args []string) error {
+	fs := flag.NewFlagSet("ggconfig ./...", flag.ExitOnError)
+	jobs := fs.Int("j", runtime.NumCPU(), "number of directives run in parallel")
+	verbose := fs.Bool("v", false, "print the output of every directive, not only of the failed ones")
+	check := fs.Bool("check", false, "pass --check to every directive: fail if any generated file is out of date")
+	dry := fs.Bool("dry-run", false, "pass --dry-run to every directive and print their diffs")
+	force := fs.Bool("force", false, "pass --force to every directive")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ggconfig [-j N] [-v] [--check] [--dry-run] [--force] ./... [more patterns]")
+		fs.PrintDefaults()
+	}
+	// Флаги допускаются и до, и после шаблонов
+	var patterns []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		patterns = append(patterns, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if *jobs < 1 {
+		*jobs = 1
+	}
+
+	var directives []workspaceDirective
+	for _, p := range patterns {
+		found, err := findWorkspaceDirectives(p)
+		if err != nil {
+			return err
+		}
+		directives = append(directives, found...)
+	}
+	if len(directives) == 0 {
+		return fmt.Errorf("no //go:generate ggconfig directives under %s", strings.Join(patterns, " "))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the ggconfig binary: %w", err)
+	}
+	var extra []string
+	if *check {
+		extra = append(extra, "--check")
+	}
+	if *dry {
+		extra = append(extra, "--dry-run")
+	}
+	if *force {
+		extra = append(extra, "--force")
+	}
+
+	start := time.Now()
+	results := make([]chan workspaceResult, len(directives))
+	for i := range results {
+		results[i] = make(chan workspaceResult, 1)
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				out, err := directives[i].run(exe, extra)
+				results[i] <- workspaceResult{out, err}
+			}
+		}()
+	}
+	go func() {
+		for i := range directives {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	// Результаты печатаются в порядке директив, по мере готовности
+	failed := 0
+	packages := map[string]bool{}
+	for i, d := range directives {
+		r := <-results[i]
+		packages[filepath.Dir(d.File)] = true
+		if r.err != nil {
+			failed++
+			fmt.Printf("❌ %s:%d: %v\n", d.File, d.Line, r.err)
+			os.Stdout.Write(r.output)
+			continue
+		}
+		fmt.Printf("✅ %s:%d\n", d.File, d.Line)
+		if *verbose || *dry {
+			os.Stdout.Write(r.output)
+		}
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d directives failed (%d packages, %s)", failed, len(directives), len(packages), elapsed)
+	}
+	fmt.Printf("✅ Ran %d ggconfig directives in %d packages (%s)\n", len(directives), len(packages), elapsed)
+	return nil
+}
+
+// run выполняет директиву, как go generate: в директории файла, с переменными $GOFILE, $GOLINE,
+// $GOPACKAGE, $GOOS, $GOARCH, $GOROOT и $DOLLAR, которые подставляются и в аргументы
+func (d workspaceDirective) run(exe string, extra []string) ([]byte, error) {
+	vars := map[string]string{
+		"GOFILE":    filepath.Base(d.File),
+		"GOLINE":    strconv.Itoa(d.Line),
+		"GOPACKAGE": d.Package,
+		"GOOS":      runtime.GOOS,
+		"GOARCH":    runtime.GOARCH,
+		"GOROOT":    runtime.GOROOT(),
+		"DOLLAR":    "$",
+	}
+	for _, name := range []string{"GOOS", "GOARCH"} {
+		if v := os.Getenv(name); v != "" {
+			vars[name] = v
+		}
+	}
+	expand := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+	var args []string
+	for _, a := range d.Args {
+		args = append(args, os.Expand(a, expand))
+	}
+
+	cmd := exec.Command(exe, append(args, extra...)...)
+	cmd.Dir = filepath.Dir(d.File)
+	cmd.Env = os.Environ()
+	for name, v := range vars {
+		cmd.Env = append(cmd.Env, name+"="+v)
+	}
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// findWorkspaceDirectives находит директивы ggconfig по шаблону: ./... - все пакеты под
+// текущей директорией, ./internal/... - под internal, путь без /... - один пакет. Обход
+// generator.WalkPackageDirs - тот же, что у go generate ./...
+func findWorkspaceDirectives(pattern string) ([]workspaceDirective, error) {
+	root, recursive := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/"), isWorkspacePattern(pattern)
+	if root == "" {
+		root = "."
+	}
+	if !recursive {
+		return scanWorkspaceDir(root)
+	}
+	var all []workspaceDirective
+	err := generator.WalkPackageDirs(root, func(dir string) error {
+		directives, err := scanWorkspaceDir(dir)
+		if err != nil {
+			return err
+		}
+		all = append(all, directives...)
+		return nil
+	})
+	return all, err
+}
+
+// scanWorkspaceDir находит директивы ggconfig в .go файлах директории (в том числе в _test.go,
+// как go generate); сгенерированные ggconfig файлы пропускаются
+func scanWorkspaceDir(dir string) ([]workspaceDirective, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var directives []workspaceDirective
+	for _, path := range files {
+		found, err := scanWorkspaceFile(path)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, found...)
+	}
+	return directives, nil
+}
+
+func scanWorkspaceFile(path string) ([]workspaceDirective, error) {
+	_, lines, err := generator.ScanDirectives(path)
+	if err != nil {
+		return nil, err
+	}
+	var directives []workspaceDirective
+	for _, l := range lines {
+		directives = append(directives, workspaceDirective{File: path, Line: l.Line, Args: l.Args})
+	}
+	if len(directives) > 0 {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, err
+		}
+		for i := range directives {
+			directives[i].Package = file.Name.Name
+		}
+	}
+	return directives, nil
+}